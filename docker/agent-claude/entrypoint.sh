@@ -32,15 +32,44 @@ if [ -n "$GIT_REPO_URL" ]; then
     git config --global user.email "agent@workgear.dev"
     git config --global user.name "WorkGear Agent"
 
-    # Clone
-    git clone "$GIT_REPO_URL" --branch "$BRANCH" --single-branch --depth 50 "$WORKSPACE" 2>&1 || {
-        echo "[agent] Failed to clone branch $BRANCH, trying default branch..."
-        git clone "$GIT_REPO_URL" --single-branch --depth 50 "$WORKSPACE" 2>&1
+    if [ -d "$WORKSPACE/.git" ]; then
+        # Shared per-flow workspace: keep the previous node's checkout (including uncommitted work)
+        echo "[agent] Reusing shared workspace checkout"
         cd "$WORKSPACE"
-        git checkout -b "$BRANCH"
-    }
+        git remote set-url origin "$GIT_REPO_URL"
+    else
+        # Clone (borrow objects from the orchestrator's repo mirror when mounted,
+        # so only objects newer than the last mirror fetch are downloaded)
+        CLONE_OPTS="--depth 50"
+        if [ -n "$GIT_REFERENCE_REPO" ] && [ -d "$GIT_REFERENCE_REPO" ]; then
+            echo "[agent] Using repo cache: $GIT_REFERENCE_REPO"
+            CLONE_OPTS="$CLONE_OPTS --reference-if-able $GIT_REFERENCE_REPO"
+            # A persistent workspace outlives this container's cache mount, so copy the borrowed objects
+            if [ "$WORKSPACE_PERSISTENT" = "true" ]; then
+                CLONE_OPTS="$CLONE_OPTS --dissociate"
+            fi
+        fi
+        git clone "$GIT_REPO_URL" --branch "$BRANCH" --single-branch $CLONE_OPTS "$WORKSPACE" 2>&1 || {
+            echo "[agent] Failed to clone branch $BRANCH, trying default branch..."
+            git clone "$GIT_REPO_URL" --single-branch $CLONE_OPTS "$WORKSPACE" 2>&1
+            cd "$WORKSPACE"
+            git checkout -b "$BRANCH"
+        }
+    fi
     cd "$WORKSPACE"
     echo "[agent] Repository cloned successfully."
+
+    # Persistent workspace: keep build/package caches inside the volume, out of git's view
+    if [ "$WORKSPACE_PERSISTENT" = "true" ]; then
+        CACHE_DIR="$WORKSPACE/.workgear-cache"
+        mkdir -p "$CACHE_DIR"
+        grep -qxF ".workgear-cache/" .git/info/exclude 2>/dev/null || echo ".workgear-cache/" >> .git/info/exclude
+        export XDG_CACHE_HOME="$CACHE_DIR"
+        export GOMODCACHE="$CACHE_DIR/go-mod"
+        export GOCACHE="$CACHE_DIR/go-build"
+        export npm_config_cache="$CACHE_DIR/npm"
+        export npm_config_store_dir="$CACHE_DIR/pnpm-store"
+    fi
 else
     echo "[agent] No GIT_REPO_URL configured, working in empty workspace."
     cd "$WORKSPACE"
@@ -68,6 +97,22 @@ if [ "$AGENT_MODE" = "test" ]; then
     fi
 fi
 
+# Tool approval gates: a PreToolUse hook pauses tool calls matching TOOL_APPROVAL_POLICY
+# until the orchestrator copies in a decision (see tool-gate.js)
+if [ -n "$TOOL_APPROVAL_POLICY" ]; then
+    GATE_TIMEOUT=$(( $(echo "$TOOL_APPROVAL_POLICY" | jq -r '.timeout_seconds // 1800') + 60 ))
+    mkdir -p "$HOME/.claude" /output/approvals
+    jq -n --argjson timeout "$GATE_TIMEOUT" '{
+        hooks: {
+            PreToolUse: [{
+                matcher: "*",
+                hooks: [{ type: "command", command: "node /usr/local/bin/tool-gate.js", timeout: $timeout }]
+            }]
+        }
+    }' > "$HOME/.claude/settings.json"
+    echo "[agent] Tool approval gates enabled ($(echo "$TOOL_APPROVAL_POLICY" | jq '.rules | length') rules)"
+fi
+
 # Build claude command
 CLAUDE_CMD="claude"
 CLAUDE_ARGS="-p --dangerously-skip-permissions"
@@ -80,22 +125,58 @@ fi
 # Add output format (stream-json for real-time log streaming)
 CLAUDE_ARGS="$CLAUDE_ARGS --output-format stream-json --verbose"
 
-# Execute claude with stream-json output
+# Claude stores sessions per working directory: ~/.claude/projects/<cwd with non-alphanumerics as ->
+SESSION_PROJECT_DIR="$HOME/.claude/projects/$(pwd | sed 's|[^a-zA-Z0-9]|-|g')"
+
+# run_claude <prompt> [extra args...]
 # Each line is a JSON event; we forward to stderr for Docker logs real-time reading
 # and extract the final "result" event for structured parsing
-$CLAUDE_CMD $CLAUDE_ARGS "$AGENT_PROMPT" 2>/tmp/claude_stderr.log | while IFS= read -r line; do
-    # Forward every line to stderr so Docker logs can stream it in real-time
-    echo "$line" >&2
-
-    # Parse JSON type field, save the last "result" event
-    TYPE=$(echo "$line" | jq -r '.type // empty' 2>/dev/null)
-    if [ "$TYPE" = "result" ]; then
-        echo "$line" > "$RESULT_FILE"
+run_claude() {
+    local PROMPT="$1"
+    shift
+    rm -f "$RESULT_FILE"
+    set +e
+    $CLAUDE_CMD $CLAUDE_ARGS "$@" "$PROMPT" 2>/tmp/claude_stderr.log | while IFS= read -r line; do
+        # Forward every line to stderr so Docker logs can stream it in real-time
+        echo "$line" >&2
+
+        # Parse JSON type field, save the last "result" event
+        TYPE=$(echo "$line" | jq -r '.type // empty' 2>/dev/null)
+        if [ "$TYPE" = "result" ]; then
+            echo "$line" > "$RESULT_FILE"
+        fi
+    done
+    local STATUS=${PIPESTATUS[0]}
+    set -e
+    return $STATUS
+}
+
+# Session continuation (re-run after a rejection): resume the previous conversation when the
+# orchestrator restored its session file to /output/session, otherwise start a new session.
+# The restored files are moved out so only this run's session is saved back.
+RESUMED=false
+if [ -n "$CLAUDE_RESUME_SESSION" ] && [ -f "/output/session/$CLAUDE_RESUME_SESSION.jsonl" ]; then
+    mkdir -p "$SESSION_PROJECT_DIR"
+    cp /output/session/*.jsonl "$SESSION_PROJECT_DIR/"
+    rm -rf /output/session
+    echo "[agent] Resuming session $CLAUDE_RESUME_SESSION"
+    RESUMED=true
+    run_claude "${AGENT_RESUME_PROMPT:-$AGENT_PROMPT}" --resume "$CLAUDE_RESUME_SESSION" && PIPE_STATUS=0 || PIPE_STATUS=$?
+    if [ "$PIPE_STATUS" != "0" ]; then
+        echo "[agent] Resuming session failed (exit code $PIPE_STATUS), starting a new session"
+        cat /tmp/claude_stderr.log 2>/dev/null
+        RESUMED=false
     fi
-done
+elif [ -n "$CLAUDE_RESUME_SESSION" ]; then
+    rm -rf /output/session
+    echo "[agent] Session $CLAUDE_RESUME_SESSION not available, starting a new session"
+fi
+
+if [ "$RESUMED" != "true" ]; then
+    run_claude "$AGENT_PROMPT" && PIPE_STATUS=0 || PIPE_STATUS=$?
+fi
 
 # Check pipeline exit status
-PIPE_STATUS=${PIPESTATUS[0]}
 if [ "$PIPE_STATUS" != "0" ]; then
     EXIT_CODE=$PIPE_STATUS
     echo "[agent] Claude CLI exited with code $EXIT_CODE"
@@ -104,6 +185,14 @@ if [ "$PIPE_STATUS" != "0" ]; then
     exit $EXIT_CODE
 fi
 
+# Save the session for continuation by a later run of this node
+SESSION_ID=$(jq -r '.session_id // empty' "$RESULT_FILE" 2>/dev/null || true)
+if [ -n "$SESSION_ID" ] && [ -f "$SESSION_PROJECT_DIR/$SESSION_ID.jsonl" ]; then
+    mkdir -p /output/session
+    cp "$SESSION_PROJECT_DIR/$SESSION_ID.jsonl" /output/session/
+    echo "[agent] Saved session $SESSION_ID"
+fi
+
 # Verify we got a result
 if [ ! -f "$RESULT_FILE" ]; then
     echo "[agent] Warning: No result event received from Claude CLI"
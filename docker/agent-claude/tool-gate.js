@@ -0,0 +1,88 @@
+#!/usr/bin/env node
+// Claude Code PreToolUse hook: pauses tool calls matching TOOL_APPROVAL_POLICY until a
+// human decides on them in WorkGear.
+//
+// Handshake with the orchestrator (file based, no network needed):
+//   1. write /output/approvals/<id>.json  {id, tool_name, tool_input, rule, reason}
+//   2. wait for /output/approvals/<id>.decision  {approved, reason}
+// Exit 0 lets the call run; exit 2 blocks it and shows stderr to the model.
+
+const fs = require('fs')
+const path = require('path')
+const crypto = require('crypto')
+
+// Both files live on the /output volume: the orchestrator cannot copy into the /tmp tmpfs
+const APPROVAL_DIR = '/output/approvals'
+const POLL_MS = 1000
+
+function readStdin() {
+  return new Promise((resolve) => {
+    let data = ''
+    process.stdin.setEncoding('utf8')
+    process.stdin.on('data', (chunk) => (data += chunk))
+    process.stdin.on('end', () => resolve(data))
+  })
+}
+
+function matchRule(rules, toolName, inputJSON) {
+  for (const rule of rules) {
+    try {
+      if (!new RegExp(`^(?:${rule.tool})$`).test(toolName)) continue
+      if (rule.input && !new RegExp(rule.input).test(inputJSON)) continue
+      return rule
+    } catch (err) {
+      process.stderr.write(`[tool-gate] invalid rule ${JSON.stringify(rule)}: ${err.message}\n`)
+    }
+  }
+  return null
+}
+
+async function main() {
+  const policy = JSON.parse(process.env.TOOL_APPROVAL_POLICY || '{}')
+  const event = JSON.parse((await readStdin()) || '{}')
+  const toolName = event.tool_name || ''
+  const toolInput = event.tool_input || {}
+
+  const rule = matchRule(policy.rules || [], toolName, JSON.stringify(toolInput))
+  if (!rule) process.exit(0)
+
+  const id = crypto.randomUUID()
+  const request = {
+    id,
+    tool_name: toolName,
+    tool_input: toolInput,
+    rule: rule.input ? `${rule.tool} ~ ${rule.input}` : rule.tool,
+    reason: rule.reason || '',
+  }
+  fs.mkdirSync(APPROVAL_DIR, { recursive: true })
+  const tmpFile = path.join(APPROVAL_DIR, `.${id}.tmp`)
+  fs.writeFileSync(tmpFile, JSON.stringify(request))
+  fs.renameSync(tmpFile, path.join(APPROVAL_DIR, `${id}.json`)) // never expose a partial file
+
+  const decisionFile = path.join(APPROVAL_DIR, `${id}.decision`)
+  const deadline = Date.now() + (policy.timeout_seconds || 1800) * 1000
+  while (Date.now() < deadline) {
+    if (fs.existsSync(decisionFile)) {
+      let decision
+      try {
+        decision = JSON.parse(fs.readFileSync(decisionFile, 'utf8'))
+      } catch {
+        await new Promise((r) => setTimeout(r, POLL_MS)) // still being written
+        continue
+      }
+      if (decision.approved) process.exit(0)
+      process.stderr.write(`Tool call denied by reviewer${decision.reason ? `: ${decision.reason}` : ''}. Do not retry it; find another way or explain why it is needed.\n`)
+      process.exit(2)
+    }
+    await new Promise((r) => setTimeout(r, POLL_MS))
+  }
+
+  process.stderr.write('Tool call was not approved in time. Do not retry it; find another way or explain why it is needed.\n')
+  process.exit(2)
+}
+
+main().catch((err) => {
+  // Fail closed: a broken gate must not let a gated call through
+  process.stderr.write(`[tool-gate] ${err.message}\n`)
+  process.exit(2)
+})
@@ -75,15 +75,44 @@ if [ -n "$GIT_REPO_URL" ]; then
     echo "[agent] Cloning repository..."
     BRANCH="${GIT_BRANCH:-main}"
 
-    # Clone
-    git clone "$GIT_REPO_URL" --branch "$BRANCH" --single-branch --depth 50 "$WORKSPACE" 2>&1 || {
-        echo "[agent] Failed to clone branch $BRANCH, trying default branch..."
-        git clone "$GIT_REPO_URL" --single-branch --depth 50 "$WORKSPACE" 2>&1
+    if [ -d "$WORKSPACE/.git" ]; then
+        # Shared per-flow workspace: keep the previous node's checkout (including uncommitted work)
+        echo "[agent] Reusing shared workspace checkout"
         cd "$WORKSPACE"
-        git checkout -b "$BRANCH"
-    }
+        git remote set-url origin "$GIT_REPO_URL"
+    else
+        # Clone (borrow objects from the orchestrator's repo mirror when mounted,
+        # so only objects newer than the last mirror fetch are downloaded)
+        CLONE_OPTS="--depth 50"
+        if [ -n "$GIT_REFERENCE_REPO" ] && [ -d "$GIT_REFERENCE_REPO" ]; then
+            echo "[agent] Using repo cache: $GIT_REFERENCE_REPO"
+            CLONE_OPTS="$CLONE_OPTS --reference-if-able $GIT_REFERENCE_REPO"
+            # A persistent workspace outlives this container's cache mount, so copy the borrowed objects
+            if [ "$WORKSPACE_PERSISTENT" = "true" ]; then
+                CLONE_OPTS="$CLONE_OPTS --dissociate"
+            fi
+        fi
+        git clone "$GIT_REPO_URL" --branch "$BRANCH" --single-branch $CLONE_OPTS "$WORKSPACE" 2>&1 || {
+            echo "[agent] Failed to clone branch $BRANCH, trying default branch..."
+            git clone "$GIT_REPO_URL" --single-branch $CLONE_OPTS "$WORKSPACE" 2>&1
+            cd "$WORKSPACE"
+            git checkout -b "$BRANCH"
+        }
+    fi
     cd "$WORKSPACE"
     echo "[agent] Repository cloned successfully."
+
+    # Persistent workspace: keep build/package caches inside the volume, out of git's view
+    if [ "$WORKSPACE_PERSISTENT" = "true" ]; then
+        CACHE_DIR="$WORKSPACE/.workgear-cache"
+        mkdir -p "$CACHE_DIR"
+        grep -qxF ".workgear-cache/" .git/info/exclude 2>/dev/null || echo ".workgear-cache/" >> .git/info/exclude
+        export XDG_CACHE_HOME="$CACHE_DIR"
+        export GOMODCACHE="$CACHE_DIR/go-mod"
+        export GOCACHE="$CACHE_DIR/go-build"
+        export npm_config_cache="$CACHE_DIR/npm"
+        export npm_config_store_dir="$CACHE_DIR/pnpm-store"
+    fi
 else
     echo "[agent] No GIT_REPO_URL configured, working in empty workspace."
     cd "$WORKSPACE"
@@ -137,8 +166,10 @@ if [ -n "$CODEX_SANDBOX" ]; then
     CODEX_ARGS="$CODEX_ARGS --sandbox $CODEX_SANDBOX"
 fi
 
-# Execute codex, tee stderr to both file and console for visibility
-$CODEX_CMD $CODEX_ARGS "$AGENT_PROMPT" > "$RESULT_FILE" 2> >(tee /tmp/codex_stderr.log >&2)
+# Execute codex: JSONL events (--json) go to stdout, which is redirected to stderr above,
+# so the orchestrator streams them live; the final message is written to the result file.
+# stderr is also teed to a file for error reporting.
+$CODEX_CMD $CODEX_ARGS --json --output-last-message "$RESULT_FILE" "$AGENT_PROMPT" 2> >(tee /tmp/codex_stderr.log >&2)
 
 # Check exit status
 CODEX_EXIT=$?
@@ -22,6 +22,17 @@ export interface AgentTypeDefinition {
   providerFields: ProviderField[]
 }
 
+/** 容器资源限制与安全选项（Docker 类 Agent 通用，均为可选） */
+const CONTAINER_FIELDS: ProviderField[] = [
+  { key: 'container_memory', label: '内存上限', type: 'string', required: false, placeholder: '2g' },
+  { key: 'container_cpus', label: 'CPU 上限', type: 'string', required: false, placeholder: '2' },
+  { key: 'container_pids_limit', label: '进程数上限', type: 'string', required: false, placeholder: '512' },
+  { key: 'container_network_mode', label: '网络模式', type: 'string', required: false, placeholder: 'bridge' },
+  { key: 'container_cap_drop', label: '移除的 Capabilities', type: 'string', required: false, placeholder: 'ALL' },
+  { key: 'container_read_only_rootfs', label: '只读根文件系统', type: 'select', required: false, options: ['false', 'true'] },
+  { key: 'container_no_new_privileges', label: 'no-new-privileges', type: 'select', required: false, options: ['false', 'true'] },
+]
+
 export const AGENT_TYPES: Record<string, AgentTypeDefinition> = {
   'claude-code': {
     name: 'ClaudeCode',
@@ -29,6 +40,7 @@ export const AGENT_TYPES: Record<string, AgentTypeDefinition> = {
     providerFields: [
       { key: 'base_url', label: 'Base URL', type: 'string', required: true, placeholder: 'https://api.anthropic.com' },
       { key: 'auth_token', label: 'Auth Token', type: 'secret', required: true },
+      ...CONTAINER_FIELDS,
     ],
   },
   'codex': {
@@ -37,6 +49,18 @@ export const AGENT_TYPES: Record<string, AgentTypeDefinition> = {
     providerFields: [
       { key: 'base_url', label: 'Base URL', type: 'string', required: true, placeholder: 'https://api.openai.com' },
       { key: 'api_key', label: 'API Key', type: 'secret', required: true },
+      ...CONTAINER_FIELDS,
+    ],
+  },
+  'http-chat': {
+    name: 'HTTP Chat',
+    description: '直接调用 Anthropic / OpenAI 兼容的 Chat API（无容器，仅适用于不需要仓库的节点）',
+    providerFields: [
+      { key: 'provider_type', label: 'API 格式', type: 'select', required: true, options: ['anthropic', 'openai'] },
+      { key: 'base_url', label: 'Base URL', type: 'string', required: true, placeholder: 'https://api.anthropic.com' },
+      { key: 'api_key', label: 'API Key', type: 'secret', required: true },
+      { key: 'max_tokens', label: 'Max Tokens', type: 'string', required: false, placeholder: '8192' },
+      { key: 'timeout', label: '请求超时', type: 'string', required: false, placeholder: '5m' },
     ],
   },
   'droid': {
@@ -0,0 +1,10 @@
+-- 创建 node_run_logs 表（节点日志分批追加）
+CREATE TABLE "node_run_logs" (
+  "node_run_id" uuid NOT NULL,
+  "seq" bigint NOT NULL,
+  "event" jsonb NOT NULL,
+  "created_at" timestamp with time zone DEFAULT now() NOT NULL,
+  CONSTRAINT "node_run_logs_pk" PRIMARY KEY("node_run_id", "seq")
+);
+--> statement-breakpoint
+ALTER TABLE "node_run_logs" ADD CONSTRAINT "node_run_logs_node_run_id_node_runs_id_fkey" FOREIGN KEY ("node_run_id") REFERENCES "node_runs"("id") ON DELETE CASCADE;
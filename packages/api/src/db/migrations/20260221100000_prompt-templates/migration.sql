@@ -0,0 +1,22 @@
+-- 创建 prompt_templates 表（Prompt 模板库，按名称 + 版本存储，project_id 为空表示全局模板）
+CREATE TABLE "prompt_templates" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+  "project_id" uuid,
+  "name" varchar(100) NOT NULL,
+  "version" integer NOT NULL,
+  "content" text NOT NULL,
+  "description" text,
+  "created_by" uuid,
+  "created_at" timestamp with time zone DEFAULT now() NOT NULL,
+  CONSTRAINT "prompt_templates_project_name_version" UNIQUE NULLS NOT DISTINCT("project_id", "name", "version")
+);
+--> statement-breakpoint
+ALTER TABLE "prompt_templates" ADD CONSTRAINT "prompt_templates_project_id_projects_id_fkey" FOREIGN KEY ("project_id") REFERENCES "projects"("id") ON DELETE CASCADE;
+--> statement-breakpoint
+ALTER TABLE "prompt_templates" ADD CONSTRAINT "prompt_templates_created_by_users_id_fkey" FOREIGN KEY ("created_by") REFERENCES "users"("id") ON DELETE SET NULL;
+--> statement-breakpoint
+CREATE INDEX "idx_prompt_templates_name" ON "prompt_templates" ("name");
+--> statement-breakpoint
+
+-- node_runs 记录本次执行实际使用的模板版本
+ALTER TABLE "node_runs" ADD COLUMN "prompt_versions" jsonb;
@@ -0,0 +1,2 @@
+-- node_runs 记录最终 Prompt 大小（字符数、估算 token 数、预算、被截断的部分）
+ALTER TABLE "node_runs" ADD COLUMN "prompt_stats" jsonb;
@@ -0,0 +1,10 @@
+-- 创建 node_run_sessions 表（Agent CLI 会话，打回后重新执行时恢复会话）
+CREATE TABLE "node_run_sessions" (
+  "node_run_id" uuid PRIMARY KEY NOT NULL,
+  "session_id" varchar(100) NOT NULL,
+  "agent_type" varchar(50) NOT NULL,
+  "state" bytea,
+  "created_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "node_run_sessions" ADD CONSTRAINT "node_run_sessions_node_run_id_node_runs_id_fkey" FOREIGN KEY ("node_run_id") REFERENCES "node_runs"("id") ON DELETE CASCADE;
@@ -0,0 +1,20 @@
+-- 创建 tool_approvals 表（Agent 工具调用审批：命中节点 tool_approval 规则的调用暂停，等待人工批准/拒绝）
+CREATE TABLE "tool_approvals" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "node_run_id" uuid NOT NULL,
+  "tool_name" varchar(200) NOT NULL,
+  "tool_input" jsonb,
+  "rule" text,
+  "reason" text,
+  "status" varchar(20) DEFAULT 'pending' NOT NULL,
+  "decision_reason" text,
+  "decided_by" uuid,
+  "requested_at" timestamp with time zone DEFAULT now() NOT NULL,
+  "decided_at" timestamp with time zone
+);
+--> statement-breakpoint
+ALTER TABLE "tool_approvals" ADD CONSTRAINT "tool_approvals_node_run_id_node_runs_id_fkey" FOREIGN KEY ("node_run_id") REFERENCES "node_runs"("id") ON DELETE CASCADE;
+--> statement-breakpoint
+ALTER TABLE "tool_approvals" ADD CONSTRAINT "tool_approvals_decided_by_users_id_fkey" FOREIGN KEY ("decided_by") REFERENCES "users"("id") ON DELETE SET NULL;
+--> statement-breakpoint
+CREATE INDEX "idx_tool_approvals_node_run" ON "tool_approvals" ("node_run_id");
@@ -0,0 +1,2 @@
+-- node_runs 增加 reviewed_by（审核人：用户 ID 或 "service"，由 gRPC 调用方身份写入）
+ALTER TABLE "node_runs" ADD COLUMN "reviewed_by" varchar(100);
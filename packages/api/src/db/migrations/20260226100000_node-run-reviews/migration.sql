@@ -0,0 +1,24 @@
+-- 创建 node_run_reviews 表（每次人工审核追加一行：审核人、严重程度、逐文件/逐行意见、检查项结果）
+CREATE TABLE "node_run_reviews" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "node_run_id" uuid NOT NULL,
+  "flow_run_id" uuid NOT NULL,
+  "node_id" varchar(100) NOT NULL,
+  "attempt" integer DEFAULT 1 NOT NULL,
+  "action" varchar(50) NOT NULL,
+  "comment" text,
+  "severity" varchar(20),
+  "comments" jsonb DEFAULT '[]'::jsonb NOT NULL,
+  "checklist" jsonb DEFAULT '[]'::jsonb NOT NULL,
+  "reviewer_id" varchar(100),
+  "reviewer_name" varchar(200),
+  "created_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "node_run_reviews" ADD CONSTRAINT "node_run_reviews_node_run_id_node_runs_id_fkey" FOREIGN KEY ("node_run_id") REFERENCES "node_runs"("id") ON DELETE CASCADE;
+--> statement-breakpoint
+ALTER TABLE "node_run_reviews" ADD CONSTRAINT "node_run_reviews_flow_run_id_flow_runs_id_fkey" FOREIGN KEY ("flow_run_id") REFERENCES "flow_runs"("id") ON DELETE CASCADE;
+--> statement-breakpoint
+CREATE INDEX "idx_node_run_reviews_flow_run" ON "node_run_reviews" ("flow_run_id", "created_at");
+--> statement-breakpoint
+CREATE INDEX "idx_node_run_reviews_node_run" ON "node_run_reviews" ("node_run_id");
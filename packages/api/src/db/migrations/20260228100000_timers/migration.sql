@@ -0,0 +1,19 @@
+-- 创建 timers 表（持久化定时器：到期后由 Orchestrator 调度器按 kind 分发给对应处理器，多实例下以 FOR UPDATE SKIP LOCKED 领取）
+CREATE TABLE "timers" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "kind" varchar(50) NOT NULL,
+  "key" varchar(200),
+  "fire_at" timestamp with time zone NOT NULL,
+  "payload" jsonb DEFAULT '{}'::jsonb NOT NULL,
+  "status" varchar(20) DEFAULT 'pending' NOT NULL,
+  "attempts" integer DEFAULT 0 NOT NULL,
+  "last_error" text,
+  "locked_by" varchar(100),
+  "locked_at" timestamp with time zone,
+  "created_at" timestamp with time zone DEFAULT now() NOT NULL,
+  "completed_at" timestamp with time zone
+);
+--> statement-breakpoint
+CREATE INDEX "idx_timers_due" ON "timers" ("status", "fire_at");
+--> statement-breakpoint
+CREATE INDEX "idx_timers_key" ON "timers" ("key");
@@ -0,0 +1,26 @@
+-- 创建 flow_schedules 表（按 cron 定时为任务启动流程，到期由 Orchestrator 的 flow_schedule 定时器触发）
+CREATE TABLE "flow_schedules" (
+  "id" uuid PRIMARY KEY NOT NULL,
+  "task_id" uuid NOT NULL,
+  "workflow_id" uuid NOT NULL,
+  "cron" varchar(100) NOT NULL,
+  "timezone" varchar(64) DEFAULT 'UTC' NOT NULL,
+  "variables" jsonb DEFAULT '{}'::jsonb NOT NULL,
+  "enabled" boolean DEFAULT true NOT NULL,
+  "next_run_at" timestamp with time zone,
+  "last_run_at" timestamp with time zone,
+  "last_flow_run_id" uuid,
+  "created_by" uuid,
+  "created_at" timestamp with time zone DEFAULT now() NOT NULL,
+  "updated_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+--> statement-breakpoint
+ALTER TABLE "flow_schedules" ADD CONSTRAINT "flow_schedules_task_id_tasks_id_fkey" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE;
+--> statement-breakpoint
+ALTER TABLE "flow_schedules" ADD CONSTRAINT "flow_schedules_workflow_id_workflows_id_fkey" FOREIGN KEY ("workflow_id") REFERENCES "workflows"("id") ON DELETE CASCADE;
+--> statement-breakpoint
+ALTER TABLE "flow_schedules" ADD CONSTRAINT "flow_schedules_last_flow_run_id_flow_runs_id_fkey" FOREIGN KEY ("last_flow_run_id") REFERENCES "flow_runs"("id") ON DELETE SET NULL;
+--> statement-breakpoint
+ALTER TABLE "flow_schedules" ADD CONSTRAINT "flow_schedules_created_by_users_id_fkey" FOREIGN KEY ("created_by") REFERENCES "users"("id") ON DELETE SET NULL;
+--> statement-breakpoint
+CREATE INDEX "idx_flow_schedules_task" ON "flow_schedules" ("task_id");
@@ -0,0 +1,9 @@
+-- 将旧的 node_runs.log_stream 日志迁入 node_run_logs（按数组顺序编号 seq），随后删除该列
+INSERT INTO "node_run_logs" ("node_run_id", "seq", "event")
+SELECT nr."id", e."ord", e."event" || jsonb_build_object('seq', e."ord")
+FROM "node_runs" nr,
+  jsonb_array_elements(nr."log_stream") WITH ORDINALITY AS e("event", "ord")
+WHERE jsonb_typeof(nr."log_stream") = 'array' AND jsonb_typeof(e."event") = 'object'
+ON CONFLICT ("node_run_id", "seq") DO NOTHING;
+--> statement-breakpoint
+ALTER TABLE "node_runs" DROP COLUMN "log_stream";
@@ -1,4 +1,4 @@
-import { pgTable, uuid, varchar, text, integer, boolean, timestamp, jsonb, unique, index } from 'drizzle-orm/pg-core'
+import { pgTable, uuid, varchar, text, integer, bigint, boolean, timestamp, jsonb, unique, index, primaryKey, customType } from 'drizzle-orm/pg-core'
 
 // ============================================================
 // 用户表
@@ -151,6 +151,27 @@ export const flowRuns = pgTable('flow_runs', {
   index('idx_flow_runs_task_id').on(table.taskId),
 ])
 
+// ============================================================
+// 流程定时启动表（cron）
+// ============================================================
+export const flowSchedules = pgTable('flow_schedules', {
+  id: uuid('id').primaryKey(),
+  taskId: uuid('task_id').notNull().references(() => tasks.id, { onDelete: 'cascade' }),
+  workflowId: uuid('workflow_id').notNull().references(() => workflows.id, { onDelete: 'cascade' }),
+  cron: varchar('cron', { length: 100 }).notNull(), // 5-field cron expression or @daily / @hourly ...
+  timezone: varchar('timezone', { length: 64 }).notNull().default('UTC'),
+  variables: jsonb('variables').notNull().default({}), // overrides the workflow's template params
+  enabled: boolean('enabled').notNull().default(true),
+  nextRunAt: timestamp('next_run_at', { withTimezone: true }),
+  lastRunAt: timestamp('last_run_at', { withTimezone: true }),
+  lastFlowRunId: uuid('last_flow_run_id').references(() => flowRuns.id, { onDelete: 'set null' }),
+  createdBy: uuid('created_by').references(() => users.id, { onDelete: 'set null' }),
+  createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
+  updatedAt: timestamp('updated_at', { withTimezone: true }).defaultNow().notNull(),
+}, (table) => [
+  index('idx_flow_schedules_task').on(table.taskId),
+])
+
 // ============================================================
 // 节点执行表
 // ============================================================
@@ -169,16 +190,107 @@ export const nodeRuns = pgTable('node_runs', {
   lockedAt: timestamp('locked_at', { withTimezone: true }),
   reviewAction: varchar('review_action', { length: 50 }),
   reviewComment: text('review_comment'),
+  reviewedBy: varchar('reviewed_by', { length: 100 }),
   reviewedAt: timestamp('reviewed_at', { withTimezone: true }),
   startedAt: timestamp('started_at', { withTimezone: true }),
   completedAt: timestamp('completed_at', { withTimezone: true }),
   recoveryCheckpoint: jsonb('recovery_checkpoint'),
-  logStream: jsonb('log_stream'),
+  promptVersions: jsonb('prompt_versions'), // [{name, version, id, scope}]：本次执行使用的 Prompt 模板版本
+  promptStats: jsonb('prompt_stats'), // {chars, estimated_tokens, budget, truncated}：最终 Prompt 大小
   createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
 }, (table) => [
   index('idx_node_runs_flow_run_id').on(table.flowRunId),
 ])
 
+// ============================================================
+// 节点执行日志表（分批追加，按 seq 分页读取）
+// ============================================================
+export const nodeRunLogs = pgTable('node_run_logs', {
+  nodeRunId: uuid('node_run_id').notNull().references(() => nodeRuns.id, { onDelete: 'cascade' }),
+  seq: bigint('seq', { mode: 'number' }).notNull(),
+  event: jsonb('event').notNull(),
+  createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
+}, (table) => [
+  primaryKey({ name: 'node_run_logs_pk', columns: [table.nodeRunId, table.seq] }),
+])
+
+// ============================================================
+// 节点 Agent 会话表（打回后重新执行时恢复 CLI 会话）
+// ============================================================
+const bytea = customType<{ data: Buffer }>({
+  dataType() {
+    return 'bytea'
+  },
+})
+
+export const nodeRunSessions = pgTable('node_run_sessions', {
+  nodeRunId: uuid('node_run_id').primaryKey().references(() => nodeRuns.id, { onDelete: 'cascade' }),
+  sessionId: varchar('session_id', { length: 100 }).notNull(),
+  agentType: varchar('agent_type', { length: 50 }).notNull(),
+  state: bytea('state'), // CLI 会话文件的 tar 包
+  createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
+})
+
+// Agent 工具调用审批（命中节点 tool_approval 规则的调用，等待人工决定）
+export const toolApprovals = pgTable('tool_approvals', {
+  id: uuid('id').primaryKey(), // 由容器内 hook 生成
+  nodeRunId: uuid('node_run_id').notNull().references(() => nodeRuns.id, { onDelete: 'cascade' }),
+  toolName: varchar('tool_name', { length: 200 }).notNull(),
+  toolInput: jsonb('tool_input'),
+  rule: text('rule'),
+  reason: text('reason'),
+  status: varchar('status', { length: 20 }).notNull().default('pending'), // pending / approved / denied / expired
+  decisionReason: text('decision_reason'),
+  decidedBy: uuid('decided_by').references(() => users.id, { onDelete: 'set null' }),
+  requestedAt: timestamp('requested_at', { withTimezone: true }).defaultNow().notNull(),
+  decidedAt: timestamp('decided_at', { withTimezone: true }),
+}, (table) => [
+  index('idx_tool_approvals_node_run').on(table.nodeRunId),
+])
+
+// ============================================================
+// 节点审核记录表（每次人工审核追加一行）
+// ============================================================
+export const nodeRunReviews = pgTable('node_run_reviews', {
+  id: uuid('id').primaryKey(),
+  nodeRunId: uuid('node_run_id').notNull().references(() => nodeRuns.id, { onDelete: 'cascade' }),
+  flowRunId: uuid('flow_run_id').notNull().references(() => flowRuns.id, { onDelete: 'cascade' }),
+  nodeId: varchar('node_id', { length: 100 }).notNull(),
+  attempt: integer('attempt').notNull().default(1),
+  action: varchar('action', { length: 50 }).notNull(), // approve / reject / edit_and_approve
+  comment: text('comment'),
+  severity: varchar('severity', { length: 20 }), // info / minor / major / critical
+  comments: jsonb('comments').notNull().default([]), // [{file, line, end_line, body, severity}]
+  checklist: jsonb('checklist').notNull().default([]), // [{item, passed, note}]
+  reviewerId: varchar('reviewer_id', { length: 100 }),
+  reviewerName: varchar('reviewer_name', { length: 200 }),
+  createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
+}, (table) => [
+  index('idx_node_run_reviews_flow_run').on(table.flowRunId, table.createdAt),
+  index('idx_node_run_reviews_node_run').on(table.nodeRunId),
+])
+
+// ============================================================
+// 定时器表（持久化定时器：节点提醒等，由 Orchestrator 调度器触发）
+// ============================================================
+export const timers = pgTable('timers', {
+  id: uuid('id').primaryKey(),
+  kind: varchar('kind', { length: 50 }).notNull(), // node_reminder / ...
+  key: varchar('key', { length: 200 }), // grouping key for cancellation, e.g. node_run:<id>
+  fireAt: timestamp('fire_at', { withTimezone: true }).notNull(),
+  payload: jsonb('payload').notNull().default({}),
+  status: varchar('status', { length: 20 }).notNull().default('pending'), // pending / firing / done / failed / cancelled
+  attempts: integer('attempts').notNull().default(0),
+  lastError: text('last_error'),
+  lockedBy: varchar('locked_by', { length: 100 }),
+  lockedAt: timestamp('locked_at', { withTimezone: true }),
+  createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
+  completedAt: timestamp('completed_at', { withTimezone: true }),
+}, (table) => [
+  index('idx_timers_due').on(table.status, table.fireAt),
+  index('idx_timers_key').on(table.key),
+])
+
 // ============================================================
 // 节点执行历史表
 // ============================================================
@@ -301,3 +413,20 @@ export const agentRoles = pgTable('agent_roles', {
   createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
   updatedAt: timestamp('updated_at', { withTimezone: true }).defaultNow().notNull(),
 })
+
+// ============================================================
+// Prompt 模板库（按名称 + 版本存储，project_id 为空表示全局模板）
+// ============================================================
+export const promptTemplates = pgTable('prompt_templates', {
+  id: uuid('id').primaryKey().defaultRandom(),
+  projectId: uuid('project_id').references(() => projects.id, { onDelete: 'cascade' }),
+  name: varchar('name', { length: 100 }).notNull(),
+  version: integer('version').notNull(),
+  content: text('content').notNull(),
+  description: text('description'),
+  createdBy: uuid('created_by').references(() => users.id, { onDelete: 'set null' }),
+  createdAt: timestamp('created_at', { withTimezone: true }).defaultNow().notNull(),
+}, (table) => [
+  unique('prompt_templates_project_name_version').on(table.projectId, table.name, table.version).nullsNotDistinct(),
+  index('idx_prompt_templates_name').on(table.name),
+])
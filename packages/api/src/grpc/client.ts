@@ -1,5 +1,7 @@
 import * as grpc from '@grpc/grpc-js'
 import * as protoLoader from '@grpc/proto-loader'
+import { createHmac } from 'crypto'
+import { readFileSync } from 'fs'
 import path from 'path'
 import { fileURLToPath } from 'url'
 
@@ -18,71 +20,226 @@ const proto = grpc.loadPackageDefinition(packageDefinition) as any
 
 const ORCHESTRATOR_URL = process.env.ORCHESTRATOR_URL || 'localhost:50051'
 
+// ─── Transport security & authentication ───
+
+// ORCHESTRATOR_TLS_CA enables TLS; ORCHESTRATOR_TLS_CERT / ORCHESTRATOR_TLS_KEY add a client certificate (mTLS)
+function channelCredentials(): grpc.ChannelCredentials {
+  const ca = process.env.ORCHESTRATOR_TLS_CA
+  if (!ca) return grpc.credentials.createInsecure()
+  const cert = process.env.ORCHESTRATOR_TLS_CERT
+  const key = process.env.ORCHESTRATOR_TLS_KEY
+  return grpc.credentials.createSsl(
+    readFileSync(ca),
+    key ? readFileSync(key) : null,
+    cert ? readFileSync(cert) : null,
+  )
+}
+
+/** The user on whose behalf a call is made (recorded as reviewer / actor by the orchestrator) */
+export interface Actor {
+  userId: string
+  email?: string
+  name?: string
+  roles: string[] // orchestrator roles, see orchestratorRoles
+}
+
+/**
+ * orchestratorRoles maps the user's role in the project a call concerns to orchestrator roles:
+ * members start / cancel flows and review them, everyone else may only read.
+ * admin (agent tests, registry reloads) is never derived from a membership.
+ */
+export function orchestratorRoles(projectRole: string | null): string[] {
+  return projectRole ? ['operator', 'reviewer'] : ['viewer']
+}
+
+/** actorOf returns the actor of an authenticated request (undefined if anonymous) */
+export function actorOf(request: { userId?: string; userEmail?: string }, projectRole: string | null): Actor | undefined {
+  return request.userId
+    ? { userId: request.userId, email: request.userEmail, roles: orchestratorRoles(projectRole) }
+    : undefined
+}
+
+const JWT_TTL_SECONDS = 300
+
+function signJwt(claims: Record<string, unknown>, secret: string): string {
+  const encode = (value: object) => Buffer.from(JSON.stringify(value)).toString('base64url')
+  const unsigned = `${encode({ alg: 'HS256', typ: 'JWT' })}.${encode(claims)}`
+  const signature = createHmac('sha256', secret).update(unsigned).digest('base64url')
+  return `${unsigned}.${signature}`
+}
+
+// Per-call credentials: a short-lived JWT for the acting user (ORCHESTRATOR_JWT_SECRET),
+// otherwise the shared ORCHESTRATOR_TOKEN. The JWT carries the actor's roles; calls the API
+// server makes on its own behalf get serviceRoles (read-only unless the RPC needs more).
+function callMetadata(actor?: Actor, serviceRoles: string[] = ['viewer']): grpc.Metadata {
+  const metadata = new grpc.Metadata()
+  const secret = process.env.ORCHESTRATOR_JWT_SECRET
+  if (secret) {
+    const now = Math.floor(Date.now() / 1000)
+    const claims: Record<string, unknown> = {
+      sub: actor?.userId || 'api-server',
+      roles: actor ? actor.roles : serviceRoles,
+      iat: now,
+      exp: now + JWT_TTL_SECONDS,
+    }
+    if (actor?.email) claims.email = actor.email
+    if (actor?.name) claims.name = actor.name
+    if (process.env.ORCHESTRATOR_JWT_AUDIENCE) claims.aud = process.env.ORCHESTRATOR_JWT_AUDIENCE
+    metadata.set('authorization', `Bearer ${signJwt(claims, secret)}`)
+  } else if (process.env.ORCHESTRATOR_TOKEN) {
+    metadata.set('authorization', `Bearer ${process.env.ORCHESTRATOR_TOKEN}`)
+  }
+  return metadata
+}
+
 // Create gRPC client
 const client = new proto.orchestrator.OrchestratorService(
   ORCHESTRATOR_URL,
-  grpc.credentials.createInsecure(),
+  channelCredentials(),
 )
 
 // ─── Promisified client methods ───
 
-export function startFlow(flowRunId: string, workflowDsl: string, variables: Record<string, string>, taskId: string, workflowId: string): Promise<{ success: boolean; error?: string }> {
+export function startFlow(flowRunId: string, workflowDsl: string, variables: Record<string, string>, taskId: string, workflowId: string, actor?: Actor): Promise<{ success: boolean; error?: string }> {
+  return new Promise((resolve, reject) => {
+    client.StartFlow({ flowRunId, workflowDsl, variables, taskId, workflowId }, callMetadata(actor), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export function cancelFlow(flowRunId: string, actor?: Actor): Promise<{ success: boolean; error?: string }> {
+  return new Promise((resolve, reject) => {
+    client.CancelFlow({ flowRunId }, callMetadata(actor), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+// ─── Flow Schedules ───
+// 时间字段为 Unix 毫秒（int64 以字符串返回），'0' 表示未设置
+
+export interface FlowScheduleInfo {
+  id: string
+  taskId: string
+  workflowId: string
+  cron: string
+  timezone: string
+  variables: Record<string, string>
+  enabled: boolean
+  nextRunAt: string
+  lastRunAt: string
+  lastFlowRunId: string
+  createdBy: string
+  createdAt: string
+}
+
+export interface ScheduleFlowParams {
+  taskId: string
+  workflowId: string
+  cron: string
+  timezone?: string
+  variables?: Record<string, string>
+  disabled?: boolean
+}
+
+export function scheduleFlow(params: ScheduleFlowParams, actor?: Actor): Promise<{ success: boolean; error?: string; schedule?: FlowScheduleInfo }> {
+  return new Promise((resolve, reject) => {
+    client.ScheduleFlow(params, callMetadata(actor), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export function listFlowSchedules(taskId = ''): Promise<{ success: boolean; error?: string; schedules: FlowScheduleInfo[] }> {
+  return new Promise((resolve, reject) => {
+    client.ListFlowSchedules({ taskId }, callMetadata(), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export function deleteFlowSchedule(scheduleId: string, actor?: Actor): Promise<{ success: boolean; error?: string }> {
+  return new Promise((resolve, reject) => {
+    client.DeleteFlowSchedule({ scheduleId }, callMetadata(actor), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+/** Structured review data recorded with approve / reject / edit (one node_run_reviews row per action) */
+export interface ReviewDetails {
+  reviewerId?: string
+  reviewerName?: string
+  severity?: 'info' | 'minor' | 'major' | 'critical'
+  comments?: { file?: string; line?: number; endLine?: number; body: string; severity?: string }[]
+  checklist?: { item: string; passed: boolean; note?: string }[]
+}
+
+export function approveNode(nodeRunId: string, review?: ReviewDetails, actor?: Actor): Promise<{ success: boolean; error?: string }> {
   return new Promise((resolve, reject) => {
-    client.StartFlow({ flowRunId, workflowDsl, variables, taskId, workflowId }, (err: any, response: any) => {
+    client.ApproveNode({ nodeRunId, review }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
   })
 }
 
-export function cancelFlow(flowRunId: string): Promise<{ success: boolean; error?: string }> {
+export function rejectNode(nodeRunId: string, feedback: string, review?: ReviewDetails, actor?: Actor): Promise<{ success: boolean; error?: string }> {
   return new Promise((resolve, reject) => {
-    client.CancelFlow({ flowRunId }, (err: any, response: any) => {
+    client.RejectNode({ nodeRunId, feedback, review }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
   })
 }
 
-export function approveNode(nodeRunId: string): Promise<{ success: boolean; error?: string }> {
+export function editNode(nodeRunId: string, editedContent: string, changeSummary: string, review?: ReviewDetails, actor?: Actor): Promise<{ success: boolean; error?: string }> {
   return new Promise((resolve, reject) => {
-    client.ApproveNode({ nodeRunId }, (err: any, response: any) => {
+    client.EditNode({ nodeRunId, editedContent, changeSummary, review }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
   })
 }
 
-export function rejectNode(nodeRunId: string, feedback: string): Promise<{ success: boolean; error?: string }> {
+export function submitHumanInput(nodeRunId: string, dataJson: string, actor?: Actor): Promise<{ success: boolean; error?: string; fieldErrors?: Record<string, string> }> {
   return new Promise((resolve, reject) => {
-    client.RejectNode({ nodeRunId, feedback }, (err: any, response: any) => {
+    client.SubmitHumanInput({ nodeRunId, dataJson }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
   })
 }
 
-export function editNode(nodeRunId: string, editedContent: string, changeSummary: string): Promise<{ success: boolean; error?: string }> {
+export function retryNode(nodeRunId: string, actor?: Actor): Promise<{ success: boolean; error?: string }> {
   return new Promise((resolve, reject) => {
-    client.EditNode({ nodeRunId, editedContent, changeSummary }, (err: any, response: any) => {
+    client.RetryNode({ nodeRunId }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
   })
 }
 
-export function submitHumanInput(nodeRunId: string, dataJson: string): Promise<{ success: boolean; error?: string }> {
+// ─── Tool Call Approval ───
+
+export function approveToolCall(approvalId: string, reason: string, decidedBy: string, actor?: Actor): Promise<{ success: boolean; error?: string }> {
   return new Promise((resolve, reject) => {
-    client.SubmitHumanInput({ nodeRunId, dataJson }, (err: any, response: any) => {
+    client.ApproveToolCall({ approvalId, reason, decidedBy }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
   })
 }
 
-export function retryNode(nodeRunId: string): Promise<{ success: boolean; error?: string }> {
+export function denyToolCall(approvalId: string, reason: string, decidedBy: string, actor?: Actor): Promise<{ success: boolean; error?: string }> {
   return new Promise((resolve, reject) => {
-    client.RetryNode({ nodeRunId }, (err: any, response: any) => {
+    client.DenyToolCall({ approvalId, reason, decidedBy }, callMetadata(actor), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
@@ -110,7 +267,179 @@ export interface TestAgentResult {
 
 export function testAgent(params: TestAgentParams): Promise<TestAgentResult> {
   return new Promise((resolve, reject) => {
-    client.TestAgent(params, { deadline: Date.now() + 120_000 }, (err: any, response: any) => {
+    client.TestAgent(params, callMetadata(undefined, ['admin']), { deadline: Date.now() + 120_000 }, (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+// ─── Agent Registry ───
+
+export interface ReloadAgentRegistryResult {
+  success: boolean
+  error?: string
+  providers: number
+  reusedProviders: number
+  roles: number
+  skipped: string[]
+}
+
+export function reloadAgentRegistry(): Promise<ReloadAgentRegistryResult> {
+  return new Promise((resolve, reject) => {
+    client.ReloadAgentRegistry({}, callMetadata(undefined, ['admin']), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export interface AgentRoleResolution {
+  role: string
+  agentType: string
+  inDatabase: boolean
+  providerId: string
+  providerName: string
+  modelName: string
+  providerSource: string
+  modelSource: string
+  resolved: boolean
+  adapterReady: boolean
+  reason: string
+}
+
+export function describeAgentRoles(): Promise<{ success: boolean; error?: string; roles: AgentRoleResolution[] }> {
+  return new Promise((resolve, reject) => {
+    client.DescribeAgentRoles({}, callMetadata(), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+// ─── Node Logs ───
+
+export interface NodeRunLogsResult {
+  success: boolean
+  error?: string
+  entries: { seq: string; eventJson: string }[]
+  nextSeq: string
+  hasMore: boolean
+}
+
+export function getNodeRunLogs(nodeRunId: string, afterSeq = 0, limit = 0): Promise<NodeRunLogsResult> {
+  return new Promise((resolve, reject) => {
+    client.GetNodeRunLogs({ nodeRunId, afterSeq, limit }, callMetadata(), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+// ─── Run Queries ───
+// 时间字段为 Unix 毫秒（int64 以字符串返回），'0' 表示未设置
+
+export interface FlowRunInfo {
+  id: string
+  taskId: string
+  workflowId: string
+  status: string
+  error: string
+  variablesJson: string
+  startedAt: string
+  completedAt: string
+  createdAt: string
+}
+
+export interface FlowDag {
+  nodes: { id: string; name: string; type: string; agentRole: string; dependsOn: string[] }[]
+  edges: { from: string; to: string }[]
+}
+
+export interface NodeRunInfo {
+  id: string
+  flowRunId: string
+  nodeId: string
+  nodeType: string
+  nodeName: string
+  status: string
+  attempt: number
+  inputJson: string
+  outputJson: string
+  error: string
+  reviewAction: string
+  reviewComment: string
+  reviewedAt: string
+  reviewedBy: string
+  startedAt: string
+  completedAt: string
+  createdAt: string
+}
+
+export interface TimelineEventInfo {
+  id: string
+  taskId: string
+  flowRunId: string
+  nodeRunId: string
+  eventType: string
+  contentJson: string
+  createdAt: string
+}
+
+export function getFlowRun(flowRunId: string, includeDag = false): Promise<{ success: boolean; error?: string; flowRun?: FlowRunInfo; dag?: FlowDag }> {
+  return new Promise((resolve, reject) => {
+    client.GetFlowRun({ flowRunId, includeDag }, callMetadata(), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export interface ListNodeRunsFilter {
+  statuses?: string[]
+  nodeId?: string
+  attempt?: number
+  latestOnly?: boolean
+}
+
+export function listNodeRuns(flowRunId: string, filter: ListNodeRunsFilter = {}): Promise<{ success: boolean; error?: string; nodeRuns: NodeRunInfo[] }> {
+  return new Promise((resolve, reject) => {
+    client.ListNodeRuns({ flowRunId, ...filter }, callMetadata(), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export function getNodeRun(nodeRunId: string): Promise<{ success: boolean; error?: string; nodeRun?: NodeRunInfo; attempts: NodeRunInfo[] }> {
+  return new Promise((resolve, reject) => {
+    client.GetNodeRun({ nodeRunId }, callMetadata(), (err: any, response: any) => {
+      if (err) return reject(err)
+      resolve(response)
+    })
+  })
+}
+
+export interface ListTimelineQuery {
+  taskId?: string
+  flowRunId?: string
+  nodeRunId?: string
+  eventTypes?: string[]
+  afterId?: string
+  limit?: number
+}
+
+export interface TimelineResult {
+  success: boolean
+  error?: string
+  events: TimelineEventInfo[]
+  nextAfterId: string
+  hasMore: boolean
+}
+
+export function listTimeline(query: ListTimelineQuery): Promise<TimelineResult> {
+  return new Promise((resolve, reject) => {
+    client.ListTimeline(query, callMetadata(), (err: any, response: any) => {
       if (err) return reject(err)
       resolve(response)
     })
@@ -129,7 +458,7 @@ export interface ServerEvent {
 }
 
 export function subscribeEvents(flowRunId?: string, onEvent?: (event: ServerEvent) => void, onError?: (err: Error) => void): { cancel: () => void } {
-  const stream = client.EventStream({ flowRunId: flowRunId || '' })
+  const stream = client.EventStream({ flowRunId: flowRunId || '' }, callMetadata())
 
   stream.on('data', (event: ServerEvent) => {
     onEvent?.(event)
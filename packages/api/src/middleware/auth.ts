@@ -1,7 +1,7 @@
 import type { FastifyRequest, FastifyReply } from 'fastify'
 import { eq, and } from 'drizzle-orm'
 import { db } from '../db/index.js'
-import { projects, projectMembers } from '../db/schema.js'
+import { flowRuns, flowSchedules, nodeRuns, projects, projectMembers, tasks } from '../db/schema.js'
 
 // Extend Fastify request with user info
 declare module 'fastify' {
@@ -51,10 +51,7 @@ export async function optionalAuth(request: FastifyRequest, _reply: FastifyReply
  * 
  * 逻辑：
  * 1. 从 request.params 中取 projectId（或 id）
- * 2. 查询项目 visibility
- * 3. 如果 public 且是 GET 请求 → 放行（只读）
- * 4. 如果用户已登录 → 检查是否为项目成员
- * 5. 可选 requiredRole 参数限制最低角色
+ * 2. 其余检查见 checkProjectAccess
  */
 export function requireProjectAccess(requiredRole?: 'owner' | 'admin' | 'member') {
   return async (request: FastifyRequest, reply: FastifyReply) => {
@@ -65,54 +62,122 @@ export function requireProjectAccess(requiredRole?: 'owner' | 'admin' | 'member'
       return reply.status(400).send({ error: 'Project ID is required' })
     }
 
-    // 查询项目
-    const [project] = await db.select({
-      id: projects.id,
-      visibility: projects.visibility,
-      ownerId: projects.ownerId,
-    }).from(projects).where(eq(projects.id, projectId))
-
-    if (!project) {
-      return reply.status(404).send({ error: 'Project not found' })
+    const denied = await checkProjectAccess(request, projectId, requiredRole)
+    if (denied) {
+      return reply.status(denied.status).send({ error: denied.error })
     }
+  }
+}
 
-    // Public 项目 + GET 请求 → 允许匿名只读访问
-    if (project.visibility === 'public' && request.method === 'GET') {
-      return
-    }
+/**
+ * checkProjectAccess — 检查用户对项目的访问权限，通过返回 null，否则返回应答的状态码与错误
+ * （projectId 不在路径参数中时由路由直接调用，如 body / query 中的 projectId）
+ *
+ * 逻辑：
+ * 1. 查询项目 visibility
+ * 2. 如果 public 且是 GET 请求 → 放行（只读）
+ * 3. 如果用户已登录 → 检查是否为项目成员
+ * 4. 可选 requiredRole 参数限制最低角色
+ */
+export async function checkProjectAccess(
+  request: FastifyRequest,
+  projectId: string,
+  requiredRole?: 'owner' | 'admin' | 'member',
+): Promise<{ status: number; error: string } | null> {
+  // 查询项目
+  const [project] = await db.select({
+    id: projects.id,
+    visibility: projects.visibility,
+    ownerId: projects.ownerId,
+  }).from(projects).where(eq(projects.id, projectId))
 
-    // 非 public 或非 GET → 必须登录
-    if (!request.userId) {
-      return reply.status(401).send({ error: 'Unauthorized' })
-    }
+  if (!project) {
+    return { status: 404, error: 'Project not found' }
+  }
+
+  // Public 项目 + GET 请求 → 允许匿名只读访问
+  if (project.visibility === 'public' && request.method === 'GET') {
+    return null
+  }
 
-    // 查询成员关系
-    const [membership] = await db.select()
-      .from(projectMembers)
-      .where(
-        and(
-          eq(projectMembers.projectId, projectId),
-          eq(projectMembers.userId, request.userId)
-        )
+  // 非 public 或非 GET → 必须登录
+  if (!request.userId) {
+    return { status: 401, error: 'Unauthorized' }
+  }
+
+  // 查询成员关系
+  const [membership] = await db.select()
+    .from(projectMembers)
+    .where(
+      and(
+        eq(projectMembers.projectId, projectId),
+        eq(projectMembers.userId, request.userId)
       )
+    )
 
-    // 项目 owner 始终有权限（兼容 ownerId 字段）
-    const isOwner = project.ownerId === request.userId
-    
-    if (!membership && !isOwner) {
-      return reply.status(403).send({ error: 'Forbidden: not a project member' })
-    }
+  // 项目 owner 始终有权限（兼容 ownerId 字段）
+  const isOwner = project.ownerId === request.userId
 
-    // 角色检查
-    if (requiredRole) {
-      const roleHierarchy: Record<string, number> = { owner: 3, admin: 2, member: 1 }
-      const userRole = isOwner ? 'owner' : (membership?.role || 'member')
-      const userLevel = roleHierarchy[userRole] || 0
-      const requiredLevel = roleHierarchy[requiredRole] || 0
+  if (!membership && !isOwner) {
+    return { status: 403, error: 'Forbidden: not a project member' }
+  }
 
-      if (userLevel < requiredLevel) {
-        return reply.status(403).send({ error: `Forbidden: requires ${requiredRole} role` })
-      }
+  // 角色检查
+  if (requiredRole) {
+    const roleHierarchy: Record<string, number> = { owner: 3, admin: 2, member: 1 }
+    const userRole = isOwner ? 'owner' : (membership?.role || 'member')
+    const userLevel = roleHierarchy[userRole] || 0
+    const requiredLevel = roleHierarchy[requiredRole] || 0
+
+    if (userLevel < requiredLevel) {
+      return { status: 403, error: `Forbidden: requires ${requiredRole} role` }
     }
   }
+  return null
+}
+
+/**
+ * projectRoleOf — 用户在项目中的角色（owner / admin / member），非成员或未登录返回 null
+ * （用于推导代表该用户调用 Orchestrator 时的角色，见 grpc/client.ts orchestratorRoles）
+ */
+export async function projectRoleOf(projectId: string | undefined, userId: string | undefined): Promise<string | null> {
+  if (!projectId || !userId) return null
+
+  const [project] = await db.select({ ownerId: projects.ownerId }).from(projects).where(eq(projects.id, projectId))
+  if (!project) return null
+  if (project.ownerId === userId) return 'owner'
+
+  const [membership] = await db.select({ role: projectMembers.role })
+    .from(projectMembers)
+    .where(and(eq(projectMembers.projectId, projectId), eq(projectMembers.userId, userId)))
+  return membership ? membership.role : null
+}
+
+/**
+ * projectIdOf — 流程执行 / 节点执行 / 定时流程所属的项目，找不到返回 undefined
+ */
+export async function projectIdOf(ref: { flowRunId?: string; nodeRunId?: string; scheduleId?: string }): Promise<string | undefined> {
+  if (ref.flowRunId) {
+    const [row] = await db.select({ projectId: tasks.projectId })
+      .from(flowRuns)
+      .innerJoin(tasks, eq(tasks.id, flowRuns.taskId))
+      .where(eq(flowRuns.id, ref.flowRunId))
+    return row?.projectId
+  }
+  if (ref.nodeRunId) {
+    const [row] = await db.select({ projectId: tasks.projectId })
+      .from(nodeRuns)
+      .innerJoin(flowRuns, eq(flowRuns.id, nodeRuns.flowRunId))
+      .innerJoin(tasks, eq(tasks.id, flowRuns.taskId))
+      .where(eq(nodeRuns.id, ref.nodeRunId))
+    return row?.projectId
+  }
+  if (ref.scheduleId) {
+    const [row] = await db.select({ projectId: tasks.projectId })
+      .from(flowSchedules)
+      .innerJoin(tasks, eq(tasks.id, flowSchedules.taskId))
+      .where(eq(flowSchedules.id, ref.scheduleId))
+    return row?.projectId
+  }
+  return undefined
 }
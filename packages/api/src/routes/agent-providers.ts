@@ -3,8 +3,19 @@ import { eq, and } from 'drizzle-orm'
 import { db } from '../db/index.js'
 import { agentProviders, agentModels } from '../db/schema.js'
 import { AGENT_TYPES, maskProviderConfig } from '../agent-types.js'
+import * as orchestrator from '../grpc/client.js'
 
 export async function agentProviderRoutes(app: FastifyInstance) {
+  // Provider / Model / 角色变更后通知 Orchestrator 热加载注册表（失败不影响本次请求）
+  app.addHook('onResponse', async (request, reply) => {
+    if (request.method === 'GET' || reply.statusCode >= 400) return
+    orchestrator.reloadAgentRegistry()
+      .then((result) => {
+        if (!result.success) app.log.warn({ error: result.error }, 'Agent registry reload failed')
+      })
+      .catch((err) => app.log.warn({ err }, 'Agent registry reload failed'))
+  })
+
   // 获取 Provider 列表（按 agent_type 过滤）
   app.get<{ Querystring: { agent_type?: string } }>('/', async (request) => {
     const { agent_type } = request.query
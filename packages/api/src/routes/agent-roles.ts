@@ -5,6 +5,17 @@ import { agentRoles, agentProviders, agentModels } from '../db/schema.js'
 import * as orchestrator from '../grpc/client.js'
 
 export async function agentRoleRoutes(app: FastifyInstance) {
+  // Provider / Model / 角色变更后通知 Orchestrator 热加载注册表（失败不影响本次请求）
+  app.addHook('onResponse', async (request, reply) => {
+    if (request.method === 'GET' || reply.statusCode >= 400) return
+    if (request.routeOptions.url?.endsWith('/test')) return
+    orchestrator.reloadAgentRegistry()
+      .then((result) => {
+        if (!result.success) app.log.warn({ error: result.error }, 'Agent registry reload failed')
+      })
+      .catch((err) => app.log.warn({ err }, 'Agent registry reload failed'))
+  })
+
   // 获取所有角色（含 provider 和 model 信息）
   app.get('/', async () => {
     const roles = await db
@@ -32,6 +43,20 @@ export async function agentRoleRoutes(app: FastifyInstance) {
     return result
   })
 
+  // 角色解析结果（Orchestrator 实际生效的 Provider / Model，及无法解析的角色）
+  app.get('/resolution', async (_request, reply) => {
+    try {
+      const result = await orchestrator.describeAgentRoles()
+      if (!result.success) {
+        return reply.status(500).send({ error: result.error || 'Orchestrator error' })
+      }
+      return result.roles
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(502).send({ error: error.message || 'Failed to reach orchestrator' })
+    }
+  })
+
   // 获取单个角色
   app.get<{ Params: { id: string } }>('/:id', async (request, reply) => {
     const { id } = request.params
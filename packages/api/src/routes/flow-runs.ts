@@ -4,7 +4,7 @@ import { db } from '../db/index.js'
 import { flowRuns, nodeRuns, tasks, workflows, timelineEvents, projects, artifacts } from '../db/schema.js'
 import { parse } from 'yaml'
 import * as orchestrator from '../grpc/client.js'
-import { authenticate } from '../middleware/auth.js'
+import { authenticate, projectIdOf, projectRoleOf } from '../middleware/auth.js'
 import { GitHubProvider } from '../lib/github-provider.js'
 
 export async function flowRunRoutes(app: FastifyInstance) {
@@ -62,7 +62,8 @@ export async function flowRunRoutes(app: FastifyInstance) {
         workflow.dsl,
         workflow.templateParams as Record<string, string> || {},
         taskId,
-        workflowId
+        workflowId,
+        orchestrator.actorOf(request, await projectRoleOf(task.projectId, request.userId)),
       )
 
       if (!result.success) {
@@ -133,7 +134,20 @@ export async function flowRunRoutes(app: FastifyInstance) {
     return result
   })
 
-  // 获取 FlowRun 的所有产物
+  // 获取 FlowRun 的 DAG 结构（由 Orchestrator 解析 DSL 快照）
+  app.get<{ Params: { id: string } }>('/:id/dag', async (request, reply) => {
+    try {
+      const result = await orchestrator.getFlowRun(request.params.id, true)
+      if (!result.success) {
+        return reply.status(404).send({ error: result.error || 'FlowRun not found' })
+      }
+      return result.dag || { nodes: [], edges: [] }
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(500).send({ error: error.message || 'Failed to load DAG' })
+    }
+  })
+
   app.get<{ Params: { id: string } }>('/:id/artifacts', async (request, reply) => {
     const { id } = request.params
 
@@ -167,7 +181,8 @@ export async function flowRunRoutes(app: FastifyInstance) {
 
     // 调用 Orchestrator 取消流程
     try {
-      const result = await orchestrator.cancelFlow(id)
+      const projectRole = await projectRoleOf(await projectIdOf({ flowRunId: id }), request.userId)
+      const result = await orchestrator.cancelFlow(id, orchestrator.actorOf(request, projectRole))
       if (!result.success) {
         return reply.status(500).send({ error: result.error || 'Failed to cancel flow' })
       }
@@ -0,0 +1,91 @@
+import type { FastifyInstance } from 'fastify'
+import { eq, and, isNull } from 'drizzle-orm'
+import { db } from '../db/index.js'
+import { tasks, workflows } from '../db/schema.js'
+import * as orchestrator from '../grpc/client.js'
+import { authenticate, projectIdOf, projectRoleOf } from '../middleware/auth.js'
+
+export async function flowScheduleRoutes(app: FastifyInstance) {
+  // 所有定时流程路由都需要登录
+  app.addHook('preHandler', authenticate)
+
+  // 列出定时流程（可按 taskId 过滤）
+  app.get<{ Querystring: { taskId?: string } }>('/', async (request, reply) => {
+    try {
+      const result = await orchestrator.listFlowSchedules(request.query.taskId || '')
+      if (!result.success) {
+        return reply.status(500).send({ error: result.error || 'Orchestrator error' })
+      }
+      return result.schedules
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(500).send({ error: error.message || 'Failed to list flow schedules' })
+    }
+  })
+
+  // 创建定时流程：cron 到期时由 Orchestrator 为任务启动一次流程
+  app.post<{
+    Body: {
+      taskId: string
+      workflowId: string
+      cron: string
+      timezone?: string
+      variables?: Record<string, string>
+      enabled?: boolean
+    }
+  }>('/', async (request, reply) => {
+    const { taskId, workflowId, cron, timezone, variables, enabled } = request.body
+
+    if (!cron) {
+      return reply.status(422).send({ error: 'cron is required' })
+    }
+
+    const [task] = await db.select().from(tasks).where(eq(tasks.id, taskId))
+    if (!task) {
+      return reply.status(404).send({ error: 'Task not found' })
+    }
+
+    const [workflow] = await db.select().from(workflows).where(and(
+      eq(workflows.id, workflowId),
+      isNull(workflows.deletedAt)
+    ))
+    if (!workflow) {
+      return reply.status(404).send({ error: 'Workflow not found' })
+    }
+
+    try {
+      const result = await orchestrator.scheduleFlow({
+        taskId,
+        workflowId,
+        cron,
+        timezone,
+        variables: variables || {},
+        disabled: enabled === false,
+      }, orchestrator.actorOf(request, await projectRoleOf(task.projectId, request.userId)))
+
+      if (!result.success) {
+        // cron / 时区 / DSL 校验失败
+        return reply.status(422).send({ error: result.error || 'Failed to schedule flow' })
+      }
+      return reply.status(201).send(result.schedule)
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(500).send({ error: error.message || 'Failed to communicate with orchestrator' })
+    }
+  })
+
+  // 删除定时流程（同时取消尚未触发的定时器）
+  app.delete<{ Params: { id: string } }>('/:id', async (request, reply) => {
+    try {
+      const projectRole = await projectRoleOf(await projectIdOf({ scheduleId: request.params.id }), request.userId)
+      const result = await orchestrator.deleteFlowSchedule(request.params.id, orchestrator.actorOf(request, projectRole))
+      if (!result.success) {
+        return reply.status(404).send({ error: result.error || 'Flow schedule not found' })
+      }
+      return { success: true }
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(500).send({ error: error.message || 'Failed to delete flow schedule' })
+    }
+  })
+}
@@ -1,9 +1,9 @@
 import type { FastifyInstance } from 'fastify'
-import { eq } from 'drizzle-orm'
+import { and, asc, eq, desc } from 'drizzle-orm'
 import { db } from '../db/index.js'
-import { nodeRuns } from '../db/schema.js'
+import { nodeRunReviews, nodeRuns, toolApprovals, users } from '../db/schema.js'
 import * as orchestrator from '../grpc/client.js'
-import { authenticate } from '../middleware/auth.js'
+import { authenticate, projectIdOf, projectRoleOf } from '../middleware/auth.js'
 
 export async function nodeRunRoutes(app: FastifyInstance) {
   // 所有节点执行路由都需要登录
@@ -29,10 +29,13 @@ export async function nodeRunRoutes(app: FastifyInstance) {
       feedback?: string
       editedContent?: string
       changeSummary?: string
+      severity?: orchestrator.ReviewDetails['severity']
+      comments?: orchestrator.ReviewDetails['comments']
+      checklist?: orchestrator.ReviewDetails['checklist']
     }
   }>('/:id/review', async (request, reply) => {
     const { id } = request.params
-    const { action, feedback, editedContent, changeSummary } = request.body
+    const { action, feedback, editedContent, changeSummary, severity, comments, checklist } = request.body
 
     // Validate node exists and is waiting for human
     const [nodeRun] = await db.select().from(nodeRuns).where(eq(nodeRuns.id, id))
@@ -44,24 +47,38 @@ export async function nodeRunRoutes(app: FastifyInstance) {
       return reply.status(422).send({ error: `Cannot review node in status: ${nodeRun.status}` })
     }
 
+    // 审核人显示名随 JWT 传给 Orchestrator，写入 node_run_reviews
+    const actor = orchestrator.actorOf(request, await projectRoleOf(await projectIdOf({ nodeRunId: id }), request.userId))
+    if (actor) {
+      const [user] = await db.select({ name: users.name }).from(users).where(eq(users.id, actor.userId))
+      actor.name = user?.name
+    }
+    const review: orchestrator.ReviewDetails = {
+      reviewerId: request.userId,
+      reviewerName: actor?.name,
+      severity,
+      comments: comments || [],
+      checklist: checklist || [],
+    }
+
     try {
       let result: { success: boolean; error?: string }
 
       switch (action) {
         case 'approve':
-          result = await orchestrator.approveNode(id)
+          result = await orchestrator.approveNode(id, review, actor)
           break
         case 'reject':
           if (!feedback) {
             return reply.status(422).send({ error: 'feedback is required for reject action' })
           }
-          result = await orchestrator.rejectNode(id, feedback)
+          result = await orchestrator.rejectNode(id, feedback, review, actor)
           break
         case 'edit_and_approve':
           if (!editedContent) {
             return reply.status(422).send({ error: 'editedContent is required for edit_and_approve action' })
           }
-          result = await orchestrator.editNode(id, editedContent, changeSummary || '')
+          result = await orchestrator.editNode(id, editedContent, changeSummary || '', review, actor)
           break
         default:
           return reply.status(422).send({ error: 'Invalid action' })
@@ -78,6 +95,22 @@ export async function nodeRunRoutes(app: FastifyInstance) {
     }
   })
 
+  // Review history of a node (every attempt of the same node in the flow run, oldest first)
+  app.get<{ Params: { id: string } }>('/:id/reviews', async (request, reply) => {
+    const { id } = request.params
+
+    const [nodeRun] = await db.select().from(nodeRuns).where(eq(nodeRuns.id, id))
+    if (!nodeRun) {
+      return reply.status(404).send({ error: 'NodeRun not found' })
+    }
+
+    return db
+      .select()
+      .from(nodeRunReviews)
+      .where(and(eq(nodeRunReviews.flowRunId, nodeRun.flowRunId), eq(nodeRunReviews.nodeId, nodeRun.nodeId)))
+      .orderBy(asc(nodeRunReviews.createdAt))
+  })
+
   // Submit human input
   app.post<{
     Params: { id: string }
@@ -97,7 +130,13 @@ export async function nodeRunRoutes(app: FastifyInstance) {
     }
 
     try {
-      const result = await orchestrator.submitHumanInput(id, JSON.stringify(data))
+      const projectRole = await projectRoleOf(await projectIdOf({ nodeRunId: id }), request.userId)
+      const result = await orchestrator.submitHumanInput(id, JSON.stringify(data), orchestrator.actorOf(request, projectRole))
+
+      // Invalid form fields: report them per field so the form can highlight them
+      if (!result.success && result.fieldErrors && Object.keys(result.fieldErrors).length > 0) {
+        return reply.status(422).send({ error: result.error || 'Invalid input', fieldErrors: result.fieldErrors })
+      }
 
       if (!result.success) {
         return reply.status(500).send({ error: result.error || 'Orchestrator error' })
@@ -110,12 +149,14 @@ export async function nodeRunRoutes(app: FastifyInstance) {
     }
   })
 
-  // Get node run log stream (for real-time and historical log viewing)
-  app.get<{ Params: { id: string } }>('/:id/logs', async (request, reply) => {
+  // Get node run logs (paginated by seq; for real-time and historical log viewing)
+  app.get<{ Params: { id: string }; Querystring: { afterSeq?: string; limit?: string } }>('/:id/logs', async (request, reply) => {
     const { id } = request.params
+    const afterSeq = Number(request.query.afterSeq) || 0
+    const limit = Number(request.query.limit) || 0
 
     const [nodeRun] = await db
-      .select({ logStream: nodeRuns.logStream })
+      .select({ id: nodeRuns.id })
       .from(nodeRuns)
       .where(eq(nodeRuns.id, id))
 
@@ -123,7 +164,21 @@ export async function nodeRunRoutes(app: FastifyInstance) {
       return reply.status(404).send({ error: 'NodeRun not found' })
     }
 
-    return { logs: nodeRun.logStream || [] }
+    try {
+      const result = await orchestrator.getNodeRunLogs(id, afterSeq, limit)
+      if (!result.success) {
+        return reply.status(500).send({ error: result.error || 'Orchestrator error' })
+      }
+
+      return {
+        logs: result.entries.map((entry) => JSON.parse(entry.eventJson)),
+        nextSeq: Number(result.nextSeq),
+        hasMore: result.hasMore,
+      }
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(500).send({ error: error.message || 'Failed to load logs' })
+    }
   })
 
   // Retry a failed node
@@ -140,7 +195,8 @@ export async function nodeRunRoutes(app: FastifyInstance) {
     }
 
     try {
-      const result = await orchestrator.retryNode(id)
+      const projectRole = await projectRoleOf(await projectIdOf({ nodeRunId: id }), request.userId)
+      const result = await orchestrator.retryNode(id, orchestrator.actorOf(request, projectRole))
 
       if (!result.success) {
         return reply.status(500).send({ error: result.error || 'Orchestrator error' })
@@ -152,4 +208,59 @@ export async function nodeRunRoutes(app: FastifyInstance) {
       return reply.status(500).send({ error: error.message || 'Failed to retry node' })
     }
   })
+
+  // List tool call approvals of a node run (newest first)
+  app.get<{ Params: { id: string } }>('/:id/tool-approvals', async (request) => {
+    return db
+      .select()
+      .from(toolApprovals)
+      .where(eq(toolApprovals.nodeRunId, request.params.id))
+      .orderBy(desc(toolApprovals.requestedAt))
+  })
+
+  // Approve or deny a pending tool call
+  app.post<{
+    Params: { id: string; approvalId: string }
+    Body: { action: 'approve' | 'deny'; reason?: string }
+  }>('/:id/tool-approvals/:approvalId', async (request, reply) => {
+    const { id, approvalId } = request.params
+    const { action, reason } = request.body
+
+    const [approval] = await db
+      .select()
+      .from(toolApprovals)
+      .where(and(eq(toolApprovals.id, approvalId), eq(toolApprovals.nodeRunId, id)))
+    if (!approval) {
+      return reply.status(404).send({ error: 'Tool approval not found' })
+    }
+
+    if (approval.status !== 'pending') {
+      return reply.status(422).send({ error: `Tool call already decided: ${approval.status}` })
+    }
+
+    try {
+      let result: { success: boolean; error?: string }
+      const actor = orchestrator.actorOf(request, await projectRoleOf(await projectIdOf({ nodeRunId: id }), request.userId))
+
+      switch (action) {
+        case 'approve':
+          result = await orchestrator.approveToolCall(approvalId, reason || '', request.userId || '', actor)
+          break
+        case 'deny':
+          result = await orchestrator.denyToolCall(approvalId, reason || '', request.userId || '', actor)
+          break
+        default:
+          return reply.status(422).send({ error: 'Invalid action' })
+      }
+
+      if (!result.success) {
+        return reply.status(500).send({ error: result.error || 'Orchestrator error' })
+      }
+
+      return { success: true }
+    } catch (error: any) {
+      app.log.error(error)
+      return reply.status(500).send({ error: error.message || 'Failed to decide tool call' })
+    }
+  })
 }
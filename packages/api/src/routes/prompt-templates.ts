@@ -0,0 +1,115 @@
+import type { FastifyInstance } from 'fastify'
+import { and, eq, isNull, desc, or, sql } from 'drizzle-orm'
+import { db } from '../db/index.js'
+import { promptTemplates } from '../db/schema.js'
+import { authenticate, checkProjectAccess } from '../middleware/auth.js'
+
+// 模板名：小写字母/数字开头，可含 . _ / -；不能含 @（@vN 用于引用版本）
+// 约定前缀：mode/<mode> 覆盖模式说明，role/<slug> 覆盖角色系统提示词
+const NAME_PATTERN = /^[a-z0-9][a-z0-9._/-]{0,99}$/
+
+function scopeFilter(projectId?: string) {
+  return projectId ? eq(promptTemplates.projectId, projectId) : isNull(promptTemplates.projectId)
+}
+
+export async function promptTemplateRoutes(app: FastifyInstance) {
+  app.addHook('preHandler', authenticate)
+
+  // 获取模板列表（每个名称的最新版本）；传 projectId 时同时返回全局模板
+  app.get<{ Querystring: { projectId?: string } }>('/', async (request, reply) => {
+    const { projectId } = request.query
+    if (projectId) {
+      const denied = await checkProjectAccess(request, projectId)
+      if (denied) return reply.status(denied.status).send({ error: denied.error })
+    }
+    const where = projectId
+      ? or(eq(promptTemplates.projectId, projectId), isNull(promptTemplates.projectId))
+      : isNull(promptTemplates.projectId)
+
+    const rows = await db
+      .select()
+      .from(promptTemplates)
+      .where(where)
+      .orderBy(promptTemplates.name, desc(promptTemplates.version))
+
+    const latest = new Map<string, typeof rows[number]>()
+    for (const row of rows) {
+      const key = `${row.projectId ?? ''}:${row.name}`
+      if (!latest.has(key)) latest.set(key, row)
+    }
+    return [...latest.values()]
+  })
+
+  // 获取模板的所有版本（名称可含 /，因此通过 query 传递）
+  app.get<{
+    Querystring: { name: string; projectId?: string }
+  }>('/versions', async (request, reply) => {
+    const { name } = request.query
+    if (!name) {
+      return reply.status(400).send({ error: 'name is required' })
+    }
+    if (request.query.projectId) {
+      const denied = await checkProjectAccess(request, request.query.projectId)
+      if (denied) return reply.status(denied.status).send({ error: denied.error })
+    }
+    const rows = await db
+      .select()
+      .from(promptTemplates)
+      .where(and(eq(promptTemplates.name, name), scopeFilter(request.query.projectId)))
+      .orderBy(desc(promptTemplates.version))
+    if (rows.length === 0) {
+      return reply.status(404).send({ error: 'Prompt template not found' })
+    }
+    return rows
+  })
+
+  // 保存模板：每次保存生成新版本（历史版本不可修改，便于追溯）
+  app.post<{
+    Body: {
+      name: string
+      projectId?: string | null
+      content: string
+      description?: string
+    }
+  }>('/', async (request, reply) => {
+    const { name, projectId, content, description } = request.body
+
+    if (!name || !content) {
+      return reply.status(400).send({ error: 'name and content are required' })
+    }
+    if (!NAME_PATTERN.test(name)) {
+      return reply.status(400).send({ error: 'Invalid template name (lowercase letters, digits, . _ / -)' })
+    }
+
+    // 项目级覆盖只能由项目成员保存
+    if (projectId) {
+      const denied = await checkProjectAccess(request, projectId)
+      if (denied) return reply.status(denied.status).send({ error: denied.error })
+    }
+
+    // 同一模板的并发保存在事务级 advisory lock 上排队，版本号在锁内计算，不会撞上唯一约束
+    const created = await db.transaction(async (tx) => {
+      await tx.execute(sql`SELECT pg_advisory_xact_lock(hashtext(${`prompt_template:${projectId || ''}:${name}`}))`)
+
+      const [latest] = await tx
+        .select({ version: sql<number>`COALESCE(MAX(${promptTemplates.version}), 0)` })
+        .from(promptTemplates)
+        .where(and(eq(promptTemplates.name, name), scopeFilter(projectId || undefined)))
+
+      const [row] = await tx
+        .insert(promptTemplates)
+        .values({
+          projectId: projectId || null,
+          name,
+          version: Number(latest?.version ?? 0) + 1,
+          content,
+          description: description || null,
+          createdBy: request.userId || null,
+        })
+        .returning()
+      return row
+    })
+
+    return reply.status(201).send(created)
+  })
+}
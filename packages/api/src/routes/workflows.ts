@@ -1,7 +1,7 @@
 import type { FastifyInstance } from 'fastify'
 import { eq, and, isNull } from 'drizzle-orm'
 import { db } from '../db/index.js'
-import { workflows, workflowTemplates } from '../db/schema.js'
+import { workflows, workflowTemplates, agentProviders, agentModels } from '../db/schema.js'
 import { authenticate } from '../middleware/auth.js'
 
 export async function workflowRoutes(app: FastifyInstance) {
@@ -173,6 +173,9 @@ export async function workflowRoutes(app: FastifyInstance) {
             }
           }
         }
+
+        // 校验 agent.provider / agent.model / allowed_models（含模板表达式的值在运行时校验）
+        errors.push(...(await validateAgentBindings(parsed)))
       }
 
       return {
@@ -188,3 +191,45 @@ export async function workflowRoutes(app: FastifyInstance) {
     }
   })
 }
+
+async function validateAgentBindings(parsed: any): Promise<string[]> {
+  const errors: string[] = []
+  const allowedModels = parsed.allowed_models
+  if (allowedModels !== undefined && (!Array.isArray(allowedModels) || allowedModels.some((m: unknown) => typeof m !== 'string'))) {
+    errors.push('allowed_models 必须是字符串数组')
+  }
+
+  const agentNodes = parsed.nodes.filter((n: any) => n.type === 'agent_task' && n.agent)
+  if (agentNodes.length === 0) return errors
+
+  const providers = await db.select().from(agentProviders)
+  const models = await db.select().from(agentModels)
+  const isTemplate = (v: unknown) => typeof v === 'string' && v.includes('{{')
+
+  for (const node of agentNodes) {
+    const { provider: providerRef, model } = node.agent
+    let providerId: string | undefined
+
+    if (providerRef && !isTemplate(providerRef)) {
+      const byId = providers.find((p) => p.id === providerRef)
+      const byName = providers.filter((p) => p.name === providerRef)
+      if (byId) providerId = byId.id
+      else if (byName.length === 1) providerId = byName[0].id
+      else if (byName.length > 1) errors.push(`节点 ${node.id} 的 provider "${providerRef}" 名称不唯一，请使用 Provider ID`)
+      else errors.push(`节点 ${node.id} 的 provider "${providerRef}" 不存在`)
+    }
+
+    if (model && !isTemplate(model)) {
+      if (providerId) {
+        const offered = models.filter((m) => m.providerId === providerId).map((m) => m.modelName)
+        if (offered.length > 0 && !offered.includes(model)) {
+          errors.push(`节点 ${node.id} 的 model "${model}" 不在该 Provider 的模型列表中（可选：${offered.join(', ')}）`)
+        }
+      }
+      if (Array.isArray(allowedModels) && allowedModels.length > 0 && !allowedModels.includes(model)) {
+        errors.push(`节点 ${node.id} 的 model "${model}" 不在 allowed_models 中`)
+      }
+    }
+  }
+  return errors
+}
@@ -11,6 +11,7 @@ import { healthRoutes } from './routes/health.js'
 import { workflowTemplateRoutes } from './routes/workflow-templates.js'
 import { workflowRoutes } from './routes/workflows.js'
 import { flowRunRoutes } from './routes/flow-runs.js'
+import { flowScheduleRoutes } from './routes/flow-schedules.js'
 import { artifactRoutes } from './routes/artifacts.js'
 import { nodeRunRoutes } from './routes/node-runs.js'
 import { openspecRoutes } from './routes/openspec.js'
@@ -18,6 +19,7 @@ import { agentRoleRoutes } from './routes/agent-roles.js'
 import { agentTypeRoutes } from './routes/agent-types.js'
 import { agentProviderRoutes, agentModelRoutes } from './routes/agent-providers.js'
 import { authRoutes } from './routes/auth.js'
+import { promptTemplateRoutes } from './routes/prompt-templates.js'
 import { wsGateway, startEventForwarding, stopEventForwarding } from './ws/gateway.js'
 
 const PORT = parseInt(process.env.PORT || '4000', 10)
@@ -49,6 +51,7 @@ await app.register(taskRoutes, { prefix: '/api/tasks' })
 await app.register(workflowTemplateRoutes, { prefix: '/api/workflow-templates' })
 await app.register(workflowRoutes, { prefix: '/api/workflows' })
 await app.register(flowRunRoutes, { prefix: '/api/flow-runs' })
+await app.register(flowScheduleRoutes, { prefix: '/api/flow-schedules' })
 await app.register(artifactRoutes, { prefix: '/api/artifacts' })
 await app.register(nodeRunRoutes, { prefix: '/api/node-runs' })
 await app.register(openspecRoutes, { prefix: '/api/projects/:projectId/openspec' })
@@ -56,6 +59,7 @@ await app.register(agentTypeRoutes, { prefix: '/api/agent-types' })
 await app.register(agentProviderRoutes, { prefix: '/api/agent-providers' })
 await app.register(agentModelRoutes, { prefix: '/api/agent-models' })
 await app.register(agentRoleRoutes, { prefix: '/api/agent-roles' })
+await app.register(promptTemplateRoutes, { prefix: '/api/prompt-templates' })
 
 // WebSocket
 await app.register(wsGateway)
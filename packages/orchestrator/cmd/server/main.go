@@ -6,7 +6,9 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	grpclib "google.golang.org/grpc"
@@ -14,10 +16,13 @@ import (
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
 	"github.com/sunshow/workgear/orchestrator/internal/db"
 	"github.com/sunshow/workgear/orchestrator/internal/engine"
 	"github.com/sunshow/workgear/orchestrator/internal/event"
+	"github.com/sunshow/workgear/orchestrator/internal/gitmirror"
 	grpcserver "github.com/sunshow/workgear/orchestrator/internal/grpc"
+	"github.com/sunshow/workgear/orchestrator/internal/secrets"
 )
 
 func main() {
@@ -54,124 +59,85 @@ func main() {
 	factoryRegistry := agent.NewAgentFactoryRegistry()
 	factoryRegistry.Register(&agent.ClaudeCodeFactory{PromptBuilder: promptBuilder})
 	factoryRegistry.Register(&agent.CodexFactory{PromptBuilder: promptBuilder})
-
-	// Load providers from database
-	providers, err := dbClient.GetAllAgentProviders(ctx)
-	if err != nil {
-		sugar.Fatalf("Failed to load agent providers: %v", err)
-	}
-
-	if len(providers) > 0 {
-		for _, p := range providers {
-			defaultModel, _ := dbClient.GetDefaultModelForProvider(ctx, p.ID)
-			modelName := ""
-			if defaultModel != nil {
-				modelName = defaultModel.ModelName
-			}
-
-			adapter, err := factoryRegistry.CreateAdapter(sugar, p.AgentType, p.ID, p.Config, modelName)
-			if err != nil {
-				sugar.Warnw("Failed to create adapter, skipping", "type", p.AgentType, "name", p.Name, "error", err)
-				continue
-			}
-			registry.RegisterProvider(p.ID, adapter)
-			sugar.Infow("Registered provider", "id", p.ID, "type", p.AgentType, "name", p.Name, "default", p.IsDefault)
+	factoryRegistry.Register(&agent.HTTPChatFactory{PromptBuilder: promptBuilder})
+
+	// Env fallback provider, used when no providers are configured in the database
+	var fallback *agent.EnvFallback
+	if os.Getenv("ANTHROPIC_API_KEY") != "" || os.Getenv("ANTHROPIC_AUTH_TOKEN") != "" {
+		envConfig := map[string]any{
+			"auth_token": os.Getenv("ANTHROPIC_AUTH_TOKEN"),
+			"base_url":   os.Getenv("ANTHROPIC_BASE_URL"),
 		}
-	} else {
-		// Fallback: use environment variables (backward compat)
-		if os.Getenv("ANTHROPIC_API_KEY") != "" || os.Getenv("ANTHROPIC_AUTH_TOKEN") != "" {
-			envConfig := map[string]any{
-				"auth_token": os.Getenv("ANTHROPIC_AUTH_TOKEN"),
-				"base_url":   os.Getenv("ANTHROPIC_BASE_URL"),
-			}
-			if envConfig["auth_token"] == "" {
-				envConfig["auth_token"] = os.Getenv("ANTHROPIC_API_KEY")
-			}
-			adapter, err := factoryRegistry.CreateAdapter(sugar, "claude-code", "env-fallback", envConfig, os.Getenv("CLAUDE_MODEL"))
-			if err != nil {
-				sugar.Fatalf("Failed to create env fallback adapter: %v", err)
-			}
-			registry.RegisterProvider("env-fallback", adapter)
-			sugar.Warn("No providers in database, using environment variable fallback")
-		} else {
-			sugar.Fatalf("No agent providers configured in database and no ANTHROPIC_API_KEY/ANTHROPIC_AUTH_TOKEN in environment")
+		if envConfig["auth_token"] == "" {
+			envConfig["auth_token"] = os.Getenv("ANTHROPIC_API_KEY")
 		}
+		fallback = &agent.EnvFallback{Config: envConfig, Model: os.Getenv("CLAUDE_MODEL")}
 	}
 
-	// Load role mappings from database
-	roleConfigs, err := dbClient.GetAllAgentRoleConfigs(ctx)
+	// Load providers and role mappings from database (reloadable at runtime via ReloadAgentRegistry)
+	registryLoader := agent.NewRegistryLoader(dbClient, registry, factoryRegistry, fallback, sugar)
+	summary, err := registryLoader.Reload(ctx)
 	if err != nil {
-		sugar.Fatalf("Failed to load agent role configs: %v", err)
-	}
-
-	for slug, rc := range roleConfigs {
-		providerID := ""
-		modelName := ""
-
-		if rc.ProviderID != nil {
-			// Role has explicit provider
-			providerID = *rc.ProviderID
-			if rc.ModelID != nil {
-				// Role has explicit model
-				m, _ := dbClient.GetAgentModel(ctx, *rc.ModelID)
-				if m != nil {
-					modelName = m.ModelName
-				}
-			} else {
-				// Use provider's default model
-				m, _ := dbClient.GetDefaultModelForProvider(ctx, providerID)
-				if m != nil {
-					modelName = m.ModelName
-				}
-			}
-		} else {
-			// Use default provider for agent type
-			dp, _ := dbClient.GetDefaultProviderForType(ctx, rc.AgentType)
-			if dp != nil {
-				providerID = dp.ID
-				m, _ := dbClient.GetDefaultModelForProvider(ctx, dp.ID)
-				if m != nil {
-					modelName = m.ModelName
-				}
-			} else if len(providers) == 0 {
-				// Env fallback
-				providerID = "env-fallback"
-				modelName = os.Getenv("CLAUDE_MODEL")
-			}
-		}
+		sugar.Fatalf("Failed to load agent registry: %v", err)
+	}
+	sugar.Infof("Agent registry initialized with %d providers", summary.Providers)
 
-		if providerID != "" {
-			registry.MapRoleToProvider(slug, providerID, modelName)
-			sugar.Infow("Mapped role", "role", slug, "provider", providerID, "model", modelName)
-		} else {
-			sugar.Warnw("No provider found for role", "role", slug, "agent_type", rc.AgentType)
+	// Optional periodic refresh (e.g. AGENT_REGISTRY_REFRESH_INTERVAL=1m)
+	if v := os.Getenv("AGENT_REGISTRY_REFRESH_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			sugar.Fatalf("Invalid AGENT_REGISTRY_REFRESH_INTERVAL %q", v)
 		}
+		go registryLoader.Watch(ctx, interval)
+		sugar.Infow("Agent registry periodic refresh enabled", "interval", interval)
+	}
+
+	// 4. Create flow executor
+	executor := engine.NewFlowExecutor(dbClient, eventBus, registry, sugar)
+
+	// Secret redaction: built-in patterns plus REDACT_PATTERNS (";"-separated regexes)
+	redactPatterns, err := agent.ParseRedactPatterns(os.Getenv("REDACT_PATTERNS"))
+	if err != nil {
+		sugar.Fatalf("Invalid REDACT_PATTERNS: %v", err)
+	}
+	executor.SetRedactor(agent.NewRedactor(redactPatterns))
+
+	// Shared per-flow workspaces (Docker volumes, used by workflows with `workspace: shared|snapshot`)
+	if workspaces, err := agent.NewWorkspaceVolumes(sugar); err != nil {
+		sugar.Warnw("Shared workspaces unavailable", "error", err)
+	} else {
+		workspaces.SetHelperImage(os.Getenv("WORKSPACE_HELPER_IMAGE"))
+		executor.SetWorkspaceVolumes(workspaces)
 	}
 
-	// Ensure common roles are mapped (fallback for roles not in DB)
-	defaultRoles := []string{"general-developer", "requirement-analyst", "code-reviewer", "qa-engineer", "spec-architect"}
-	for _, role := range defaultRoles {
-		if _, err := registry.GetAdapter(role); err != nil {
-			// Try to map to default provider for claude-code
-			dp, _ := dbClient.GetDefaultProviderForType(ctx, "claude-code")
-			if dp != nil {
-				m, _ := dbClient.GetDefaultModelForProvider(ctx, dp.ID)
-				mn := ""
-				if m != nil {
-					mn = m.ModelName
-				}
-				registry.MapRoleToProvider(role, dp.ID, mn)
-				sugar.Infow("Auto-mapped default role", "role", role, "provider", dp.ID)
-			} else if len(providers) == 0 {
-				registry.MapRoleToProvider(role, "env-fallback", os.Getenv("CLAUDE_MODEL"))
-			}
+	// Optional warm repo cache (bare mirrors shared by all agent runs)
+	if cacheDir := os.Getenv("REPO_CACHE_DIR"); cacheDir != "" {
+		repoMirror, err := gitmirror.NewManager(cacheDir, os.Getenv("REPO_CACHE_HOST_SOURCE"), sugar)
+		if err != nil {
+			sugar.Fatalf("Failed to initialize repo cache: %v", err)
 		}
+		executor.SetRepoMirror(repoMirror)
+		sugar.Infow("Repo cache enabled", "dir", cacheDir, "host_source", repoMirror.HostSource())
 	}
 
-	sugar.Infof("Agent registry initialized with %d providers", len(providers))
+	// Script nodes: containers by default (SCRIPT_IMAGE overrides the image); plain local
+	// processes only when SCRIPT_LOCAL_EXECUTOR=true, as they run unisolated on this host
+	if scriptRunner, err := agent.NewDockerScriptRunner(sugar, os.Getenv("SCRIPT_IMAGE")); err != nil {
+		sugar.Warnw("Docker script executor unavailable", "error", err)
+	} else {
+		executor.SetScriptRunner(scriptRunner)
+	}
+	if os.Getenv("SCRIPT_LOCAL_EXECUTOR") == "true" {
+		executor.SetScriptRunner(agent.NewLocalScriptRunner(sugar, os.Getenv("SCRIPT_LOCAL_WORKDIR")))
+		sugar.Warnw("Local script executor enabled: script nodes with executor: local run unisolated on this host")
+	}
 
-	// 4. Create flow executor
-	executor := engine.NewFlowExecutor(dbClient, eventBus, registry, sugar)
+	// Secrets for http_request nodes: WORKGEAR_SECRET_<NAME> env vars, then files in SECRETS_DIR
+	secretStore := secrets.Chain{secrets.EnvStore{Prefix: "WORKGEAR_SECRET_"}}
+	if dir := os.Getenv("SECRETS_DIR"); dir != "" {
+		secretStore = append(secretStore, secrets.DirStore{Dir: dir})
+	}
+	executor.SetSecretStore(secretStore)
 
 	// 5. Start the worker loop (recovers stale state + polls for work)
 	if err := executor.Start(ctx); err != nil {
@@ -184,7 +150,34 @@ func main() {
 		sugar.Fatalf("Failed to listen: %v", err)
 	}
 
-	server := grpclib.NewServer()
+	var serverOpts []grpclib.ServerOption
+
+	// Optional TLS (GRPC_TLS_CERT / GRPC_TLS_KEY); GRPC_TLS_CLIENT_CA additionally requires
+	// client certificates (mTLS)
+	if certFile := os.Getenv("GRPC_TLS_CERT"); certFile != "" {
+		creds, err := grpcserver.TLSCredentials(certFile, os.Getenv("GRPC_TLS_KEY"), os.Getenv("GRPC_TLS_CLIENT_CA"))
+		if err != nil {
+			sugar.Fatalf("Failed to load gRPC TLS credentials: %v", err)
+		}
+		serverOpts = append(serverOpts, grpclib.Creds(creds))
+		sugar.Infow("gRPC TLS enabled", "mtls", os.Getenv("GRPC_TLS_CLIENT_CA") != "")
+	}
+
+	// Bearer authentication: shared secret (GRPC_AUTH_TOKEN) and/or JWTs signed by the API
+	// server (GRPC_JWT_SECRET)
+	verifier := auth.NewVerifier(auth.Config{
+		SharedToken: os.Getenv("GRPC_AUTH_TOKEN"),
+		TokenRoles:  splitList(os.Getenv("GRPC_AUTH_TOKEN_ROLES")),
+		JWTSecret:   os.Getenv("GRPC_JWT_SECRET"),
+		Audience:    os.Getenv("GRPC_JWT_AUDIENCE"),
+	})
+	if verifier == nil {
+		sugar.Warn("gRPC authentication disabled (set GRPC_AUTH_TOKEN or GRPC_JWT_SECRET)")
+	} else {
+		serverOpts = append(serverOpts, grpcserver.NewAuthenticator(verifier, sugar).ServerOptions()...)
+	}
+
+	server := grpclib.NewServer(serverOpts...)
 
 	// Register health check
 	healthServer := health.NewServer()
@@ -192,7 +185,7 @@ func main() {
 	healthServer.SetServingStatus("orchestrator", healthpb.HealthCheckResponse_SERVING)
 
 	// Register orchestrator service
-	orchServer := grpcserver.NewOrchestratorServer(executor, eventBus, registry, factoryRegistry, sugar)
+	orchServer := grpcserver.NewOrchestratorServer(executor, eventBus, registry, factoryRegistry, registryLoader, sugar)
 	orchServer.Register(server)
 
 	sugar.Infof("WorkGear Orchestrator gRPC server listening on :%s", port)
@@ -209,3 +202,14 @@ func main() {
 	server.GracefulStop()
 	sugar.Info("Server stopped")
 }
+
+// splitList splits a comma-separated env value, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"io"
+	"sync"
 	"time"
 )
 
@@ -9,24 +11,54 @@ import (
 
 // AgentRequest represents a request to an agent
 type AgentRequest struct {
-	TaskID          string         `json:"task_id"`
-	FlowRunID       string         `json:"flow_run_id"`
-	NodeID          string         `json:"node_id"`
-	Mode            string         `json:"mode"` // spec / execute / review / opsx_plan / opsx_apply
-	Prompt          string         `json:"prompt"`
-	Context         map[string]any `json:"context"`
-	WorkDir         string         `json:"work_dir"`
-	GitBranch       string         `json:"git_branch"`
-	GitRepoURL      string         `json:"git_repo_url"`
-	GitAccessToken  string         `json:"git_access_token"`
-	TaskTitle       string         `json:"task_title"`
-	NodeName        string         `json:"node_name"`
-	RolePrompt      string         `json:"role_prompt"`
-	Feedback        string         `json:"feedback"`
-	Model           string         `json:"model"` // Request-level model (highest priority)
-	OpsxConfig      *OpsxConfig    `json:"opsx,omitempty"`
+	TaskID              string              `json:"task_id"`
+	FlowRunID           string              `json:"flow_run_id"`
+	NodeID              string              `json:"node_id"`
+	Mode                string              `json:"mode"`                       // spec / execute / review / opsx_plan / opsx_apply
+	ModeInstruction     string              `json:"mode_instruction,omitempty"` // Overrides the built-in instruction for Mode (project prompt library)
+	Prompt              string              `json:"prompt"`
+	Context             map[string]any      `json:"context"`
+	WorkDir             string              `json:"work_dir"`
+	GitBranch           string              `json:"git_branch"`
+	GitRepoURL          string              `json:"git_repo_url"`
+	GitAccessToken      string              `json:"git_access_token"`
+	TaskTitle           string              `json:"task_title"`
+	NodeName            string              `json:"node_name"`
+	RolePrompt          string              `json:"role_prompt"`
+	Feedback            string              `json:"feedback"`
+	Model               string              `json:"model"` // Request-level model (highest priority)
+	OpsxConfig          *OpsxConfig         `json:"opsx,omitempty"`
+	ContainerLimits     *ContainerLimits    `json:"-"` // Node-level container limits (from DSL)
+	Mounts              []Mount             `json:"-"` // Extra mounts for container executors (e.g. repo cache)
+	GitReferenceRepo    string              `json:"-"` // Path of a local mirror inside the container, used as `git clone --reference`
+	PersistentWorkspace bool                `json:"-"` // /workspace is a volume shared with other nodes of the flow run
+	LogSink             LogSink             `json:"-"` // Receives real-time log events of this execution
+	Redactor            *Redactor           `json:"-"` // Scrubs secrets from logs, errors and output; learns the execution's env secrets
+	OutputSchema        map[string]any      `json:"-"` // JSON Schema the final output must match (injected into the prompt)
+	OutputErrors        []string            `json:"-"` // Validation errors of the previous attempt (repair re-prompt)
+	AncestorContext     []ContextEntry      `json:"-"` // Outputs of older (non-direct) ancestor nodes, nearest first
+	PromptBudget        int                 `json:"-"` // Prompt token budget (0 = derived from the model's context window)
+	PromptStats         *PromptStats        `json:"-"` // Set by PromptBuilder.Build: size and truncation of the final prompt
+	ResumeSession       *AgentSession       `json:"-"` // Session of a previous run of this node to continue (adapters that cannot resume ignore it)
+	ToolApproval        *ToolApprovalPolicy `json:"-"` // Tool calls that pause for human approval (only adapters where SupportsToolApproval may receive it)
+	ToolApprover        ToolApprover        `json:"-"` // Decides the calls ToolApproval pauses
+	Timeout             time.Duration       `json:"-"` // Node-level timeout (DSL `timeout`); honoured by http-chat, container agents keep their own
 }
 
+// Mount is an extra filesystem mount for container executors
+type Mount struct {
+	Type     string // MountTypeBind or MountTypeVolume
+	Source   string // Host path (bind) or volume name (volume)
+	Target   string // Path inside the container
+	SubPath  string // Volume only: mount this directory of the volume instead of its root
+	ReadOnly bool
+}
+
+const (
+	MountTypeBind   = "bind"
+	MountTypeVolume = "volume"
+)
+
 // OpsxConfig holds OpenSpec-specific configuration for opsx_plan / opsx_apply modes
 type OpsxConfig struct {
 	ChangeName    string `json:"change_name" yaml:"change_name"`
@@ -41,6 +73,13 @@ type AgentResponse struct {
 	Metrics       *ExecutionMetrics `json:"metrics,omitempty"`
 	GitMetadata   *GitMetadata      `json:"git_metadata,omitempty"`
 	ArtifactFiles []ArtifactFile    `json:"artifact_files,omitempty"` // 新增：产物文件列表
+	Session       *AgentSession     `json:"-"`                        // CLI session this execution ended with (if the adapter supports resuming)
+}
+
+// AgentSession is an agent CLI conversation that a later execution can resume
+type AgentSession struct {
+	ID    string
+	State []byte // tar archive of the CLI's session files
 }
 
 // ArtifactFile represents a single artifact file to be created
@@ -102,25 +141,62 @@ type Executor interface {
 
 // ExecutorRequest is the runtime-layer request
 type ExecutorRequest struct {
-	Image   string            // Docker image name
-	Command []string          // Command to run inside container
-	Env     map[string]string // Environment variables
-	WorkDir string            // Working directory
-	Timeout time.Duration     // Execution timeout
+	Image        string            // Docker image name
+	Command      []string          // Command to run inside container
+	Env          map[string]string // Environment variables
+	WorkDir      string            // Working directory
+	Timeout      time.Duration     // Execution timeout
+	Prompt       string            // Full prompt (for executors that call model APIs directly)
+	Model        string            // Model name (for executors that call model APIs directly)
+	Limits       *ContainerLimits  // Node-level container limits, merged over the executor's defaults
+	Mounts       []Mount           // Extra mounts (container executors only)
+	LogSink      LogSink           // Real-time log events of this execution (may be nil)
+	StreamFormat string            // Format of the container's event stream: StreamFormatClaude (default) / StreamFormatCodex
+	SessionState []byte            // Tar archive extracted into /output before start (session resume, container executors only)
+	ToolApprover ToolApprover      // Decides tool calls the container's approval hook pauses (container executors only)
 }
 
 // ExecutorResponse is the runtime-layer response
 type ExecutorResponse struct {
-	ExitCode    int
-	Stdout      string
-	Stderr      string
-	GitMetadata *GitMetadata // Extracted from /output/git_metadata.json in container
+	ExitCode     int
+	Stdout       string
+	Stderr       string
+	GitMetadata  *GitMetadata      // Extracted from /output/git_metadata.json in container
+	Metrics      *ExecutionMetrics // Token usage reported by the executor (if available)
+	SessionState []byte            // Tar archive of /output/session in container (agent CLI session files)
+}
+
+// LogSink receives the real-time log events of a single execution.
+// It travels with the request (never on the executor), so concurrent runs that share
+// an executor instance keep their logs apart. Every executor forwards its events to it.
+type LogSink interface {
+	HandleLogEvent(event ClaudeStreamEvent)
+}
+
+// LogSinkFunc adapts a plain function to LogSink
+type LogSinkFunc func(event ClaudeStreamEvent)
+
+func (f LogSinkFunc) HandleLogEvent(event ClaudeStreamEvent) { f(event) }
+
+// emitLog stamps and forwards an event to the sink (no-op when sink is nil)
+func emitLog(sink LogSink, event ClaudeStreamEvent) {
+	if sink == nil {
+		return
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().UnixMilli()
+	}
+	sink.HandleLogEvent(event)
 }
 
 // CombinedAdapter bridges TypeAdapter + Executor into the Adapter interface
 type CombinedAdapter struct {
 	typeAdapter TypeAdapter
 	executor    Executor
+
+	mu      sync.Mutex
+	running int  // executions in flight
+	closed  bool // Close was called; the last running execution closes the executor
 }
 
 // NewCombinedAdapter creates a combined adapter from a type adapter and executor
@@ -130,19 +206,77 @@ func NewCombinedAdapter(ta TypeAdapter, exec Executor) *CombinedAdapter {
 
 func (a *CombinedAdapter) Name() string { return a.typeAdapter.Name() }
 
-// Executor returns the underlying executor (for injecting callbacks)
+// Executor returns the underlying executor
 func (a *CombinedAdapter) Executor() Executor { return a.executor }
 
+// Close releases the executor's resources (e.g. its Docker client) once the executions that
+// already hold this adapter have finished. Called when a registry reload drops the adapter.
+func (a *CombinedAdapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.closed = true
+	if a.running > 0 {
+		return nil
+	}
+	return a.closeExecutor()
+}
+
+func (a *CombinedAdapter) closeExecutor() error {
+	if closer, ok := a.executor.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func (a *CombinedAdapter) Execute(ctx context.Context, req *AgentRequest) (*AgentResponse, error) {
+	a.mu.Lock()
+	a.running++
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if a.running--; a.running == 0 && a.closed {
+			_ = a.closeExecutor()
+		}
+	}()
+
 	execReq, err := a.typeAdapter.BuildRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+
+	// Everything leaving the execution (log events, errors incl. stderr, output) is
+	// scrubbed of the secrets handed to it. The caller's redactor learns them too, so
+	// it can scrub whatever it derives from the response later.
+	redactor := req.Redactor
+	if redactor == nil {
+		redactor = NewRedactor(nil)
+	}
+	redactor.AddSecrets(req.GitAccessToken)
+	redactor.AddEnvSecrets(execReq.Env)
+
+	if execReq.LogSink == nil {
+		execReq.LogSink = req.LogSink
+	}
+	if sink := execReq.LogSink; sink != nil {
+		execReq.LogSink = LogSinkFunc(func(event ClaudeStreamEvent) {
+			sink.HandleLogEvent(redactor.Event(event))
+		})
+	}
+
 	execResp, err := a.executor.Execute(ctx, execReq)
 	if err != nil {
-		return nil, err
+		return nil, redactor.Error(err)
+	}
+	resp, err := a.typeAdapter.ParseResponse(execResp)
+	if err != nil {
+		return nil, redactor.Error(err)
+	}
+	resp.Output = redactor.Map(resp.Output)
+	if resp.GitMetadata != nil {
+		resp.GitMetadata.RepoURL = redactor.String(resp.GitMetadata.RepoURL)
 	}
-	return a.typeAdapter.ParseResponse(execResp)
+	return resp, nil
 }
 
 // RoleMapping maps a role to a specific provider and model
@@ -151,8 +285,11 @@ type RoleMapping struct {
 	ModelName  string
 }
 
-// Registry manages available agent adapters
+// Registry manages available agent adapters.
+// It is safe for concurrent use; Swap replaces its contents atomically on reload, while
+// callers that already resolved an adapter keep using it until their execution ends.
 type Registry struct {
+	mu       sync.RWMutex
 	adapters map[string]Adapter      // provider_id → adapter (new)
 	legacy   map[string]Adapter      // name → adapter (backward compat)
 	roles    map[string]*RoleMapping // role → mapping
@@ -169,54 +306,79 @@ func NewRegistry() *Registry {
 
 // Register adds an adapter to the registry by name (backward compat)
 func (r *Registry) Register(adapter Adapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.legacy[adapter.Name()] = adapter
 }
 
 // RegisterProvider adds an adapter to the registry by provider ID
 func (r *Registry) RegisterProvider(providerID string, adapter Adapter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.adapters[providerID] = adapter
 }
 
 // MapRole maps an agent role to an adapter name (backward compat)
 func (r *Registry) MapRole(role, adapterName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.roles[role] = &RoleMapping{ProviderID: adapterName}
 }
 
 // MapRoleToProvider maps an agent role to a provider ID and model name
 func (r *Registry) MapRoleToProvider(role, providerID, modelName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.roles[role] = &RoleMapping{
 		ProviderID: providerID,
 		ModelName:  modelName,
 	}
 }
 
+// Swap atomically replaces the providers and role mappings with next's.
+// Legacy name-based adapters are kept. next must not be used afterwards.
+func (r *Registry) Swap(next *Registry) {
+	next.mu.Lock()
+	adapters, roles := next.adapters, next.roles
+	next.mu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adapters = adapters
+	r.roles = roles
+}
+
 // GetAdapter returns the adapter for a given role (backward compat)
 func (r *Registry) GetAdapter(role string) (Adapter, error) {
-	mapping, ok := r.roles[role]
-	if !ok {
-		return nil, &NoAdapterError{Role: role}
-	}
-	// Try provider-based lookup first
-	if adapter, ok := r.adapters[mapping.ProviderID]; ok {
-		return adapter, nil
-	}
-	// Fallback to legacy name-based lookup
-	if adapter, ok := r.legacy[mapping.ProviderID]; ok {
-		return adapter, nil
-	}
-	return nil, &NoAdapterError{Role: role}
+	adapter, _, err := r.GetAdapterForRole(role)
+	return adapter, err
 }
 
 // GetAdapterByProvider returns the adapter for a given provider ID (direct lookup)
 func (r *Registry) GetAdapterByProvider(providerID string) (Adapter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if adapter, ok := r.adapters[providerID]; ok {
 		return adapter, true
 	}
 	return nil, false
 }
 
+// GetRoleMapping returns the provider and model a role is mapped to
+func (r *Registry) GetRoleMapping(role string) (RoleMapping, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	mapping, ok := r.roles[role]
+	if !ok {
+		return RoleMapping{}, false
+	}
+	return *mapping, true
+}
+
 // GetAdapterForRole returns the adapter and model name for a given role
 func (r *Registry) GetAdapterForRole(role string) (Adapter, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	mapping, ok := r.roles[role]
 	if !ok {
 		return nil, "", &NoAdapterError{Role: role}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+// blockingExecutor blocks Execute until release is closed and counts Close calls
+type blockingExecutor struct {
+	started chan struct{}
+	release chan struct{}
+	closed  atomic.Int32
+}
+
+func (e *blockingExecutor) Kind() string { return "test" }
+
+func (e *blockingExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*ExecutorResponse, error) {
+	close(e.started)
+	<-e.release
+	return &ExecutorResponse{}, nil
+}
+
+func (e *blockingExecutor) Close() error {
+	e.closed.Add(1)
+	return nil
+}
+
+type nopTypeAdapter struct{}
+
+func (nopTypeAdapter) Name() string { return "nop" }
+
+func (nopTypeAdapter) BuildRequest(ctx context.Context, req *AgentRequest) (*ExecutorRequest, error) {
+	return &ExecutorRequest{}, nil
+}
+
+func (nopTypeAdapter) ParseResponse(execResp *ExecutorResponse) (*AgentResponse, error) {
+	return &AgentResponse{}, nil
+}
+
+func TestCombinedAdapterCloseWaitsForRunningExecutions(t *testing.T) {
+	exec := &blockingExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	adapter := NewCombinedAdapter(nopTypeAdapter{}, exec)
+
+	done := make(chan error)
+	go func() {
+		_, err := adapter.Execute(context.Background(), &AgentRequest{})
+		done <- err
+	}()
+	<-exec.started
+
+	if err := adapter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := exec.closed.Load(); n != 0 {
+		t.Fatalf("executor closed %d times while an execution was running", n)
+	}
+
+	close(exec.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := exec.closed.Load(); n != 1 {
+		t.Fatalf("executor closed %d times after the execution finished, want 1", n)
+	}
+}
+
+func TestCombinedAdapterCloseIdle(t *testing.T) {
+	exec := &blockingExecutor{}
+	if err := NewCombinedAdapter(nopTypeAdapter{}, exec).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := exec.closed.Load(); n != 1 {
+		t.Fatalf("executor closed %d times, want 1", n)
+	}
+}
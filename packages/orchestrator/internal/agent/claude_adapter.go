@@ -79,6 +79,12 @@ func (a *ClaudeCodeAdapter) BuildRequest(ctx context.Context, req *AgentRequest)
 	if req.GitRepoURL != "" {
 		env["GIT_REPO_URL"] = req.GitRepoURL
 	}
+	if req.GitReferenceRepo != "" {
+		env["GIT_REFERENCE_REPO"] = req.GitReferenceRepo
+	}
+	if req.PersistentWorkspace {
+		env["WORKSPACE_PERSISTENT"] = "true"
+	}
 	
 	// Base branch (for cloning)
 	baseBranch := req.GitBranch
@@ -140,16 +146,56 @@ func (a *ClaudeCodeAdapter) BuildRequest(ctx context.Context, req *AgentRequest)
 		}
 	}
 
+	// Session continuation: the entrypoint resumes the session with the short revision prompt
+	// when its files were restored, and falls back to a fresh session with AGENT_PROMPT otherwise
+	var sessionState []byte
+	if req.ResumeSession != nil && req.ResumeSession.ID != "" {
+		env["CLAUDE_RESUME_SESSION"] = req.ResumeSession.ID
+		env["AGENT_RESUME_PROMPT"] = a.promptBuilder.BuildResume(req)
+		sessionState = req.ResumeSession.State
+	}
+
+	// Tool approval gates: the entrypoint installs a PreToolUse hook that blocks matching
+	// calls until the executor delivers a decision
+	var approver ToolApprover
+	if req.ToolApproval != nil && len(req.ToolApproval.Rules) > 0 && req.ToolApprover != nil {
+		policy, err := req.ToolApproval.Env()
+		if err != nil {
+			return nil, fmt.Errorf("encode tool approval policy: %w", err)
+		}
+		env["TOOL_APPROVAL_POLICY"] = policy
+		approver = req.ToolApprover
+	}
+
 	// 3. Build executor request
 	return &ExecutorRequest{
-		Image:   a.image,
-		Command: nil, // Use image's ENTRYPOINT
-		Env:     env,
-		WorkDir: "/workspace",
-		Timeout: 10 * time.Minute,
+		Image:        a.image,
+		Command:      nil, // Use image's ENTRYPOINT
+		Env:          env,
+		WorkDir:      "/workspace",
+		Timeout:      10 * time.Minute,
+		Limits:       req.ContainerLimits,
+		Mounts:       req.Mounts,
+		SessionState: sessionState,
+		ToolApprover: approver,
 	}, nil
 }
 
+// claudeSession returns the CLI session an execution ended with: the session_id of the
+// final result event plus the session files the entrypoint saved to /output/session
+func claudeSession(resp *ExecutorResponse) *AgentSession {
+	if len(resp.SessionState) == 0 {
+		return nil
+	}
+	var result struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal([]byte(resp.Stdout), &result); err != nil || result.SessionID == "" {
+		return nil
+	}
+	return &AgentSession{ID: result.SessionID, State: resp.SessionState}
+}
+
 func (a *ClaudeCodeAdapter) ParseResponse(resp *ExecutorResponse) (*AgentResponse, error) {
 	if resp.ExitCode != 0 {
 		return nil, fmt.Errorf("claude execution failed (exit code %d): %s", resp.ExitCode, resp.Stderr)
@@ -169,6 +215,7 @@ func (a *ClaudeCodeAdapter) ParseResponse(resp *ExecutorResponse) (*AgentRespons
 				DurationMs: 0,
 			},
 			GitMetadata: resp.GitMetadata, // Pass through even on parse failure
+			Session:     claudeSession(resp),
 		}, nil
 	}
 
@@ -206,6 +253,7 @@ func (a *ClaudeCodeAdapter) ParseResponse(resp *ExecutorResponse) (*AgentRespons
 		Output:      output,
 		Metrics:     metrics,
 		GitMetadata: resp.GitMetadata, // Pass through from executor
+		Session:     claudeSession(resp),
 	}, nil
 }
 
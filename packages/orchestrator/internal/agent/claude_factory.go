@@ -16,10 +16,16 @@ func (f *ClaudeCodeFactory) CreateAdapter(logger *zap.SugaredLogger, providerID
 	authToken, _ := config["auth_token"].(string)
 	baseURL, _ := config["base_url"].(string)
 
+	limits, err := ContainerLimitsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	dockerExec, err := NewDockerExecutor(logger)
 	if err != nil {
 		return nil, err
 	}
+	dockerExec.SetDefaultLimits(limits)
 
 	adapter := NewClaudeCodeAdapter(f.PromptBuilder, providerID, baseURL, authToken, modelName)
 	return NewCombinedAdapter(adapter, dockerExec), nil
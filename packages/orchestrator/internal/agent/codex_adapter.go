@@ -78,6 +78,12 @@ func (a *CodexAdapter) BuildRequest(ctx context.Context, req *AgentRequest) (*Ex
 	if req.GitRepoURL != "" {
 		env["GIT_REPO_URL"] = req.GitRepoURL
 	}
+	if req.GitReferenceRepo != "" {
+		env["GIT_REFERENCE_REPO"] = req.GitReferenceRepo
+	}
+	if req.PersistentWorkspace {
+		env["WORKSPACE_PERSISTENT"] = "true"
+	}
 
 	baseBranch := req.GitBranch
 	if baseBranch == "" {
@@ -129,6 +135,10 @@ func (a *CodexAdapter) BuildRequest(ctx context.Context, req *AgentRequest) (*Ex
 		Env:     env,
 		WorkDir: "/workspace",
 		Timeout: 10 * time.Minute,
+		Limits:  req.ContainerLimits,
+		Mounts:  req.Mounts,
+
+		StreamFormat: StreamFormatCodex,
 	}, nil
 }
 
@@ -16,10 +16,16 @@ func (f *CodexFactory) CreateAdapter(logger *zap.SugaredLogger, providerID strin
 	apiKey, _ := config["api_key"].(string)
 	baseURL, _ := config["base_url"].(string)
 
+	limits, err := ContainerLimitsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	dockerExec, err := NewDockerExecutorWithImage(logger, "workgear/agent-codex:latest")
 	if err != nil {
 		return nil, err
 	}
+	dockerExec.SetDefaultLimits(limits)
 
 	adapter := NewCodexAdapter(f.PromptBuilder, providerID, apiKey, baseURL, modelName)
 	return NewCombinedAdapter(adapter, dockerExec), nil
@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Stream formats understood by DockerExecutor
+const (
+	StreamFormatClaude = ""           // Claude CLI --output-format stream-json (default)
+	StreamFormatCodex  = "codex-json" // codex exec --json (JSONL thread/turn/item events)
+)
+
+// codexEvent is a single line of `codex exec --json` output
+type codexEvent struct {
+	Type    string     `json:"type"` // thread.started / turn.started / item.started / item.completed / turn.completed / turn.failed / error
+	Item    *codexItem `json:"item,omitempty"`
+	Message string     `json:"message,omitempty"` // for "error"
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"` // for "turn.failed"
+}
+
+// codexItem is a thread item (agent message, command, file change, tool call, ...)
+type codexItem struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"` // agent_message / reasoning / command_execution / file_change / mcp_tool_call / web_search / todo_list
+	Text             string `json:"text,omitempty"`
+	Command          string `json:"command,omitempty"`
+	AggregatedOutput string `json:"aggregated_output,omitempty"`
+	ExitCode         *int   `json:"exit_code,omitempty"`
+	Status           string `json:"status,omitempty"`
+	Changes          []any  `json:"changes,omitempty"`
+	Server           string `json:"server,omitempty"`
+	Tool             string `json:"tool,omitempty"`
+	Query            string `json:"query,omitempty"`
+}
+
+// codexStream converts codex JSONL events into Claude-style stream events,
+// so log consumers handle both agents the same way
+type codexStream struct {
+	lastMessage string
+}
+
+func (s *codexStream) parse(line []byte) []ClaudeStreamEvent {
+	var evt codexEvent
+	if err := json.Unmarshal(line, &evt); err != nil || evt.Type == "" {
+		return nil
+	}
+
+	switch evt.Type {
+	case "item.started":
+		if evt.Item != nil {
+			if use := codexToolUse(evt.Item); use != nil {
+				return []ClaudeStreamEvent{messageEvent("assistant", *use)}
+			}
+		}
+	case "item.completed":
+		if evt.Item != nil {
+			return s.itemCompleted(evt.Item)
+		}
+	case "turn.completed":
+		return []ClaudeStreamEvent{{Type: "result", Subtype: "success", Result: s.lastMessage}}
+	case "turn.failed":
+		msg := "codex turn failed"
+		if evt.Error != nil && evt.Error.Message != "" {
+			msg = evt.Error.Message
+		}
+		return []ClaudeStreamEvent{{Type: "result", Subtype: "error", Result: msg}}
+	case "error":
+		return []ClaudeStreamEvent{{Type: "result", Subtype: "error", Result: evt.Message}}
+	}
+	return nil
+}
+
+func (s *codexStream) itemCompleted(item *codexItem) []ClaudeStreamEvent {
+	switch item.Type {
+	case "agent_message":
+		s.lastMessage = item.Text
+		return []ClaudeStreamEvent{messageEvent("assistant", ContentBlock{Type: "text", Text: item.Text})}
+	case "command_execution", "mcp_tool_call":
+		content := item.AggregatedOutput
+		if item.ExitCode != nil {
+			content = fmt.Sprintf("%s\n(exit code %d)", content, *item.ExitCode)
+		}
+		return []ClaudeStreamEvent{messageEvent("user", ContentBlock{Type: "tool_result", ToolUseID: item.ID, Content: content})}
+	case "file_change", "web_search":
+		// Reported once on completion; no separate start event worth showing
+		if use := codexToolUse(item); use != nil {
+			return []ClaudeStreamEvent{messageEvent("assistant", *use)}
+		}
+	}
+	return nil
+}
+
+// codexToolUse maps a started tool-like item to a tool_use block
+func codexToolUse(item *codexItem) *ContentBlock {
+	switch item.Type {
+	case "command_execution":
+		return &ContentBlock{Type: "tool_use", ID: item.ID, Name: "Bash", Input: map[string]any{"command": item.Command}}
+	case "mcp_tool_call":
+		return &ContentBlock{Type: "tool_use", ID: item.ID, Name: item.Server + "." + item.Tool, Input: map[string]any{}}
+	case "file_change":
+		return &ContentBlock{Type: "tool_use", ID: item.ID, Name: "Edit", Input: map[string]any{"changes": item.Changes, "status": item.Status}}
+	case "web_search":
+		return &ContentBlock{Type: "tool_use", ID: item.ID, Name: "WebSearch", Input: map[string]any{"query": item.Query}}
+	}
+	return nil
+}
+
+func messageEvent(role string, block ContentBlock) ClaudeStreamEvent {
+	return ClaudeStreamEvent{
+		Type:    role,
+		Message: &StreamMessage{Role: role, Content: []ContentBlock{block}},
+	}
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describeEvents flattens stream events to one line each for comparison
+func describeEvents(events []ClaudeStreamEvent) []string {
+	var lines []string
+	for _, e := range events {
+		if e.Message == nil {
+			lines = append(lines, fmt.Sprintf("%s/%s: %v", e.Type, e.Subtype, e.Result))
+			continue
+		}
+		for _, b := range e.Message.Content {
+			switch b.Type {
+			case "text":
+				lines = append(lines, fmt.Sprintf("%s text: %s", e.Type, b.Text))
+			case "tool_use":
+				lines = append(lines, fmt.Sprintf("%s tool_use %s %s: %v", e.Type, b.ID, b.Name, b.Input))
+			case "tool_result":
+				lines = append(lines, fmt.Sprintf("%s tool_result %s: %v", e.Type, b.ToolUseID, b.Content))
+			}
+		}
+	}
+	return lines
+}
+
+// parseCodexLines feeds recorded `codex exec --json` lines through one stream
+func parseCodexLines(lines ...string) []ClaudeStreamEvent {
+	s := &codexStream{}
+	var events []ClaudeStreamEvent
+	for _, line := range lines {
+		events = append(events, s.parse([]byte(line))...)
+	}
+	return events
+}
+
+func TestCodexStreamTurn(t *testing.T) {
+	events := parseCodexLines(
+		`{"type":"thread.started","thread_id":"0199a213-81c0-7800-8aa1-bbab2a035a53"}`,
+		`{"type":"turn.started"}`,
+		`{"type":"item.completed","item":{"id":"item_0","type":"reasoning","text":"**Scanning the repo**"}}`,
+		`{"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","aggregated_output":"","status":"in_progress"}}`,
+		`{"type":"item.completed","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","aggregated_output":"README.md\nsrc\n","exit_code":0,"status":"completed"}}`,
+		`{"type":"item.started","item":{"id":"item_2","type":"mcp_tool_call","server":"github","tool":"search_issues","status":"in_progress"}}`,
+		`{"type":"item.completed","item":{"id":"item_2","type":"mcp_tool_call","server":"github","tool":"search_issues","aggregated_output":"[]","status":"completed"}}`,
+		`{"type":"item.completed","item":{"id":"item_3","type":"file_change","changes":[{"path":"src/app.ts","kind":"update"}],"status":"completed"}}`,
+		`{"type":"item.completed","item":{"id":"item_4","type":"web_search","query":"codex exec json"}}`,
+		`{"type":"item.completed","item":{"id":"item_5","type":"agent_message","text":"draft"}}`,
+		`{"type":"item.completed","item":{"id":"item_6","type":"agent_message","text":"{\"summary\":\"done\"}"}}`,
+		`{"type":"turn.completed","usage":{"input_tokens":24763,"cached_input_tokens":24448,"output_tokens":122}}`,
+	)
+
+	want := []string{
+		"assistant tool_use item_1 Bash: map[command:bash -lc ls]",
+		"user tool_result item_1: README.md\nsrc\n\n(exit code 0)",
+		"assistant tool_use item_2 github.search_issues: map[]",
+		"user tool_result item_2: []",
+		"assistant tool_use item_3 Edit: map[changes:[map[kind:update path:src/app.ts]] status:completed]",
+		"assistant tool_use item_4 WebSearch: map[query:codex exec json]",
+		"assistant text: draft",
+		`assistant text: {"summary":"done"}`,
+		// The final output is the last agent message
+		`result/success: {"summary":"done"}`,
+	}
+	got := describeEvents(events)
+	if strings.Join(got, "\n---\n") != strings.Join(want, "\n---\n") {
+		t.Errorf("events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCodexStreamFailures(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "turn failed",
+			lines: []string{`{"type":"turn.failed","error":{"message":"stream disconnected before completion"}}`},
+			want:  []string{"result/error: stream disconnected before completion"},
+		},
+		{
+			name:  "turn failed without a message",
+			lines: []string{`{"type":"turn.failed"}`},
+			want:  []string{"result/error: codex turn failed"},
+		},
+		{
+			name:  "error event",
+			lines: []string{`{"type":"error","message":"unexpected status 401 Unauthorized"}`},
+			want:  []string{"result/error: unexpected status 401 Unauthorized"},
+		},
+		{
+			name:  "turn completed without an agent message",
+			lines: []string{`{"type":"turn.completed"}`},
+			want:  []string{"result/success: "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describeEvents(parseCodexLines(tt.lines...))
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodexStreamSkipsMalformedLines(t *testing.T) {
+	events := parseCodexLines(
+		`{"type":"item.completed","item":{"id":"item_0","type":"agent_message","text":"first"}}`,
+		// A line cut off mid-event, garbage, events without a type or item, unknown items
+		`{"type":"item.completed","item":{"id":"item_1","type":"agent_mess`,
+		`not json at all`,
+		``,
+		`{"item":{"id":"item_2","type":"agent_message","text":"no type"}}`,
+		`{"type":"item.completed"}`,
+		`{"type":"item.started"}`,
+		`{"type":"item.completed","item":{"id":"item_3","type":"todo_list","items":[]}}`,
+		`{"type":"item.started","item":{"id":"item_4","type":"agent_message","text":"partial"}}`,
+		`{"type":"turn.completed"}`,
+	)
+	want := []string{"assistant text: first", "result/success: first"}
+	if got := describeEvents(events); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/strslice"
+	units "github.com/docker/go-units"
+)
+
+// ContainerLimits holds resource limits and security options for agent containers.
+// Zero values mean "not set" (Docker defaults apply).
+type ContainerLimits struct {
+	Memory          int64    // bytes
+	NanoCPUs        int64    // CPU quota in units of 1e-9 CPUs
+	PidsLimit       int64    // max processes inside the container
+	ReadOnlyRootfs  bool     // mount rootfs read-only; /workspace, /output, /tmp and $HOME stay writable
+	NetworkMode     string   // e.g. "none", "bridge", or a user-defined egress allow-list network
+	CapDrop         []string // capabilities to drop, e.g. ["ALL"]
+	NoNewPrivileges bool     // set no-new-privileges security option
+}
+
+// writablePaths are kept writable when the root filesystem is read-only
+var (
+	writableVolumePaths = []string{"/workspace", "/output"}
+	writableTmpfsPaths  = []string{"/tmp", "/home/agent"}
+)
+
+// Merge returns provider-level limits overlaid with node-level limits.
+// Resource values from the node apply only when they are lower than the provider's (the
+// provider's value is a cap); security options are additive — a node can tighten the
+// provider's policy but never relax it.
+func (l *ContainerLimits) Merge(override *ContainerLimits) *ContainerLimits {
+	if l == nil && override == nil {
+		return nil
+	}
+	merged := &ContainerLimits{}
+	if l != nil {
+		*merged = *l
+		merged.CapDrop = append([]string(nil), l.CapDrop...)
+	}
+	if override == nil {
+		return merged
+	}
+
+	merged.Memory = tighterLimit(merged.Memory, override.Memory)
+	merged.NanoCPUs = tighterLimit(merged.NanoCPUs, override.NanoCPUs)
+	merged.PidsLimit = tighterLimit(merged.PidsLimit, override.PidsLimit)
+	if merged.NetworkMode == "" {
+		merged.NetworkMode = override.NetworkMode
+	}
+	merged.ReadOnlyRootfs = merged.ReadOnlyRootfs || override.ReadOnlyRootfs
+	merged.NoNewPrivileges = merged.NoNewPrivileges || override.NoNewPrivileges
+	for _, c := range override.CapDrop {
+		if !containsFold(merged.CapDrop, c) {
+			merged.CapDrop = append(merged.CapDrop, c)
+		}
+	}
+	return merged
+}
+
+// tighterLimit returns the lower of two resource limits, where 0 means "not set"
+func tighterLimit(limit, override int64) int64 {
+	if override <= 0 {
+		return limit
+	}
+	if limit <= 0 {
+		return override
+	}
+	return min(limit, override)
+}
+
+// HostConfig builds the Docker host config that enforces these limits
+func (l *ContainerLimits) HostConfig() *container.HostConfig {
+	if l == nil {
+		return nil
+	}
+
+	hc := &container.HostConfig{}
+	if l.Memory > 0 {
+		hc.Memory = l.Memory
+		hc.MemorySwap = l.Memory // disable swap beyond the memory limit
+	}
+	if l.NanoCPUs > 0 {
+		hc.NanoCPUs = l.NanoCPUs
+	}
+	if l.PidsLimit > 0 {
+		pids := l.PidsLimit
+		hc.PidsLimit = &pids
+	}
+	if l.NetworkMode != "" {
+		hc.NetworkMode = container.NetworkMode(l.NetworkMode)
+	}
+	if len(l.CapDrop) > 0 {
+		hc.CapDrop = strslice.StrSlice(l.CapDrop)
+	}
+	if l.NoNewPrivileges {
+		hc.SecurityOpt = append(hc.SecurityOpt, "no-new-privileges:true")
+	}
+	if l.ReadOnlyRootfs {
+		hc.ReadonlyRootfs = true
+		for _, p := range writableVolumePaths {
+			// Anonymous volumes: initialized from the image (keeps ownership), removed with the container
+			hc.Mounts = append(hc.Mounts, mount.Mount{Type: mount.TypeVolume, Target: p})
+		}
+		hc.Tmpfs = make(map[string]string, len(writableTmpfsPaths))
+		for _, p := range writableTmpfsPaths {
+			hc.Tmpfs[p] = "rw,exec,mode=1777"
+		}
+	}
+	return hc
+}
+
+// ContainerLimitsFromConfig reads container_* keys from a provider config.
+// Values may be JSON numbers/booleans or form strings. Returns nil if nothing is set.
+func ContainerLimitsFromConfig(config map[string]any) (*ContainerLimits, error) {
+	limits := &ContainerLimits{}
+	set := false
+
+	if v := configString(config, "container_memory"); v != "" {
+		mem, err := ParseMemoryLimit(v)
+		if err != nil {
+			return nil, err
+		}
+		limits.Memory = mem
+		set = true
+	}
+	if v := configString(config, "container_cpus"); v != "" {
+		cpus, err := ParseCPULimit(v)
+		if err != nil {
+			return nil, err
+		}
+		limits.NanoCPUs = cpus
+		set = true
+	}
+	if v := configString(config, "container_pids_limit"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid container_pids_limit: %s", v)
+		}
+		limits.PidsLimit = n
+		set = true
+	}
+	if v := configString(config, "container_network_mode"); v != "" {
+		limits.NetworkMode = v
+		set = true
+	}
+	if v := configString(config, "container_cap_drop"); v != "" {
+		for _, c := range strings.Split(v, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				limits.CapDrop = append(limits.CapDrop, c)
+			}
+		}
+		set = true
+	}
+	if v := configString(config, "container_read_only_rootfs"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container_read_only_rootfs: %s", v)
+		}
+		limits.ReadOnlyRootfs = b
+		set = true
+	}
+	if v := configString(config, "container_no_new_privileges"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container_no_new_privileges: %s", v)
+		}
+		limits.NoNewPrivileges = b
+		set = true
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return limits, nil
+}
+
+// ParseMemoryLimit parses a human-readable memory size such as "512m" or "2g"
+func ParseMemoryLimit(s string) (int64, error) {
+	mem, err := units.RAMInBytes(s)
+	if err != nil || mem < 0 {
+		return 0, fmt.Errorf("invalid memory limit: %s", s)
+	}
+	return mem, nil
+}
+
+// ParseCPULimit parses a fractional CPU count such as "1.5" into NanoCPUs
+func ParseCPULimit(s string) (int64, error) {
+	cpus, err := strconv.ParseFloat(s, 64)
+	if err != nil || cpus < 0 {
+		return 0, fmt.Errorf("invalid cpu limit: %s", s)
+	}
+	return int64(cpus * 1e9), nil
+}
+
+// configString reads a provider config value as a string, accepting numbers and booleans
+func configString(config map[string]any, key string) string {
+	switch v := config[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprintf("%v", item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return ""
+	}
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
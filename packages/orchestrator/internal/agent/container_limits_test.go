@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestContainerLimitsMerge(t *testing.T) {
+	provider := &ContainerLimits{Memory: 2 << 30, NanoCPUs: 2e9, CapDrop: []string{"NET_RAW"}}
+
+	tests := []struct {
+		name     string
+		provider *ContainerLimits
+		node     *ContainerLimits
+		want     ContainerLimits
+	}{
+		{
+			name:     "node cannot raise provider caps",
+			provider: provider,
+			node:     &ContainerLimits{Memory: 8 << 30, NanoCPUs: 4e9},
+			want:     ContainerLimits{Memory: 2 << 30, NanoCPUs: 2e9, CapDrop: []string{"NET_RAW"}},
+		},
+		{
+			name:     "node tightens provider caps",
+			provider: provider,
+			node:     &ContainerLimits{Memory: 512 << 20, NanoCPUs: 5e8, PidsLimit: 256},
+			want:     ContainerLimits{Memory: 512 << 20, NanoCPUs: 5e8, PidsLimit: 256, CapDrop: []string{"NET_RAW"}},
+		},
+		{
+			name: "node limits apply without provider caps",
+			node: &ContainerLimits{Memory: 1 << 30, NetworkMode: "none"},
+			want: ContainerLimits{Memory: 1 << 30, NetworkMode: "none"},
+		},
+		{
+			name:     "security options are additive",
+			provider: &ContainerLimits{NetworkMode: "bridge", ReadOnlyRootfs: true, CapDrop: []string{"NET_RAW"}},
+			node:     &ContainerLimits{NetworkMode: "host", NoNewPrivileges: true, CapDrop: []string{"net_raw", "ALL"}},
+			want:     ContainerLimits{NetworkMode: "bridge", ReadOnlyRootfs: true, NoNewPrivileges: true, CapDrop: []string{"NET_RAW", "ALL"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.provider.Merge(tt.node)
+			if got.Memory != tt.want.Memory || got.NanoCPUs != tt.want.NanoCPUs || got.PidsLimit != tt.want.PidsLimit ||
+				got.NetworkMode != tt.want.NetworkMode || got.ReadOnlyRootfs != tt.want.ReadOnlyRootfs ||
+				got.NoNewPrivileges != tt.want.NoNewPrivileges || !slices.Equal(got.CapDrop, tt.want.CapDrop) {
+				t.Errorf("Merge() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	if provider.Merge(&ContainerLimits{CapDrop: []string{"ALL"}}); !slices.Equal(provider.CapDrop, []string{"NET_RAW"}) {
+		t.Errorf("Merge modified the provider limits: %+v", provider.CapDrop)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	"go.uber.org/zap"
@@ -48,8 +50,8 @@ type ContentBlock struct {
 type DockerExecutor struct {
 	cli          *client.Client
 	defaultImage string
+	limits       *ContainerLimits // Provider-level container limits
 	logger       *zap.SugaredLogger
-	onLogEvent   func(event ClaudeStreamEvent) // Callback for real-time log events
 }
 
 // NewDockerExecutor creates a new Docker executor
@@ -80,6 +82,11 @@ func NewDockerExecutorWithImage(logger *zap.SugaredLogger, defaultImage string)
 
 func (e *DockerExecutor) Kind() string { return "docker" }
 
+// SetDefaultLimits sets provider-level resource limits and security options for every container
+func (e *DockerExecutor) SetDefaultLimits(limits *ContainerLimits) {
+	e.limits = limits
+}
+
 func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*ExecutorResponse, error) {
 	imageName := req.Image
 	if imageName == "" {
@@ -97,8 +104,11 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 	if timeout == 0 {
 		timeout = 10 * time.Minute
 	}
-	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	// The deadline pauses while a gated tool call waits for approval
+	execCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	deadline := newPausableDeadline(timeout, cancel)
+	defer deadline.Stop()
 
 	// 1. Ensure image exists locally
 	if err := e.ensureImage(execCtx, imageName); err != nil {
@@ -117,13 +127,23 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 
 	containerName := fmt.Sprintf("workgear-agent-%s-%d", req.Env["TASK_ID"], time.Now().UnixMilli())
 
-	e.logger.Infow("Creating agent container",
-		"image", imageName,
-		"container", containerName,
-		"timeout", timeout,
-	)
+	// Resource limits and security options (provider defaults + node overrides)
+	limits := e.limits.Merge(req.Limits)
+	hostConfig := containerHostConfig(limits, req.Mounts)
+
+	logFields := []any{"image", imageName, "container", containerName, "timeout", timeout, "mounts", len(req.Mounts)}
+	if limits != nil {
+		logFields = append(logFields,
+			"memory", limits.Memory,
+			"nano_cpus", limits.NanoCPUs,
+			"pids_limit", limits.PidsLimit,
+			"network_mode", limits.NetworkMode,
+			"read_only_rootfs", limits.ReadOnlyRootfs,
+		)
+	}
+	e.logger.Infow("Creating agent container", logFields...)
 
-	createResp, err := e.cli.ContainerCreate(execCtx, containerConfig, nil, nil, nil, containerName)
+	createResp, err := e.cli.ContainerCreate(execCtx, containerConfig, hostConfig, nil, nil, containerName)
 	if err != nil {
 		return nil, fmt.Errorf("create container: %w", err)
 	}
@@ -133,13 +153,20 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 	defer func() {
 		removeCtx, removeCancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer removeCancel()
-		if err := e.cli.ContainerRemove(removeCtx, containerID, container.RemoveOptions{Force: true}); err != nil {
+		if err := e.cli.ContainerRemove(removeCtx, containerID, container.RemoveOptions{Force: true, RemoveVolumes: true}); err != nil {
 			e.logger.Warnw("Failed to remove container", "container_id", containerID, "error", err)
 		} else {
 			e.logger.Infow("Removed agent container", "container_id", containerID[:12])
 		}
 	}()
 
+	// 2b. Restore a previous agent session (entrypoint resumes it if present; otherwise starts fresh)
+	if len(req.SessionState) > 0 {
+		if err := e.restoreSessionState(execCtx, containerID, req.SessionState); err != nil {
+			e.logger.Warnw("Failed to restore agent session, starting a new one", "container_id", containerID[:12], "error", err)
+		}
+	}
+
 	// 3. Start container
 	if err := e.cli.ContainerStart(execCtx, containerID, container.StartOptions{}); err != nil {
 		return nil, fmt.Errorf("start container: %w", err)
@@ -151,11 +178,18 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 	logStreamDone := make(chan struct{})
 	go func() {
 		defer close(logStreamDone)
-		if err := e.streamLogs(execCtx, containerID); err != nil {
+		if err := e.streamLogs(execCtx, containerID, req.StreamFormat, req.LogSink); err != nil {
 			e.logger.Debugw("Log stream ended", "error", err)
 		}
 	}()
 
+	// 4b. Relay tool approval requests of the container's hook
+	watchCtx, stopWatch := context.WithCancel(execCtx)
+	defer stopWatch()
+	if req.ToolApprover != nil {
+		go e.watchToolApprovals(watchCtx, containerID, req.ToolApprover, deadline)
+	}
+
 	// 5. Wait for completion
 	statusCh, errCh := e.cli.ContainerWait(execCtx, containerID, container.WaitConditionNotRunning)
 
@@ -178,6 +212,8 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 		return nil, fmt.Errorf("container execution timed out after %s", timeout)
 	}
 
+	stopWatch()
+
 	// 6. Wait for log stream to finish (ensure all logs are processed)
 	<-logStreamDone
 
@@ -187,8 +223,9 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 		return nil, fmt.Errorf("collect logs: %w", err)
 	}
 
-	// 8. Extract git metadata from container (before cleanup)
+	// 8. Extract git metadata and agent session files from container (before cleanup)
 	gitMetadata := e.extractGitMetadata(ctx, containerID)
+	sessionState := e.extractSessionState(ctx, containerID)
 
 	e.logger.Infow("Agent container finished",
 		"container_id", containerID[:12],
@@ -196,23 +233,49 @@ func (e *DockerExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*Ex
 		"stdout_len", len(stdout),
 		"stderr_len", len(stderr),
 		"has_git_metadata", gitMetadata != nil,
+		"session_state_bytes", len(sessionState),
 	)
 
 	return &ExecutorResponse{
-		ExitCode:    exitCode,
-		Stdout:      stdout,
-		Stderr:      stderr,
-		GitMetadata: gitMetadata,
+		ExitCode:     exitCode,
+		Stdout:       stdout,
+		Stderr:       stderr,
+		GitMetadata:  gitMetadata,
+		SessionState: sessionState,
 	}, nil
 }
 
-// SetLogEventCallback sets the callback for real-time log events
-func (e *DockerExecutor) SetLogEventCallback(callback func(ClaudeStreamEvent)) {
-	e.onLogEvent = callback
+// containerHostConfig builds the host config enforcing limits, with the extra mounts added
+func containerHostConfig(limits *ContainerLimits, mounts []Mount) *container.HostConfig {
+	hostConfig := limits.HostConfig()
+	if len(mounts) == 0 {
+		return hostConfig
+	}
+	if hostConfig == nil {
+		hostConfig = &container.HostConfig{}
+	}
+	for _, m := range mounts {
+		// An explicit mount replaces the anonymous volume a read-only rootfs adds at the same path
+		hostConfig.Mounts = slices.DeleteFunc(hostConfig.Mounts, func(existing mount.Mount) bool {
+			return existing.Target == m.Target
+		})
+		dockerMount := mount.Mount{
+			Type:     mount.Type(m.Type),
+			Source:   m.Source,
+			Target:   m.Target,
+			ReadOnly: m.ReadOnly,
+		}
+		if m.SubPath != "" {
+			dockerMount.VolumeOptions = &mount.VolumeOptions{Subpath: m.SubPath}
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, dockerMount)
+	}
+	return hostConfig
 }
 
-// streamLogs reads container logs in real-time and parses stream-json events
-func (e *DockerExecutor) streamLogs(ctx context.Context, containerID string) error {
+// streamLogs reads container logs in real-time, parses stream events in the given format
+// and forwards them to this execution's sink
+func (e *DockerExecutor) streamLogs(ctx context.Context, containerID, format string, sink LogSink) error {
 	logReader, err := e.cli.ContainerLogs(ctx, containerID, container.LogsOptions{
 		ShowStdout: true,
 		ShowStderr: true,
@@ -238,6 +301,7 @@ func (e *DockerExecutor) streamLogs(ctx context.Context, containerID string) err
 	scanner := bufio.NewScanner(stderrReader)
 	// Increase buffer size for potentially large JSON lines
 	scanner.Buffer(make([]byte, 0, 256*1024), 1024*1024)
+	codex := &codexStream{}
 	for scanner.Scan() {
 		line := scanner.Text()
 
@@ -253,42 +317,44 @@ func (e *DockerExecutor) streamLogs(ctx context.Context, containerID string) err
 			continue
 		}
 
-		var event ClaudeStreamEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			e.logger.Debugw("Failed to parse stream-json event", "error", err.Error())
-			continue
-		}
-
-		// Skip events without a type
-		if event.Type == "" {
-			truncated := line
-			if len(truncated) > 200 {
-				truncated = truncated[:200]
+		var events []ClaudeStreamEvent
+		if format == StreamFormatCodex {
+			events = codex.parse([]byte(line))
+		} else {
+			var event ClaudeStreamEvent
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				e.logger.Debugw("Failed to parse stream-json event", "error", err.Error())
+				continue
 			}
-			e.logger.Debugw("Skipping event with empty type", "raw", truncated)
-			continue
+			// Skip events without a type
+			if event.Type == "" {
+				truncated := line
+				if len(truncated) > 200 {
+					truncated = truncated[:200]
+				}
+				e.logger.Debugw("Skipping event with empty type", "raw", truncated)
+				continue
+			}
+			events = []ClaudeStreamEvent{event}
 		}
 
-		// Add timestamp
-		event.Timestamp = time.Now().UnixMilli()
+		for _, event := range events {
+			// Add timestamp
+			event.Timestamp = time.Now().UnixMilli()
 
-		// Log parsed event
-		contentBlockCount := 0
-		if event.Message != nil {
-			contentBlockCount = len(event.Message.Content)
-		}
-		e.logger.Infow("Parsed stream event",
-			"type", event.Type,
-			"subtype", event.Subtype,
-			"content_blocks", contentBlockCount,
-			"has_result", event.Result != nil,
-		)
-
-		// Trigger callback
-		if e.onLogEvent != nil {
-			e.onLogEvent(event)
-		} else {
-			e.logger.Warnw("Log event callback not set, event dropped")
+			// Log parsed event
+			contentBlockCount := 0
+			if event.Message != nil {
+				contentBlockCount = len(event.Message.Content)
+			}
+			e.logger.Infow("Parsed stream event",
+				"type", event.Type,
+				"subtype", event.Subtype,
+				"content_blocks", contentBlockCount,
+				"has_result", event.Result != nil,
+			)
+
+			emitLog(sink, event)
 		}
 	}
 
@@ -297,13 +363,18 @@ func (e *DockerExecutor) streamLogs(ctx context.Context, containerID string) err
 
 // ensureImage checks if the image exists locally, pulls if not
 func (e *DockerExecutor) ensureImage(ctx context.Context, imageName string) error {
-	_, _, err := e.cli.ImageInspectWithRaw(ctx, imageName)
+	return pullImageIfMissing(ctx, e.cli, e.logger, imageName)
+}
+
+// pullImageIfMissing pulls imageName unless it already exists locally
+func pullImageIfMissing(ctx context.Context, cli *client.Client, logger *zap.SugaredLogger, imageName string) error {
+	_, _, err := cli.ImageInspectWithRaw(ctx, imageName)
 	if err == nil {
 		return nil // Image exists
 	}
 
-	e.logger.Infow("Pulling agent image", "image", imageName)
-	reader, err := e.cli.ImagePull(ctx, imageName, image.PullOptions{})
+	logger.Infow("Pulling agent image", "image", imageName)
+	reader, err := cli.ImagePull(ctx, imageName, image.PullOptions{})
 	if err != nil {
 		return fmt.Errorf("pull image: %w", err)
 	}
@@ -381,6 +452,44 @@ func (e *DockerExecutor) extractGitMetadata(ctx context.Context, containerID str
 	return nil
 }
 
+// sessionRestoreDir receives the session archive (a "session/" directory) before the container
+// starts. /output is a volume even with a read-only rootfs, where /tmp is a tmpfs that only
+// exists once the container runs, so copying there fails.
+const sessionRestoreDir = "/output"
+
+// restoreSessionState copies a session archive from extractSessionState into a created container
+func (e *DockerExecutor) restoreSessionState(ctx context.Context, containerID string, state []byte) error {
+	return e.cli.CopyToContainer(ctx, containerID, sessionRestoreDir, bytes.NewReader(state), container.CopyToContainerOptions{})
+}
+
+// maxSessionStateBytes caps the session archive kept for resuming (larger sessions are dropped)
+const maxSessionStateBytes = 32 << 20
+
+// extractSessionState returns /output/session from a stopped container as a tar archive
+// (nil if the agent wrote no session files)
+func (e *DockerExecutor) extractSessionState(ctx context.Context, containerID string) []byte {
+	reader, stat, err := e.cli.CopyFromContainer(ctx, containerID, "/output/session")
+	if err != nil {
+		e.logger.Debugw("No agent session files in container", "error", err)
+		return nil
+	}
+	defer reader.Close()
+	if !stat.Mode.IsDir() {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxSessionStateBytes+1))
+	if err != nil {
+		e.logger.Warnw("Failed to read agent session files", "error", err)
+		return nil
+	}
+	if len(data) > maxSessionStateBytes {
+		e.logger.Warnw("Agent session files too large, session will not be resumable", "limit_bytes", maxSessionStateBytes)
+		return nil
+	}
+	return data
+}
+
 // Close releases the Docker client resources
 func (e *DockerExecutor) Close() error {
 	return e.cli.Close()
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"go.uber.org/zap"
+)
+
+// fakeDocker is a minimal Docker daemon for one container. Like the real daemon its archive
+// API cannot reach tmpfs mounts and refuses uploads onto a read-only rootfs unless the path
+// is on a volume.
+type fakeDocker struct {
+	mu       sync.Mutex
+	host     container.HostConfig
+	started  bool
+	archives map[string][]byte // archive path → tar served for it
+	uploads  map[string][]byte // archive path → tar uploaded there
+	rejected []string          // archive paths refused
+}
+
+var fakeDockerRoute = regexp.MustCompile(`^/v[0-9.]+(/.*)$`)
+
+func (d *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	m := fakeDockerRoute.FindStringSubmatch(r.URL.Path)
+	if m == nil {
+		http.NotFound(w, r)
+		return
+	}
+	route := r.Method + " " + m[1]
+	switch {
+	case strings.HasPrefix(route, "GET /images/"):
+		_ = json.NewEncoder(w).Encode(map[string]any{"Id": "sha256:fake"})
+	case route == "POST /containers/create":
+		var body struct{ HostConfig container.HostConfig }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		d.host = body.HostConfig
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"Id": "0123456789abcdef"})
+	case route == "GET /containers/0123456789abcdef/archive":
+		data, ok := d.archives[r.URL.Query().Get("path")]
+		if !ok {
+			http.Error(w, `{"message":"no such file or directory"}`, http.StatusNotFound)
+			return
+		}
+		stat, _ := json.Marshal(container.PathStat{Name: path.Base(r.URL.Query().Get("path")), Mode: os.ModeDir | 0o755})
+		w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+		_, _ = w.Write(data)
+	case route == "PUT /containers/0123456789abcdef/archive":
+		path := r.URL.Query().Get("path")
+		if !d.writable(path) {
+			d.rejected = append(d.rejected, path)
+			http.Error(w, `{"message":"container rootfs is marked read-only"}`, http.StatusInternalServerError)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		d.uploads[path] = data
+	case route == "POST /containers/0123456789abcdef/start":
+		d.started = true
+		w.WriteHeader(http.StatusNoContent)
+	case route == "GET /containers/0123456789abcdef/logs":
+		w.WriteHeader(http.StatusOK)
+	case route == "POST /containers/0123456789abcdef/wait":
+		_ = json.NewEncoder(w).Encode(map[string]any{"StatusCode": 0})
+	case route == "DELETE /containers/0123456789abcdef":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}
+}
+
+// writable reports whether an archive extracted at p would be seen inside the container
+func (d *fakeDocker) writable(p string) bool {
+	under := func(target string) bool { return p == target || strings.HasPrefix(p, target+"/") }
+	for target := range d.host.Tmpfs {
+		if under(target) {
+			return false
+		}
+	}
+	if !d.host.ReadonlyRootfs {
+		return true
+	}
+	for _, m := range d.host.Mounts {
+		if m.Type == mount.TypeVolume && under(m.Target) {
+			return true
+		}
+	}
+	return false
+}
+
+func newFakeDockerExecutor(t *testing.T) (*DockerExecutor, *fakeDocker) {
+	t.Helper()
+	daemon := &fakeDocker{archives: map[string][]byte{}, uploads: map[string][]byte{}}
+	srv := httptest.NewServer(daemon)
+	t.Cleanup(srv.Close)
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+srv.Listener.Addr().String()), client.WithVersion("1.44"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = cli.Close() })
+	return &DockerExecutor{cli: cli, defaultImage: "workgear/agent-claude:test", logger: zap.NewNop().Sugar()}, daemon
+}
+
+// sessionArchive builds the tar extractSessionState returns for a saved session
+func sessionArchive(t *testing.T, sessionID string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := []byte(`{"type":"user","sessionId":"` + sessionID + `"}` + "\n")
+	_ = tw.WriteHeader(&tar.Header{Name: "session/", Typeflag: tar.TypeDir, Mode: 0o755})
+	_ = tw.WriteHeader(&tar.Header{Name: "session/" + sessionID + ".jsonl", Mode: 0o644, Size: int64(len(content))})
+	_, _ = tw.Write(content)
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDockerExecutorRestoresSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits *ContainerLimits
+	}{
+		{"writable rootfs", nil},
+		{"read-only rootfs", &ContainerLimits{ReadOnlyRootfs: true, CapDrop: []string{"ALL"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, daemon := newFakeDockerExecutor(t)
+			exec.SetDefaultLimits(tt.limits)
+			state := sessionArchive(t, "sess-1")
+
+			if _, err := exec.Execute(context.Background(), &ExecutorRequest{
+				Env:          map[string]string{"TASK_ID": "t1", "CLAUDE_RESUME_SESSION": "sess-1"},
+				SessionState: state,
+			}); err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+
+			daemon.mu.Lock()
+			defer daemon.mu.Unlock()
+			if len(daemon.rejected) > 0 {
+				t.Fatalf("session restore was refused at %v", daemon.rejected)
+			}
+			// The entrypoint looks for <restore dir>/session/<id>.jsonl
+			if !bytes.Equal(daemon.uploads[sessionRestoreDir], state) {
+				t.Errorf("uploads = %v, want the session archive at %s", slices.Sorted(maps.Keys(daemon.uploads)), sessionRestoreDir)
+			}
+			if !daemon.started {
+				t.Error("container was not started")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultHTTPChatTimeout bounds a chat API call without provider or node timeout
+const defaultHTTPChatTimeout = 5 * time.Minute
+
+// HTTPChatAdapter is a TypeAdapter that sends the built prompt straight to a chat API.
+// Only modes that don't need repository access are supported.
+type HTTPChatAdapter struct {
+	promptBuilder *PromptBuilder
+	providerID    string
+	model         string
+	timeout       time.Duration // provider-level timeout (0 = defaultHTTPChatTimeout)
+}
+
+// NewHTTPChatAdapter creates a new HTTP chat adapter with provider-level config
+func NewHTTPChatAdapter(promptBuilder *PromptBuilder, providerID, model string) *HTTPChatAdapter {
+	return &HTTPChatAdapter{
+		promptBuilder: promptBuilder,
+		providerID:    providerID,
+		model:         model,
+	}
+}
+
+func (a *HTTPChatAdapter) Name() string { return "http-chat" }
+
+// SetTimeout sets the provider-level request timeout; a node's `timeout` overrides it
+func (a *HTTPChatAdapter) SetTimeout(timeout time.Duration) {
+	a.timeout = timeout
+}
+
+// repoModes are modes that need a git checkout and therefore a container
+var repoModes = map[string]bool{
+	"execute":    true,
+	"opsx_plan":  true,
+	"opsx_apply": true,
+}
+
+func (a *HTTPChatAdapter) BuildRequest(ctx context.Context, req *AgentRequest) (*ExecutorRequest, error) {
+	if repoModes[req.Mode] {
+		return nil, fmt.Errorf("mode %s requires repository access and is not supported by the http-chat agent", req.Mode)
+	}
+
+	prompt := a.promptBuilder.Build(req)
+	if req.Mode == "test" && strings.TrimSpace(prompt) == "" {
+		prompt = `Reply with "HTTP agent test successful" and nothing else.`
+	}
+
+	// Model selection: req.Model (highest priority) > a.model (global default)
+	model := req.Model
+	if model == "" {
+		model = a.model
+	}
+	if model == "" {
+		return nil, fmt.Errorf("no model configured for agent request (task=%s, node=%s)", req.TaskID, req.NodeID)
+	}
+
+	// Timeout: node `timeout` > provider timeout > default
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = a.timeout
+	}
+	if timeout <= 0 {
+		timeout = defaultHTTPChatTimeout
+	}
+
+	return &ExecutorRequest{
+		Env: map[string]string{
+			"AGENT_MODE": req.Mode,
+			"TASK_ID":    req.TaskID,
+			"NODE_ID":    req.NodeID,
+		},
+		Timeout: timeout,
+		Prompt:  prompt,
+		Model:   model,
+	}, nil
+}
+
+func (a *HTTPChatAdapter) ParseResponse(resp *ExecutorResponse) (*AgentResponse, error) {
+	if resp.ExitCode != 0 {
+		return nil, fmt.Errorf("http chat execution failed (exit code %d): %s", resp.ExitCode, resp.Stderr)
+	}
+
+	metrics := resp.Metrics
+	if metrics == nil {
+		metrics = &ExecutionMetrics{}
+	}
+
+	return &AgentResponse{
+		Output:  parseChatOutput(resp.Stdout),
+		Metrics: metrics,
+	}, nil
+}
+
+// parseChatOutput converts the model's reply into a node output.
+// A JSON object reply (optionally wrapped in a ```json fence) becomes the output directly;
+// anything else is stored under "result".
+func parseChatOutput(text string) map[string]any {
+	trimmed := strings.TrimSpace(text)
+	if body, ok := stripCodeFence(trimmed); ok {
+		trimmed = body
+	}
+
+	var output map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &output); err == nil && output != nil {
+		return output
+	}
+
+	return map[string]any{
+		"result": strings.TrimSpace(text),
+	}
+}
+
+// stripCodeFence returns the body of a markdown code fence that wraps the whole text
+func stripCodeFence(text string) (string, bool) {
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return "", false
+	}
+	body := strings.TrimSuffix(text[3:], "```")
+	// Drop the language tag on the opening line (e.g. ```json)
+	if nl := strings.Index(body, "\n"); nl >= 0 {
+		body = body[nl+1:]
+	} else {
+		return "", false
+	}
+	return strings.TrimSpace(body), true
+}
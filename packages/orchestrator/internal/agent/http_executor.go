@@ -0,0 +1,372 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Supported chat API formats for HTTPExecutor
+const (
+	APIFormatAnthropic = "anthropic" // Anthropic Messages API (/v1/messages)
+	APIFormatOpenAI    = "openai"    // OpenAI Chat Completions API (/v1/chat/completions)
+)
+
+const defaultHTTPMaxTokens = 8192
+
+// HTTPExecutor calls an Anthropic/OpenAI-compatible chat API directly (no container).
+// Suitable for lightweight modes that don't need repository access.
+type HTTPExecutor struct {
+	client    *http.Client
+	apiFormat string
+	baseURL   string
+	apiKey    string
+	maxTokens int
+	logger    *zap.SugaredLogger
+}
+
+// NewHTTPExecutor creates a new HTTP chat executor
+func NewHTTPExecutor(logger *zap.SugaredLogger, apiFormat, baseURL, apiKey string) (*HTTPExecutor, error) {
+	switch apiFormat {
+	case "":
+		apiFormat = APIFormatAnthropic
+	case APIFormatAnthropic, APIFormatOpenAI:
+	default:
+		return nil, fmt.Errorf("unsupported chat API format: %s", apiFormat)
+	}
+
+	if baseURL == "" {
+		if apiFormat == APIFormatOpenAI {
+			baseURL = "https://api.openai.com"
+		} else {
+			baseURL = "https://api.anthropic.com"
+		}
+	}
+
+	return &HTTPExecutor{
+		client:    &http.Client{},
+		apiFormat: apiFormat,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		apiKey:    apiKey,
+		maxTokens: defaultHTTPMaxTokens,
+		logger:    logger,
+	}, nil
+}
+
+// SetHTTPClient overrides the underlying HTTP client (e.g. for custom transports)
+func (e *HTTPExecutor) SetHTTPClient(client *http.Client) {
+	e.client = client
+}
+
+// SetMaxTokens overrides the max_tokens sent with each request
+func (e *HTTPExecutor) SetMaxTokens(maxTokens int) {
+	if maxTokens > 0 {
+		e.maxTokens = maxTokens
+	}
+}
+
+func (e *HTTPExecutor) Kind() string { return "http" }
+
+func (e *HTTPExecutor) Execute(ctx context.Context, req *ExecutorRequest) (*ExecutorResponse, error) {
+	if req.Model == "" {
+		return nil, fmt.Errorf("no model specified for http executor")
+	}
+
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Minute
+	}
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	httpReq, err := e.buildHTTPRequest(execCtx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	e.logger.Infow("Calling chat API",
+		"format", e.apiFormat,
+		"url", httpReq.URL.String(),
+		"model", req.Model,
+		"prompt_len", len(req.Prompt),
+	)
+
+	start := time.Now()
+	httpResp, err := e.client.Do(httpReq)
+	if err != nil {
+		if execCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("chat API request timed out after %s", timeout)
+		}
+		return nil, fmt.Errorf("chat API request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		return nil, fmt.Errorf("chat API returned HTTP %d: %s", httpResp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	stream := &chatStream{sink: req.LogSink}
+	if e.apiFormat == APIFormatOpenAI {
+		err = readSSE(httpResp.Body, stream.handleOpenAI)
+	} else {
+		err = readSSE(httpResp.Body, stream.handleAnthropic)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read chat stream: %w", err)
+	}
+	stream.flush(true)
+
+	text := stream.text.String()
+	emitLog(req.LogSink, ClaudeStreamEvent{Type: "result", Subtype: "success", Result: text})
+
+	metrics := &ExecutionMetrics{
+		TokenInput:  stream.inputTokens,
+		TokenOutput: stream.outputTokens,
+		DurationMs:  time.Since(start).Milliseconds(),
+	}
+
+	e.logger.Infow("Chat API finished",
+		"format", e.apiFormat,
+		"output_len", len(text),
+		"token_input", metrics.TokenInput,
+		"token_output", metrics.TokenOutput,
+		"duration_ms", metrics.DurationMs,
+	)
+
+	return &ExecutorResponse{
+		ExitCode: 0,
+		Stdout:   text,
+		Metrics:  metrics,
+	}, nil
+}
+
+// buildHTTPRequest builds the streaming chat request for the configured API format
+func (e *HTTPExecutor) buildHTTPRequest(ctx context.Context, req *ExecutorRequest) (*http.Request, error) {
+	messages := []map[string]any{
+		{"role": "user", "content": req.Prompt},
+	}
+
+	var url string
+	body := map[string]any{
+		"model":      req.Model,
+		"max_tokens": e.maxTokens,
+		"messages":   messages,
+		"stream":     true,
+	}
+	if e.apiFormat == APIFormatOpenAI {
+		url = e.baseURL + "/v1/chat/completions"
+		body["stream_options"] = map[string]any{"include_usage": true}
+	} else {
+		url = e.baseURL + "/v1/messages"
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal chat request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create chat request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	if e.apiFormat == APIFormatOpenAI {
+		if e.apiKey != "" {
+			httpReq.Header.Set("Authorization", "Bearer "+e.apiKey)
+		}
+	} else {
+		httpReq.Header.Set("anthropic-version", "2023-06-01")
+		if e.apiKey != "" {
+			httpReq.Header.Set("x-api-key", e.apiKey)
+		}
+	}
+
+	return httpReq, nil
+}
+
+// chatStream accumulates streamed text and usage from SSE events
+type chatStream struct {
+	sink         LogSink
+	text         strings.Builder
+	pending      strings.Builder // text not yet emitted as a log event
+	inputTokens  int
+	outputTokens int
+}
+
+// flush emits pending text as an "assistant" log event.
+// Unless force is set, only complete paragraphs are emitted so the log isn't flooded with deltas.
+func (s *chatStream) flush(force bool) {
+	pending := s.pending.String()
+	if pending == "" {
+		return
+	}
+	cut := len(pending)
+	if !force {
+		cut = strings.LastIndex(pending, "\n\n")
+		if cut < 0 {
+			return
+		}
+		cut += 2
+	}
+	if chunk := strings.TrimSpace(pending[:cut]); chunk != "" {
+		emitLog(s.sink, ClaudeStreamEvent{
+			Type: "assistant",
+			Message: &StreamMessage{
+				Role:    "assistant",
+				Content: []ContentBlock{{Type: "text", Text: chunk}},
+			},
+		})
+	}
+	s.pending.Reset()
+	s.pending.WriteString(pending[cut:])
+}
+
+func (s *chatStream) appendText(text string) {
+	s.text.WriteString(text)
+	s.pending.WriteString(text)
+	s.flush(false)
+}
+
+// handleAnthropic processes a single Anthropic Messages API stream event
+func (s *chatStream) handleAnthropic(eventType, data string) error {
+	var evt struct {
+		Type    string `json:"type"`
+		Message struct {
+			Usage struct {
+				InputTokens  int `json:"input_tokens"`
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+		} `json:"message"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+		Usage struct {
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+		Error *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &evt); err != nil {
+		return fmt.Errorf("parse anthropic event %q: %w", eventType, err)
+	}
+	if evt.Type == "" {
+		evt.Type = eventType
+	}
+
+	switch evt.Type {
+	case "message_start":
+		s.inputTokens = evt.Message.Usage.InputTokens
+		s.outputTokens = evt.Message.Usage.OutputTokens
+	case "content_block_delta":
+		if evt.Delta.Type == "text_delta" {
+			s.appendText(evt.Delta.Text)
+		}
+	case "content_block_stop":
+		s.flush(true)
+	case "message_delta":
+		if evt.Usage.OutputTokens > 0 {
+			s.outputTokens = evt.Usage.OutputTokens
+		}
+	case "error":
+		if evt.Error != nil {
+			return fmt.Errorf("%s: %s", evt.Error.Type, evt.Error.Message)
+		}
+		return fmt.Errorf("anthropic stream error: %s", data)
+	}
+	return nil
+}
+
+// handleOpenAI processes a single Chat Completions stream chunk
+func (s *chatStream) handleOpenAI(_ string, data string) error {
+	if data == "[DONE]" {
+		return nil
+	}
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+			FinishReason *string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage *struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+		return fmt.Errorf("parse openai chunk: %w", err)
+	}
+	if chunk.Error != nil {
+		return fmt.Errorf("openai stream error: %s", chunk.Error.Message)
+	}
+
+	for _, choice := range chunk.Choices {
+		if choice.Delta.Content != "" {
+			s.appendText(choice.Delta.Content)
+		}
+		if choice.FinishReason != nil {
+			s.flush(true)
+		}
+	}
+	if chunk.Usage != nil {
+		s.inputTokens = chunk.Usage.PromptTokens
+		s.outputTokens = chunk.Usage.CompletionTokens
+	}
+	return nil
+}
+
+// readSSE reads a text/event-stream body and calls fn for every dispatched event
+func readSSE(r io.Reader, fn func(eventType, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	eventType := ""
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			eventType = ""
+			return nil
+		}
+		err := fn(eventType, strings.Join(data, "\n"))
+		eventType = ""
+		data = data[:0]
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
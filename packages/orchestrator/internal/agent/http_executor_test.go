@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// chatStub serves a canned SSE stream and records the last request's headers
+type chatStub struct {
+	t       *testing.T
+	path    string
+	events  []string
+	mu      sync.Mutex
+	header  http.Header
+	delay   time.Duration
+	status  int
+	errBody string
+}
+
+func (s *chatStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.header = r.Header.Clone()
+	s.mu.Unlock()
+	if r.URL.Path != s.path {
+		s.t.Errorf("path = %s, want %s", r.URL.Path, s.path)
+	}
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		fmt.Fprint(w, s.errBody)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, e := range s.events {
+		fmt.Fprint(w, e+"\n\n")
+	}
+}
+
+// lastHeader returns the headers of the last request served
+func (s *chatStub) lastHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+func newChatAdapter(t *testing.T, config map[string]any) Adapter {
+	t.Helper()
+	adapter, err := (&HTTPChatFactory{PromptBuilder: NewPromptBuilder()}).CreateAdapter(zap.NewNop().Sugar(), "p1", config, "test-model")
+	if err != nil {
+		t.Fatalf("CreateAdapter: %v", err)
+	}
+	return adapter
+}
+
+func TestHTTPChatAnthropic(t *testing.T) {
+	stub := &chatStub{t: t, path: "/v1/messages", events: []string{
+		`event: message_start` + "\n" + `data: {"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"{\"answer\": "}}`,
+		`event: content_block_delta` + "\n" + `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"42}"}}`,
+		`event: content_block_stop` + "\n" + `data: {"type":"content_block_stop"}`,
+		`event: message_delta` + "\n" + `data: {"type":"message_delta","usage":{"output_tokens":7}}`,
+	}}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	adapter := newChatAdapter(t, map[string]any{"provider_type": "anthropic", "base_url": srv.URL, "api_key": "sk-test-key"})
+	var events []ClaudeStreamEvent
+	resp, err := adapter.Execute(context.Background(), &AgentRequest{
+		Mode:    "spec",
+		Prompt:  "question",
+		LogSink: LogSinkFunc(func(e ClaudeStreamEvent) { events = append(events, e) }),
+	})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if resp.Output["answer"] != float64(42) {
+		t.Errorf("output = %v", resp.Output)
+	}
+	if resp.Metrics.TokenInput != 12 || resp.Metrics.TokenOutput != 7 {
+		t.Errorf("metrics = %+v", resp.Metrics)
+	}
+	if got := stub.lastHeader().Get("x-api-key"); got != "sk-test-key" {
+		t.Errorf("x-api-key = %q", got)
+	}
+	if got := stub.lastHeader().Get("Authorization"); got != "" {
+		t.Errorf("Authorization must not be sent to the Anthropic API, got %q", got)
+	}
+	if len(events) == 0 || events[len(events)-1].Type != "result" {
+		t.Errorf("log events = %+v", events)
+	}
+}
+
+func TestHTTPChatOpenAI(t *testing.T) {
+	stub := &chatStub{t: t, path: "/v1/chat/completions", events: []string{
+		`data: {"choices":[{"delta":{"content":"hello "}}]}`,
+		`data: {"choices":[{"delta":{"content":"world"},"finish_reason":"stop"}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2}}`,
+		`data: [DONE]`,
+	}}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	adapter := newChatAdapter(t, map[string]any{"provider_type": "openai", "base_url": srv.URL, "api_key": "sk-test-key"})
+	resp, err := adapter.Execute(context.Background(), &AgentRequest{Mode: "spec", Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if resp.Output["result"] != "hello world" {
+		t.Errorf("output = %v", resp.Output)
+	}
+	if resp.Metrics.TokenInput != 3 || resp.Metrics.TokenOutput != 2 {
+		t.Errorf("metrics = %+v", resp.Metrics)
+	}
+	if got := stub.lastHeader().Get("Authorization"); got != "Bearer sk-test-key" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := stub.lastHeader().Get("x-api-key"); got != "" {
+		t.Errorf("x-api-key must not be sent to the OpenAI API, got %q", got)
+	}
+}
+
+func TestHTTPChatErrorStatusIsRedacted(t *testing.T) {
+	stub := &chatStub{t: t, path: "/v1/messages", status: http.StatusUnauthorized, errBody: `{"error":"bad key sk-test-key"}`}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	adapter := newChatAdapter(t, map[string]any{"base_url": srv.URL, "api_key": "sk-test-key"})
+	redactor := NewRedactor(nil)
+	redactor.AddSecrets("sk-test-key")
+	_, err := adapter.Execute(context.Background(), &AgentRequest{Mode: "spec", Prompt: "hi", Redactor: redactor})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Fatalf("err = %v, want HTTP 401", err)
+	}
+	if strings.Contains(err.Error(), "sk-test-key") {
+		t.Errorf("error leaks the key: %v", err)
+	}
+}
+
+func TestHTTPChatTimeout(t *testing.T) {
+	stub := &chatStub{t: t, path: "/v1/messages", delay: time.Second}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	// Provider timeout applies unless the node sets one
+	adapter := newChatAdapter(t, map[string]any{"base_url": srv.URL, "timeout": "100ms"})
+	start := time.Now()
+	_, err := adapter.Execute(context.Background(), &AgentRequest{Mode: "spec", Prompt: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Fatalf("err = %v, want provider timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed >= stub.delay {
+		t.Errorf("timeout took %s", elapsed)
+	}
+
+	_, err = adapter.Execute(context.Background(), &AgentRequest{Mode: "spec", Prompt: "hi", Timeout: 50 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Fatalf("err = %v, want node timeout", err)
+	}
+}
+
+func TestHTTPChatTimeoutConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  any
+		want    time.Duration
+		wantErr bool
+	}{
+		{"default", nil, defaultHTTPChatTimeout, false},
+		{"duration", "90s", 90 * time.Second, false},
+		{"seconds string", "120", 2 * time.Minute, false},
+		{"seconds number", float64(30), 30 * time.Second, false},
+		{"invalid", "soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]any{}
+			if tt.config != nil {
+				config["timeout"] = tt.config
+			}
+			adapter, err := (&HTTPChatFactory{PromptBuilder: NewPromptBuilder()}).CreateAdapter(zap.NewNop().Sugar(), "p1", config, "m")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			execReq, err := adapter.(*CombinedAdapter).typeAdapter.BuildRequest(context.Background(), &AgentRequest{Mode: "spec"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if execReq.Timeout != tt.want {
+				t.Errorf("timeout = %s, want %s", execReq.Timeout, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HTTPChatFactory creates adapters for the http-chat agent type (no container)
+type HTTPChatFactory struct {
+	PromptBuilder *PromptBuilder
+}
+
+func (f *HTTPChatFactory) AgentType() string { return "http-chat" }
+
+func (f *HTTPChatFactory) CreateAdapter(logger *zap.SugaredLogger, providerID string, config map[string]any, modelName string) (Adapter, error) {
+	// provider_type selects the wire format: "anthropic" (Messages) or "openai" (Chat Completions)
+	providerType, _ := config["provider_type"].(string)
+	baseURL, _ := config["base_url"].(string)
+	apiKey, _ := config["api_key"].(string)
+
+	httpExec, err := NewHTTPExecutor(logger, providerType, baseURL, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// max_tokens may arrive as a JSON number or a form string
+	switch v := config["max_tokens"].(type) {
+	case float64:
+		httpExec.SetMaxTokens(int(v))
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			httpExec.SetMaxTokens(n)
+		}
+	}
+
+	adapter := NewHTTPChatAdapter(f.PromptBuilder, providerID, modelName)
+
+	// timeout may be a duration string ("90s", "5m") or a number of seconds
+	switch v := config["timeout"].(type) {
+	case float64:
+		adapter.SetTimeout(time.Duration(v * float64(time.Second)))
+	case string:
+		if v != "" {
+			timeout, err := time.ParseDuration(v)
+			if err != nil {
+				if seconds, convErr := strconv.Atoi(v); convErr == nil {
+					timeout, err = time.Duration(seconds)*time.Second, nil
+				}
+			}
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid timeout %q", v)
+			}
+			adapter.SetTimeout(timeout)
+		}
+	}
+	return NewCombinedAdapter(adapter, httpExec), nil
+}
@@ -0,0 +1,404 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// BuiltinOutputSchemas are named output contracts usable as `config.output_schema: <name>`
+var BuiltinOutputSchemas = map[string]map[string]any{
+	"review": {
+		"type":     "object",
+		"required": []any{"passed", "issues"},
+		"properties": map[string]any{
+			"passed":  map[string]any{"type": "boolean"},
+			"summary": map[string]any{"type": "string"},
+			"issues": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":     "object",
+					"required": []any{"description"},
+					"properties": map[string]any{
+						"severity":    map[string]any{"type": "string", "enum": []any{"critical", "major", "minor", "info"}},
+						"file":        map[string]any{"type": "string"},
+						"description": map[string]any{"type": "string"},
+					},
+				},
+			},
+		},
+	},
+	"change_name": {
+		"type":     "object",
+		"required": []any{"change_name"},
+		"properties": map[string]any{
+			"change_name": map[string]any{"type": "string", "pattern": "^[a-z0-9]+(-[a-z0-9]+)*$", "maxLength": 50},
+		},
+	},
+}
+
+// maxSchemaErrors caps the validation errors reported (and fed back in repair prompts)
+const maxSchemaErrors = 20
+
+// schemaKeywords are the JSON Schema keywords ValidateSchema enforces; schemaAnnotations
+// are accepted but have no effect on validation
+var (
+	schemaKeywords = []string{"type", "enum", "const", "properties", "required", "additionalProperties",
+		"items", "minItems", "maxItems", "minLength", "maxLength", "pattern", "minimum", "maximum"}
+	schemaAnnotations = []string{"title", "description", "default", "examples", "$schema", "$comment"}
+	schemaTypeNames   = []string{"object", "array", "string", "boolean", "null", "number", "integer"}
+)
+
+// CheckSchema reports the first problem that would keep ValidateSchema from enforcing
+// schema: an unsupported keyword (oneOf, $ref, format, ...), a malformed keyword value or
+// a pattern that does not compile. Workflows are checked when they are parsed, so an
+// invalid contract cannot silently let output through.
+func CheckSchema(schema map[string]any) error {
+	return checkSchemaNode(schema, "$")
+}
+
+func checkSchemaNode(schema map[string]any, path string) error {
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := schema[k]
+		invalid := func(format string, args ...any) error {
+			return fmt.Errorf("%s.%s: %s", path, k, fmt.Sprintf(format, args...))
+		}
+		switch k {
+		case "type":
+			types := schemaTypes(v)
+			if len(types) == 0 {
+				return invalid("must be a type name or a list of type names")
+			}
+			for _, typ := range types {
+				if !slices.Contains(schemaTypeNames, typ) {
+					return invalid("unknown type %q", typ)
+				}
+			}
+		case "enum":
+			if _, ok := v.([]any); !ok {
+				return invalid("must be a list")
+			}
+		case "required":
+			list, ok := v.([]any)
+			if !ok || len(toStrings(list)) != len(list) {
+				return invalid("must be a list of field names")
+			}
+		case "properties":
+			props, ok := v.(map[string]any)
+			if !ok {
+				return invalid("must map field names to schemas")
+			}
+			for name, sub := range props {
+				subSchema, ok := sub.(map[string]any)
+				if !ok {
+					return fmt.Errorf("%s.properties.%s: must be a schema object", path, name)
+				}
+				if err := checkSchemaNode(subSchema, path+".properties."+name); err != nil {
+					return err
+				}
+			}
+		case "items", "additionalProperties":
+			if _, ok := v.(bool); ok && k == "additionalProperties" {
+				continue
+			}
+			sub, ok := v.(map[string]any)
+			if !ok {
+				return invalid("must be a schema object")
+			}
+			if err := checkSchemaNode(sub, path+"."+k); err != nil {
+				return err
+			}
+		case "minItems", "maxItems", "minLength", "maxLength", "minimum", "maximum":
+			if _, ok := toNumber(v); !ok {
+				return invalid("must be a number")
+			}
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return invalid("must be a string")
+			}
+			if _, err := regexp.Compile(p); err != nil {
+				return invalid("invalid pattern: %v", err)
+			}
+		case "const":
+		default:
+			if !slices.Contains(schemaAnnotations, k) {
+				return fmt.Errorf("%s: unsupported keyword %q (supported: %s)", path, k, strings.Join(schemaKeywords, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSchema validates value against a JSON Schema and returns readable errors
+// ("$.issues[0].severity: ..."). It enforces the keywords in schemaKeywords; use
+// CheckSchema to reject schemas relying on anything else.
+func ValidateSchema(schema map[string]any, value any) []string {
+	var errs []string
+	validateNode(schema, value, "$", &errs)
+	if len(errs) > maxSchemaErrors {
+		errs = append(errs[:maxSchemaErrors], fmt.Sprintf("…and %d more", len(errs)-maxSchemaErrors))
+	}
+	return errs
+}
+
+func validateNode(schema map[string]any, value any, path string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := schema["type"]; ok {
+		types := schemaTypes(t)
+		if len(types) > 0 && !slices.ContainsFunc(types, func(typ string) bool { return matchesType(typ, value) }) {
+			fail("expected %s, got %s", strings.Join(types, " or "), jsonTypeOf(value))
+			return
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(v any) bool { return jsonEqual(v, value) }) {
+		fail("must be one of %s", compactJSON(enum))
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, value) {
+		fail("must equal %s", compactJSON(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for _, name := range toStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				fail("missing required field %q", name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if sub, ok := props[k].(map[string]any); ok {
+				validateNode(sub, v[k], path+"."+k, errs)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					fail("unexpected field %q", k)
+				}
+			case map[string]any:
+				validateNode(extra, v[k], path+"."+k, errs)
+			}
+		}
+	case []any:
+		if n, ok := toNumber(schema["minItems"]); ok && float64(len(v)) < n {
+			fail("must have at least %v items", n)
+		}
+		if n, ok := toNumber(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail("must have at most %v items", n)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateNode(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if n, ok := toNumber(schema["minLength"]); ok && length < n {
+			fail("must be at least %v characters", n)
+		}
+		if n, ok := toNumber(schema["maxLength"]); ok && length > n {
+			fail("must be at most %v characters", n)
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err != nil {
+				fail("schema pattern %s does not compile", p)
+			} else if !re.MatchString(v) {
+				fail("must match pattern %s", p)
+			}
+		}
+	case float64, int, int64:
+		num, _ := toNumber(v)
+		if n, ok := toNumber(schema["minimum"]); ok && num < n {
+			fail("must be >= %v", n)
+		}
+		if n, ok := toNumber(schema["maximum"]); ok && num > n {
+			fail("must be <= %v", n)
+		}
+	}
+}
+
+// ExtractStructuredOutput finds the JSON object an agent produced for an output contract:
+// the output itself, or a JSON object embedded in its raw `result` text (optionally in a
+// ```json fence). Returns the object that best matches the schema and its validation errors.
+func ExtractStructuredOutput(output map[string]any, schema map[string]any) (map[string]any, []string) {
+	best, bestErrs := output, ValidateSchema(schema, output)
+	if len(bestErrs) == 0 {
+		return best, nil
+	}
+
+	text, _ := output["result"].(string)
+	for _, candidate := range jsonObjectCandidates(text) {
+		errs := ValidateSchema(schema, candidate)
+		if len(errs) == 0 {
+			return candidate, nil
+		}
+		if len(errs) < len(bestErrs) {
+			best, bestErrs = candidate, errs
+		}
+	}
+	return best, bestErrs
+}
+
+var jsonFencePattern = regexp.MustCompile("(?s)```(?:json)?\\s*(\\{.*?\\})\\s*```")
+
+// jsonObjectCandidates returns JSON objects found in text, most specific first
+func jsonObjectCandidates(text string) []map[string]any {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	var sources []string
+
+	// Claude CLI envelope: {"type":"result","result":"..."}
+	var envelope struct {
+		Result string `json:"result"`
+	}
+	if json.Unmarshal([]byte(text), &envelope) == nil && envelope.Result != "" {
+		text = strings.TrimSpace(envelope.Result)
+	}
+
+	for _, m := range jsonFencePattern.FindAllStringSubmatch(text, -1) {
+		sources = append(sources, m[1])
+	}
+	sources = append(sources, text)
+	if start, end := strings.Index(text, "{"), strings.LastIndex(text, "}"); start >= 0 && end > start {
+		sources = append(sources, text[start:end+1])
+	}
+
+	var result []map[string]any
+	for _, src := range sources {
+		var obj map[string]any
+		if json.Unmarshal([]byte(src), &obj) == nil {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// FormatOutputSchema renders a schema for inclusion in a prompt
+func FormatOutputSchema(schema map[string]any) string {
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", schema)
+	}
+	return string(b)
+}
+
+func schemaTypes(t any) []string {
+	if s, ok := t.(string); ok {
+		return []string{s}
+	}
+	return toStrings(t)
+}
+
+func matchesType(typ string, value any) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := toNumber(value)
+		return ok
+	case "integer":
+		n, ok := toNumber(value)
+		return ok && n == math.Trunc(n)
+	}
+	return true // unknown type keyword: don't reject
+}
+
+func jsonTypeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	default:
+		if _, ok := toNumber(v); ok {
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toStrings(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonEqual compares values by their JSON encoding (so 1 == 1.0 across YAML/JSON sources)
+func jsonEqual(a, b any) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+func compactJSON(v any) string {
+	if n, ok := toNumber(v); ok {
+		v = n
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
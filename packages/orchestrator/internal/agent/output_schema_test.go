@@ -0,0 +1,235 @@
+package agent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testIssueSchema = map[string]any{
+	"type":     "object",
+	"required": []any{"passed", "score"},
+	"properties": map[string]any{
+		"passed": map[string]any{"type": "boolean"},
+		"score":  map[string]any{"type": "integer", "minimum": 0, "maximum": 100},
+		"name":   map[string]any{"type": "string", "minLength": 2, "maxLength": 5, "pattern": "^[a-z]+$"},
+		"level":  map[string]any{"enum": []any{"low", "high"}},
+		"kind":   map[string]any{"const": "review"},
+		"ratio":  map[string]any{"type": []any{"number", "null"}},
+		"tags":   map[string]any{"type": "array", "minItems": 1, "maxItems": 2, "items": map[string]any{"type": "string"}},
+	},
+	"additionalProperties": false,
+}
+
+func TestValidateSchema(t *testing.T) {
+	valid := func(extra map[string]any) map[string]any {
+		v := map[string]any{"passed": true, "score": float64(90)}
+		for k, item := range extra {
+			v[k] = item
+		}
+		return v
+	}
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{"valid", valid(map[string]any{"name": "abc", "level": "low", "kind": "review", "ratio": nil, "tags": []any{"x"}}), nil},
+		{"integer from YAML", map[string]any{"passed": false, "score": 7}, nil},
+		{"wrong root type", []any{}, []string{"$: expected object, got array"}},
+		{"missing required", map[string]any{"passed": true}, []string{`$: missing required field "score"`}},
+		{"wrong field type", valid(map[string]any{"passed": "yes"}), []string{"$.passed: expected boolean, got string"}},
+		{"not an integer", valid(map[string]any{"score": 1.5}), []string{"$.score: expected integer, got number"}},
+		{"below minimum", valid(map[string]any{"score": float64(-1)}), []string{"$.score: must be >= 0"}},
+		{"above maximum", valid(map[string]any{"score": float64(101)}), []string{"$.score: must be <= 100"}},
+		{"too short", valid(map[string]any{"name": "a"}), []string{"$.name: must be at least 2 characters"}},
+		{"too long counts runes", valid(map[string]any{"name": "ééééééé"}), []string{
+			"$.name: must be at most 5 characters",
+			"$.name: must match pattern ^[a-z]+$",
+		}},
+		{"pattern", valid(map[string]any{"name": "AB"}), []string{"$.name: must match pattern ^[a-z]+$"}},
+		{"enum", valid(map[string]any{"level": "mid"}), []string{`$.level: must be one of ["low","high"]`}},
+		{"const", valid(map[string]any{"kind": "spec"}), []string{`$.kind: must equal "review"`}},
+		{"type list", valid(map[string]any{"ratio": "half"}), []string{"$.ratio: expected number or null, got string"}},
+		{"too few items", valid(map[string]any{"tags": []any{}}), []string{"$.tags: must have at least 1 items"}},
+		{"too many items", valid(map[string]any{"tags": []any{"a", "b", "c"}}), []string{"$.tags: must have at most 2 items"}},
+		{"item type", valid(map[string]any{"tags": []any{"a", 1.0}}), []string{"$.tags[1]: expected string, got number"}},
+		{"additional property", valid(map[string]any{"extra": 1.0, "another": 2.0}), []string{
+			`$: unexpected field "another"`,
+			`$: unexpected field "extra"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateSchema(testIssueSchema, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaNested(t *testing.T) {
+	review := BuiltinOutputSchemas["review"]
+	value := map[string]any{
+		"passed": false,
+		"issues": []any{
+			map[string]any{"description": "ok", "severity": "major"},
+			map[string]any{"severity": "blocker"},
+		},
+	}
+	want := []string{
+		`$.issues[1]: missing required field "description"`,
+		`$.issues[1].severity: must be one of ["critical","major","minor","info"]`,
+	}
+	if got := ValidateSchema(review, value); !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %q, want %q", got, want)
+	}
+
+	// Schemas of additional properties apply to every unlisted field
+	schema := map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "number"}}
+	if got := ValidateSchema(schema, map[string]any{"a": 1.0, "b": "x"}); !reflect.DeepEqual(got, []string{"$.b: expected number, got string"}) {
+		t.Errorf("additionalProperties schema: %q", got)
+	}
+}
+
+func TestValidateSchemaErrorCap(t *testing.T) {
+	schema := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	items := make([]any, maxSchemaErrors+5)
+	for i := range items {
+		items[i] = float64(i)
+	}
+	errs := ValidateSchema(schema, items)
+	if len(errs) != maxSchemaErrors+1 {
+		t.Fatalf("got %d errors, want %d", len(errs), maxSchemaErrors+1)
+	}
+	if errs[maxSchemaErrors] != "…and 5 more" {
+		t.Errorf("last error = %q", errs[maxSchemaErrors])
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  map[string]any
+		wantErr string
+	}{
+		{"supported keywords", testIssueSchema, ""},
+		{"built-in review", BuiltinOutputSchemas["review"], ""},
+		{"built-in change_name", BuiltinOutputSchemas["change_name"], ""},
+		{"annotations", map[string]any{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "T", "description": "d", "type": "string", "default": "x", "examples": []any{"y"}}, ""},
+		{"oneOf", map[string]any{"oneOf": []any{map[string]any{"type": "string"}}}, `$: unsupported keyword "oneOf"`},
+		{"anyOf", map[string]any{"anyOf": []any{}}, `unsupported keyword "anyOf"`},
+		{"$ref", map[string]any{"properties": map[string]any{"a": map[string]any{"$ref": "#/defs/a"}}}, `$.properties.a: unsupported keyword "$ref"`},
+		{"nested format", map[string]any{"items": map[string]any{"format": "date"}}, `$.items: unsupported keyword "format"`},
+		{"invalid pattern", map[string]any{"properties": map[string]any{"a": map[string]any{"pattern": "([a-z"}}}, "$.properties.a.pattern: invalid pattern"},
+		{"unknown type", map[string]any{"type": "float"}, `$.type: unknown type "float"`},
+		{"type list", map[string]any{"type": []any{"string", "null"}}, ""},
+		{"properties not a map", map[string]any{"properties": []any{"a"}}, "$.properties: must map field names to schemas"},
+		{"property not a schema", map[string]any{"properties": map[string]any{"a": "string"}}, "$.properties.a: must be a schema object"},
+		{"required not names", map[string]any{"required": []any{"a", 1}}, "$.required: must be a list of field names"},
+		{"enum not a list", map[string]any{"enum": "a"}, "$.enum: must be a list"},
+		{"bound not a number", map[string]any{"maxLength": "10"}, "$.maxLength: must be a number"},
+		{"items not a schema", map[string]any{"items": true}, "$.items: must be a schema object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSchema(tt.schema)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractStructuredOutput(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"passed", "score"},
+		"properties": map[string]any{
+			"passed": map[string]any{"type": "boolean"},
+			"score":  map[string]any{"type": "number"},
+		},
+	}
+	tests := []struct {
+		name     string
+		output   map[string]any
+		want     map[string]any
+		wantErrs int
+	}{
+		{
+			name:   "output is already the object",
+			output: map[string]any{"passed": true, "score": 1.0},
+			want:   map[string]any{"passed": true, "score": 1.0},
+		},
+		{
+			name:   "json fence in the result text",
+			output: map[string]any{"result": "Done.\n```json\n{\"passed\": true, \"score\": 3}\n```\nBye"},
+			want:   map[string]any{"passed": true, "score": 3.0},
+		},
+		{
+			name:   "plain fence",
+			output: map[string]any{"result": "```\n{\"passed\": false, \"score\": 0}\n```"},
+			want:   map[string]any{"passed": false, "score": 0.0},
+		},
+		{
+			name:   "object embedded in prose",
+			output: map[string]any{"result": `The verdict is {"passed": true, "score": 9} as requested.`},
+			want:   map[string]any{"passed": true, "score": 9.0},
+		},
+		{
+			name:   "Claude CLI result envelope",
+			output: map[string]any{"result": `{"type":"result","result":"{\"passed\": true, \"score\": 5}"}`},
+			want:   map[string]any{"passed": true, "score": 5.0},
+		},
+		{
+			name:   "the valid fence wins over an earlier invalid one",
+			output: map[string]any{"result": "```json\n{\"passed\": \"maybe\"}\n```\n```json\n{\"passed\": true, \"score\": 2}\n```"},
+			want:   map[string]any{"passed": true, "score": 2.0},
+		},
+		{
+			name:     "no valid candidate keeps the closest one",
+			output:   map[string]any{"result": "```json\n{\"passed\": true}\n```"},
+			want:     map[string]any{"passed": true},
+			wantErrs: 1,
+		},
+		{
+			name:     "no JSON at all",
+			output:   map[string]any{"result": "I could not finish.", "raw": true},
+			want:     map[string]any{"result": "I could not finish.", "raw": true},
+			wantErrs: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := ExtractStructuredOutput(tt.output, schema)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("object = %v, want %v", got, tt.want)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("errors = %q, want %d", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestExtractStructuredOutputCapsErrors(t *testing.T) {
+	properties := map[string]any{}
+	required := []any{}
+	for i := range maxSchemaErrors + 10 {
+		name := fmt.Sprintf("f%02d", i)
+		properties[name] = map[string]any{"type": "string"}
+		required = append(required, name)
+	}
+	schema := map[string]any{"type": "object", "required": required, "properties": properties}
+	_, errs := ExtractStructuredOutput(map[string]any{"result": "nothing"}, schema)
+	if len(errs) != maxSchemaErrors+1 || !strings.HasPrefix(errs[maxSchemaErrors], "…and ") {
+		t.Errorf("got %d errors, last %q", len(errs), errs[len(errs)-1])
+	}
+}
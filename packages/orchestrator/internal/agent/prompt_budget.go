@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultContextWindow is assumed for models missing from modelContextWindows
+const defaultContextWindow = 128000
+
+// promptBudgetRatio is the share of the context window the initial prompt may use;
+// the rest is left for the agent's own system prompt, file reads, tool results and output
+const promptBudgetRatio = 0.5
+
+// modelContextWindows maps model name prefixes to context window sizes (tokens).
+// The longest matching prefix wins.
+var modelContextWindows = map[string]int{
+	"claude":   200000,
+	"gpt-5":    400000,
+	"gpt-4.1":  1000000,
+	"gpt-4o":   128000,
+	"o3":       200000,
+	"o4":       200000,
+	"codex":    200000,
+	"deepseek": 128000,
+	"qwen":     128000,
+	"glm":      128000,
+	"kimi":     128000,
+}
+
+// PromptStats describes the final prompt of an agent request
+type PromptStats struct {
+	Chars           int      `json:"chars"`
+	EstimatedTokens int      `json:"estimated_tokens"`
+	Budget          int      `json:"budget"`
+	Truncated       []string `json:"truncated,omitempty"` // sections that were shortened or omitted
+}
+
+// ContextEntry is the output of one node, used for prompt context
+type ContextEntry struct {
+	NodeID string
+	Output any
+}
+
+// EstimateTokens estimates the token count of s deterministically: ~4 ASCII characters
+// per token, one token per non-ASCII rune (CJK text is roughly one token per character)
+func EstimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// PromptBudgetForModel returns the prompt token budget for a model
+func PromptBudgetForModel(model string) int {
+	window, matched := defaultContextWindow, ""
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:] // provider-prefixed names, e.g. "anthropic/claude-sonnet-4"
+	}
+	for prefix, size := range modelContextWindows {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(matched) {
+			window, matched = size, prefix
+		}
+	}
+	return int(float64(window) * promptBudgetRatio)
+}
+
+// TruncateRunes returns the first n runes of s
+func TruncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
+
+// fitText shortens s to at most maxTokens, keeping its head and tail around an omission
+// marker. ok is false if s had to be shortened.
+func fitText(s string, maxTokens int) (string, bool) {
+	if EstimateTokens(s) <= maxTokens {
+		return s, true
+	}
+	runes := []rune(s)
+	marker := func(omitted int) string { return fmt.Sprintf("\n…[已截断，省略 %d 字符]…\n", omitted) }
+
+	// Binary search the number of runes to keep (2/3 head, 1/3 tail)
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		head := mid * 2 / 3
+		candidate := string(runes[:head]) + marker(len(runes)-mid) + string(runes[len(runes)-(mid-head):])
+		if EstimateTokens(candidate) <= maxTokens {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		return "", false
+	}
+	head := lo * 2 / 3
+	return string(runes[:head]) + marker(len(runes)-lo) + string(runes[len(runes)-(lo-head):]), false
+}
+
+// shrinkLevels are the successively tighter limits applied to JSON context values:
+// maximum runes per string and items per array
+var shrinkLevels = []struct{ maxRunes, maxItems int }{
+	{4000, 100},
+	{1000, 30},
+	{200, 10},
+	{50, 3},
+}
+
+// shrinkValue truncates long strings and arrays inside a JSON value
+func shrinkValue(v any, maxRunes, maxItems int) any {
+	switch val := v.(type) {
+	case string:
+		if n := utf8.RuneCountInString(val); n > maxRunes {
+			return TruncateRunes(val, maxRunes) + fmt.Sprintf("…[省略 %d 字符]", n-maxRunes)
+		}
+		return val
+	case []any:
+		items := val
+		if len(items) > maxItems {
+			items = items[:maxItems]
+		}
+		out := make([]any, 0, len(items)+1)
+		for _, item := range items {
+			out = append(out, shrinkValue(item, maxRunes, maxItems))
+		}
+		if len(val) > maxItems {
+			out = append(out, fmt.Sprintf("…[共 %d 项，省略 %d 项]", len(val), len(val)-maxItems))
+		}
+		return out
+	case []string:
+		items := make([]any, len(val))
+		for i, s := range val {
+			items[i] = s
+		}
+		return shrinkValue(items, maxRunes, maxItems)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = shrinkValue(item, maxRunes, maxItems)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// fitJSON renders obj as indented JSON within maxTokens, shrinking long values level by
+// level and finally omitting the largest entries. ok is false if anything was cut.
+func fitJSON(obj map[string]any, maxTokens int) (string, bool) {
+	render := func(m map[string]any) string {
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%v", m)
+		}
+		return string(b)
+	}
+
+	if s := render(obj); EstimateTokens(s) <= maxTokens {
+		return s, true
+	}
+
+	var shrunk map[string]any
+	for _, level := range shrinkLevels {
+		shrunk = shrinkValue(obj, level.maxRunes, level.maxItems).(map[string]any)
+		if s := render(shrunk); EstimateTokens(s) <= maxTokens {
+			return s, false
+		}
+	}
+
+	// Still too large: omit the largest entries (ties broken by key)
+	keys := make([]string, 0, len(shrunk))
+	sizes := make(map[string]int, len(shrunk))
+	for k, v := range shrunk {
+		keys = append(keys, k)
+		sizes[k] = EstimateTokens(render(map[string]any{k: v}))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if sizes[keys[i]] != sizes[keys[j]] {
+			return sizes[keys[i]] > sizes[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	var omitted []string
+	for _, k := range keys {
+		delete(shrunk, k)
+		omitted = append(omitted, k)
+		sort.Strings(omitted)
+		shrunk["_omitted"] = "超出 Prompt 预算，已省略：" + strings.Join(omitted, ", ")
+		if s := render(shrunk); EstimateTokens(s) <= maxTokens {
+			return s, false
+		}
+		delete(shrunk, "_omitted")
+	}
+	return "", false
+}
@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPromptBudgetForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"claude-sonnet-4-5", 100000},
+		{"anthropic/claude-opus-4", 100000},
+		{"GPT-5-codex", 200000},
+		{"gpt-4.1-mini", 500000},
+		{"gpt-4o", 64000},
+		{"openrouter/qwen/qwen3-coder", 64000},
+		{"unknown-model", 64000},
+		{"", 64000},
+	}
+	for _, tt := range tests {
+		if got := PromptBudgetForModel(tt.model); got != tt.want {
+			t.Errorf("PromptBudgetForModel(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for s, want := range map[string]int{"": 0, "abcd": 1, "abcde": 2, "需求文档": 4, "ab需求": 3} {
+		if got := EstimateTokens(s); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"hello", 3, "hel"},
+		{"hello", 10, "hello"},
+		{"hello", 0, ""},
+		{"需求文档说明", 2, "需求"},
+		{"a😀b", 2, "a😀"},
+	}
+	for _, tt := range tests {
+		got := TruncateRunes(tt.in, tt.n)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if got, ok := fitText("short", 10); !ok || got != "short" {
+		t.Errorf("fitting text = %q, %v", got, ok)
+	}
+
+	text := strings.Repeat("需求😀", 200) + "END"
+	got, ok := fitText(text, 100)
+	if ok {
+		t.Fatal("over-budget text reported as fitting")
+	}
+	if EstimateTokens(got) > 100 || !utf8.ValidString(got) {
+		t.Errorf("fitted text: %d tokens, valid UTF-8 %v", EstimateTokens(got), utf8.ValidString(got))
+	}
+	// Head and tail survive around the omission marker
+	if !strings.HasPrefix(got, "需求") || !strings.HasSuffix(got, "END") || !strings.Contains(got, "[已截断，省略") {
+		t.Errorf("fitted text = %q", got)
+	}
+
+	if got, ok := fitText(text, 0); ok || got != "" {
+		t.Errorf("no budget: %q, %v", got, ok)
+	}
+}
+
+func TestFitJSON(t *testing.T) {
+	obj := map[string]any{
+		"small": "ok",
+		"large": strings.Repeat("数据", 5000),
+		"list":  make([]any, 500),
+	}
+	got, ok := fitJSON(obj, 2000)
+	if ok {
+		t.Fatal("over-budget JSON reported as fitting")
+	}
+	if EstimateTokens(got) > 2000 || !utf8.ValidString(got) {
+		t.Errorf("fitted JSON: %d tokens", EstimateTokens(got))
+	}
+	if !strings.Contains(got, `"small": "ok"`) || !strings.Contains(got, "省略") {
+		t.Errorf("fitted JSON = %.300s", got)
+	}
+
+	// Too tight for even shrunk values: the largest entries are dropped, named in _omitted
+	got, _ = fitJSON(map[string]any{"a": strings.Repeat("x", 400), "b": "keep"}, 25)
+	if !strings.Contains(got, `"b": "keep"`) || !strings.Contains(got, "已省略：a") {
+		t.Errorf("omitting entries: %s", got)
+	}
+	if got, ok := fitJSON(obj, 0); ok || got != "" {
+		t.Errorf("no budget: %q, %v", got, ok)
+	}
+}
+
+// budgetRequest builds a request whose sections together far exceed the budget
+func budgetRequest(budget int) *AgentRequest {
+	ancestors := make([]ContextEntry, 0, 3)
+	for i := range 3 {
+		ancestors = append(ancestors, ContextEntry{
+			NodeID: fmt.Sprintf("older_%d", i),
+			Output: map[string]any{"summary": strings.Repeat("较早的输出", 400)},
+		})
+	}
+	return &AgentRequest{
+		RolePrompt:   "你是开发者。",
+		Feedback:     "FEEDBACK " + strings.Repeat("请修改接口命名。", 20),
+		Prompt:       "TASK " + strings.Repeat("实现登录功能。", 40),
+		PromptBudget: budget,
+		Context: map[string]any{
+			"plan":  map[string]any{"steps": []any{"一", "二"}, "detail": strings.Repeat("方案细节", 2000)},
+			"_role": "general-developer",
+		},
+		AncestorContext: ancestors,
+	}
+}
+
+func TestBuildPromptBudgetPriority(t *testing.T) {
+	tests := []struct {
+		name          string
+		budget        int
+		wantSections  []string
+		wantMissing   []string
+		wantTruncated []string
+	}{
+		{
+			// Feedback and task are kept whole; context and ancestors are shrunk
+			name:          "context and ancestors give way",
+			budget:        1500,
+			wantSections:  []string{"## 人工反馈", "## 任务说明", "## 上游节点输出", "## 更早节点输出", "TASK", "FEEDBACK"},
+			wantMissing:   []string{"[已截断"},
+			wantTruncated: []string{"context", "ancestor_context"},
+		},
+		{
+			// Only feedback fits whole; the task is cut and there is no room for context
+			name:          "feedback outranks the task",
+			budget:        330,
+			wantSections:  []string{"## 人工反馈", "## 任务说明", "FEEDBACK", "[已截断"},
+			wantMissing:   []string{"## 上游节点输出", "## 更早节点输出"},
+			wantTruncated: []string{"prompt_template", "context", "ancestor_context"},
+		},
+		{
+			name:          "everything fits",
+			budget:        1000000,
+			wantSections:  []string{"## 人工反馈", "## 任务说明", "## 上游节点输出", "## 更早节点输出", "older_2"},
+			wantTruncated: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := budgetRequest(tt.budget)
+			prompt := NewPromptBuilder().Build(req)
+			for _, s := range tt.wantSections {
+				if !strings.Contains(prompt, s) {
+					t.Errorf("prompt is missing %q", s)
+				}
+			}
+			for _, s := range tt.wantMissing {
+				if strings.Contains(prompt, s) {
+					t.Errorf("prompt unexpectedly contains %q", s)
+				}
+			}
+			stats := req.PromptStats
+			if !reflect.DeepEqual(stats.Truncated, tt.wantTruncated) {
+				t.Errorf("truncated = %v, want %v", stats.Truncated, tt.wantTruncated)
+			}
+			if stats.Budget != tt.budget || stats.EstimatedTokens != EstimateTokens(prompt) || stats.Chars != utf8.RuneCountInString(prompt) {
+				t.Errorf("stats = %+v", stats)
+			}
+			if stats.EstimatedTokens > tt.budget {
+				t.Errorf("prompt uses %d tokens, budget %d", stats.EstimatedTokens, tt.budget)
+			}
+			if !utf8.ValidString(prompt) || strings.ContainsRune(prompt, utf8.RuneError) {
+				t.Error("prompt contains a split multi-byte character")
+			}
+			if strings.Contains(prompt, "_role") {
+				t.Error("internal context keys leaked into the prompt")
+			}
+		})
+	}
+}
+
+func TestBuildPromptIsDeterministic(t *testing.T) {
+	for _, budget := range []int{330, 1500, 4000} {
+		first := NewPromptBuilder().Build(budgetRequest(budget))
+		for range 5 {
+			if got := NewPromptBuilder().Build(budgetRequest(budget)); got != first {
+				t.Fatalf("budget %d: prompt differs between runs", budget)
+			}
+		}
+	}
+}
+
+func TestBuildPromptDefaultsToModelBudget(t *testing.T) {
+	req := &AgentRequest{Model: "claude-sonnet-4-5", Prompt: "hi"}
+	NewPromptBuilder().Build(req)
+	if req.PromptStats.Budget != 100000 || len(req.PromptStats.Truncated) != 0 {
+		t.Errorf("stats = %+v", req.PromptStats)
+	}
+}
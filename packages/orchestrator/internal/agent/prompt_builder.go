@@ -1,9 +1,8 @@
 package agent
 
 import (
-	"encoding/json"
-	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 // DefaultRolePrompts provides built-in system prompts for common roles
@@ -65,42 +64,170 @@ func (b *PromptBuilder) SetRolePrompt(role, prompt string) {
 	b.rolePrompts[role] = prompt
 }
 
-// Build constructs the full prompt from role prompt + DSL template + upstream context + feedback
+// Build constructs the full prompt from role prompt + DSL template + upstream context + feedback.
+// The prompt is kept within the request's token budget: the role prompt and output requirements
+// are always included, then feedback, the prompt template, direct upstream outputs and older
+// node outputs get the remaining budget in that order, long values being shortened
+// deterministically. Size and truncation are reported in req.PromptStats.
 func (b *PromptBuilder) Build(req *AgentRequest) string {
-	var parts []string
+	budget := req.PromptBudget
+	if budget <= 0 {
+		budget = PromptBudgetForModel(req.Model)
+	}
+	stats := &PromptStats{Budget: budget}
+	remaining := budget
 
-	// 1. Role system prompt
-	if req.RolePrompt != "" {
-		parts = append(parts, req.RolePrompt)
-	} else if rolePrompt, ok := b.rolePrompts[extractRole(req)]; ok {
-		parts = append(parts, rolePrompt)
+	// Role system prompt
+	rolePrompt := req.RolePrompt
+	if rolePrompt == "" {
+		rolePrompt = b.rolePrompts[extractRole(req)]
 	}
 
-	// 2. DSL prompt_template
-	if req.Prompt != "" {
-		parts = append(parts, "---\n## 任务说明\n"+req.Prompt)
+	// Mode-specific instructions + output contract, validation errors of the previous attempt
+	outputSection, errorsSection := outputSections(req)
+
+	// Always included
+	for _, fixed := range []string{rolePrompt, outputSection, errorsSection} {
+		remaining -= sectionCost(fixed)
 	}
 
-	// 3. Upstream node outputs (context)
-	if len(req.Context) > 0 {
-		contextStr := formatContext(req.Context)
-		if contextStr != "" {
-			parts = append(parts, "---\n## 上游节点输出\n"+contextStr)
+	// Budgeted sections, in priority order: feedback from rejection, DSL prompt_template,
+	// upstream node outputs (context), outputs of older ancestor nodes
+	feedbackSection := fitTextSection(&remaining, stats, "feedback", "---\n## 人工反馈（请根据以下反馈修改）\n", req.Feedback)
+	taskSection := fitTextSection(&remaining, stats, "prompt_template", "---\n## 任务说明\n", req.Prompt)
+	contextSection := fitContextSection(&remaining, stats, filterContext(req.Context))
+	ancestorSection := fitAncestorSection(&remaining, stats, req.AncestorContext)
+
+	var parts []string
+	for _, part := range []string{rolePrompt, taskSection, contextSection, ancestorSection, feedbackSection, outputSection, errorsSection} {
+		if part != "" {
+			parts = append(parts, part)
 		}
 	}
+	prompt := strings.Join(parts, "\n\n")
+
+	stats.Chars = utf8.RuneCountInString(prompt)
+	stats.EstimatedTokens = EstimateTokens(prompt)
+	req.PromptStats = stats
+	return prompt
+}
 
-	// 4. Feedback from rejection
+// BuildResume constructs the prompt for continuing a previous session of the same node
+// (e.g. after a rejection): the conversation already holds the role, task and context, so
+// only the feedback and output requirements are sent
+func (b *PromptBuilder) BuildResume(req *AgentRequest) string {
+	parts := []string{"你之前在本会话中完成的工作未被通过，请在已有工作的基础上修改，不要从头开始。"}
 	if req.Feedback != "" {
 		parts = append(parts, "---\n## 人工反馈（请根据以下反馈修改）\n"+req.Feedback)
 	}
+	outputSection, errorsSection := outputSections(req)
+	for _, part := range []string{outputSection, errorsSection} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
 
-	// 5. Mode-specific instructions
-	modeInstr := modeInstruction(req.Mode)
+// outputSections returns the output requirements (mode instruction + output contract) and
+// the validation errors of the previous attempt (output repair) sections
+func outputSections(req *AgentRequest) (string, string) {
+	var outputReqs []string
+	modeInstr := req.ModeInstruction
+	if modeInstr == "" {
+		modeInstr = modeInstruction(req.Mode)
+	}
 	if modeInstr != "" {
-		parts = append(parts, "---\n## 输出要求\n"+modeInstr)
+		outputReqs = append(outputReqs, modeInstr)
+	}
+	if req.OutputSchema != nil {
+		outputReqs = append(outputReqs, "最终回复必须且只能是一个符合以下 JSON Schema 的 JSON 对象（不要输出其他内容）：\n```json\n"+
+			FormatOutputSchema(req.OutputSchema)+"\n```")
+	}
+	outputSection := ""
+	if len(outputReqs) > 0 {
+		outputSection = "---\n## 输出要求\n" + strings.Join(outputReqs, "\n\n")
 	}
 
-	return strings.Join(parts, "\n\n")
+	errorsSection := ""
+	if len(req.OutputErrors) > 0 {
+		errorsSection = "---\n## 上次输出未通过校验（请修正后重新输出）\n- " + strings.Join(req.OutputErrors, "\n- ")
+	}
+	return outputSection, errorsSection
+}
+
+// sectionCost is the token cost of a prompt section including its separator
+func sectionCost(section string) int {
+	if section == "" {
+		return 0
+	}
+	return EstimateTokens(section) + 1
+}
+
+// fitTextSection renders header+text within the remaining budget, shortening text if needed
+func fitTextSection(remaining *int, stats *PromptStats, name, header, text string) string {
+	if text == "" {
+		return ""
+	}
+	fitted, ok := fitText(text, *remaining-sectionCost(header))
+	if !ok {
+		stats.Truncated = append(stats.Truncated, name)
+	}
+	if fitted == "" {
+		return ""
+	}
+	section := header + fitted
+	*remaining -= sectionCost(section)
+	return section
+}
+
+// fitContextSection renders the direct upstream context within the remaining budget
+func fitContextSection(remaining *int, stats *PromptStats, ctx map[string]any) string {
+	if len(ctx) == 0 {
+		return ""
+	}
+	header := "---\n## 上游节点输出\n"
+	contextStr, ok := fitJSON(ctx, *remaining-sectionCost(header))
+	if !ok {
+		stats.Truncated = append(stats.Truncated, "context")
+	}
+	if contextStr == "" {
+		return ""
+	}
+	section := header + contextStr
+	*remaining -= sectionCost(section)
+	return section
+}
+
+// fitAncestorSection renders older node outputs, nearest first, until the budget runs out
+func fitAncestorSection(remaining *int, stats *PromptStats, entries []ContextEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	header := "---\n## 更早节点输出\n"
+	left := *remaining - sectionCost(header)
+	var blocks []string
+	truncated := false
+	for _, entry := range entries {
+		out, ok := fitJSON(map[string]any{entry.NodeID: entry.Output}, left)
+		if !ok {
+			truncated = true
+		}
+		if out == "" {
+			break
+		}
+		blocks = append(blocks, out)
+		left -= EstimateTokens(out) + 1
+	}
+	if truncated {
+		stats.Truncated = append(stats.Truncated, "ancestor_context")
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+	section := header + strings.Join(blocks, "\n")
+	*remaining -= sectionCost(section)
+	return section
 }
 
 // extractRole tries to determine the role from the request context
@@ -113,25 +240,15 @@ func extractRole(req *AgentRequest) string {
 	return ""
 }
 
-// formatContext formats upstream node outputs as readable text
-func formatContext(ctx map[string]any) string {
-	// Filter out internal fields
+// filterContext drops internal ("_"-prefixed) fields from the upstream context
+func filterContext(ctx map[string]any) map[string]any {
 	filtered := make(map[string]any)
 	for k, v := range ctx {
 		if !strings.HasPrefix(k, "_") {
 			filtered[k] = v
 		}
 	}
-
-	if len(filtered) == 0 {
-		return ""
-	}
-
-	b, err := json.MarshalIndent(filtered, "", "  ")
-	if err != nil {
-		return fmt.Sprintf("%v", filtered)
-	}
-	return string(b)
+	return filtered
 }
 
 // modeInstruction returns mode-specific output instructions
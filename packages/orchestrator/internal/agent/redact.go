@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedPlaceholder replaces every secret found in agent output, logs and errors
+const RedactedPlaceholder = "[REDACTED]"
+
+// minSecretLen avoids scrubbing short, common values (e.g. "true", "main") that happen
+// to sit in a secret-looking env var
+const minSecretLen = 8
+
+// builtinSecretPatterns match well-known credential formats regardless of where they came from
+var builtinSecretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(https?://)[^/\s:@]+(:[^/\s@]*)?@`), // credentials in URLs (userinfo is replaced)
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),        // GitHub tokens
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`),      // GitHub fine-grained tokens
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`),         // GitLab tokens
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}\b`),            // Anthropic / OpenAI keys
+	regexp.MustCompile(`(?i)\b(Bearer|token)\s+[A-Za-z0-9_\-\.=]{20,}`),
+}
+
+// secretEnvKeyPattern marks env vars whose values are treated as secrets
+var secretEnvKeyPattern = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|API_KEY|AUTH|CREDENTIAL|PRIVATE_KEY)`)
+
+// Redactor scrubs secrets from text and JSON-like values before they are persisted or
+// published. It knows exact secret values (e.g. the tokens passed to an agent container)
+// plus regex patterns; it is safe for concurrent use.
+type Redactor struct {
+	patterns []*regexp.Regexp
+
+	mu      sync.RWMutex
+	secrets []string // longest first, so overlapping secrets are fully replaced
+}
+
+// NewRedactor creates a redactor using the built-in patterns plus extra ones
+func NewRedactor(extra []*regexp.Regexp) *Redactor {
+	patterns := make([]*regexp.Regexp, 0, len(builtinSecretPatterns)+len(extra))
+	patterns = append(patterns, builtinSecretPatterns...)
+	patterns = append(patterns, extra...)
+	return &Redactor{patterns: patterns}
+}
+
+// ParseRedactPatterns compiles a ";"-separated list of regular expressions (REDACT_PATTERNS)
+func ParseRedactPatterns(spec string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, expr := range strings.Split(spec, ";") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// Fork returns a redactor with the same patterns and secrets that can learn
+// additional secrets without affecting r (one per execution)
+func (r *Redactor) Fork() *Redactor {
+	if r == nil {
+		return NewRedactor(nil)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return &Redactor{
+		patterns: r.patterns,
+		secrets:  append([]string(nil), r.secrets...),
+	}
+}
+
+// AddSecrets registers exact values to scrub; short or empty values are ignored
+func (r *Redactor) AddSecrets(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < minSecretLen || slices.Contains(r.secrets, v) {
+			continue
+		}
+		r.secrets = append(r.secrets, v)
+	}
+	sort.Slice(r.secrets, func(i, j int) bool { return len(r.secrets[i]) > len(r.secrets[j]) })
+}
+
+// AddEnvSecrets registers the values of secret-looking variables (…_TOKEN, …_API_KEY, …)
+func (r *Redactor) AddEnvSecrets(env map[string]string) {
+	for key, value := range env {
+		if secretEnvKeyPattern.MatchString(key) {
+			r.AddSecrets(value)
+		}
+	}
+}
+
+// String scrubs secrets from s
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, RedactedPlaceholder)
+	}
+	r.mu.RUnlock()
+	for _, re := range r.patterns {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			// keep the scheme of credentialed URLs so they remain readable
+			if sub := re.FindStringSubmatch(match); len(sub) > 1 && strings.HasSuffix(sub[1], "://") {
+				return sub[1] + RedactedPlaceholder + "@"
+			}
+			return RedactedPlaceholder
+		})
+	}
+	return s
+}
+
+// Value scrubs secrets from a JSON-like value (strings, maps, slices), returning a copy
+func (r *Redactor) Value(v any) any {
+	if r == nil {
+		return v
+	}
+	switch val := v.(type) {
+	case string:
+		return r.String(val)
+	case map[string]any:
+		return r.Map(val)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = r.Value(item)
+		}
+		return out
+	case []string:
+		out := make([]string, len(val))
+		for i, item := range val {
+			out[i] = r.String(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Map scrubs secrets from all values of m, returning a copy (nil stays nil)
+func (r *Redactor) Map(m map[string]any) map[string]any {
+	if r == nil || m == nil {
+		return m
+	}
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = r.Value(v)
+	}
+	return out
+}
+
+// Error scrubs secrets from err's message, keeping the original in the chain
+func (r *Redactor) Error(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+	msg := r.String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// Event scrubs secrets from a stream event's text, tool input/result and final result
+func (r *Redactor) Event(event ClaudeStreamEvent) ClaudeStreamEvent {
+	if r == nil {
+		return event
+	}
+	if event.Message != nil {
+		msg := *event.Message
+		msg.Content = make([]ContentBlock, len(event.Message.Content))
+		for i, block := range event.Message.Content {
+			block.Text = r.String(block.Text)
+			block.Input = r.Map(block.Input)
+			block.Content = r.Value(block.Content)
+			msg.Content[i] = block
+		}
+		event.Message = &msg
+	}
+	event.Result = r.Value(event.Result)
+	return event
+}
+
+// redactedError carries a scrubbed message while still unwrapping to the original error
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,231 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// envFallbackProviderID is the provider registered from environment variables when the
+// database has no providers
+const envFallbackProviderID = "env-fallback"
+
+// defaultRoles are mapped to the default claude-code provider when missing from the database
+var defaultRoles = []string{"general-developer", "requirement-analyst", "code-reviewer", "qa-engineer", "spec-architect"}
+
+// EnvFallback is the claude-code provider used when no providers are configured in the database
+type EnvFallback struct {
+	Config map[string]any
+	Model  string
+}
+
+// RegistryLoader (re)builds the agent registry from the database: providers, their default
+// models and role mappings. Each Reload builds a fresh registry and swaps it in atomically;
+// adapters whose provider config is unchanged are reused, so running executions are unaffected,
+// and the others are closed once their running executions finish.
+type RegistryLoader struct {
+	db        *db.Client
+	registry  *Registry
+	factories *AgentFactoryRegistry
+	fallback  *EnvFallback // nil = no env fallback
+	logger    *zap.SugaredLogger
+
+	mu    sync.Mutex // serializes reloads
+	built map[string]builtAdapter
+}
+
+// builtAdapter remembers which provider config an adapter was created from
+type builtAdapter struct {
+	fingerprint string
+	adapter     Adapter
+}
+
+// ReloadSummary describes the registry after a reload
+type ReloadSummary struct {
+	Providers int      // registered providers
+	Reused    int      // providers whose adapter was kept from the previous load
+	Roles     int      // mapped roles
+	Skipped   []string // providers / roles that could not be registered, with reasons
+}
+
+// NewRegistryLoader creates a loader that populates registry
+func NewRegistryLoader(dbClient *db.Client, registry *Registry, factories *AgentFactoryRegistry, fallback *EnvFallback, logger *zap.SugaredLogger) *RegistryLoader {
+	return &RegistryLoader{
+		db:        dbClient,
+		registry:  registry,
+		factories: factories,
+		fallback:  fallback,
+		logger:    logger,
+		built:     make(map[string]builtAdapter),
+	}
+}
+
+// Reload rebuilds the registry from the database and swaps it in.
+// On error the current registry is left untouched.
+func (l *RegistryLoader) Reload(ctx context.Context) (*ReloadSummary, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next := NewRegistry()
+	built := make(map[string]builtAdapter)
+	summary := &ReloadSummary{}
+
+	providers, resolver, roleConfigs, err := l.loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Providers
+	if len(providers) > 0 {
+		for _, p := range providers {
+			adapter, reused, err := l.buildAdapter(p.ID, p.AgentType, p.Config, resolver.DefaultModel(p.ID), built)
+			if err != nil {
+				l.logger.Warnw("Failed to create adapter, skipping", "type", p.AgentType, "name", p.Name, "error", err)
+				summary.Skipped = append(summary.Skipped, fmt.Sprintf("provider %s: %v", p.Name, err))
+				continue
+			}
+			next.RegisterProvider(p.ID, adapter)
+			summary.Providers++
+			if reused {
+				summary.Reused++
+			}
+			l.logger.Debugw("Registered provider", "id", p.ID, "type", p.AgentType, "name", p.Name, "default", p.IsDefault, "reused", reused)
+		}
+	} else {
+		// Fallback: use environment variables (backward compat)
+		if l.fallback == nil {
+			return nil, fmt.Errorf("no agent providers configured in database and no ANTHROPIC_API_KEY/ANTHROPIC_AUTH_TOKEN in environment")
+		}
+		adapter, reused, err := l.buildAdapter(envFallbackProviderID, "claude-code", l.fallback.Config, l.fallback.Model, built)
+		if err != nil {
+			return nil, fmt.Errorf("create env fallback adapter: %w", err)
+		}
+		next.RegisterProvider(envFallbackProviderID, adapter)
+		summary.Providers++
+		if reused {
+			summary.Reused++
+		}
+		l.logger.Warn("No providers in database, using environment variable fallback")
+	}
+
+	// 2. Role mappings (configured roles plus built-in defaults)
+	for _, res := range resolver.Resolve(roleConfigs) {
+		mapping := res.Mapping()
+		if mapping == nil {
+			l.logger.Warnw("No provider found for role", "role", res.Role, "agent_type", res.AgentType, "reason", res.Reason)
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("role %s: %s", res.Role, res.Reason))
+			continue
+		}
+		next.MapRoleToProvider(res.Role, mapping.ProviderID, mapping.ModelName)
+		l.logger.Debugw("Mapped role", "role", res.Role, "provider", mapping.ProviderID, "model", mapping.ModelName, "reason", res.Reason)
+	}
+	summary.Roles = len(next.roles)
+
+	// 3. Swap in, then close replaced and dropped adapters; they stay usable until the
+	// executions that already hold them finish
+	l.registry.Swap(next)
+	prevBuilt := l.built
+	l.built = built
+	for providerID, prev := range prevBuilt {
+		if cur, ok := built[providerID]; ok && cur.adapter == prev.adapter {
+			continue
+		}
+		if closer, ok := prev.adapter.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				l.logger.Warnw("Failed to close replaced adapter", "provider", providerID, "error", err)
+			}
+		}
+	}
+
+	l.logger.Infow("Agent registry loaded",
+		"providers", summary.Providers,
+		"reused", summary.Reused,
+		"roles", summary.Roles,
+		"skipped", len(summary.Skipped),
+	)
+	return summary, nil
+}
+
+// Describe resolves all roles against the current database configuration and reports,
+// for each resolved role, whether its provider has a working adapter in the registry
+func (l *RegistryLoader) Describe(ctx context.Context) ([]*RoleResolution, map[string]bool, error) {
+	_, resolver, roleConfigs, err := l.loadConfig(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolutions := resolver.Resolve(roleConfigs)
+	ready := make(map[string]bool, len(resolutions))
+	for _, res := range resolutions {
+		if res.Resolved {
+			_, ok := l.registry.GetAdapterByProvider(res.ProviderID)
+			ready[res.Role] = ok
+		}
+	}
+	return resolutions, ready, nil
+}
+
+// loadConfig reads providers, models and role configs and builds a resolver over them
+func (l *RegistryLoader) loadConfig(ctx context.Context) ([]*db.AgentProvider, *RoleResolver, map[string]*db.AgentRoleConfig, error) {
+	providers, err := l.db.GetAllAgentProviders(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("load agent providers: %w", err)
+	}
+	models, err := l.db.GetAllAgentModels(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("load agent models: %w", err)
+	}
+	roleConfigs, err := l.db.GetAllAgentRoleConfigs(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("load agent role configs: %w", err)
+	}
+	return providers, NewRoleResolver(providers, models, l.fallback), roleConfigs, nil
+}
+
+// Watch reloads the registry every interval until ctx is done
+func (l *RegistryLoader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := l.Reload(ctx); err != nil {
+				l.logger.Warnw("Periodic agent registry reload failed", "error", err)
+			}
+		}
+	}
+}
+
+// buildAdapter returns the previous adapter for providerID if its config is unchanged,
+// otherwise creates a new one. The result is recorded in built.
+func (l *RegistryLoader) buildAdapter(providerID, agentType string, config map[string]any, modelName string, built map[string]builtAdapter) (Adapter, bool, error) {
+	raw, err := json.Marshal(struct {
+		AgentType string         `json:"agent_type"`
+		Config    map[string]any `json:"config"`
+		Model     string         `json:"model"`
+	}{agentType, config, modelName})
+	if err != nil {
+		return nil, false, fmt.Errorf("fingerprint provider config: %w", err)
+	}
+	fingerprint := string(raw)
+
+	if prev, ok := l.built[providerID]; ok && prev.fingerprint == fingerprint {
+		built[providerID] = prev
+		return prev.adapter, true, nil
+	}
+
+	adapter, err := l.factories.CreateAdapter(l.logger, agentType, providerID, config, modelName)
+	if err != nil {
+		return nil, false, err
+	}
+	built[providerID] = builtAdapter{fingerprint: fingerprint, adapter: adapter}
+	return adapter, false, nil
+}
@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Where a role's provider came from
+const (
+	ProviderSourceRole        = "role"         // agent_roles.provider_id
+	ProviderSourceTypeDefault = "type_default" // default provider of the role's agent type
+	ProviderSourceEnvFallback = "env_fallback" // no providers in database, environment variables used
+)
+
+// Where a role's model came from ("" = none; the adapter's own default applies)
+const (
+	ModelSourceRole            = "role"             // agent_roles.model_id
+	ModelSourceProviderDefault = "provider_default" // default model of the resolved provider
+	ModelSourceEnvFallback     = "env_fallback"     // CLAUDE_MODEL
+)
+
+// RoleResolution is the effective provider and model of one role, and how they were chosen
+type RoleResolution struct {
+	Role           string
+	AgentType      string
+	InDatabase     bool // false for built-in default roles missing from agent_roles
+	ProviderID     string
+	ProviderName   string
+	ModelName      string
+	ProviderSource string
+	ModelSource    string
+	Resolved       bool
+	Reason         string // human-readable explanation, or why the role cannot resolve
+}
+
+// Mapping returns the registry mapping for a resolved role (nil if unresolved)
+func (r *RoleResolution) Mapping() *RoleMapping {
+	if !r.Resolved {
+		return nil
+	}
+	return &RoleMapping{ProviderID: r.ProviderID, ModelName: r.ModelName}
+}
+
+// RoleResolver computes role mappings from the agent configuration rows. Precedence:
+// role provider → role model → provider default model → agent type default provider →
+// env fallback (only when the database has no providers). Built-in default roles missing
+// from the database resolve to the default claude-code provider.
+//
+// Compared with the loader's former inline rules: a role whose provider does not exist is
+// unresolved (it used to be mapped to the missing provider and failed at execution); a role
+// model that does not exist falls back to the provider's default model (it used to leave the
+// model empty); and when an agent type has several default providers the first one in
+// GetAllAgentProviders order (oldest) wins instead of an arbitrary one.
+type RoleResolver struct {
+	providers        map[string]*db.AgentProvider
+	defaultProviders map[string]*db.AgentProvider // agent type → default provider
+	models           map[string]*db.AgentModel
+	defaultModels    map[string]*db.AgentModel // provider ID → default model
+	fallback         *EnvFallback              // used only when there are no providers
+}
+
+// NewRoleResolver indexes providers and models. fallback may be nil.
+func NewRoleResolver(providers []*db.AgentProvider, models []*db.AgentModel, fallback *EnvFallback) *RoleResolver {
+	r := &RoleResolver{
+		providers:        make(map[string]*db.AgentProvider),
+		defaultProviders: make(map[string]*db.AgentProvider),
+		models:           make(map[string]*db.AgentModel),
+		defaultModels:    make(map[string]*db.AgentModel),
+	}
+	for _, p := range providers {
+		r.providers[p.ID] = p
+		if _, ok := r.defaultProviders[p.AgentType]; !ok && p.IsDefault {
+			r.defaultProviders[p.AgentType] = p
+		}
+	}
+	for _, m := range models {
+		r.models[m.ID] = m
+		if _, ok := r.defaultModels[m.ProviderID]; !ok && m.IsDefault {
+			r.defaultModels[m.ProviderID] = m
+		}
+	}
+	if len(providers) == 0 {
+		r.fallback = fallback
+	}
+	return r
+}
+
+// DefaultModel returns the default model name of a provider ("" if none)
+func (r *RoleResolver) DefaultModel(providerID string) string {
+	if m, ok := r.defaultModels[providerID]; ok {
+		return m.ModelName
+	}
+	return ""
+}
+
+// Resolve resolves every configured role plus the built-in default roles, sorted by role
+func (r *RoleResolver) Resolve(roleConfigs map[string]*db.AgentRoleConfig) []*RoleResolution {
+	result := make([]*RoleResolution, 0, len(roleConfigs)+len(defaultRoles))
+	for _, rc := range roleConfigs {
+		result = append(result, r.ResolveRole(rc))
+	}
+	for _, role := range defaultRoles {
+		if _, ok := roleConfigs[role]; ok {
+			continue
+		}
+		res := r.ResolveRole(&db.AgentRoleConfig{Slug: role, AgentType: "claude-code"})
+		res.InDatabase = false
+		result = append(result, res)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Role < result[j].Role })
+	return result
+}
+
+// ResolveRole resolves a single role configuration
+func (r *RoleResolver) ResolveRole(rc *db.AgentRoleConfig) *RoleResolution {
+	res := &RoleResolution{Role: rc.Slug, AgentType: rc.AgentType, InDatabase: true}
+
+	// 1. Provider
+	switch {
+	case rc.ProviderID != nil:
+		p, ok := r.providers[*rc.ProviderID]
+		if !ok {
+			res.ProviderID = *rc.ProviderID
+			res.Reason = fmt.Sprintf("role provider %s does not exist", *rc.ProviderID)
+			return res
+		}
+		res.ProviderID, res.ProviderName, res.ProviderSource = p.ID, p.Name, ProviderSourceRole
+	case r.defaultProviders[rc.AgentType] != nil:
+		p := r.defaultProviders[rc.AgentType]
+		res.ProviderID, res.ProviderName, res.ProviderSource = p.ID, p.Name, ProviderSourceTypeDefault
+	case r.fallback != nil:
+		res.ProviderID, res.ProviderName, res.ProviderSource = envFallbackProviderID, envFallbackProviderID, ProviderSourceEnvFallback
+		res.ModelName, res.ModelSource = r.fallback.Model, ModelSourceEnvFallback
+		res.Resolved = true
+		res.Reason = "no providers in database, using environment variable fallback"
+		return res
+	default:
+		res.Reason = fmt.Sprintf("no provider set on role and no default provider for agent type %s", rc.AgentType)
+		return res
+	}
+	res.Resolved = true
+
+	// 2. Model
+	if rc.ModelID != nil {
+		m, ok := r.models[*rc.ModelID]
+		switch {
+		case !ok:
+			res.Reason = fmt.Sprintf("role model %s does not exist; ", *rc.ModelID)
+		case m.ProviderID != res.ProviderID:
+			res.ModelName, res.ModelSource = m.ModelName, ModelSourceRole
+			res.Reason = fmt.Sprintf("warning: role model %s belongs to another provider; ", m.ModelName)
+		default:
+			res.ModelName, res.ModelSource = m.ModelName, ModelSourceRole
+		}
+	}
+	if res.ModelSource == "" {
+		if m, ok := r.defaultModels[res.ProviderID]; ok {
+			res.ModelName, res.ModelSource = m.ModelName, ModelSourceProviderDefault
+		}
+	}
+
+	res.Reason += describeSources(res)
+	return res
+}
+
+// describeSources explains a resolved role's provider and model choice
+func describeSources(res *RoleResolution) string {
+	provider := "role provider"
+	if res.ProviderSource == ProviderSourceTypeDefault {
+		provider = "default provider for " + res.AgentType
+	}
+	switch res.ModelSource {
+	case ModelSourceRole:
+		return provider + ", role model"
+	case ModelSourceProviderDefault:
+		return provider + ", provider default model"
+	default:
+		return provider + ", no model configured (adapter default)"
+	}
+}
@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+func strPtr(s string) *string { return &s }
+
+// Providers are listed as GetAllAgentProviders returns them (agent_type, created_at)
+var (
+	testProviders = []*db.AgentProvider{
+		{ID: "claude-a", AgentType: "claude-code", Name: "Claude A", IsDefault: true},
+		{ID: "claude-b", AgentType: "claude-code", Name: "Claude B", IsDefault: true},
+		{ID: "claude-c", AgentType: "claude-code", Name: "Claude C"},
+		{ID: "codex-a", AgentType: "codex", Name: "Codex A"},
+	}
+	testModels = []*db.AgentModel{
+		{ID: "m-sonnet", ProviderID: "claude-a", ModelName: "sonnet", IsDefault: true},
+		{ID: "m-opus", ProviderID: "claude-a", ModelName: "opus"},
+		{ID: "m-haiku", ProviderID: "claude-c", ModelName: "haiku", IsDefault: true},
+		{ID: "m-gpt", ProviderID: "codex-a", ModelName: "gpt-5"},
+	}
+)
+
+func TestResolveRole(t *testing.T) {
+	resolver := NewRoleResolver(testProviders, testModels, &EnvFallback{Model: "env-model"})
+
+	tests := []struct {
+		name           string
+		role           *db.AgentRoleConfig
+		wantResolved   bool
+		wantProvider   string
+		wantModel      string
+		providerSource string
+		modelSource    string
+		reason         string
+	}{
+		{
+			name:           "role provider and role model",
+			role:           &db.AgentRoleConfig{Slug: "r", AgentType: "claude-code", ProviderID: strPtr("claude-a"), ModelID: strPtr("m-opus")},
+			wantResolved:   true,
+			wantProvider:   "claude-a",
+			wantModel:      "opus",
+			providerSource: ProviderSourceRole,
+			modelSource:    ModelSourceRole,
+		},
+		{
+			name:           "role provider falls back to its default model",
+			role:           &db.AgentRoleConfig{Slug: "r", AgentType: "claude-code", ProviderID: strPtr("claude-c")},
+			wantResolved:   true,
+			wantProvider:   "claude-c",
+			wantModel:      "haiku",
+			providerSource: ProviderSourceRole,
+			modelSource:    ModelSourceProviderDefault,
+		},
+		{
+			name:           "role provider without models leaves the adapter default",
+			role:           &db.AgentRoleConfig{Slug: "r", AgentType: "codex", ProviderID: strPtr("codex-a")},
+			wantResolved:   true,
+			wantProvider:   "codex-a",
+			providerSource: ProviderSourceRole,
+			reason:         "adapter default",
+		},
+		{
+			// Several is_default providers: the first listed (oldest) wins
+			name:           "agent type default provider",
+			role:           &db.AgentRoleConfig{Slug: "r", AgentType: "claude-code"},
+			wantResolved:   true,
+			wantProvider:   "claude-a",
+			wantModel:      "sonnet",
+			providerSource: ProviderSourceTypeDefault,
+			modelSource:    ModelSourceProviderDefault,
+		},
+		{
+			// Changed: the old loader mapped the role to an empty model
+			name:           "missing role model falls back to the provider default",
+			role:           &db.AgentRoleConfig{Slug: "r", AgentType: "claude-code", ProviderID: strPtr("claude-a"), ModelID: strPtr("gone")},
+			wantResolved:   true,
+			wantProvider:   "claude-a",
+			wantModel:      "sonnet",
+			providerSource: ProviderSourceRole,
+			modelSource:    ModelSourceProviderDefault,
+			reason:         "role model gone does not exist",
+		},
+		{
+			name:           "role model of another provider is used with a warning",
+			role:           &db.AgentRoleConfig{Slug: "r", AgentType: "claude-code", ProviderID: strPtr("claude-c"), ModelID: strPtr("m-opus")},
+			wantResolved:   true,
+			wantProvider:   "claude-c",
+			wantModel:      "opus",
+			providerSource: ProviderSourceRole,
+			modelSource:    ModelSourceRole,
+			reason:         "belongs to another provider",
+		},
+		{
+			// Changed: the old loader mapped the role to the missing provider
+			name:         "missing role provider is unresolved",
+			role:         &db.AgentRoleConfig{Slug: "r", AgentType: "claude-code", ProviderID: strPtr("gone")},
+			wantProvider: "gone",
+			reason:       "role provider gone does not exist",
+		},
+		{
+			name:   "no default provider for the agent type is unresolved",
+			role:   &db.AgentRoleConfig{Slug: "r", AgentType: "codex"},
+			reason: "no default provider for agent type codex",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resolver.ResolveRole(tt.role)
+			if res.Resolved != tt.wantResolved || res.ProviderID != tt.wantProvider || res.ModelName != tt.wantModel {
+				t.Fatalf("resolved=%v provider=%q model=%q, want resolved=%v provider=%q model=%q (%s)",
+					res.Resolved, res.ProviderID, res.ModelName, tt.wantResolved, tt.wantProvider, tt.wantModel, res.Reason)
+			}
+			if res.ProviderSource != tt.providerSource || res.ModelSource != tt.modelSource {
+				t.Errorf("sources = %q/%q, want %q/%q", res.ProviderSource, res.ModelSource, tt.providerSource, tt.modelSource)
+			}
+			if !strings.Contains(res.Reason, tt.reason) {
+				t.Errorf("reason = %q, want it to contain %q", res.Reason, tt.reason)
+			}
+			if mapping := res.Mapping(); (mapping != nil) != tt.wantResolved {
+				t.Errorf("Mapping() = %+v, resolved = %v", mapping, tt.wantResolved)
+			}
+		})
+	}
+}
+
+func TestResolveEnvFallback(t *testing.T) {
+	fallback := &EnvFallback{Model: "env-model"}
+
+	// Without providers every role uses the environment fallback, even one naming a provider's type
+	res := NewRoleResolver(nil, nil, fallback).ResolveRole(&db.AgentRoleConfig{Slug: "r", AgentType: "codex"})
+	if !res.Resolved || res.ProviderID != envFallbackProviderID || res.ModelName != "env-model" ||
+		res.ProviderSource != ProviderSourceEnvFallback || res.ModelSource != ModelSourceEnvFallback {
+		t.Errorf("no providers: %+v", res)
+	}
+
+	// Once any provider exists the fallback is ignored
+	res = NewRoleResolver(testProviders[3:], nil, fallback).ResolveRole(&db.AgentRoleConfig{Slug: "r", AgentType: "claude-code"})
+	if res.Resolved {
+		t.Errorf("fallback used although providers exist: %+v", res)
+	}
+
+	// No providers and no fallback
+	if res := NewRoleResolver(nil, nil, nil).ResolveRole(&db.AgentRoleConfig{Slug: "r", AgentType: "claude-code"}); res.Resolved {
+		t.Errorf("resolved without providers or fallback: %+v", res)
+	}
+}
+
+func TestResolveDefaultRoles(t *testing.T) {
+	resolver := NewRoleResolver(testProviders, testModels, nil)
+	configured := map[string]*db.AgentRoleConfig{
+		"code-reviewer": {Slug: "code-reviewer", AgentType: "codex", ProviderID: strPtr("codex-a"), ModelID: strPtr("m-gpt")},
+		"custom":        {Slug: "custom", AgentType: "claude-code", ProviderID: strPtr("claude-c")},
+	}
+
+	results := resolver.Resolve(configured)
+	if len(results) != len(defaultRoles)+1 {
+		t.Fatalf("got %d roles, want %d", len(results), len(defaultRoles)+1)
+	}
+	byRole := make(map[string]*RoleResolution)
+	for i, res := range results {
+		if i > 0 && results[i-1].Role >= res.Role {
+			t.Errorf("roles not sorted: %s before %s", results[i-1].Role, res.Role)
+		}
+		byRole[res.Role] = res
+	}
+
+	// A configured built-in role keeps its database config
+	if res := byRole["code-reviewer"]; !res.InDatabase || res.ProviderID != "codex-a" || res.ModelName != "gpt-5" {
+		t.Errorf("code-reviewer = %+v", res)
+	}
+	// Missing built-in roles map to the default claude-code provider
+	for _, role := range defaultRoles {
+		if role == "code-reviewer" {
+			continue
+		}
+		res := byRole[role]
+		if res == nil || res.InDatabase || res.ProviderID != "claude-a" || res.ModelName != "sonnet" {
+			t.Errorf("%s = %+v", role, res)
+		}
+	}
+	if res := byRole["custom"]; res == nil || !res.InDatabase || res.ModelName != "haiku" {
+		t.Errorf("custom = %+v", res)
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	resolver := NewRoleResolver(testProviders, testModels, nil)
+	if got := resolver.DefaultModel("claude-a"); got != "sonnet" {
+		t.Errorf("DefaultModel(claude-a) = %q", got)
+	}
+	if got := resolver.DefaultModel("codex-a"); got != "" {
+		t.Errorf("DefaultModel(codex-a) = %q, want none", got)
+	}
+}
@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScriptRequest is a deterministic command run by a script node, in a checkout of the task
+// repository prepared the same way agent containers prepare theirs
+type ScriptRequest struct {
+	NodeRunID           string
+	Command             string            // shell command, run with `sh -c` in the checkout
+	Image               string            // container image (container runners; "" = runner default)
+	Env                 map[string]string // extra environment variables
+	Timeout             time.Duration     // default 10m
+	OutputFile          string            // JSON file (relative to the checkout) parsed into ScriptResult.Output
+	GitRepoURL          string            // cloned into the working directory when set (may carry a token)
+	GitBranch           string            // branch to check out (default main)
+	GitReferenceRepo    string            // local mirror used as `git clone --reference` (container path / host path)
+	PersistentWorkspace bool              // the working directory is a volume shared with other nodes of the flow run
+	Limits              *ContainerLimits  // node-level container limits (container runners only)
+	Mounts              []Mount           // extra mounts, e.g. the shared workspace (container runners only)
+	OnOutput            ScriptOutputFunc  // receives stdout / stderr line by line while the script runs
+}
+
+// ScriptOutputFunc receives one line of a script's output; stream is "stdout" or "stderr"
+type ScriptOutputFunc func(stream, line string)
+
+// ScriptResult is the outcome of a script that ran to completion (any exit code)
+type ScriptResult struct {
+	ExitCode    int
+	Stdout      string         // last scriptTailBytes of stdout
+	Stderr      string         // last scriptTailBytes of stderr
+	Output      map[string]any // parsed OutputFile (nil when not configured or unreadable)
+	OutputError string         // why OutputFile could not be read or parsed
+	DurationMs  int64
+}
+
+// ScriptRunner runs script node commands
+type ScriptRunner interface {
+	Kind() string // "docker" / "local"
+	Run(ctx context.Context, req *ScriptRequest) (*ScriptResult, error)
+}
+
+// Script runner kinds
+const (
+	ScriptRunnerDocker = "docker"
+	ScriptRunnerLocal  = "local"
+)
+
+const (
+	defaultScriptTimeout = 10 * time.Minute
+	scriptTailBytes      = 64 * 1024 // stdout / stderr kept in ScriptResult
+	maxScriptOutputBytes = 1 << 20   // largest OutputFile that is parsed
+)
+
+// scriptCheckout prepares the working directory like the agent entrypoint does: reuse a
+// shared workspace checkout, otherwise clone the task branch (borrowing objects from the
+// repo mirror when available). Checkout failures exit with 128 before the command runs.
+const scriptCheckout = `if [ -n "$GIT_REPO_URL" ]; then
+  if [ -d .git ]; then
+    echo "[script] Reusing shared workspace checkout" >&2
+    git remote set-url origin "$GIT_REPO_URL" || exit 128
+  else
+    CLONE_OPTS="--depth 50"
+    if [ -n "$GIT_REFERENCE_REPO" ] && [ -d "$GIT_REFERENCE_REPO" ]; then
+      CLONE_OPTS="$CLONE_OPTS --reference-if-able $GIT_REFERENCE_REPO"
+      if [ "$WORKSPACE_PERSISTENT" = "true" ]; then CLONE_OPTS="$CLONE_OPTS --dissociate"; fi
+    fi
+    echo "[script] Cloning branch ${GIT_BRANCH:-main}..." >&2
+    git clone --quiet "$GIT_REPO_URL" --branch "${GIT_BRANCH:-main}" --single-branch $CLONE_OPTS . >&2 || {
+      echo "[script] Failed to clone branch ${GIT_BRANCH:-main}, trying default branch..." >&2
+      git clone --quiet "$GIT_REPO_URL" --single-branch $CLONE_OPTS . >&2 || { echo "[script] git checkout failed" >&2; exit 128; }
+    }
+  fi
+fi
+`
+
+// scriptShell returns the `sh -c` script that checks out the repository and runs command
+func scriptShell(command string) string {
+	return scriptCheckout + command + "\n"
+}
+
+// scriptEnv returns the request's environment plus the checkout variables
+func scriptEnv(req *ScriptRequest) map[string]string {
+	env := make(map[string]string, len(req.Env)+4)
+	for k, v := range req.Env {
+		env[k] = v
+	}
+	branch := req.GitBranch
+	if branch == "" {
+		branch = "main"
+	}
+	env["GIT_REPO_URL"] = req.GitRepoURL
+	env["GIT_BRANCH"] = branch
+	env["GIT_TERMINAL_PROMPT"] = "0"
+	if req.GitReferenceRepo != "" {
+		env["GIT_REFERENCE_REPO"] = req.GitReferenceRepo
+	}
+	if req.PersistentWorkspace {
+		env["WORKSPACE_PERSISTENT"] = "true"
+	}
+	return env
+}
+
+// scriptTimeout returns the request's timeout or the default
+func scriptTimeout(req *ScriptRequest) time.Duration {
+	if req.Timeout > 0 {
+		return req.Timeout
+	}
+	return defaultScriptTimeout
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+		t.truncated = true
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	if t.truncated {
+		return "…" + strings.ToValidUTF8(string(t.buf), "")
+	}
+	return string(t.buf)
+}
+
+// scriptOutput streams a script's stdout and stderr line by line to the request's
+// OnOutput and keeps their tails
+type scriptOutput struct {
+	onOutput ScriptOutputFunc
+	mu       sync.Mutex // serializes OnOutput calls of the two streams
+	stdout   tailBuffer
+	stderr   tailBuffer
+	wg       sync.WaitGroup
+}
+
+func newScriptOutput(onOutput ScriptOutputFunc) *scriptOutput {
+	return &scriptOutput{
+		onOutput: onOutput,
+		stdout:   tailBuffer{max: scriptTailBytes},
+		stderr:   tailBuffer{max: scriptTailBytes},
+	}
+}
+
+// follow reads r until EOF in the background
+func (o *scriptOutput) follow(stream string, r io.Reader) {
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		reader := bufio.NewReaderSize(r, 64*1024)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				o.line(stream, line)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func (o *scriptOutput) line(stream, line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if stream == "stderr" {
+		_, _ = o.stderr.Write([]byte(line))
+	} else {
+		_, _ = o.stdout.Write([]byte(line))
+	}
+	if o.onOutput != nil {
+		o.onOutput(stream, strings.TrimRight(line, "\r\n"))
+	}
+}
+
+// wait blocks until both streams reached EOF
+func (o *scriptOutput) wait() {
+	o.wg.Wait()
+}
+
+// parseScriptOutput decodes a script's JSON output file into a result
+func parseScriptOutput(res *ScriptResult, data []byte, err error) {
+	if err != nil {
+		res.OutputError = err.Error()
+		return
+	}
+	if len(data) > maxScriptOutputBytes {
+		res.OutputError = fmt.Sprintf("output file exceeds %d bytes", maxScriptOutputBytes)
+		return
+	}
+	var output map[string]any
+	if err := json.Unmarshal(data, &output); err != nil {
+		res.OutputError = fmt.Sprintf("output file is not a JSON object: %v", err)
+		return
+	}
+	res.Output = output
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"go.uber.org/zap"
+)
+
+// defaultScriptImage has git, sh, node, jq and the OpenSpec CLI
+const defaultScriptImage = "workgear/agent-claude:latest"
+
+// DockerScriptRunner runs script node commands in a container, with the checkout in /workspace
+type DockerScriptRunner struct {
+	cli          *client.Client
+	defaultImage string
+	logger       *zap.SugaredLogger
+}
+
+// NewDockerScriptRunner creates a script runner using the Docker environment config.
+// defaultImage is used by nodes without an image ("" = the Claude agent image).
+func NewDockerScriptRunner(logger *zap.SugaredLogger, defaultImage string) (*DockerScriptRunner, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("create docker client: %w", err)
+	}
+	if defaultImage == "" {
+		defaultImage = defaultScriptImage
+	}
+	return &DockerScriptRunner{cli: cli, defaultImage: defaultImage, logger: logger}, nil
+}
+
+func (r *DockerScriptRunner) Kind() string { return ScriptRunnerDocker }
+
+func (r *DockerScriptRunner) Run(ctx context.Context, req *ScriptRequest) (*ScriptResult, error) {
+	imageName := req.Image
+	if imageName == "" {
+		imageName = r.defaultImage
+	}
+	timeout := scriptTimeout(req)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := pullImageIfMissing(execCtx, r.cli, r.logger, imageName); err != nil {
+		return nil, fmt.Errorf("ensure image %s: %w", imageName, err)
+	}
+
+	env := scriptEnv(req)
+	envList := make([]string, 0, len(env))
+	for k, v := range env {
+		envList = append(envList, k+"="+v)
+	}
+	containerConfig := &container.Config{
+		Image:      imageName,
+		Entrypoint: []string{"sh", "-c"},
+		Cmd:        []string{scriptShell(req.Command)},
+		Env:        envList,
+		WorkingDir: WorkspaceMountPath,
+	}
+	hostConfig := containerHostConfig(req.Limits, req.Mounts)
+	containerName := fmt.Sprintf("workgear-script-%s-%d", req.NodeRunID, time.Now().UnixMilli())
+
+	r.logger.Infow("Creating script container", "image", imageName, "container", containerName,
+		"timeout", timeout, "mounts", len(req.Mounts))
+	createResp, err := r.cli.ContainerCreate(execCtx, containerConfig, hostConfig, nil, nil, containerName)
+	if err != nil {
+		return nil, fmt.Errorf("create container: %w", err)
+	}
+	containerID := createResp.ID
+	defer func() {
+		removeCtx, removeCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer removeCancel()
+		if err := r.cli.ContainerRemove(removeCtx, containerID, container.RemoveOptions{Force: true, RemoveVolumes: true}); err != nil {
+			r.logger.Warnw("Failed to remove script container", "container_id", containerID, "error", err)
+		}
+	}()
+
+	start := time.Now()
+	if err := r.cli.ContainerStart(execCtx, containerID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("start container: %w", err)
+	}
+
+	// Stream stdout / stderr while the script runs
+	out := newScriptOutput(req.OnOutput)
+	logReader, err := r.cli.ContainerLogs(execCtx, containerID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("attach container logs: %w", err)
+	}
+	defer logReader.Close()
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	go func() {
+		_, err := stdcopy.StdCopy(stdoutWriter, stderrWriter, logReader)
+		stdoutWriter.CloseWithError(err)
+		stderrWriter.CloseWithError(err)
+	}()
+	out.follow("stdout", stdoutReader)
+	out.follow("stderr", stderrReader)
+
+	statusCh, errCh := r.cli.ContainerWait(execCtx, containerID, container.WaitConditionNotRunning)
+	var exitCode int
+	select {
+	case err := <-errCh:
+		if execCtx.Err() == context.DeadlineExceeded {
+			r.killContainer(containerID)
+			return nil, fmt.Errorf("script timed out after %s", timeout)
+		}
+		return nil, fmt.Errorf("wait container: %w", err)
+	case status := <-statusCh:
+		exitCode = int(status.StatusCode)
+	case <-execCtx.Done():
+		r.killContainer(containerID)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("script timed out after %s", timeout)
+	}
+	out.wait()
+
+	res := &ScriptResult{
+		ExitCode:   exitCode,
+		Stdout:     out.stdout.String(),
+		Stderr:     out.stderr.String(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if req.OutputFile != "" {
+		data, err := r.readFile(ctx, containerID, path.Join(WorkspaceMountPath, req.OutputFile))
+		parseScriptOutput(res, data, err)
+	}
+
+	r.logger.Infow("Script container finished", "container_id", containerID[:12], "exit_code", exitCode,
+		"duration_ms", res.DurationMs, "has_output", res.Output != nil)
+	return res, nil
+}
+
+func (r *DockerScriptRunner) killContainer(containerID string) {
+	killCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = r.cli.ContainerKill(killCtx, containerID, "SIGKILL")
+}
+
+// readFile reads a regular file from a stopped container
+func (r *DockerScriptRunner) readFile(ctx context.Context, containerID, filePath string) ([]byte, error) {
+	reader, _, err := r.cli.CopyFromContainer(ctx, containerID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("output file %s not found", filePath)
+	}
+	defer reader.Close()
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("output file %s is not a regular file", filePath)
+		}
+		if header.Typeflag == tar.TypeReg {
+			return io.ReadAll(io.LimitReader(tr, maxScriptOutputBytes+1))
+		}
+	}
+}
+
+// Close releases the Docker client resources
+func (r *DockerScriptRunner) Close() error {
+	return r.cli.Close()
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LocalScriptRunner runs script node commands as processes of the orchestrator, each in a
+// fresh temporary checkout. It has none of the isolation of containers, so it is meant for
+// trusted workflows and for development and tests.
+type LocalScriptRunner struct {
+	baseDir string // parent of the per-run working directories ("" = os.TempDir())
+	logger  *zap.SugaredLogger
+}
+
+// NewLocalScriptRunner creates a local process script runner
+func NewLocalScriptRunner(logger *zap.SugaredLogger, baseDir string) *LocalScriptRunner {
+	return &LocalScriptRunner{baseDir: baseDir, logger: logger}
+}
+
+// localScriptInheritedEnv are the only orchestrator environment variables a local script
+// sees; everything else (database URL, API keys, secret stores) stays out of its reach
+var localScriptInheritedEnv = []string{"PATH", "HOME", "LANG"}
+
+// localScriptEnv returns the environment of a local script: the inherited basics plus the
+// request's variables
+func localScriptEnv(req *ScriptRequest) []string {
+	var env []string
+	for _, key := range localScriptInheritedEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	for k, v := range scriptEnv(req) {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+func (r *LocalScriptRunner) Kind() string { return ScriptRunnerLocal }
+
+func (r *LocalScriptRunner) Run(ctx context.Context, req *ScriptRequest) (*ScriptResult, error) {
+	if req.PersistentWorkspace {
+		return nil, fmt.Errorf("the local script executor cannot use a shared workspace")
+	}
+	workDir, err := os.MkdirTemp(r.baseDir, "workgear-script-")
+	if err != nil {
+		return nil, fmt.Errorf("create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	timeout := scriptTimeout(req)
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(execCtx, "sh", "-c", scriptShell(req.Command))
+	cmd.Dir = workDir
+	cmd.Env = localScriptEnv(req)
+	// Output goes through pipes copied by exec, so Wait returns at most WaitDelay after the
+	// shell exits even when orphaned children still hold the pipes open
+	cmd.WaitDelay = 5 * time.Second
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+
+	r.logger.Infow("Starting local script", "node_run_id", req.NodeRunID, "dir", workDir, "timeout", timeout)
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start script: %w", err)
+	}
+	out := newScriptOutput(req.OnOutput)
+	out.follow("stdout", stdoutReader)
+	out.follow("stderr", stderrReader)
+	err = cmd.Wait()
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	out.wait()
+
+	if execCtx.Err() != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("script timed out after %s", timeout)
+	}
+	// A non-zero exit and background children outliving the shell (ErrWaitDelay) still
+	// leave the shell's exit code
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		return nil, fmt.Errorf("run script: %w", err)
+	}
+	exitCode := cmd.ProcessState.ExitCode()
+
+	res := &ScriptResult{
+		ExitCode:   exitCode,
+		Stdout:     out.stdout.String(),
+		Stderr:     out.stderr.String(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if req.OutputFile != "" {
+		data, err := os.ReadFile(filepath.Join(workDir, filepath.FromSlash(req.OutputFile)))
+		if errors.Is(err, os.ErrNotExist) {
+			err = fmt.Errorf("output file %s not found", req.OutputFile)
+		}
+		parseScriptOutput(res, data, err)
+	}
+
+	r.logger.Infow("Local script finished", "node_run_id", req.NodeRunID, "exit_code", exitCode,
+		"duration_ms", res.DurationMs, "has_output", res.Output != nil)
+	return res, nil
+}
@@ -0,0 +1,32 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestLocalScriptEnvironment(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://orchestrator:secret@db/workgear")
+	t.Setenv("LANG", "C.UTF-8")
+
+	runner := NewLocalScriptRunner(zap.NewNop().Sugar(), t.TempDir())
+	res, err := runner.Run(context.Background(), &ScriptRequest{
+		Command: `echo "db=${DATABASE_URL:-unset} lang=$LANG change=$CHANGE"; command -v sh >/dev/null && echo path=ok`,
+		Env:     map[string]string{"CHANGE": "add-login"},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.ExitCode != 0 {
+		t.Fatalf("exit code %d: %s", res.ExitCode, res.Stderr)
+	}
+	if want := "db=unset lang=C.UTF-8 change=add-login\npath=ok\n"; res.Stdout != want {
+		t.Errorf("stdout = %q, want %q", res.Stdout, want)
+	}
+	if strings.Contains(res.Stdout, "secret") {
+		t.Errorf("orchestrator environment leaked: %q", res.Stdout)
+	}
+}
@@ -0,0 +1,258 @@
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// DefaultToolApprovalTimeout is how long a gated tool call waits for a decision before it is denied
+const DefaultToolApprovalTimeout = 30 * time.Minute
+
+// SupportsToolApproval reports whether agents of the type can pause tool calls for approval.
+// Only claude-code installs the approval hook; on any other agent the gated calls would run
+// unchecked, so nodes with tool_approval must not run there.
+func SupportsToolApproval(agentType string) bool {
+	return agentType == "claude-code"
+}
+
+// toolApprovalPollInterval is how often the executor looks for new approval requests
+const toolApprovalPollInterval = 2 * time.Second
+
+// ToolApprovalRule matches agent tool calls that need human approval.
+// Both patterns are RE2 expressions that must also be valid JavaScript regexes,
+// since the container-side hook evaluates them.
+type ToolApprovalRule struct {
+	Tool   string `json:"tool" yaml:"tool"`                         // Matched against the whole tool name, e.g. "Bash" or "Edit|Write"
+	Input  string `json:"input,omitempty" yaml:"input"`             // Searched in the tool input JSON, e.g. "git push.*(--force|-f)"; empty matches any input
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"` // Shown to the approver
+}
+
+// ToolApprovalPolicy lists the tool calls of an execution that pause for approval
+type ToolApprovalPolicy struct {
+	Rules   []ToolApprovalRule
+	Timeout time.Duration // Undecided calls are denied after this long (0 = DefaultToolApprovalTimeout)
+}
+
+// Validate checks that every rule has a tool pattern and all patterns compile
+func (p *ToolApprovalPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		if rule.Tool == "" {
+			return fmt.Errorf("rule %d: tool is required", i)
+		}
+		if _, err := regexp.Compile("^(?:" + rule.Tool + ")$"); err != nil {
+			return fmt.Errorf("rule %d: invalid tool pattern: %w", i, err)
+		}
+		if rule.Input != "" {
+			if _, err := regexp.Compile(rule.Input); err != nil {
+				return fmt.Errorf("rule %d: invalid input pattern: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// EffectiveTimeout returns the decision timeout, applying the default
+func (p *ToolApprovalPolicy) EffectiveTimeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return DefaultToolApprovalTimeout
+}
+
+// Env returns the policy as the TOOL_APPROVAL_POLICY value read by the container hook
+func (p *ToolApprovalPolicy) Env() (string, error) {
+	data, err := json.Marshal(map[string]any{
+		"rules":           p.Rules,
+		"timeout_seconds": int(p.EffectiveTimeout().Seconds()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ToolApprovalRequest is a tool call the agent is blocked on
+type ToolApprovalRequest struct {
+	ID        string         `json:"id"` // UUID generated by the container hook
+	ToolName  string         `json:"tool_name"`
+	ToolInput map[string]any `json:"tool_input"`
+	Rule      string         `json:"rule"` // The matching rule, "<tool> ~ <input>"
+	Reason    string         `json:"reason,omitempty"`
+}
+
+// ToolApprovalDecision is the answer delivered back to the container hook
+type ToolApprovalDecision struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ToolApprover decides gated tool calls. RequestToolApproval blocks until the call is
+// decided, times out or ctx is cancelled (the latter two deny it).
+type ToolApprover interface {
+	RequestToolApproval(ctx context.Context, req *ToolApprovalRequest) ToolApprovalDecision
+}
+
+// ─── Docker handshake ───
+//
+// The hook writes /output/approvals/<id>.json and polls /output/approvals/<id>.decision;
+// the executor picks up requests, asks the approver and copies the decision in. Both sides
+// use /output because it is a volume even with a read-only rootfs: the archive API cannot
+// reach into the /tmp tmpfs.
+
+// toolApprovalDir holds the request and decision files of the handshake
+const toolApprovalDir = "/output/approvals"
+
+// watchToolApprovals relays approval requests of a running container to the approver
+// until ctx is done. The execution deadline is paused while a decision is pending.
+func (e *DockerExecutor) watchToolApprovals(ctx context.Context, containerID string, approver ToolApprover, deadline *pausableDeadline) {
+	seen := make(map[string]bool)
+	ticker := time.NewTicker(toolApprovalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, req := range e.readToolApprovalRequests(ctx, containerID) {
+			if seen[req.ID] {
+				continue
+			}
+			seen[req.ID] = true
+			e.logger.Infow("Agent tool call waiting for approval", "container_id", containerID[:12], "approval_id", req.ID, "tool", req.ToolName)
+
+			go func(req *ToolApprovalRequest) {
+				deadline.Pause()
+				decision := approver.RequestToolApproval(ctx, req)
+				deadline.Resume()
+				if err := e.writeToolApprovalDecision(ctx, containerID, req.ID, decision); err != nil {
+					e.logger.Warnw("Failed to deliver tool approval decision", "approval_id", req.ID, "error", err)
+				}
+			}(req)
+		}
+	}
+}
+
+// readToolApprovalRequests returns the request files currently in toolApprovalDir
+func (e *DockerExecutor) readToolApprovalRequests(ctx context.Context, containerID string) []*ToolApprovalRequest {
+	reader, _, err := e.cli.CopyFromContainer(ctx, containerID, toolApprovalDir)
+	if err != nil {
+		return nil // directory not created yet
+	}
+	defer reader.Close()
+
+	var requests []*ToolApprovalRequest
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			continue
+		}
+		var req ToolApprovalRequest
+		if err := json.Unmarshal(data, &req); err != nil || req.ID == "" {
+			e.logger.Warnw("Ignoring malformed tool approval request", "file", header.Name, "error", err)
+			continue
+		}
+		if req.ID+".json" != path.Base(header.Name) {
+			continue
+		}
+		requests = append(requests, &req)
+	}
+	return requests
+}
+
+// writeToolApprovalDecision copies <id>.decision into toolApprovalDir, next to the request
+// the hook created (so the directory exists)
+func (e *DockerExecutor) writeToolApprovalDecision(ctx context.Context, containerID, id string, decision ToolApprovalDecision) error {
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: id + ".decision", Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	// The decision must arrive even if the execution is being cancelled, so the hook can exit
+	copyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	return e.cli.CopyToContainer(copyCtx, containerID, toolApprovalDir, &buf, container.CopyToContainerOptions{})
+}
+
+// pausableDeadline calls cancel once timeout has elapsed, not counting the time spent
+// paused (waiting for tool approvals). Pauses nest.
+type pausableDeadline struct {
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	remaining time.Duration
+	started   time.Time
+	timer     *time.Timer
+	paused    int
+	stopped   bool
+}
+
+func newPausableDeadline(timeout time.Duration, cancel context.CancelFunc) *pausableDeadline {
+	return &pausableDeadline{
+		cancel:    cancel,
+		remaining: timeout,
+		started:   time.Now(),
+		timer:     time.AfterFunc(timeout, cancel),
+	}
+}
+
+// Pause stops the clock
+func (d *pausableDeadline) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused++
+	if d.paused == 1 && !d.stopped && d.timer.Stop() {
+		d.remaining -= time.Since(d.started)
+	}
+}
+
+// Resume restarts the clock once every Pause has been matched
+func (d *pausableDeadline) Resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused--
+	if d.paused == 0 && !d.stopped {
+		d.started = time.Now()
+		d.timer = time.AfterFunc(max(d.remaining, 0), d.cancel)
+	}
+}
+
+// Stop disarms the deadline for good
+func (d *pausableDeadline) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	d.timer.Stop()
+}
@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// tarFiles builds an archive of the given regular files, in order
+func tarFiles(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f[0], Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(f[1]))}); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(f[1]))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// untarFiles returns the regular files of an archive by name
+func untarFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		if header.Typeflag == tar.TypeReg {
+			files[header.Name] = string(content)
+		}
+	}
+}
+
+func TestWriteToolApprovalDecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     container.HostConfig
+		decision ToolApprovalDecision
+	}{
+		{
+			name:     "approved, writable rootfs",
+			decision: ToolApprovalDecision{Approved: true},
+		},
+		{
+			name:     "denied, read-only rootfs",
+			host:     *(&ContainerLimits{ReadOnlyRootfs: true}).HostConfig(),
+			decision: ToolApprovalDecision{Approved: false, Reason: "no force pushes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, daemon := newFakeDockerExecutor(t)
+			daemon.host = tt.host
+
+			if err := exec.writeToolApprovalDecision(context.Background(), "0123456789abcdef", "req-1", tt.decision); err != nil {
+				t.Fatalf("writeToolApprovalDecision: %v", err)
+			}
+
+			daemon.mu.Lock()
+			defer daemon.mu.Unlock()
+			// The hook polls for the decision next to its request file
+			files := untarFiles(t, daemon.uploads[toolApprovalDir])
+			var got ToolApprovalDecision
+			if err := json.Unmarshal([]byte(files["req-1.decision"]), &got); err != nil {
+				t.Fatalf("decision files = %v: %v", files, err)
+			}
+			if got != tt.decision || len(files) != 1 {
+				t.Errorf("decision = %+v (files %v), want %+v", got, files, tt.decision)
+			}
+		})
+	}
+}
+
+func TestWriteToolApprovalDecisionAfterCancel(t *testing.T) {
+	exec, daemon := newFakeDockerExecutor(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A cancelled execution still delivers its denial so the hook can exit
+	if err := exec.writeToolApprovalDecision(ctx, "0123456789abcdef", "req-1", ToolApprovalDecision{}); err != nil {
+		t.Fatalf("writeToolApprovalDecision: %v", err)
+	}
+	daemon.mu.Lock()
+	defer daemon.mu.Unlock()
+	if _, ok := untarFiles(t, daemon.uploads[toolApprovalDir])["req-1.decision"]; !ok {
+		t.Error("decision was not delivered")
+	}
+}
+
+func TestReadToolApprovalRequests(t *testing.T) {
+	exec, daemon := newFakeDockerExecutor(t)
+	if got := exec.readToolApprovalRequests(context.Background(), "0123456789abcdef"); got != nil {
+		t.Fatalf("requests before the directory exists = %v", got)
+	}
+
+	daemon.archives[toolApprovalDir] = tarFiles(t,
+		[2]string{"approvals/a1.json", `{"id":"a1","tool_name":"Bash","tool_input":{"command":"git push -f"},"rule":"Bash ~ git push"}`},
+		[2]string{"approvals/a1.decision", `{"approved":true}`},
+		[2]string{"approvals/.a2.tmp", `{"id":"a2"`},
+		[2]string{"approvals/a3.json", `not json`},
+		[2]string{"approvals/a4.json", `{"id":"other"}`},
+	)
+	got := exec.readToolApprovalRequests(context.Background(), "0123456789abcdef")
+	if len(got) != 1 || got[0].ID != "a1" || got[0].ToolName != "Bash" || got[0].ToolInput["command"] != "git push -f" {
+		t.Errorf("requests = %+v", got)
+	}
+}
+
+func TestPausableDeadline(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	fired := func(ctx context.Context, within time.Duration) bool {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-time.After(within):
+			return false
+		}
+	}
+
+	t.Run("fires after the timeout", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		d := newPausableDeadline(timeout, cancel)
+		defer d.Stop()
+		if !fired(ctx, 10*timeout) {
+			t.Fatal("deadline did not fire")
+		}
+	})
+
+	t.Run("paused time does not count", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		d := newPausableDeadline(timeout, cancel)
+		defer d.Stop()
+
+		d.Pause()
+		d.Pause() // nested: an approval requested while another is pending
+		if fired(ctx, 3*timeout) {
+			t.Fatal("deadline fired while paused")
+		}
+		d.Resume()
+		if fired(ctx, 2*timeout) {
+			t.Fatal("deadline fired before every pause was resumed")
+		}
+		d.Resume()
+		if !fired(ctx, 10*timeout) {
+			t.Fatal("deadline did not fire after resuming")
+		}
+	})
+
+	t.Run("stop disarms it", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		d := newPausableDeadline(timeout, cancel)
+		d.Pause()
+		d.Stop()
+		d.Resume()
+		if fired(ctx, 3*timeout) {
+			t.Fatal("stopped deadline fired")
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+	"go.uber.org/zap"
+)
+
+// WorkspaceMountPath is where agent containers keep their git checkout
+const WorkspaceMountPath = "/workspace"
+
+// Volume labels used to find a flow run's workspace and snapshot volumes
+const (
+	labelFlowRunID  = "workgear.flow_run_id"
+	labelVolumeKind = "workgear.volume_kind" // "workspace" / "snapshot"
+)
+
+const defaultWorkspaceHelperImage = "alpine:3"
+
+// WorkspaceVolumes manages Docker volumes that keep /workspace alive across the
+// agent nodes of one flow run, plus per-node snapshots used to roll back on reject
+type WorkspaceVolumes struct {
+	cli         *client.Client
+	helperImage string // small image with sh/cp, used to copy volume contents
+	logger      *zap.SugaredLogger
+}
+
+// NewWorkspaceVolumes creates a workspace volume manager using the Docker environment config
+func NewWorkspaceVolumes(logger *zap.SugaredLogger) (*WorkspaceVolumes, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("create docker client: %w", err)
+	}
+	return &WorkspaceVolumes{
+		cli:         cli,
+		helperImage: defaultWorkspaceHelperImage,
+		logger:      logger,
+	}, nil
+}
+
+// SetHelperImage overrides the image used for snapshot/restore copies
+func (w *WorkspaceVolumes) SetHelperImage(image string) {
+	if image != "" {
+		w.helperImage = image
+	}
+}
+
+// WorkspaceVolumeName returns the shared workspace volume name for a flow run
+func WorkspaceVolumeName(flowRunID string) string {
+	return "workgear-ws-" + volumeNameSafe(flowRunID)
+}
+
+func snapshotVolumeName(flowRunID, nodeID string) string {
+	return WorkspaceVolumeName(flowRunID) + "-snap-" + volumeNameSafe(nodeID)
+}
+
+// Ensure creates the flow run's workspace volume if missing and returns a mount for it
+func (w *WorkspaceVolumes) Ensure(ctx context.Context, flowRunID string) (Mount, error) {
+	name := WorkspaceVolumeName(flowRunID)
+	if err := w.ensureVolume(ctx, name, flowRunID, "workspace"); err != nil {
+		return Mount{}, err
+	}
+	return Mount{Type: MountTypeVolume, Source: name, Target: WorkspaceMountPath}, nil
+}
+
+// Snapshot copies the workspace volume into the node's snapshot volume (replacing any earlier snapshot)
+func (w *WorkspaceVolumes) Snapshot(ctx context.Context, flowRunID, nodeID string) error {
+	snap := snapshotVolumeName(flowRunID, nodeID)
+	if err := w.ensureVolume(ctx, snap, flowRunID, "snapshot"); err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := w.copyVolume(ctx, WorkspaceVolumeName(flowRunID), snap); err != nil {
+		return fmt.Errorf("snapshot workspace: %w", err)
+	}
+	w.logger.Infow("Workspace snapshot taken", "flow_run_id", flowRunID, "node_id", nodeID,
+		"duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
+
+// Restore resets the workspace volume to the node's snapshot.
+// Returns false if the node has no snapshot.
+func (w *WorkspaceVolumes) Restore(ctx context.Context, flowRunID, nodeID string) (bool, error) {
+	snap := snapshotVolumeName(flowRunID, nodeID)
+	if _, err := w.cli.VolumeInspect(ctx, snap); err != nil {
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("inspect snapshot volume: %w", err)
+	}
+	start := time.Now()
+	if err := w.copyVolume(ctx, snap, WorkspaceVolumeName(flowRunID)); err != nil {
+		return false, fmt.Errorf("restore workspace: %w", err)
+	}
+	w.logger.Infow("Workspace restored from snapshot", "flow_run_id", flowRunID, "node_id", nodeID,
+		"duration_ms", time.Since(start).Milliseconds())
+	return true, nil
+}
+
+// Remove deletes the flow run's workspace and all of its snapshots
+func (w *WorkspaceVolumes) Remove(ctx context.Context, flowRunID string) error {
+	list, err := w.cli.VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", labelFlowRunID+"="+flowRunID)),
+	})
+	if err != nil {
+		return fmt.Errorf("list workspace volumes: %w", err)
+	}
+
+	var firstErr error
+	for _, v := range list.Volumes {
+		if err := w.cli.VolumeRemove(ctx, v.Name, true); err != nil && !client.IsErrNotFound(err) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("remove volume %s: %w", v.Name, err)
+			}
+			continue
+		}
+		w.logger.Infow("Removed workspace volume", "volume", v.Name, "flow_run_id", flowRunID)
+	}
+	return firstErr
+}
+
+func (w *WorkspaceVolumes) ensureVolume(ctx context.Context, name, flowRunID, kind string) error {
+	if _, err := w.cli.VolumeInspect(ctx, name); err == nil {
+		return nil
+	} else if !client.IsErrNotFound(err) {
+		return fmt.Errorf("inspect volume %s: %w", name, err)
+	}
+	_, err := w.cli.VolumeCreate(ctx, volume.CreateOptions{
+		Name: name,
+		Labels: map[string]string{
+			labelFlowRunID:  flowRunID,
+			labelVolumeKind: kind,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("create volume %s: %w", name, err)
+	}
+	w.logger.Infow("Created workspace volume", "volume", name, "kind", kind, "flow_run_id", flowRunID)
+	return nil
+}
+
+// copyVolume replaces dst's contents with src's, preserving ownership and modes
+func (w *WorkspaceVolumes) copyVolume(ctx context.Context, src, dst string) error {
+	if err := pullImageIfMissing(ctx, w.cli, w.logger, w.helperImage); err != nil {
+		return fmt.Errorf("ensure helper image %s: %w", w.helperImage, err)
+	}
+
+	createResp, err := w.cli.ContainerCreate(ctx,
+		&container.Config{
+			Image: w.helperImage,
+			User:  "0:0",
+			Cmd:   []string{"sh", "-c", "rm -rf /to/..?* /to/.[!.]* /to/* && cp -a /from/. /to/"},
+		},
+		&container.HostConfig{
+			NetworkMode: "none",
+			Mounts: []mount.Mount{
+				{Type: mount.TypeVolume, Source: src, Target: "/from", ReadOnly: true},
+				{Type: mount.TypeVolume, Source: dst, Target: "/to"},
+			},
+		},
+		nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("create copy container: %w", err)
+	}
+	defer func() {
+		removeCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = w.cli.ContainerRemove(removeCtx, createResp.ID, container.RemoveOptions{Force: true})
+	}()
+
+	if err := w.cli.ContainerStart(ctx, createResp.ID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("start copy container: %w", err)
+	}
+
+	statusCh, errCh := w.cli.ContainerWait(ctx, createResp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("wait copy container: %w", err)
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			return fmt.Errorf("copy %s → %s exited with code %d", src, dst, status.StatusCode)
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Close releases the Docker client
+func (w *WorkspaceVolumes) Close() error {
+	return w.cli.Close()
+}
+
+// volumeNameSafe maps an ID onto Docker's volume name charset [a-zA-Z0-9_.-]
+func volumeNameSafe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s)
+}
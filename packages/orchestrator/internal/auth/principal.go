@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Roles granted to gRPC callers. admin implies every other role; operator and reviewer
+// imply viewer.
+const (
+	RoleViewer   = "viewer"   // read runs, logs and events
+	RoleOperator = "operator" // start / cancel flows, retry nodes, submit human input
+	RoleReviewer = "reviewer" // approve / reject / edit reviews, decide tool calls
+	RoleAdmin    = "admin"    // everything, including TestAgent and registry reloads
+)
+
+// Principal kinds
+const (
+	KindToken = "token" // shared secret (a trusted service, no user identity)
+	KindJWT   = "jwt"   // JWT signed by the API server on behalf of a user
+)
+
+// Principal is the authenticated caller of an RPC
+type Principal struct {
+	Subject string // JWT sub (user ID) or "service" for the shared secret
+	Name    string // display name / email, if the token carries one
+	Kind    string
+	Roles   []string
+}
+
+// HasRole reports whether the principal holds role, directly or implied
+func (p *Principal) HasRole(role string) bool {
+	if slices.Contains(p.Roles, RoleAdmin) || slices.Contains(p.Roles, role) {
+		return true
+	}
+	if role == RoleViewer {
+		return slices.Contains(p.Roles, RoleOperator) || slices.Contains(p.Roles, RoleReviewer)
+	}
+	return false
+}
+
+// UserID returns the user the call was made for ("" for service callers)
+func (p *Principal) UserID() string {
+	if p == nil || p.Kind != KindJWT {
+		return ""
+	}
+	return p.Subject
+}
+
+// Actor identifies the caller in audit records: the user ID, or "service" ("" if nil)
+func (p *Principal) Actor() string {
+	if p == nil {
+		return ""
+	}
+	return p.Subject
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches the caller to ctx
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller of the current RPC (nil if authentication is disabled or
+// the call did not come through the gRPC server)
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ServiceSubject is the subject of callers authenticated with the shared secret
+const ServiceSubject = "service"
+
+// clockSkew is the leeway applied to exp / nbf
+const clockSkew = 30 * time.Second
+
+// Verifier validates bearer tokens: a shared secret and/or HS256 JWTs
+type Verifier struct {
+	sharedToken string
+	tokenRoles  []string
+	jwtSecret   []byte
+	audience    string // required aud claim ("" = not checked)
+}
+
+// Config configures a Verifier; at least one of SharedToken and JWTSecret must be set
+type Config struct {
+	SharedToken string   // GRPC_AUTH_TOKEN
+	TokenRoles  []string // roles of shared-secret callers (default admin)
+	JWTSecret   string   // GRPC_JWT_SECRET (HS256, shared with the API server)
+	Audience    string   // GRPC_JWT_AUDIENCE
+}
+
+// NewVerifier creates a Verifier. Returns nil if neither credential is configured
+// (authentication disabled).
+func NewVerifier(cfg Config) *Verifier {
+	if cfg.SharedToken == "" && cfg.JWTSecret == "" {
+		return nil
+	}
+	roles := cfg.TokenRoles
+	if len(roles) == 0 {
+		roles = []string{RoleAdmin}
+	}
+	return &Verifier{
+		sharedToken: cfg.SharedToken,
+		tokenRoles:  roles,
+		jwtSecret:   []byte(cfg.JWTSecret),
+		audience:    cfg.Audience,
+	}
+}
+
+// ErrUnauthenticated is returned for missing, malformed or invalid tokens
+var ErrUnauthenticated = errors.New("invalid or missing credentials")
+
+// Verify authenticates a bearer token
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	if v.sharedToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(v.sharedToken)) == 1 {
+		return &Principal{Subject: ServiceSubject, Kind: KindToken, Roles: v.tokenRoles}, nil
+	}
+	if len(v.jwtSecret) > 0 && strings.Count(token, ".") == 2 {
+		return v.verifyJWT(token, time.Now())
+	}
+	return nil, ErrUnauthenticated
+}
+
+type jwtClaims struct {
+	Sub   string          `json:"sub"`
+	Name  string          `json:"name"`
+	Email string          `json:"email"`
+	Roles []string        `json:"roles"`
+	Aud   json.RawMessage `json:"aud"` // string or array
+	Exp   *int64          `json:"exp"`
+	Nbf   *int64          `json:"nbf"`
+}
+
+// verifyJWT checks an HS256 JWT's signature, exp (required), nbf and aud
+func (v *Verifier) verifyJWT(token string, now time.Time) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != "HS256" {
+		return nil, fmt.Errorf("%w: unsupported JWT algorithm", ErrUnauthenticated)
+	}
+
+	mac := hmac.New(sha256.New, v.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("%w: bad JWT signature", ErrUnauthenticated)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrUnauthenticated
+	}
+	if claims.Sub == "" {
+		return nil, fmt.Errorf("%w: JWT has no subject", ErrUnauthenticated)
+	}
+	if claims.Exp == nil || now.After(time.Unix(*claims.Exp, 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: JWT expired", ErrUnauthenticated)
+	}
+	if claims.Nbf != nil && now.Add(clockSkew).Before(time.Unix(*claims.Nbf, 0)) {
+		return nil, fmt.Errorf("%w: JWT not valid yet", ErrUnauthenticated)
+	}
+	if v.audience != "" && !audienceMatches(claims.Aud, v.audience) {
+		return nil, fmt.Errorf("%w: JWT audience mismatch", ErrUnauthenticated)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.Email
+	}
+	return &Principal{Subject: claims.Sub, Name: name, Kind: KindJWT, Roles: claims.Roles}, nil
+}
+
+func audienceMatches(raw json.RawMessage, want string) bool {
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		return single == want
+	}
+	var list []string
+	return json.Unmarshal(raw, &list) == nil && slices.Contains(list, want)
+}
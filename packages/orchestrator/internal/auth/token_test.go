@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "jwt-secret-for-tests"
+
+// signJWT signs claims the way the API server does (HS256 unless header overrides alg)
+func signJWT(t *testing.T, secret string, header, claims map[string]any) string {
+	t.Helper()
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	if header == nil {
+		header = map[string]any{"alg": "HS256", "typ": "JWT"}
+	}
+	unsigned := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyJWT(t *testing.T) {
+	v := NewVerifier(Config{JWTSecret: testSecret, Audience: "workgear-orchestrator"})
+	now := time.Now().Unix()
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{
+			"sub":   "user-1",
+			"email": "dev@example.com",
+			"roles": []string{RoleReviewer},
+			"aud":   "workgear-orchestrator",
+			"exp":   now + 300,
+		}
+		for k, value := range extra {
+			if value == nil {
+				delete(c, k)
+			} else {
+				c[k] = value
+			}
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"valid", signJWT(t, testSecret, nil, claims(nil)), ""},
+		{"audience list", signJWT(t, testSecret, nil, claims(map[string]any{"aud": []string{"other", "workgear-orchestrator"}})), ""},
+		{"within clock skew", signJWT(t, testSecret, nil, claims(map[string]any{"exp": now - 10, "nbf": now + 10})), ""},
+		{"bad signature", signJWT(t, "another-secret", nil, claims(nil)), "bad JWT signature"},
+		{"tampered payload", tamper(signJWT(t, testSecret, nil, claims(nil))), "bad JWT signature"},
+		{"alg none", signJWT(t, testSecret, map[string]any{"alg": "none"}, claims(nil)), "unsupported JWT algorithm"},
+		{"alg HS512", signJWT(t, testSecret, map[string]any{"alg": "HS512"}, claims(nil)), "unsupported JWT algorithm"},
+		{"alg RS256", signJWT(t, testSecret, map[string]any{"alg": "RS256"}, claims(nil)), "unsupported JWT algorithm"},
+		{"expired", signJWT(t, testSecret, nil, claims(map[string]any{"exp": now - 120})), "JWT expired"},
+		{"no exp", signJWT(t, testSecret, nil, claims(map[string]any{"exp": nil})), "JWT expired"},
+		{"not valid yet", signJWT(t, testSecret, nil, claims(map[string]any{"nbf": now + 120})), "JWT not valid yet"},
+		{"no subject", signJWT(t, testSecret, nil, claims(map[string]any{"sub": nil})), "JWT has no subject"},
+		{"wrong audience", signJWT(t, testSecret, nil, claims(map[string]any{"aud": "someone-else"})), "JWT audience mismatch"},
+		{"no audience", signJWT(t, testSecret, nil, claims(map[string]any{"aud": nil})), "JWT audience mismatch"},
+		{"not a JWT", "just-a-token", "invalid or missing credentials"},
+		{"garbage segments", "a.b.c", "invalid or missing credentials"},
+		{"empty", "", "invalid or missing credentials"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				want := &Principal{Subject: "user-1", Name: "dev@example.com", Kind: KindJWT, Roles: []string{RoleReviewer}}
+				if !reflect.DeepEqual(p, want) {
+					t.Errorf("principal = %+v, want %+v", p, want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("err = %v, want ErrUnauthenticated", err)
+			}
+		})
+	}
+}
+
+// tamper raises the roles in a signed token's payload without re-signing it
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	payload = []byte(strings.Replace(string(payload), `"reviewer"`, `"admin"`, 1))
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+}
+
+func TestVerifyWithoutAudience(t *testing.T) {
+	v := NewVerifier(Config{JWTSecret: testSecret})
+	token := signJWT(t, testSecret, nil, map[string]any{"sub": "user-1", "name": "Dev", "exp": time.Now().Unix() + 60})
+	p, err := v.Verify(token)
+	if err != nil || p.Name != "Dev" || len(p.Roles) != 0 {
+		t.Fatalf("principal = %+v, err = %v", p, err)
+	}
+}
+
+func TestVerifySharedToken(t *testing.T) {
+	v := NewVerifier(Config{SharedToken: "shared-secret-token", JWTSecret: testSecret})
+	p, err := v.Verify("shared-secret-token")
+	if err != nil || p.Kind != KindToken || p.Subject != ServiceSubject || !reflect.DeepEqual(p.Roles, []string{RoleAdmin}) {
+		t.Fatalf("principal = %+v, err = %v", p, err)
+	}
+	if _, err := v.Verify("shared-secret-tokeN"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("wrong token: err = %v", err)
+	}
+
+	scoped := NewVerifier(Config{SharedToken: "shared-secret-token", TokenRoles: []string{RoleViewer}})
+	if p, _ := scoped.Verify("shared-secret-token"); !reflect.DeepEqual(p.Roles, []string{RoleViewer}) {
+		t.Errorf("token roles = %v", p.Roles)
+	}
+	// Without a JWT secret, JWTs are not accepted at all
+	if _, err := scoped.Verify(signJWT(t, testSecret, nil, map[string]any{"sub": "u", "exp": time.Now().Unix() + 60})); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("JWT without a secret: err = %v", err)
+	}
+	if NewVerifier(Config{}) != nil {
+		t.Error("a verifier without credentials must be nil (authentication disabled)")
+	}
+}
+
+func TestPrincipalHasRole(t *testing.T) {
+	tests := []struct {
+		roles []string
+		role  string
+		want  bool
+	}{
+		{[]string{RoleAdmin}, RoleReviewer, true},
+		{[]string{RoleOperator}, RoleViewer, true},
+		{[]string{RoleReviewer}, RoleViewer, true},
+		{[]string{RoleOperator}, RoleReviewer, false},
+		{[]string{RoleReviewer}, RoleOperator, false},
+		{[]string{RoleViewer}, RoleOperator, false},
+		{[]string{RoleOperator, RoleReviewer}, RoleAdmin, false},
+		{nil, RoleViewer, false},
+	}
+	for _, tt := range tests {
+		p := &Principal{Roles: tt.roles}
+		if got := p.HasRole(tt.role); got != tt.want {
+			t.Errorf("%v HasRole(%s) = %v, want %v", tt.roles, tt.role, got, tt.want)
+		}
+	}
+}
@@ -23,7 +23,7 @@ type NodeRun struct {
 	NodeID          string     `json:"node_id"`
 	NodeType        *string    `json:"node_type"`
 	NodeName        *string    `json:"node_name"`
-	Status          string     `json:"status"` // pending / queued / running / completed / failed / rejected / waiting_human
+	Status          string     `json:"status"` // pending / queued / running / completed / failed / rejected / waiting_human / waiting_tool_approval / waiting_timer
 	Attempt         int        `json:"attempt"`
 	Input           *string    `json:"input"`  // JSON string
 	Output          *string    `json:"output"` // JSON string
@@ -32,11 +32,11 @@ type NodeRun struct {
 	LockedAt        *time.Time `json:"locked_at"`
 	ReviewAction    *string    `json:"review_action"`  // approve / reject / edit_and_approve
 	ReviewComment   *string    `json:"review_comment"`
+	ReviewedBy      *string    `json:"reviewed_by"` // user ID or "service"
 	ReviewedAt      *time.Time `json:"reviewed_at"`
 	StartedAt       *time.Time `json:"started_at"`
 	CompletedAt     *time.Time `json:"completed_at"`
 	RecoveryCheckpoint *string `json:"recovery_checkpoint"`
-	LogStream          *string `json:"log_stream"` // JSON array: [{type, content, timestamp}, ...]
 	CreatedAt          time.Time  `json:"created_at"`
 }
 
@@ -51,6 +51,14 @@ type TimelineEvent struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// NodeRunLog 节点执行日志（按 seq 分批追加）
+type NodeRunLog struct {
+	NodeRunID string    `json:"node_run_id"`
+	Seq       int64     `json:"seq"`
+	Event     string    `json:"event"` // JSON object: {type, content, timestamp, ...}
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // NodeRun 状态常量
 const (
 	StatusPending      = "pending"
@@ -61,6 +69,12 @@ const (
 	StatusRejected     = "rejected"
 	StatusWaitingHuman = "waiting_human"
 	StatusCancelled    = "cancelled"
+
+	// StatusWaitingToolApproval: the agent is running but blocked on a gated tool call
+	StatusWaitingToolApproval = "waiting_tool_approval"
+
+	// StatusWaitingTimer: a wait node parked until its timer fires (no worker is held)
+	StatusWaitingTimer = "waiting_timer"
 )
 
 // AgentProvider holds agent provider configuration from database
@@ -89,3 +103,127 @@ type AgentRoleConfig struct {
 	ModelID    *string // nil = use default model for provider
 	SystemPrompt string
 }
+
+// PromptTemplate is one version of a named prompt library template
+type PromptTemplate struct {
+	ID        string
+	ProjectID *string // nil = global template
+	Name      string
+	Version   int
+	Content   string
+}
+
+// NodeRunSession is the agent CLI session a node run ended with, used to resume it
+type NodeRunSession struct {
+	NodeRunID string
+	SessionID string
+	AgentType string // adapter that produced the session; only the same adapter can resume it
+	State     []byte // tar archive of the CLI's session files
+}
+
+// ToolApproval is an agent tool call paused by the node's tool_approval policy
+type ToolApproval struct {
+	ID             string
+	NodeRunID      string
+	ToolName       string
+	ToolInput      map[string]any
+	Rule           string
+	Reason         string
+	Status         string // pending / approved / denied / expired
+	DecisionReason *string
+	RequestedAt    time.Time
+	DecidedAt      *time.Time
+}
+
+// ToolApproval 状态常量
+const (
+	ToolApprovalPending  = "pending"
+	ToolApprovalApproved = "approved"
+	ToolApprovalDenied   = "denied"
+	ToolApprovalExpired  = "expired"
+)
+
+// Timer is a durable time-based trigger: when FireAt passes, the scheduler dispatches it to the
+// handler registered for Kind. Timers live in the database, so restarts lose none and any
+// orchestrator instance may fire them.
+type Timer struct {
+	ID          string
+	Kind        string         // handler name, e.g. "node_reminder"
+	Key         string         // optional grouping key for cancellation, e.g. "node_run:<id>"
+	FireAt      time.Time
+	Payload     map[string]any
+	Status      string // pending / firing / done / failed / cancelled
+	Attempts    int
+	LastError   *string
+	LockedBy    *string
+	LockedAt    *time.Time
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// Timer 状态常量
+const (
+	TimerPending   = "pending"
+	TimerFiring    = "firing"
+	TimerDone      = "done"
+	TimerFailed    = "failed"
+	TimerCancelled = "cancelled"
+)
+
+// FlowSchedule starts flow runs of a workflow for a task on a cron schedule
+type FlowSchedule struct {
+	ID            string
+	TaskID        string
+	WorkflowID    string
+	Cron          string            // 5-field cron expression or macro (@daily, ...)
+	Timezone      string            // IANA name the cron expression is evaluated in
+	Variables     map[string]string // merged over the workflow's template params
+	Enabled       bool
+	NextRunAt     *time.Time
+	LastRunAt     *time.Time
+	LastFlowRunID *string
+	CreatedBy     *string
+	CreatedAt     time.Time
+}
+
+// NodeRunReview is one human action (approve / reject / edit_and_approve) on a node run.
+// Reviews are append-only, so every round of a review loop is kept.
+type NodeRunReview struct {
+	ID           string                `json:"id"`
+	NodeRunID    string                `json:"node_run_id"`
+	FlowRunID    string                `json:"flow_run_id"`
+	NodeID       string                `json:"node_id"`
+	Attempt      int                   `json:"attempt"`
+	Action       string                `json:"action"`
+	Comment      string                `json:"comment"` // reject feedback / edit change summary
+	Severity     string                `json:"severity,omitempty"`
+	Comments     []ReviewComment       `json:"comments,omitempty"`
+	Checklist    []ReviewChecklistItem `json:"checklist,omitempty"`
+	ReviewerID   string                `json:"reviewer_id,omitempty"`
+	ReviewerName string                `json:"reviewer_name,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+// ReviewComment is a review comment anchored to a file (and optionally a line range)
+type ReviewComment struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	EndLine  int    `json:"end_line,omitempty"`
+	Body     string `json:"body"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// ReviewChecklistItem is the result of one review checklist item
+type ReviewChecklistItem struct {
+	Item   string `json:"item"`
+	Passed bool   `json:"passed"`
+	Note   string `json:"note,omitempty"`
+}
+
+// Review severities
+const (
+	ReviewSeverityInfo     = "info"
+	ReviewSeverityMinor    = "minor"
+	ReviewSeverityMajor    = "major"
+	ReviewSeverityCritical = "critical"
+)
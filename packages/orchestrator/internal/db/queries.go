@@ -3,10 +3,12 @@ package db
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -29,6 +31,59 @@ func (c *Client) GetFlowRun(ctx context.Context, id string) (*FlowRun, error) {
 	return &fr, nil
 }
 
+// CreateFlowRun inserts a pending flow run of a workflow for a task (flow runs started by the
+// orchestrator itself, e.g. on a schedule)
+func (c *Client) CreateFlowRun(ctx context.Context, taskID, workflowID string) (string, error) {
+	var id string
+	if err := c.pool.QueryRow(ctx, `
+		INSERT INTO flow_runs (task_id, workflow_id, status)
+		VALUES ($1, $2, 'pending')
+		RETURNING id
+	`, taskID, workflowID).Scan(&id); err != nil {
+		return "", fmt.Errorf("create flow run: %w", err)
+	}
+	return id, nil
+}
+
+// HasActiveFlowRun reports whether the task has a pending or running flow run
+func (c *Client) HasActiveFlowRun(ctx context.Context, taskID string) (bool, error) {
+	var active bool
+	if err := c.pool.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM flow_runs WHERE task_id = $1 AND status IN ('pending', 'running'))
+	`, taskID).Scan(&active); err != nil {
+		return false, fmt.Errorf("check active flow run: %w", err)
+	}
+	return active, nil
+}
+
+// GetWorkflowDSL returns the DSL and template params of a workflow (not deleted)
+func (c *Client) GetWorkflowDSL(ctx context.Context, workflowID string) (string, map[string]string, error) {
+	var dsl string
+	var paramsJSON []byte
+	err := c.pool.QueryRow(ctx, `
+		SELECT dsl, template_params FROM workflows WHERE id = $1 AND deleted_at IS NULL
+	`, workflowID).Scan(&dsl, &paramsJSON)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", nil, fmt.Errorf("workflow not found: %s", workflowID)
+		}
+		return "", nil, fmt.Errorf("get workflow: %w", err)
+	}
+
+	params := map[string]string{}
+	if len(paramsJSON) > 0 {
+		var raw map[string]any
+		if err := json.Unmarshal(paramsJSON, &raw); err == nil {
+			for k, v := range raw {
+				if v != nil {
+					params[k] = fmt.Sprint(v)
+				}
+			}
+		}
+	}
+	return dsl, params, nil
+}
+
 // GetTaskGitInfo retrieves git repo URL and branch from a task
 func (c *Client) GetTaskGitInfo(ctx context.Context, taskID string) (repoURL string, branch string, err error) {
 	row := c.pool.QueryRow(ctx, `
@@ -215,7 +270,7 @@ func (c *Client) GetNodeRun(ctx context.Context, id string) (*NodeRun, error) {
 	row := c.pool.QueryRow(ctx, `
 		SELECT id, flow_run_id, node_id, node_type, node_name, status, attempt,
 		       input, output, error, locked_by, locked_at,
-		       review_action, review_comment, reviewed_at,
+		       review_action, review_comment, reviewed_by, reviewed_at,
 		       started_at, completed_at, created_at
 		FROM node_runs WHERE id = $1
 	`, id)
@@ -223,7 +278,7 @@ func (c *Client) GetNodeRun(ctx context.Context, id string) (*NodeRun, error) {
 	var nr NodeRun
 	err := row.Scan(&nr.ID, &nr.FlowRunID, &nr.NodeID, &nr.NodeType, &nr.NodeName,
 		&nr.Status, &nr.Attempt, &nr.Input, &nr.Output, &nr.Error,
-		&nr.LockedBy, &nr.LockedAt, &nr.ReviewAction, &nr.ReviewComment, &nr.ReviewedAt,
+		&nr.LockedBy, &nr.LockedAt, &nr.ReviewAction, &nr.ReviewComment, &nr.ReviewedBy, &nr.ReviewedAt,
 		&nr.StartedAt, &nr.CompletedAt, &nr.CreatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get node run: %w", err)
@@ -236,7 +291,7 @@ func (c *Client) GetNodeRunsByFlowRunID(ctx context.Context, flowRunID string) (
 	rows, err := c.pool.Query(ctx, `
 		SELECT id, flow_run_id, node_id, node_type, node_name, status, attempt,
 		       input, output, error, locked_by, locked_at,
-		       review_action, review_comment, reviewed_at,
+		       review_action, review_comment, reviewed_by, reviewed_at,
 		       started_at, completed_at, created_at
 		FROM node_runs WHERE flow_run_id = $1
 		ORDER BY created_at ASC
@@ -251,7 +306,7 @@ func (c *Client) GetNodeRunsByFlowRunID(ctx context.Context, flowRunID string) (
 		var nr NodeRun
 		err := rows.Scan(&nr.ID, &nr.FlowRunID, &nr.NodeID, &nr.NodeType, &nr.NodeName,
 			&nr.Status, &nr.Attempt, &nr.Input, &nr.Output, &nr.Error,
-			&nr.LockedBy, &nr.LockedAt, &nr.ReviewAction, &nr.ReviewComment, &nr.ReviewedAt,
+			&nr.LockedBy, &nr.LockedAt, &nr.ReviewAction, &nr.ReviewComment, &nr.ReviewedBy, &nr.ReviewedAt,
 			&nr.StartedAt, &nr.CompletedAt, &nr.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan node run: %w", err)
@@ -261,6 +316,54 @@ func (c *Client) GetNodeRunsByFlowRunID(ctx context.Context, flowRunID string) (
 	return nodeRuns, nil
 }
 
+// NodeRunFilter narrows ListNodeRuns; zero values don't filter
+type NodeRunFilter struct {
+	FlowRunID  string
+	Statuses   []string
+	NodeID     string
+	Attempt    int
+	LatestOnly bool // only the latest attempt of each node (applied before the other filters)
+}
+
+// ListNodeRuns retrieves the node runs of a flow run matching a filter, oldest first
+func (c *Client) ListNodeRuns(ctx context.Context, f NodeRunFilter) ([]*NodeRun, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT id, flow_run_id, node_id, node_type, node_name, status, attempt,
+		       input, output, error, locked_by, locked_at,
+		       review_action, review_comment, reviewed_by, reviewed_at,
+		       started_at, completed_at, created_at
+		FROM node_runs n
+		WHERE n.flow_run_id = $1
+		  AND (COALESCE(cardinality($2::text[]), 0) = 0 OR n.status = ANY($2::text[]))
+		  AND ($3::text = '' OR n.node_id = $3)
+		  AND ($4::int = 0 OR n.attempt = $4)
+		  AND (NOT $5::bool OR n.id = (
+		        SELECT m.id FROM node_runs m
+		        WHERE m.flow_run_id = n.flow_run_id AND m.node_id = n.node_id
+		        ORDER BY m.attempt DESC, m.created_at DESC
+		        LIMIT 1))
+		ORDER BY n.created_at ASC
+	`, f.FlowRunID, f.Statuses, f.NodeID, f.Attempt, f.LatestOnly)
+	if err != nil {
+		return nil, fmt.Errorf("list node runs: %w", err)
+	}
+	defer rows.Close()
+
+	var nodeRuns []*NodeRun
+	for rows.Next() {
+		var nr NodeRun
+		err := rows.Scan(&nr.ID, &nr.FlowRunID, &nr.NodeID, &nr.NodeType, &nr.NodeName,
+			&nr.Status, &nr.Attempt, &nr.Input, &nr.Output, &nr.Error,
+			&nr.LockedBy, &nr.LockedAt, &nr.ReviewAction, &nr.ReviewComment, &nr.ReviewedBy, &nr.ReviewedAt,
+			&nr.StartedAt, &nr.CompletedAt, &nr.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan node run: %w", err)
+		}
+		nodeRuns = append(nodeRuns, &nr)
+	}
+	return nodeRuns, rows.Err()
+}
+
 // UpdateNodeRunStatus updates the status of a node run
 func (c *Client) UpdateNodeRunStatus(ctx context.Context, id, status string) error {
 	var completedAt *time.Time
@@ -277,6 +380,25 @@ func (c *Client) UpdateNodeRunStatus(ctx context.Context, id, status string) err
 	return err
 }
 
+// TransitionNodeRunStatus moves a node run from one status to another. Returns false if the
+// node run was no longer in the from status (e.g. a concurrent action already moved it).
+func (c *Client) TransitionNodeRunStatus(ctx context.Context, id, from, to string) (bool, error) {
+	var completedAt *time.Time
+	if to == StatusCompleted || to == StatusFailed || to == StatusRejected {
+		now := time.Now()
+		completedAt = &now
+	}
+	result, err := c.pool.Exec(ctx, `
+		UPDATE node_runs
+		SET status = $3, completed_at = COALESCE($4, completed_at)
+		WHERE id = $1 AND status = $2
+	`, id, from, to, completedAt)
+	if err != nil {
+		return false, fmt.Errorf("transition node run status: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
 // UpdateNodeRunOutput sets the output of a node run
 func (c *Client) UpdateNodeRunOutput(ctx context.Context, id string, output map[string]any) error {
 	outputJSON, err := json.Marshal(output)
@@ -298,17 +420,6 @@ func (c *Client) UpdateNodeRunError(ctx context.Context, id, status, errMsg stri
 	return err
 }
 
-// UpdateNodeRunReview records a review action on a node run
-func (c *Client) UpdateNodeRunReview(ctx context.Context, id, action, comment string) error {
-	now := time.Now()
-	_, err := c.pool.Exec(ctx, `
-		UPDATE node_runs
-		SET review_action = $2, review_comment = $3, reviewed_at = $4
-		WHERE id = $1
-	`, id, action, comment, now)
-	return err
-}
-
 // UpdateNodeRunStatusByFlowAndNode updates status by flow_run_id + node_id combo
 func (c *Client) UpdateNodeRunStatusByFlowAndNode(ctx context.Context, flowRunID, nodeID, status string) error {
 	_, err := c.pool.Exec(ctx, `
@@ -409,7 +520,7 @@ func (c *Client) AllNodesCompleted(ctx context.Context, flowRunID string) (bool,
 func (c *Client) CancelPendingNodeRuns(ctx context.Context, flowRunID string) error {
 	_, err := c.pool.Exec(ctx, `
 		UPDATE node_runs SET status = 'cancelled', completed_at = COALESCE(completed_at, NOW())
-		WHERE flow_run_id = $1 AND status IN ('pending', 'queued', 'waiting_human', 'running')
+		WHERE flow_run_id = $1 AND status IN ('pending', 'queued', 'waiting_human', 'running', 'waiting_tool_approval', 'waiting_timer')
 	`, flowRunID)
 	return err
 }
@@ -419,7 +530,7 @@ func (c *Client) GetActiveNodeRuns(ctx context.Context, flowRunID string) ([]*No
 	rows, err := c.pool.Query(ctx, `
 		SELECT id, flow_run_id, node_id, node_type, node_name, status
 		FROM node_runs
-		WHERE flow_run_id = $1 AND status IN ('pending', 'queued', 'waiting_human', 'running')
+		WHERE flow_run_id = $1 AND status IN ('pending', 'queued', 'waiting_human', 'running', 'waiting_tool_approval', 'waiting_timer')
 	`, flowRunID)
 	if err != nil {
 		return nil, err
@@ -492,7 +603,7 @@ func (c *Client) ResetStaleRunningNodes(ctx context.Context) (int, error) {
 	result, err := c.pool.Exec(ctx, `
 		UPDATE node_runs
 		SET status = 'queued', locked_by = NULL, locked_at = NULL, started_at = NULL
-		WHERE status = 'running' AND locked_by IS NOT NULL
+		WHERE status IN ('running', 'waiting_tool_approval') AND locked_by IS NOT NULL
 	`)
 	if err != nil {
 		return 0, err
@@ -510,16 +621,60 @@ func (c *Client) UpdateNodeRunInput(ctx context.Context, id string, input *strin
 	return err
 }
 
-// UpdateNodeRunLogStream saves log stream events to node_runs.log_stream
-func (c *Client) UpdateNodeRunLogStream(ctx context.Context, id string, logEvents []map[string]any) error {
-	logJSON, err := json.Marshal(logEvents)
+// AppendNodeRunLogs inserts a batch of log events with consecutive seq numbers starting at firstSeq
+func (c *Client) AppendNodeRunLogs(ctx context.Context, nodeRunID string, firstSeq int64, events []string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, err := c.pool.Exec(ctx, `
+		INSERT INTO node_run_logs (node_run_id, seq, event)
+		SELECT $1, $2 + t.ord - 1, t.event::jsonb
+		FROM unnest($3::text[]) WITH ORDINALITY AS t(event, ord)
+		ON CONFLICT (node_run_id, seq) DO NOTHING
+	`, nodeRunID, firstSeq, events)
+	if err != nil {
+		return fmt.Errorf("append node run logs: %w", err)
+	}
+	return nil
+}
+
+// GetNodeRunLogs returns up to limit log events with seq > afterSeq, in order
+func (c *Client) GetNodeRunLogs(ctx context.Context, nodeRunID string, afterSeq int64, limit int) ([]*NodeRunLog, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT node_run_id, seq, event::text, created_at
+		FROM node_run_logs
+		WHERE node_run_id = $1 AND seq > $2
+		ORDER BY seq
+		LIMIT $3
+	`, nodeRunID, afterSeq, limit)
 	if err != nil {
-		return fmt.Errorf("marshal log events: %w", err)
+		return nil, fmt.Errorf("get node run logs: %w", err)
 	}
-	_, err = c.pool.Exec(ctx, `
-		UPDATE node_runs SET log_stream = $2 WHERE id = $1
-	`, id, string(logJSON))
-	return err
+	defer rows.Close()
+
+	var result []*NodeRunLog
+	for rows.Next() {
+		var l NodeRunLog
+		if err := rows.Scan(&l.NodeRunID, &l.Seq, &l.Event, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, &l)
+	}
+	return result, rows.Err()
+}
+
+// GetNodeRunLogStats returns the highest seq and total stored event bytes of a node run's log
+// (used to resume appending after a restart)
+func (c *Client) GetNodeRunLogStats(ctx context.Context, nodeRunID string) (maxSeq int64, totalBytes int64, err error) {
+	err = c.pool.QueryRow(ctx, `
+		SELECT COALESCE(MAX(seq), 0), COALESCE(SUM(octet_length(event::text)), 0)
+		FROM node_run_logs
+		WHERE node_run_id = $1
+	`, nodeRunID).Scan(&maxSeq, &totalBytes)
+	if err != nil {
+		return 0, 0, fmt.Errorf("get node run log stats: %w", err)
+	}
+	return maxSeq, totalBytes, nil
 }
 
 // GetAllNodeRunOutputs returns a map of nodeID → parsed output for all completed nodes in a flow run.
@@ -571,6 +726,72 @@ func (c *Client) CreateTimelineEvent(ctx context.Context, evt *TimelineEvent) er
 	return err
 }
 
+// TimelineFilter narrows ListTimelineEvents; zero values don't filter
+type TimelineFilter struct {
+	TaskID     string
+	FlowRunID  string
+	NodeRunID  string
+	EventTypes []string
+}
+
+// ErrInvalidTimelineCursor is returned when the after_id of a timeline page is not an event
+// matching the page's filter
+var ErrInvalidTimelineCursor = errors.New("invalid timeline cursor")
+
+// ListTimelineEvents returns up to limit timeline events after the event afterID ("" = from
+// the start), oldest first
+func (c *Client) ListTimelineEvents(ctx context.Context, f TimelineFilter, afterID string, limit int) ([]*TimelineEvent, error) {
+	// Resolve the cursor first: an unknown one would otherwise yield a silently empty page
+	var afterAt *time.Time
+	if afterID != "" {
+		if _, err := uuid.Parse(afterID); err != nil {
+			return nil, fmt.Errorf("%w: after_id %q is not an event ID", ErrInvalidTimelineCursor, afterID)
+		}
+		var at time.Time
+		err := c.pool.QueryRow(ctx, `
+			SELECT created_at FROM timeline_events
+			WHERE id = $1
+			  AND ($2::text = '' OR task_id = NULLIF($2, '')::uuid)
+			  AND ($3::text = '' OR flow_run_id = NULLIF($3, '')::uuid)
+			  AND ($4::text = '' OR node_run_id = NULLIF($4, '')::uuid)
+		`, afterID, f.TaskID, f.FlowRunID, f.NodeRunID).Scan(&at)
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: event %s not found in this timeline", ErrInvalidTimelineCursor, afterID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get timeline cursor: %w", err)
+		}
+		afterAt = &at
+	}
+
+	rows, err := c.pool.Query(ctx, `
+		SELECT id, task_id, flow_run_id, node_run_id, event_type, content::text, created_at
+		FROM timeline_events t
+		WHERE ($1::text = '' OR t.task_id = NULLIF($1, '')::uuid)
+		  AND ($2::text = '' OR t.flow_run_id = NULLIF($2, '')::uuid)
+		  AND ($3::text = '' OR t.node_run_id = NULLIF($3, '')::uuid)
+		  AND (COALESCE(cardinality($4::text[]), 0) = 0 OR t.event_type = ANY($4::text[]))
+		  AND ($5::timestamptz IS NULL OR (t.created_at, t.id) > ($5, NULLIF($6, '')::uuid))
+		ORDER BY t.created_at, t.id
+		LIMIT $7
+	`, f.TaskID, f.FlowRunID, f.NodeRunID, f.EventTypes, afterAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list timeline events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*TimelineEvent
+	for rows.Next() {
+		var evt TimelineEvent
+		if err := rows.Scan(&evt.ID, &evt.TaskID, &evt.FlowRunID, &evt.NodeRunID,
+			&evt.EventType, &evt.Content, &evt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan timeline event: %w", err)
+		}
+		events = append(events, &evt)
+	}
+	return events, rows.Err()
+}
+
 // ─── Artifact Queries ───
 
 // CreateArtifact creates a new artifact record
@@ -821,6 +1042,29 @@ func (c *Client) GetDefaultModelForProvider(ctx context.Context, providerID stri
 	return &m, nil
 }
 
+// GetAllAgentModels retrieves all agent models of all providers
+func (c *Client) GetAllAgentModels(ctx context.Context) ([]*AgentModel, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT id, provider_id, model_name, display_name, is_default
+		FROM agent_models
+		ORDER BY provider_id, created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("get all agent models: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*AgentModel
+	for rows.Next() {
+		var m AgentModel
+		if err := rows.Scan(&m.ID, &m.ProviderID, &m.ModelName, &m.DisplayName, &m.IsDefault); err != nil {
+			return nil, fmt.Errorf("scan agent model: %w", err)
+		}
+		result = append(result, &m)
+	}
+	return result, nil
+}
+
 // GetModelsForProvider retrieves all models for a provider
 func (c *Client) GetModelsForProvider(ctx context.Context, providerID string) ([]*AgentModel, error) {
 	rows, err := c.pool.Query(ctx, `
@@ -846,3 +1090,505 @@ func (c *Client) GetModelsForProvider(ctx context.Context, providerID string) ([
 }
 
 
+
+// ─── Prompt Template Queries ───
+
+// GetPromptTemplate retrieves a prompt library template by name. version 0 means the latest
+// version. A project's own template takes precedence over a global one of the same name.
+// Returns nil if no matching template exists.
+func (c *Client) GetPromptTemplate(ctx context.Context, projectID, name string, version int) (*PromptTemplate, error) {
+	row := c.pool.QueryRow(ctx, `
+		SELECT id, project_id, name, version, content
+		FROM prompt_templates
+		WHERE name = $2
+		  AND (project_id IS NULL OR project_id = NULLIF($1, '')::uuid)
+		  AND ($3 = 0 OR version = $3)
+		ORDER BY project_id IS NULL, version DESC
+		LIMIT 1
+	`, projectID, name, version)
+
+	var t PromptTemplate
+	if err := row.Scan(&t.ID, &t.ProjectID, &t.Name, &t.Version, &t.Content); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get prompt template: %w", err)
+	}
+	return &t, nil
+}
+
+// GetTaskProjectID retrieves the project a task belongs to
+func (c *Client) GetTaskProjectID(ctx context.Context, taskID string) (string, error) {
+	var projectID string
+	if err := c.pool.QueryRow(ctx, `SELECT project_id FROM tasks WHERE id = $1`, taskID).Scan(&projectID); err != nil {
+		return "", fmt.Errorf("get task project: %w", err)
+	}
+	return projectID, nil
+}
+
+// UpdateNodeRunPromptVersions records the prompt library template versions a node run used
+func (c *Client) UpdateNodeRunPromptVersions(ctx context.Context, id string, versions any) error {
+	versionsJSON, err := json.Marshal(versions)
+	if err != nil {
+		return fmt.Errorf("marshal prompt versions: %w", err)
+	}
+	_, err = c.pool.Exec(ctx, `
+		UPDATE node_runs SET prompt_versions = $2 WHERE id = $1
+	`, id, string(versionsJSON))
+	return err
+}
+
+// UpdateNodeRunPromptStats records the size of the prompt a node run sent to the agent
+func (c *Client) UpdateNodeRunPromptStats(ctx context.Context, id string, stats any) error {
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("marshal prompt stats: %w", err)
+	}
+	_, err = c.pool.Exec(ctx, `
+		UPDATE node_runs SET prompt_stats = $2 WHERE id = $1
+	`, id, string(statsJSON))
+	return err
+}
+
+// ─── Agent Session Queries ───
+
+// SaveNodeRunSession stores (or replaces) the agent session of a node run
+func (c *Client) SaveNodeRunSession(ctx context.Context, s *NodeRunSession) error {
+	_, err := c.pool.Exec(ctx, `
+		INSERT INTO node_run_sessions (node_run_id, session_id, agent_type, state, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (node_run_id) DO UPDATE
+		SET session_id = EXCLUDED.session_id, agent_type = EXCLUDED.agent_type,
+		    state = EXCLUDED.state, created_at = EXCLUDED.created_at
+	`, s.NodeRunID, s.SessionID, s.AgentType, s.State)
+	if err != nil {
+		return fmt.Errorf("save node run session: %w", err)
+	}
+	return nil
+}
+
+// GetPreviousNodeRunSession retrieves the session of the most recent earlier run of the same
+// node in a flow run (excluding currentNodeRunID). Returns nil if there is none.
+func (c *Client) GetPreviousNodeRunSession(ctx context.Context, flowRunID, nodeID, currentNodeRunID string) (*NodeRunSession, error) {
+	row := c.pool.QueryRow(ctx, `
+		SELECT s.node_run_id, s.session_id, s.agent_type, s.state
+		FROM node_run_sessions s
+		JOIN node_runs n ON n.id = s.node_run_id
+		WHERE n.flow_run_id = $1 AND n.node_id = $2 AND n.id <> $3
+		ORDER BY n.created_at DESC
+		LIMIT 1
+	`, flowRunID, nodeID, currentNodeRunID)
+
+	var s NodeRunSession
+	if err := row.Scan(&s.NodeRunID, &s.SessionID, &s.AgentType, &s.State); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get previous node run session: %w", err)
+	}
+	return &s, nil
+}
+
+// ─── Tool Approval Queries ───
+
+// CreateToolApproval records a pending tool approval and moves the node run to
+// waiting_tool_approval
+func (c *Client) CreateToolApproval(ctx context.Context, a *ToolApproval) error {
+	inputJSON, err := json.Marshal(a.ToolInput)
+	if err != nil {
+		return fmt.Errorf("marshal tool input: %w", err)
+	}
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO tool_approvals (id, node_run_id, tool_name, tool_input, rule, reason, status, requested_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', NOW())
+	`, a.ID, a.NodeRunID, a.ToolName, string(inputJSON), a.Rule, a.Reason); err != nil {
+		return fmt.Errorf("create tool approval: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE node_runs SET status = 'waiting_tool_approval'
+		WHERE id = $1 AND status = 'running'
+	`, a.NodeRunID); err != nil {
+		return fmt.Errorf("set node run waiting for tool approval: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetToolApproval retrieves a tool approval by ID. Returns nil if not found.
+func (c *Client) GetToolApproval(ctx context.Context, id string) (*ToolApproval, error) {
+	row := c.pool.QueryRow(ctx, `
+		SELECT id, node_run_id, tool_name, tool_input, COALESCE(rule, ''), COALESCE(reason, ''),
+		       status, decision_reason, requested_at, decided_at
+		FROM tool_approvals WHERE id = $1
+	`, id)
+
+	var a ToolApproval
+	var inputJSON []byte
+	if err := row.Scan(&a.ID, &a.NodeRunID, &a.ToolName, &inputJSON, &a.Rule, &a.Reason,
+		&a.Status, &a.DecisionReason, &a.RequestedAt, &a.DecidedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get tool approval: %w", err)
+	}
+	if len(inputJSON) > 0 {
+		_ = json.Unmarshal(inputJSON, &a.ToolInput)
+	}
+	return &a, nil
+}
+
+// DecideToolApproval sets the status of a pending tool approval (approved / denied / expired).
+// decidedBy may be empty. Returns false if the approval was no longer pending.
+func (c *Client) DecideToolApproval(ctx context.Context, id, status, reason, decidedBy string) (bool, error) {
+	var decisionReason, decider *string
+	if reason != "" {
+		decisionReason = &reason
+	}
+	if decidedBy != "" {
+		decider = &decidedBy
+	}
+	result, err := c.pool.Exec(ctx, `
+		UPDATE tool_approvals
+		SET status = $2, decision_reason = $3, decided_by = $4, decided_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, status, decisionReason, decider)
+	if err != nil {
+		return false, fmt.Errorf("decide tool approval: %w", err)
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// ResumeNodeRunAfterToolApproval moves a node run back to running once none of its
+// tool approvals are pending
+func (c *Client) ResumeNodeRunAfterToolApproval(ctx context.Context, nodeRunID string) error {
+	_, err := c.pool.Exec(ctx, `
+		UPDATE node_runs SET status = 'running'
+		WHERE id = $1 AND status = 'waiting_tool_approval'
+		  AND NOT EXISTS (SELECT 1 FROM tool_approvals WHERE node_run_id = $1 AND status = 'pending')
+	`, nodeRunID)
+	return err
+}
+
+// ─── Node Run Review Queries ───
+
+// RecordNodeRunReview appends a review record and stores it as the node run's latest review
+// action, in one transaction. r.ReviewerID is the caller's user ID or "service" ("" if
+// unauthenticated).
+func (c *Client) RecordNodeRunReview(ctx context.Context, r *NodeRunReview) error {
+	comments, checklist := r.Comments, r.Checklist
+	if comments == nil {
+		comments = []ReviewComment{}
+	}
+	if checklist == nil {
+		checklist = []ReviewChecklistItem{}
+	}
+	commentsJSON, err := json.Marshal(comments)
+	if err != nil {
+		return fmt.Errorf("marshal review comments: %w", err)
+	}
+	checklistJSON, err := json.Marshal(checklist)
+	if err != nil {
+		return fmt.Errorf("marshal review checklist: %w", err)
+	}
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE node_runs
+		SET review_action = $2, review_comment = $3, reviewed_by = NULLIF($4, ''), reviewed_at = $5
+		WHERE id = $1
+	`, r.NodeRunID, r.Action, r.Comment, r.ReviewerID, r.CreatedAt); err != nil {
+		return fmt.Errorf("update node run review: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO node_run_reviews (id, node_run_id, flow_run_id, node_id, attempt, action, comment,
+		                              severity, comments, checklist, reviewer_id, reviewer_name, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, NULLIF($11, ''), NULLIF($12, ''), $13)
+	`, r.ID, r.NodeRunID, r.FlowRunID, r.NodeID, r.Attempt, r.Action, r.Comment,
+		r.Severity, string(commentsJSON), string(checklistJSON), r.ReviewerID, r.ReviewerName, r.CreatedAt); err != nil {
+		return fmt.Errorf("create node run review: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListNodeRunReviews retrieves the reviews of a flow run, oldest first
+func (c *Client) ListNodeRunReviews(ctx context.Context, flowRunID string) ([]*NodeRunReview, error) {
+	return c.queryNodeRunReviews(ctx, "flow_run_id", flowRunID)
+}
+
+// ListNodeRunReviewsByNodeRun retrieves the reviews of a single node run, oldest first
+func (c *Client) ListNodeRunReviewsByNodeRun(ctx context.Context, nodeRunID string) ([]*NodeRunReview, error) {
+	return c.queryNodeRunReviews(ctx, "node_run_id", nodeRunID)
+}
+
+func (c *Client) queryNodeRunReviews(ctx context.Context, column, id string) ([]*NodeRunReview, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT id, node_run_id, flow_run_id, node_id, attempt, action, COALESCE(comment, ''),
+		       COALESCE(severity, ''), comments, checklist,
+		       COALESCE(reviewer_id, ''), COALESCE(reviewer_name, ''), created_at
+		FROM node_run_reviews
+		WHERE `+column+` = $1
+		ORDER BY created_at ASC, id ASC
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("list node run reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*NodeRunReview
+	for rows.Next() {
+		var r NodeRunReview
+		var commentsJSON, checklistJSON []byte
+		if err := rows.Scan(&r.ID, &r.NodeRunID, &r.FlowRunID, &r.NodeID, &r.Attempt, &r.Action, &r.Comment,
+			&r.Severity, &commentsJSON, &checklistJSON, &r.ReviewerID, &r.ReviewerName, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan node run review: %w", err)
+		}
+		if len(commentsJSON) > 0 {
+			_ = json.Unmarshal(commentsJSON, &r.Comments)
+		}
+		if len(checklistJSON) > 0 {
+			_ = json.Unmarshal(checklistJSON, &r.Checklist)
+		}
+		reviews = append(reviews, &r)
+	}
+	return reviews, rows.Err()
+}
+
+// ResolveReviewers returns the IDs of the users named by assignees (user IDs or emails) and of
+// the members of the task's project holding one of groups (project member roles)
+func (c *Client) ResolveReviewers(ctx context.Context, taskID string, assignees, groups []string) ([]string, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT u.id::text FROM users u
+		WHERE u.id::text = ANY($2::text[]) OR u.email = ANY($2::text[])
+		UNION
+		SELECT pm.user_id::text FROM project_members pm
+		JOIN tasks t ON t.project_id = pm.project_id
+		WHERE t.id = $1 AND pm.role = ANY($3::text[])
+	`, taskID, assignees, groups)
+	if err != nil {
+		return nil, fmt.Errorf("resolve reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan reviewer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ─── Timer Queries ───
+
+// ErrTimerLeaseLost is returned when a worker records the outcome of a timer it no longer
+// holds: its lease expired and another worker reclaimed the timer
+var ErrTimerLeaseLost = errors.New("timer lease lost")
+
+// CreateTimer stores a pending timer
+func (c *Client) CreateTimer(ctx context.Context, t *Timer) error {
+	payload, err := json.Marshal(t.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal timer payload: %w", err)
+	}
+	if _, err := c.pool.Exec(ctx, `
+		INSERT INTO timers (id, kind, key, fire_at, payload, status, attempts, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, 'pending', 0, NOW())
+	`, t.ID, t.Kind, t.Key, t.FireAt, string(payload)); err != nil {
+		return fmt.Errorf("create timer: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueTimers locks up to limit timers due at now for workerID (FOR UPDATE SKIP LOCKED, so
+// replicas never claim the same timer). Timers left firing longer than lease by a dead worker
+// are claimed again.
+func (c *Client) ClaimDueTimers(ctx context.Context, workerID string, now time.Time, lease time.Duration, limit int) ([]*Timer, error) {
+	rows, err := c.pool.Query(ctx, `
+		UPDATE timers
+		SET status = 'firing', locked_by = $1, locked_at = $2, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM timers
+			WHERE (status = 'pending' AND fire_at <= $2)
+			   OR (status = 'firing' AND locked_at < $3)
+			ORDER BY fire_at ASC
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, kind, COALESCE(key, ''), fire_at, payload, status, attempts, last_error,
+		          locked_by, locked_at, created_at, completed_at
+	`, workerID, now, now.Add(-lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim due timers: %w", err)
+	}
+	defer rows.Close()
+
+	var timers []*Timer
+	for rows.Next() {
+		var t Timer
+		var payload []byte
+		if err := rows.Scan(&t.ID, &t.Kind, &t.Key, &t.FireAt, &payload, &t.Status, &t.Attempts, &t.LastError,
+			&t.LockedBy, &t.LockedAt, &t.CreatedAt, &t.CompletedAt); err != nil {
+			return nil, fmt.Errorf("scan timer: %w", err)
+		}
+		if len(payload) > 0 {
+			_ = json.Unmarshal(payload, &t.Payload)
+		}
+		timers = append(timers, &t)
+	}
+	return timers, rows.Err()
+}
+
+// CompleteTimer marks a timer fired by workerID done
+func (c *Client) CompleteTimer(ctx context.Context, id, workerID string) error {
+	result, err := c.pool.Exec(ctx, `
+		UPDATE timers SET status = 'done', locked_by = NULL, locked_at = NULL, completed_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'firing'
+	`, id, workerID)
+	return timerOutcome(result.RowsAffected(), err, "complete timer")
+}
+
+// RetryTimer puts a timer fired by workerID whose handler failed back to pending, due at fireAt
+func (c *Client) RetryTimer(ctx context.Context, id, workerID string, fireAt time.Time, lastError string) error {
+	result, err := c.pool.Exec(ctx, `
+		UPDATE timers SET status = 'pending', fire_at = $3, last_error = $4, locked_by = NULL, locked_at = NULL
+		WHERE id = $1 AND locked_by = $2 AND status = 'firing'
+	`, id, workerID, fireAt, lastError)
+	return timerOutcome(result.RowsAffected(), err, "retry timer")
+}
+
+// FailTimer gives up on a timer fired by workerID
+func (c *Client) FailTimer(ctx context.Context, id, workerID, lastError string) error {
+	result, err := c.pool.Exec(ctx, `
+		UPDATE timers SET status = 'failed', last_error = $3, locked_by = NULL, locked_at = NULL, completed_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'firing'
+	`, id, workerID, lastError)
+	return timerOutcome(result.RowsAffected(), err, "fail timer")
+}
+
+// timerOutcome turns an outcome update that matched no row into ErrTimerLeaseLost
+func timerOutcome(rowsAffected int64, err error, op string) error {
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return ErrTimerLeaseLost
+	}
+	return nil
+}
+
+// CancelTimers cancels the pending timers with the given key
+func (c *Client) CancelTimers(ctx context.Context, key string) (int, error) {
+	result, err := c.pool.Exec(ctx, `
+		UPDATE timers SET status = 'cancelled', completed_at = NOW()
+		WHERE key = $1 AND status = 'pending'
+	`, key)
+	if err != nil {
+		return 0, fmt.Errorf("cancel timers: %w", err)
+	}
+	return int(result.RowsAffected()), nil
+}
+
+// ─── Flow Schedule Queries ───
+
+const flowScheduleColumns = `id, task_id, workflow_id, cron, timezone, variables, enabled,
+	next_run_at, last_run_at, last_flow_run_id, created_by, created_at`
+
+func scanFlowSchedule(row pgx.Row) (*FlowSchedule, error) {
+	var s FlowSchedule
+	var varsJSON []byte
+	if err := row.Scan(&s.ID, &s.TaskID, &s.WorkflowID, &s.Cron, &s.Timezone, &varsJSON, &s.Enabled,
+		&s.NextRunAt, &s.LastRunAt, &s.LastFlowRunID, &s.CreatedBy, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(varsJSON) > 0 {
+		_ = json.Unmarshal(varsJSON, &s.Variables)
+	}
+	return &s, nil
+}
+
+// CreateFlowSchedule stores a flow schedule
+func (c *Client) CreateFlowSchedule(ctx context.Context, s *FlowSchedule) error {
+	varsJSON, err := json.Marshal(s.Variables)
+	if err != nil {
+		return fmt.Errorf("marshal schedule variables: %w", err)
+	}
+	if _, err := c.pool.Exec(ctx, `
+		INSERT INTO flow_schedules (id, task_id, workflow_id, cron, timezone, variables, enabled, next_run_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`, s.ID, s.TaskID, s.WorkflowID, s.Cron, s.Timezone, string(varsJSON), s.Enabled, s.NextRunAt, s.CreatedBy); err != nil {
+		return fmt.Errorf("create flow schedule: %w", err)
+	}
+	return nil
+}
+
+// GetFlowSchedule retrieves a flow schedule by ID. Returns nil if not found.
+func (c *Client) GetFlowSchedule(ctx context.Context, id string) (*FlowSchedule, error) {
+	s, err := scanFlowSchedule(c.pool.QueryRow(ctx, `SELECT `+flowScheduleColumns+` FROM flow_schedules WHERE id = $1`, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get flow schedule: %w", err)
+	}
+	return s, nil
+}
+
+// ListFlowSchedules lists the flow schedules of a task (all schedules when taskID is empty)
+func (c *Client) ListFlowSchedules(ctx context.Context, taskID string) ([]*FlowSchedule, error) {
+	rows, err := c.pool.Query(ctx, `
+		SELECT `+flowScheduleColumns+` FROM flow_schedules
+		WHERE $1 = '' OR task_id::text = $1
+		ORDER BY created_at ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("list flow schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []*FlowSchedule
+	for rows.Next() {
+		s, err := scanFlowSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan flow schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// UpdateFlowScheduleNextRun sets when a schedule fires next
+func (c *Client) UpdateFlowScheduleNextRun(ctx context.Context, id string, nextRunAt time.Time) error {
+	_, err := c.pool.Exec(ctx, `
+		UPDATE flow_schedules SET next_run_at = $2, updated_at = NOW() WHERE id = $1
+	`, id, nextRunAt)
+	return err
+}
+
+// UpdateFlowScheduleLastRun records a run started by a schedule
+func (c *Client) UpdateFlowScheduleLastRun(ctx context.Context, id string, lastRunAt time.Time, flowRunID string) error {
+	_, err := c.pool.Exec(ctx, `
+		UPDATE flow_schedules SET last_run_at = $2, last_flow_run_id = NULLIF($3, '')::uuid, updated_at = NOW()
+		WHERE id = $1
+	`, id, lastRunAt, flowRunID)
+	return err
+}
+
+// DeleteFlowSchedule deletes a flow schedule. Returns false if it did not exist.
+func (c *Client) DeleteFlowSchedule(ctx context.Context, id string) (bool, error) {
+	result, err := c.pool.Exec(ctx, `DELETE FROM flow_schedules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete flow schedule: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
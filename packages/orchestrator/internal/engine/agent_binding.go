@@ -0,0 +1,243 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+const defaultAgentRole = "general-developer"
+
+// validateAgentBindings checks every agent node's provider and model against the configured
+// providers and the workflow's allowed_models, so a bad DSL fails at start instead of mid-flow.
+// Values containing runtime templates are checked when the node executes.
+func (e *FlowExecutor) validateAgentBindings(ctx context.Context, wf *WorkflowDSL) error {
+	providers, err := e.db.GetAllAgentProviders(ctx)
+	if err != nil {
+		return fmt.Errorf("load agent providers: %w", err)
+	}
+
+	var errs []error
+	for _, node := range wf.Nodes {
+		if node.Type != "agent_task" {
+			continue
+		}
+		def := node.Agent
+		if def == nil {
+			def = &AgentDef{}
+		}
+		if isTemplated(def.Role) || isTemplated(def.Provider) || isTemplated(def.Model) {
+			continue
+		}
+
+		providerID, defaultModel, err := e.nodeProvider(ctx, providers, def)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", node.ID, err))
+			continue
+		}
+		if node.Config != nil && node.Config.ToolApproval != nil {
+			if err := checkToolApprovalSupport(e.nodeAgentType(providers, providerID, def)); err != nil {
+				errs = append(errs, fmt.Errorf("node %s: %w", node.ID, err))
+				continue
+			}
+		}
+
+		model := def.Model
+		if model != "" && providerID != "" {
+			if err := e.checkProviderModel(ctx, providerID, model); err != nil {
+				errs = append(errs, fmt.Errorf("node %s: %w", node.ID, err))
+				continue
+			}
+		}
+		if model == "" {
+			model = defaultModel
+		}
+		if err := checkAllowedModel(wf, model); err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", node.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveNodeAdapter picks the adapter and model for an agent node at execution time:
+// `agent.provider` overrides the role's provider; `agent.model` overrides the provider's /
+// role's model and must be offered by the provider and allowed by the workflow.
+func (e *FlowExecutor) resolveNodeAdapter(ctx context.Context, wf *WorkflowDSL, def *AgentDef, role string, runtimeCtx map[string]any) (agent.Adapter, string, error) {
+	var adapter agent.Adapter
+	providerID, model := "", ""
+
+	providerRef := ""
+	if def != nil {
+		providerRef = renderOrRaw(def.Provider, runtimeCtx)
+	}
+
+	if providerRef != "" {
+		providers, err := e.db.GetAllAgentProviders(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("load agent providers: %w", err)
+		}
+		p, err := findProvider(providers, providerRef)
+		if err != nil {
+			return nil, "", err
+		}
+		a, ok := e.registry.GetAdapterByProvider(p.ID)
+		if !ok {
+			return nil, "", fmt.Errorf("provider %s has no registered adapter (check its configuration)", p.Name)
+		}
+		adapter, providerID = a, p.ID
+		if m, _ := e.db.GetDefaultModelForProvider(ctx, p.ID); m != nil {
+			model = m.ModelName
+		}
+	} else {
+		a, registryModel, err := e.registry.GetAdapterForRole(role)
+		if err != nil {
+			return nil, "", fmt.Errorf("get agent adapter: %w", err)
+		}
+		adapter, model = a, registryModel
+		if mapping, ok := e.registry.GetRoleMapping(role); ok {
+			providerID = mapping.ProviderID
+		}
+	}
+
+	// DSL explicit model > provider / role model > adapter default. A templated model that
+	// renders empty keeps the provider / role model.
+	if def != nil && def.Model != "" {
+		if rendered := renderOrRaw(def.Model, runtimeCtx); rendered != "" {
+			if err := e.checkProviderModel(ctx, providerID, rendered); err != nil {
+				return nil, "", err
+			}
+			model = rendered
+		}
+	}
+	if err := checkAllowedModel(wf, model); err != nil {
+		return nil, "", err
+	}
+	return adapter, model, nil
+}
+
+// nodeProvider returns the provider an agent node will run on and that provider's
+// default model. providerID is "" when the role maps to a non-database provider.
+func (e *FlowExecutor) nodeProvider(ctx context.Context, providers []*db.AgentProvider, def *AgentDef) (string, string, error) {
+	if def.Provider != "" {
+		p, err := findProvider(providers, def.Provider)
+		if err != nil {
+			return "", "", err
+		}
+		defaultModel := ""
+		if m, _ := e.db.GetDefaultModelForProvider(ctx, p.ID); m != nil {
+			defaultModel = m.ModelName
+		}
+		return p.ID, defaultModel, nil
+	}
+
+	role := def.Role
+	if role == "" {
+		role = defaultAgentRole
+	}
+	mapping, ok := e.registry.GetRoleMapping(role)
+	if !ok {
+		return "", "", fmt.Errorf("agent role %s has no provider mapping", role)
+	}
+	if _, err := findProvider(providers, mapping.ProviderID); err != nil {
+		return "", mapping.ModelName, nil // env fallback / legacy adapter
+	}
+	return mapping.ProviderID, mapping.ModelName, nil
+}
+
+// nodeAgentType returns the agent type an agent node runs on ("" when unknown)
+func (e *FlowExecutor) nodeAgentType(providers []*db.AgentProvider, providerID string, def *AgentDef) string {
+	for _, p := range providers {
+		if p.ID == providerID {
+			return p.AgentType
+		}
+	}
+	// Env fallback / legacy adapter: the registry's adapter name is its agent type
+	role := def.Role
+	if role == "" {
+		role = defaultAgentRole
+	}
+	if adapter, _, err := e.registry.GetAdapterForRole(role); err == nil {
+		return adapter.Name()
+	}
+	return ""
+}
+
+// checkToolApprovalSupport rejects tool_approval on agents that cannot enforce it, so the
+// gate fails closed instead of letting the gated tool calls through
+func checkToolApprovalSupport(agentType string) error {
+	if !agent.SupportsToolApproval(agentType) {
+		return fmt.Errorf("tool_approval is not supported by the %s agent (only claude-code can pause tool calls)", agentType)
+	}
+	return nil
+}
+
+// checkProviderModel verifies that the provider offers model. Providers without any
+// configured models accept any model name.
+func (e *FlowExecutor) checkProviderModel(ctx context.Context, providerID, model string) error {
+	if providerID == "" || model == "" {
+		return nil
+	}
+	models, err := e.db.GetModelsForProvider(ctx, providerID)
+	if err != nil {
+		return fmt.Errorf("load models for provider: %w", err)
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		if m.ModelName == model {
+			return nil
+		}
+		names = append(names, m.ModelName)
+	}
+	return fmt.Errorf("model %q is not offered by provider %s (available: %s)", model, providerID, strings.Join(names, ", "))
+}
+
+// checkAllowedModel enforces the workflow's allowed_models list
+func checkAllowedModel(wf *WorkflowDSL, model string) error {
+	if wf == nil || wf.ModelAllowed(model) {
+		return nil
+	}
+	if model == "" {
+		return fmt.Errorf("no model configured, but the workflow restricts allowed_models to: %s", strings.Join(wf.AllowedModels, ", "))
+	}
+	return fmt.Errorf("model %q is not in the workflow's allowed_models (%s)", model, strings.Join(wf.AllowedModels, ", "))
+}
+
+// findProvider looks a provider up by ID, then by (unique) name
+func findProvider(providers []*db.AgentProvider, ref string) (*db.AgentProvider, error) {
+	var byName []*db.AgentProvider
+	for _, p := range providers {
+		if p.ID == ref {
+			return p, nil
+		}
+		if p.Name == ref {
+			byName = append(byName, p)
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("agent provider %q not found", ref)
+	case 1:
+		return byName[0], nil
+	default:
+		return nil, fmt.Errorf("agent provider name %q is ambiguous, use the provider ID", ref)
+	}
+}
+
+// renderOrRaw renders template expressions in s and trims the result, keeping s unchanged on error
+func renderOrRaw(s string, runtimeCtx map[string]any) string {
+	if rendered, err := RenderTemplate(s, runtimeCtx); err == nil {
+		return strings.TrimSpace(rendered)
+	}
+	return s
+}
+
+func isTemplated(s string) bool {
+	return strings.Contains(s, "{{")
+}
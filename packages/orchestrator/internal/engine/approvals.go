@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// ApprovalProgress is the approval state of a human_review node run
+type ApprovalProgress struct {
+	Policy     string   `json:"policy"`
+	Approved   int      `json:"approved"`
+	Required   int      `json:"required"`
+	ApprovedBy []string `json:"approved_by"`
+	Satisfied  bool     `json:"satisfied"`
+}
+
+// approvalsOf returns the node's approvals config (nil: the first approval completes the node)
+func approvalsOf(nodeDef *NodeDef) *ApprovalsDef {
+	if nodeDef == nil || nodeDef.Config == nil {
+		return nil
+	}
+	return nodeDef.Config.Approvals
+}
+
+// eligibleReviewers resolves the users allowed to review (nil: anyone may review)
+func (e *FlowExecutor) eligibleReviewers(ctx context.Context, flowRun *db.FlowRun, approvals *ApprovalsDef) ([]string, error) {
+	if !approvals.Restricted() {
+		return nil, nil
+	}
+	reviewers, err := e.db.ResolveReviewers(ctx, flowRun.TaskID, approvals.Assignees, approvals.Groups)
+	if err != nil {
+		return nil, err
+	}
+	if len(reviewers) == 0 {
+		return nil, fmt.Errorf("no reviewers match the node's assignees / groups")
+	}
+	return reviewers, nil
+}
+
+// authorizeReviewer resolves the node's approvals config and checks that the caller may review it
+func (e *FlowExecutor) authorizeReviewer(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun, details ReviewDetails) (*ApprovalsDef, []string, string, error) {
+	nodeDef, _ := e.getNodeDef(flowRun, nodeRun.NodeID)
+	approvals := approvalsOf(nodeDef)
+	eligible, err := e.eligibleReviewers(ctx, flowRun, approvals)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	reviewerID, _ := reviewerIdentity(ctx, details)
+	if err := checkReviewer(approvals, eligible, reviewerID); err != nil {
+		return nil, nil, "", err
+	}
+	return approvals, eligible, reviewerID, nil
+}
+
+// checkReviewer verifies the reviewer may act on a node with the given approvals config
+func checkReviewer(approvals *ApprovalsDef, eligible []string, reviewerID string) error {
+	if !approvals.Restricted() && !approvals.MultiApprover() {
+		return nil
+	}
+	if reviewerID == "" || reviewerID == auth.ServiceSubject {
+		return fmt.Errorf("a reviewer identity is required for this review")
+	}
+	if approvals.Restricted() && !slices.Contains(eligible, reviewerID) {
+		return fmt.Errorf("reviewer %s is not assigned to this review", reviewerID)
+	}
+	return nil
+}
+
+// approvalProgress counts the distinct (eligible) reviewers who approved the node run
+func (e *FlowExecutor) approvalProgress(ctx context.Context, nodeRunID string, approvals *ApprovalsDef, eligible []string) (*ApprovalProgress, error) {
+	progress := &ApprovalProgress{Policy: ApprovalPolicyAny, Required: 1, ApprovedBy: []string{}}
+	if approvals != nil && approvals.Policy != "" {
+		progress.Policy = approvals.Policy
+	}
+	switch progress.Policy {
+	case ApprovalPolicyQuorum:
+		progress.Required = max(approvals.Required, 1)
+	case ApprovalPolicyAll:
+		progress.Required = len(eligible)
+	}
+
+	reviews, err := e.db.ListNodeRunReviewsByNodeRun(ctx, nodeRunID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		if r.Action != "approve" && r.Action != "edit_and_approve" {
+			continue
+		}
+		if approvals.Restricted() && !slices.Contains(eligible, r.ReviewerID) {
+			continue
+		}
+		if r.ReviewerID != "" && slices.Contains(progress.ApprovedBy, r.ReviewerID) {
+			continue
+		}
+		progress.Approved++
+		progress.ApprovedBy = append(progress.ApprovedBy, r.ReviewerID)
+	}
+	progress.Satisfied = progress.Approved >= progress.Required
+	return progress, nil
+}
+
+// hasApproved reports whether the reviewer already approved the node run
+func (e *FlowExecutor) hasApproved(ctx context.Context, nodeRunID, reviewerID string) (bool, error) {
+	reviews, err := e.db.ListNodeRunReviewsByNodeRun(ctx, nodeRunID)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range reviews {
+		if r.ReviewerID == reviewerID && (r.Action == "approve" || r.Action == "edit_and_approve") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
 	"github.com/sunshow/workgear/orchestrator/internal/db"
 )
 
@@ -94,6 +95,8 @@ func (e *FlowExecutor) advanceDAG(ctx context.Context, flowRunID string) error {
 			if err := e.db.UpdateTaskColumn(ctx, flowRun.TaskID, "Done"); err != nil {
 				e.logger.Warnw("Failed to move task to Done", "task_id", flowRun.TaskID, "error", err)
 			}
+
+			e.releaseWorkspace(flowRun)
 		}
 
 		e.logger.Infow("Flow completed", "flow_run_id", flowRunID)
@@ -152,7 +155,9 @@ func (e *FlowExecutor) StartFlow(ctx context.Context, flowRunID, dsl string, var
 	if err != nil {
 		return fmt.Errorf("parse DSL: %w", err)
 	}
-	_ = wf
+	if err := e.validateAgentBindings(ctx, wf); err != nil {
+		return fmt.Errorf("invalid agent config: %w", err)
+	}
 
 	// 3. Save rendered DSL snapshot to flow run (preserves runtime template vars)
 	if err := e.db.SaveFlowRunDslSnapshot(ctx, flowRunID, renderedDSL, variables); err != nil {
@@ -213,10 +218,10 @@ func (e *FlowExecutor) StartFlow(ctx context.Context, flowRunID, dsl string, var
 	})
 
 	// 7. Record timeline
-	e.recordTimeline(ctx, flowRun.TaskID, flowRunID, "", "flow_started", map[string]any{
+	e.recordTimeline(ctx, flowRun.TaskID, flowRunID, "", "flow_started", withActor(ctx, map[string]any{
 		"message":       fmt.Sprintf("流程已启动：%s", wf.Name),
 		"workflow_name": wf.Name,
-	})
+	}))
 
 	// 8. Auto-move task to "In Progress" column
 	if err := e.db.UpdateTaskColumn(ctx, flowRun.TaskID, "In Progress"); err != nil {
@@ -251,8 +256,11 @@ func (e *FlowExecutor) CancelFlow(ctx context.Context, flowRunID string) error {
 		return fmt.Errorf("cancel active nodes: %w", err)
 	}
 
-	// 4. Publish node.cancelled event for each affected node
+	// 4. Publish node.cancelled event for each affected node and drop its pending timers
 	for _, node := range activeNodes {
+		if _, err := e.scheduler.Cancel(ctx, nodeRunTimerKey(node.ID)); err != nil {
+			e.logger.Warnw("Failed to cancel node timers", "node_run_id", node.ID, "error", err)
+		}
 		e.publishEvent(flowRunID, node.ID, node.NodeID, "node.cancelled", map[string]any{
 			"previous_status": node.Status,
 		})
@@ -265,22 +273,28 @@ func (e *FlowExecutor) CancelFlow(ctx context.Context, flowRunID string) error {
 
 	e.publishEvent(flowRunID, "", "", "flow.cancelled", nil)
 
-	e.recordTimeline(ctx, flowRun.TaskID, flowRunID, "", "flow_cancelled", map[string]any{
+	e.recordTimeline(ctx, flowRun.TaskID, flowRunID, "", "flow_cancelled", withActor(ctx, map[string]any{
 		"message": "流程已取消",
-	})
+	}))
 
 	// Auto-move task back to "Backlog" column
 	if err := e.db.UpdateTaskColumn(ctx, flowRun.TaskID, "Backlog"); err != nil {
 		e.logger.Warnw("Failed to move task to Backlog", "task_id", flowRun.TaskID, "error", err)
 	}
 
+	e.releaseWorkspace(flowRun)
+
 	return nil
 }
 
 // ─── Human Actions ───
 
 // HandleApprove processes an approve action on a human_review node
-func (e *FlowExecutor) HandleApprove(ctx context.Context, nodeRunID string) error {
+func (e *FlowExecutor) HandleApprove(ctx context.Context, nodeRunID string, details ReviewDetails) error {
+	if err := details.Validate(); err != nil {
+		return err
+	}
+
 	nodeRun, err := e.db.GetNodeRun(ctx, nodeRunID)
 	if err != nil {
 		return fmt.Errorf("get node run: %w", err)
@@ -299,11 +313,51 @@ func (e *FlowExecutor) HandleApprove(ctx context.Context, nodeRunID string) erro
 		return fmt.Errorf("node is not waiting for human action, current status: %s", nodeRun.Status)
 	}
 
+	// Multi-approver reviews: only assigned reviewers count, each once
+	approvals, eligible, reviewerID, err := e.authorizeReviewer(ctx, flowRun, nodeRun, details)
+	if err != nil {
+		return err
+	}
+	if approvals.MultiApprover() {
+		if approved, err := e.hasApproved(ctx, nodeRunID, reviewerID); err != nil {
+			return err
+		} else if approved {
+			return fmt.Errorf("reviewer %s has already approved this node", reviewerID)
+		}
+	}
+
 	// Record review
-	if err := e.db.UpdateNodeRunReview(ctx, nodeRunID, "approve", ""); err != nil {
+	review, err := e.recordReview(ctx, nodeRun, "approve", "", details)
+	if err != nil {
 		return fmt.Errorf("record review: %w", err)
 	}
 
+	progress, err := e.approvalProgress(ctx, nodeRunID, approvals, eligible)
+	if err != nil {
+		return fmt.Errorf("count approvals: %w", err)
+	}
+	if approvals != nil {
+		e.publishEvent(nodeRun.FlowRunID, nodeRunID, nodeRun.NodeID, "node.approval_progress", map[string]any{
+			"policy":      progress.Policy,
+			"approved":    progress.Approved,
+			"required":    progress.Required,
+			"remaining":   max(progress.Required-progress.Approved, 0),
+			"approved_by": progress.ApprovedBy,
+			"satisfied":   progress.Satisfied,
+		})
+	}
+	if !progress.Satisfied {
+		e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_approval_progress", withActor(ctx, map[string]any{
+			"node_id":   nodeRun.NodeID,
+			"node_name": ptrStr(nodeRun.NodeName),
+			"review_id": review.ID,
+			"approved":  progress.Approved,
+			"required":  progress.Required,
+			"message":   fmt.Sprintf("审核通过 %d/%d：%s", progress.Approved, progress.Required, ptrStr(nodeRun.NodeName)),
+		}))
+		return nil
+	}
+
 	// Pass input through as output (approved content)
 	var output map[string]any
 	if nodeRun.Input != nil {
@@ -318,27 +372,47 @@ func (e *FlowExecutor) HandleApprove(ctx context.Context, nodeRunID string) erro
 		return fmt.Errorf("save output: %w", err)
 	}
 
-	if err := e.db.UpdateNodeRunStatus(ctx, nodeRunID, db.StatusCompleted); err != nil {
+	// Only one of several concurrent approvals completes the node
+	completed, err := e.db.TransitionNodeRunStatus(ctx, nodeRunID, db.StatusWaitingHuman, db.StatusCompleted)
+	if err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
+	if !completed {
+		// A concurrent approval completing the node is fine; anything else (a reject, a
+		// cancel) won the race and this approval did not take effect
+		current, err := e.db.GetNodeRun(ctx, nodeRunID)
+		if err != nil {
+			return fmt.Errorf("get node run: %w", err)
+		}
+		if current.Status == db.StatusCompleted {
+			return nil
+		}
+		return fmt.Errorf("conflict: node is no longer waiting for human action, current status: %s", current.Status)
+	}
 
 	e.publishEvent(nodeRun.FlowRunID, nodeRunID, nodeRun.NodeID, "node.completed", map[string]any{
 		"review_action": "approve",
 	})
 
 	// Record timeline (flowRun already fetched above)
-	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_approved", map[string]any{
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_approved", withActor(ctx, map[string]any{
 		"node_id":   nodeRun.NodeID,
 		"node_name": ptrStr(nodeRun.NodeName),
+		"review_id": review.ID,
+		"severity":  review.Severity,
 		"message":   fmt.Sprintf("审核通过：%s", ptrStr(nodeRun.NodeName)),
-	})
+	}))
 
 	// Advance DAG
 	return e.advanceDAG(ctx, nodeRun.FlowRunID)
 }
 
 // HandleReject processes a reject action — rolls back to the target node
-func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback string) error {
+func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback string, details ReviewDetails) error {
+	if err := details.Validate(); err != nil {
+		return err
+	}
+
 	nodeRun, err := e.db.GetNodeRun(ctx, nodeRunID)
 	if err != nil {
 		return fmt.Errorf("get node run: %w", err)
@@ -357,15 +431,25 @@ func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback str
 		return fmt.Errorf("node is not waiting for human action, current status: %s", nodeRun.Status)
 	}
 
+	// Any assigned reviewer's reject rejects the node
+	if _, _, _, err := e.authorizeReviewer(ctx, flowRun, nodeRun, details); err != nil {
+		return err
+	}
+
 	// Record review
-	if err := e.db.UpdateNodeRunReview(ctx, nodeRunID, "reject", feedback); err != nil {
+	review, err := e.recordReview(ctx, nodeRun, "reject", feedback, details)
+	if err != nil {
 		return fmt.Errorf("record review: %w", err)
 	}
 
 	// Mark current node as REJECTED
-	if err := e.db.UpdateNodeRunStatus(ctx, nodeRunID, db.StatusRejected); err != nil {
+	rejected, err := e.db.TransitionNodeRunStatus(ctx, nodeRunID, db.StatusWaitingHuman, db.StatusRejected)
+	if err != nil {
 		return fmt.Errorf("update status: %w", err)
 	}
+	if !rejected {
+		return fmt.Errorf("node is no longer waiting for human action")
+	}
 
 	e.publishEvent(nodeRun.FlowRunID, nodeRunID, nodeRun.NodeID, "node.rejected", map[string]any{
 		"feedback": feedback,
@@ -377,15 +461,50 @@ func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback str
 		return fmt.Errorf("get node def: %w", err)
 	}
 
+	// Roll back with feedback injection
+	targetNodeID, attempt, exhausted, err := e.rollbackFrom(ctx, flowRun, nodeRun, nodeDef, map[string]any{
+		"_feedback":    feedback,
+		"_reject_from": nodeRun.NodeID,
+		"_review":      review,
+	})
+	if err != nil || exhausted {
+		return err
+	}
+
+	// Record timeline
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_rejected", withActor(ctx, map[string]any{
+		"node_id":        nodeRun.NodeID,
+		"node_name":      ptrStr(nodeRun.NodeName),
+		"feedback":       feedback,
+		"review_id":      review.ID,
+		"severity":       review.Severity,
+		"comment_count":  len(review.Comments),
+		"rollback_to":    targetNodeID,
+		"attempt":        attempt,
+		"message":        fmt.Sprintf("审核打回：%s → 回退到 %s（第 %d 次）", ptrStr(nodeRun.NodeName), targetNodeID, attempt),
+	}))
+
+	e.logger.Infow("Rejected and rolling back",
+		"from_node", nodeRun.NodeID,
+		"to_node", targetNodeID,
+		"attempt", attempt,
+	)
+
+	return nil
+}
+
+// rollbackFrom re-queues the on_reject target of a rejected node (default: the previous node
+// in the DAG) with input plus _attempt, and resets the nodes in between. When the target
+// reached on_reject.max_loops the flow is failed instead and exhausted is set.
+func (e *FlowExecutor) rollbackFrom(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun, nodeDef *NodeDef, input map[string]any) (targetNodeID string, attempt int, exhausted bool, err error) {
 	// Determine rollback target
-	targetNodeID := ""
 	if nodeDef.OnReject != nil && nodeDef.OnReject.Goto != "" {
 		targetNodeID = nodeDef.OnReject.Goto
 	} else {
 		// Default: roll back to the previous node in the DAG
 		_, dag, err := ParseDSL(*flowRun.DslSnapshot)
 		if err != nil {
-			return fmt.Errorf("parse DSL: %w", err)
+			return "", 0, false, fmt.Errorf("parse DSL: %w", err)
 		}
 		prevNode := dag.GetPreviousNode(nodeRun.NodeID)
 		if prevNode != nil {
@@ -394,7 +513,7 @@ func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback str
 	}
 
 	if targetNodeID == "" {
-		return fmt.Errorf("no rollback target found for node %s", nodeRun.NodeID)
+		return "", 0, false, fmt.Errorf("no rollback target found for node %s", nodeRun.NodeID)
 	}
 
 	// Check max_loops
@@ -405,18 +524,19 @@ func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback str
 			// Max loops reached — fail the flow
 			errMsg := fmt.Sprintf("打回次数已达上限 (%d)，节点: %s", maxLoops, nodeRun.NodeID)
 			if err := e.db.UpdateFlowRunError(ctx, nodeRun.FlowRunID, db.StatusFailed, errMsg); err != nil {
-				return err
+				return "", 0, false, err
 			}
 			e.publishEvent(nodeRun.FlowRunID, "", "", "flow.failed", map[string]any{
 				"error": errMsg,
 			})
-			return nil
+			e.scheduleWorkspaceRelease(ctx, nodeRun.FlowRunID)
+			return targetNodeID, 0, true, nil
 		}
 	}
 
 	// Get the existing target node run to determine attempt number
 	existingTarget, _ := e.db.GetNodeRunByFlowAndNode(ctx, nodeRun.FlowRunID, targetNodeID)
-	attempt := 1
+	attempt = 1
 	if existingTarget != nil {
 		attempt = existingTarget.Attempt + 1
 	}
@@ -425,10 +545,6 @@ func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback str
 	_, dag, _ := ParseDSL(*flowRun.DslSnapshot)
 	targetDef := dag.GetNode(targetNodeID)
 
-	// Build input with feedback injection
-	input := make(map[string]any)
-	input["_feedback"] = feedback
-	input["_reject_from"] = nodeRun.NodeID
 	input["_attempt"] = attempt
 
 	// Create new QUEUED node run for the target
@@ -445,34 +561,22 @@ func (e *FlowExecutor) HandleReject(ctx context.Context, nodeRunID, feedback str
 	}
 
 	if err := e.db.CreateNodeRun(ctx, newNodeRun); err != nil {
-		return fmt.Errorf("create rollback node run: %w", err)
+		return "", 0, false, fmt.Errorf("create rollback node run: %w", err)
 	}
 
 	// Also reset nodes between target and current (mark them as needing re-execution)
 	// For linear flows, we need to re-create PENDING nodes for nodes between target and current
 	e.resetIntermediateNodes(ctx, flowRun, dag, targetNodeID, nodeRun.NodeID)
 
-	// Record timeline
-	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_rejected", map[string]any{
-		"node_id":        nodeRun.NodeID,
-		"node_name":      ptrStr(nodeRun.NodeName),
-		"feedback":       feedback,
-		"rollback_to":    targetNodeID,
-		"attempt":        attempt,
-		"message":        fmt.Sprintf("审核打回：%s → 回退到 %s（第 %d 次）", ptrStr(nodeRun.NodeName), targetNodeID, attempt),
-	})
-
-	e.logger.Infow("Rejected and rolling back",
-		"from_node", nodeRun.NodeID,
-		"to_node", targetNodeID,
-		"attempt", attempt,
-	)
-
-	return nil
+	return targetNodeID, attempt, false, nil
 }
 
 // HandleEdit processes an edit_and_approve action
-func (e *FlowExecutor) HandleEdit(ctx context.Context, nodeRunID, editedContent, changeSummary string) error {
+func (e *FlowExecutor) HandleEdit(ctx context.Context, nodeRunID, editedContent, changeSummary string, details ReviewDetails) error {
+	if err := details.Validate(); err != nil {
+		return err
+	}
+
 	nodeRun, err := e.db.GetNodeRun(ctx, nodeRunID)
 	if err != nil {
 		return fmt.Errorf("get node run: %w", err)
@@ -491,8 +595,18 @@ func (e *FlowExecutor) HandleEdit(ctx context.Context, nodeRunID, editedContent,
 		return fmt.Errorf("node is not waiting for human action, current status: %s", nodeRun.Status)
 	}
 
+	// Editing replaces the reviewed content, so it can't be one of several approvals
+	approvals, _, _, err := e.authorizeReviewer(ctx, flowRun, nodeRun, details)
+	if err != nil {
+		return err
+	}
+	if approvals.MultiApprover() {
+		return fmt.Errorf("edit_and_approve is not supported on multi-approver reviews (policy %s)", approvals.Policy)
+	}
+
 	// Record review
-	if err := e.db.UpdateNodeRunReview(ctx, nodeRunID, "edit_and_approve", changeSummary); err != nil {
+	review, err := e.recordReview(ctx, nodeRun, "edit_and_approve", changeSummary, details)
+	if err != nil {
 		return fmt.Errorf("record review: %w", err)
 	}
 
@@ -520,12 +634,14 @@ func (e *FlowExecutor) HandleEdit(ctx context.Context, nodeRunID, editedContent,
 	})
 
 	// Record timeline (flowRun already fetched above)
-	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_edited", map[string]any{
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "review_edited", withActor(ctx, map[string]any{
 		"node_id":        nodeRun.NodeID,
 		"node_name":      ptrStr(nodeRun.NodeName),
 		"change_summary": changeSummary,
+		"review_id":      review.ID,
+		"severity":       review.Severity,
 		"message":        fmt.Sprintf("编辑后通过：%s", ptrStr(nodeRun.NodeName)),
-	})
+	}))
 
 	return e.advanceDAG(ctx, nodeRun.FlowRunID)
 }
@@ -550,11 +666,15 @@ func (e *FlowExecutor) HandleHumanInput(ctx context.Context, nodeRunID, dataJSON
 		return fmt.Errorf("node is not waiting for human action, current status: %s", nodeRun.Status)
 	}
 
-	// Parse submitted data as output
-	var output map[string]any
-	if err := json.Unmarshal([]byte(dataJSON), &output); err != nil {
+	// Parse submitted data and validate it against the node's form
+	var data map[string]any
+	if err := json.Unmarshal([]byte(dataJSON), &data); err != nil {
 		return fmt.Errorf("invalid input data: %w", err)
 	}
+	output, err := validateForm(e.humanInputForm(ctx, flowRun, nodeRun), data)
+	if err != nil {
+		return err
+	}
 
 	if err := e.db.UpdateNodeRunOutput(ctx, nodeRunID, output); err != nil {
 		return fmt.Errorf("save output: %w", err)
@@ -569,11 +689,11 @@ func (e *FlowExecutor) HandleHumanInput(ctx context.Context, nodeRunID, dataJSON
 	})
 
 	// Record timeline (flowRun already fetched above)
-	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "human_input_submitted", map[string]any{
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRunID, "human_input_submitted", withActor(ctx, map[string]any{
 		"node_id":   nodeRun.NodeID,
 		"node_name": ptrStr(nodeRun.NodeName),
 		"message":   fmt.Sprintf("人工输入已提交：%s", ptrStr(nodeRun.NodeName)),
-	})
+	}))
 
 	return e.advanceDAG(ctx, nodeRun.FlowRunID)
 }
@@ -626,6 +746,9 @@ func (e *FlowExecutor) HandleRetry(ctx context.Context, nodeRunID string) error
 		if err := e.db.UpdateFlowRunStatus(ctx, flowRun.ID, db.StatusRunning); err != nil {
 			return fmt.Errorf("update flow status: %w", err)
 		}
+		if _, err := e.scheduler.Cancel(ctx, workspaceTimerKey(flowRun.ID)); err != nil {
+			e.logger.Warnw("Failed to cancel workspace release", "flow_run_id", flowRun.ID, "error", err)
+		}
 	}
 
 	e.publishEvent(nodeRun.FlowRunID, newNodeRun.ID, nodeRun.NodeID, "node.queued", map[string]any{
@@ -689,9 +812,21 @@ func (e *FlowExecutor) walkAndReset(ctx context.Context, flowRun *db.FlowRun, da
 	}
 }
 
+// withActor adds the authenticated caller of the current RPC (if any) to the timeline
+// content of a human action
+func withActor(ctx context.Context, content map[string]any) map[string]any {
+	if p := auth.FromContext(ctx); p != nil {
+		content["actor_id"] = p.Subject
+		if p.Name != "" {
+			content["actor_name"] = p.Name
+		}
+	}
+	return content
+}
+
 // recordTimeline creates a timeline event
 func (e *FlowExecutor) recordTimeline(ctx context.Context, taskID, flowRunID, nodeRunID, eventType string, content map[string]any) {
-	contentJSON, _ := json.Marshal(content)
+	contentJSON, _ := json.Marshal(e.redactor.Map(content))
 
 	evt := &db.TimelineEvent{
 		ID:        uuid.New().String(),
@@ -2,53 +2,260 @@ package engine
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
 )
 
 // ─── DSL Data Structures ───
 
 // WorkflowDSL represents the parsed YAML workflow definition
 type WorkflowDSL struct {
-	Name        string            `yaml:"name"`
-	Version     string            `yaml:"version"`
-	Description string            `yaml:"description"`
-	Variables   map[string]string `yaml:"variables"`
-	Nodes       []NodeDef         `yaml:"nodes"`
-	Edges       []EdgeDef         `yaml:"edges"`
+	Name          string            `yaml:"name"`
+	Version       string            `yaml:"version"`
+	Description   string            `yaml:"description"`
+	Variables     map[string]string `yaml:"variables"`
+	Workspace     string            `yaml:"workspace"`      // "" (fresh per node) / shared / snapshot
+	AllowedModels []string          `yaml:"allowed_models"` // models agent nodes may run with (empty = any)
+	Schemas       map[string]any    `yaml:"schemas"`        // named output schemas for config.output_schema
+	Nodes         []NodeDef         `yaml:"nodes"`
+	Edges         []EdgeDef         `yaml:"edges"`
+}
+
+// Workspace modes for agent nodes of a flow run
+const (
+	WorkspaceEphemeral = ""         // each agent container clones into a fresh /workspace
+	WorkspaceShared    = "shared"   // one Docker volume per flow run, mounted at /workspace for every agent node
+	WorkspaceSnapshot  = "snapshot" // shared, plus a per-node snapshot restored when a reject rolls back to that node
+)
+
+// SharedWorkspace reports whether agent nodes share a per-flow workspace volume
+func (wf *WorkflowDSL) SharedWorkspace() bool {
+	return wf.Workspace == WorkspaceShared || wf.Workspace == WorkspaceSnapshot
+}
+
+// ModelAllowed reports whether agent nodes may use model under allowed_models
+func (wf *WorkflowDSL) ModelAllowed(model string) bool {
+	return len(wf.AllowedModels) == 0 || slices.Contains(wf.AllowedModels, model)
+}
+
+// defaultOutputRepairs is how many times an agent is re-prompted when its output misses the schema
+const defaultOutputRepairs = 2
+
+// OutputSchema resolves a node's `config.output_schema`: an inline schema, or the name of a
+// schema under the workflow's `schemas` or a built-in one. Returns nil if none is set.
+func (wf *WorkflowDSL) OutputSchema(node *NodeDef) (map[string]any, error) {
+	if node.Config == nil || node.Config.OutputSchema == nil {
+		return nil, nil
+	}
+	switch schema := node.Config.OutputSchema.(type) {
+	case map[string]any:
+		if err := agent.CheckSchema(schema); err != nil {
+			return nil, fmt.Errorf("invalid output_schema: %w", err)
+		}
+		return schema, nil
+	case string:
+		if named, ok := wf.Schemas[schema].(map[string]any); ok {
+			if err := agent.CheckSchema(named); err != nil {
+				return nil, fmt.Errorf("invalid schema %q: %w", schema, err)
+			}
+			return named, nil
+		}
+		if builtin, ok := agent.BuiltinOutputSchemas[schema]; ok {
+			return builtin, nil
+		}
+		return nil, fmt.Errorf("unknown output_schema %q", schema)
+	default:
+		return nil, fmt.Errorf("output_schema must be a schema object or a schema name")
+	}
+}
+
+// OutputRepairs returns how many repair re-prompts a node allows
+func (n *NodeDef) OutputRepairs() int {
+	if n.Config == nil || n.Config.OutputRepairs == nil {
+		return defaultOutputRepairs
+	}
+	return max(*n.Config.OutputRepairs, 0)
 }
 
 // NodeDef represents a node definition in the DSL
 type NodeDef struct {
-	ID       string          `yaml:"id"`
-	Name     string          `yaml:"name"`
-	Type     string          `yaml:"type"` // agent_task / human_review / human_input
-	Agent    *AgentDef       `yaml:"agent"`
-	Config   *NodeConfigDef  `yaml:"config"`
-	OnReject *OnRejectDef    `yaml:"on_reject"`
-	Timeout  string          `yaml:"timeout"`
-	Retry    *RetryDef       `yaml:"retry"`
+	ID       string         `yaml:"id"`
+	Name     string         `yaml:"name"`
+	Type     string         `yaml:"type"` // agent_task / human_review / human_input / wait / script / http_request
+	Agent    *AgentDef      `yaml:"agent"`
+	Config   *NodeConfigDef `yaml:"config"`
+	OnReject *OnRejectDef   `yaml:"on_reject"`
+	Timeout  string         `yaml:"timeout"`
+	Retry    *RetryDef      `yaml:"retry"`
 }
 
 // AgentDef defines which agent to use
 type AgentDef struct {
 	Role         string `yaml:"role"`
 	FallbackRole string `yaml:"fallback_role"`
+	Provider     string `yaml:"provider"` // provider ID or name; overrides the role's provider
 	Model        string `yaml:"model"`
 }
 
 // NodeConfigDef holds node-specific configuration
 type NodeConfigDef struct {
-	Mode           string            `yaml:"mode"` // spec / execute / review / opsx_plan / opsx_apply
-	PromptTemplate string            `yaml:"prompt_template"`
-	ReviewTarget   string            `yaml:"review_target"`
-	Actions        []string          `yaml:"actions"`
-	Form           []FormFieldDef    `yaml:"form"`
-	Timeout        string            `yaml:"timeout"`
-	Opsx           *OpsxConfigDef    `yaml:"opsx"`
-	Artifact       *ArtifactConfigDef `yaml:"artifact"`
-	ShowArtifacts  bool              `yaml:"show_artifacts"`
-	ArtifactPaths  []string          `yaml:"artifact_paths"`
+	Mode           string              `yaml:"mode"` // spec / execute / review / opsx_plan / opsx_apply
+	PromptTemplate string              `yaml:"prompt_template"`
+	ReviewTarget   string              `yaml:"review_target"`
+	Actions        []string            `yaml:"actions"`
+	Form           []FormFieldDef      `yaml:"form"`
+	Timeout        string              `yaml:"timeout"`
+	Opsx           *OpsxConfigDef      `yaml:"opsx"`
+	Artifact       *ArtifactConfigDef  `yaml:"artifact"`
+	ShowArtifacts  bool                `yaml:"show_artifacts"`
+	ArtifactPaths  []string            `yaml:"artifact_paths"`
+	Container      *ContainerConfigDef `yaml:"container"`
+	OutputSchema   any                 `yaml:"output_schema"`  // inline JSON Schema, or the name of a workflow / built-in schema
+	OutputRepairs  *int                `yaml:"output_repairs"` // repair re-prompts on schema mismatch (default 2)
+	PromptBudget   int                 `yaml:"prompt_budget"`  // prompt token budget (default: derived from the model's context window)
+	ToolApproval   *ToolApprovalDef    `yaml:"tool_approval"`  // agent tool calls that pause for human approval
+	Approvals      *ApprovalsDef       `yaml:"approvals"`      // human_review: who must approve before the node completes
+	Reminders      []ReminderDef       `yaml:"reminders"`      // human_review / human_input: reminders while waiting
+	Duration       string              `yaml:"duration"`       // wait: how long to wait, e.g. "30m", "1d"
+	Until          string              `yaml:"until"`          // wait: timestamp template to wait until (RFC3339)
+	Run            string              `yaml:"run"`            // script: shell command template, run with sh -c in the checkout
+	Image          string              `yaml:"image"`          // script: container image (default: the script runner's)
+	Executor       string              `yaml:"executor"`       // script: docker (default) / local
+	Env            map[string]string   `yaml:"env"`            // script: environment variables (values are templates)
+	OutputFile     string              `yaml:"output_file"`    // script: JSON file in the checkout parsed into outputs.result
+	OnFailure      string              `yaml:"on_failure"`     // script: fail (default) / reject on a non-zero exit
+	Method         string              `yaml:"method"`         // http_request: GET (default; POST with a body) / POST / PUT / ...
+	URL            string              `yaml:"url"`            // http_request: URL template
+	Headers        map[string]string   `yaml:"headers"`        // http_request: header templates, may use {{secrets.NAME}}
+	Body           string              `yaml:"body"`           // http_request: raw body template
+	JSON           any                 `yaml:"json"`           // http_request: JSON body; string values are templates
+	AllowStatus    []any               `yaml:"allow_status"`   // http_request: non-2xx statuses that complete the node, e.g. 404, "4xx"
+	Outputs        map[string]string   `yaml:"outputs"`        // http_request: output name → JSONPath into the response body
+}
+
+// ReminderDef schedules a reminder for a node waiting on a human. With escalate_to the
+// reminder is an escalation to that project member role, or user ID / email.
+type ReminderDef struct {
+	After      string `yaml:"after"` // time since the node started waiting, e.g. "4h", "2d"
+	EscalateTo string `yaml:"escalate_to"`
+}
+
+// Delay parses After
+func (d *ReminderDef) Delay() (time.Duration, error) {
+	delay, err := parseDelay(d.After)
+	if err != nil {
+		return 0, fmt.Errorf("invalid after %q", d.After)
+	}
+	return delay, nil
+}
+
+// parseDelay parses a positive Go duration ("90s", "4h") or a number of days ("2d")
+func parseDelay(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	delay, err := time.ParseDuration(s)
+	if err != nil || delay <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return delay, nil
+}
+
+// Approval policies of human_review nodes
+const (
+	ApprovalPolicyAny    = "any"    // the first approval completes the node (default)
+	ApprovalPolicyQuorum = "quorum" // `required` distinct reviewers must approve
+	ApprovalPolicyAll    = "all"    // every assigned reviewer must approve
+)
+
+// ApprovalsDef configures multi-approver reviews. Any reject rejects the node.
+type ApprovalsDef struct {
+	Required  int      `yaml:"required"`  // quorum size (default 1)
+	Assignees []string `yaml:"assignees"` // user IDs or emails allowed to review
+	Groups    []string `yaml:"groups"`    // project member roles allowed to review (owner / admin / member)
+	Policy    string   `yaml:"policy"`    // any (default) / quorum / all
+}
+
+// Validate checks the policy and its parameters
+func (d *ApprovalsDef) Validate() error {
+	if d.Required < 0 {
+		return fmt.Errorf("required must be positive")
+	}
+	switch d.Policy {
+	case "", ApprovalPolicyAny, ApprovalPolicyAll:
+	case ApprovalPolicyQuorum:
+		if len(d.Groups) == 0 && len(d.Assignees) > 0 && d.Required > len(d.Assignees) {
+			return fmt.Errorf("required (%d) exceeds the number of assignees (%d)", d.Required, len(d.Assignees))
+		}
+	default:
+		return fmt.Errorf("invalid policy %q (expected any, quorum or all)", d.Policy)
+	}
+	if d.Required > 1 && d.Policy != ApprovalPolicyQuorum {
+		return fmt.Errorf("required (%d) needs policy quorum; with policy %s it would be ignored", d.Required, orDefault(d.Policy, ApprovalPolicyAny))
+	}
+	if d.Policy == ApprovalPolicyAll && !d.Restricted() {
+		return fmt.Errorf("policy all requires assignees or groups")
+	}
+	return nil
+}
+
+// Restricted reports whether only assignees / group members may review
+func (d *ApprovalsDef) Restricted() bool {
+	return d != nil && (len(d.Assignees) > 0 || len(d.Groups) > 0)
+}
+
+// MultiApprover reports whether the policy may need more than one approval
+func (d *ApprovalsDef) MultiApprover() bool {
+	if d == nil {
+		return false
+	}
+	return d.Policy == ApprovalPolicyAll || (d.Policy == ApprovalPolicyQuorum && d.Required > 1)
+}
+
+// ToolApprovalDef lists agent tool calls that pause until a human approves them.
+// Undecided calls are denied after the timeout.
+type ToolApprovalDef struct {
+	Timeout string                   `yaml:"timeout"` // e.g. "30m" (default 30m)
+	Rules   []agent.ToolApprovalRule `yaml:"rules"`
+}
+
+// Policy converts the DSL definition into an agent tool approval policy
+func (d *ToolApprovalDef) Policy() (*agent.ToolApprovalPolicy, error) {
+	policy := &agent.ToolApprovalPolicy{Rules: d.Rules}
+	if d.Timeout != "" {
+		timeout, err := time.ParseDuration(d.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", d.Timeout)
+		}
+		policy.Timeout = timeout
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ContainerConfigDef holds node-level resource limits and security options for agent containers.
+// Resource values override the provider's; security options can only tighten the provider's policy.
+type ContainerConfigDef struct {
+	Memory          string   `yaml:"memory"` // e.g. "512m", "2g"
+	CPUs            string   `yaml:"cpus"`   // e.g. "1.5"
+	PidsLimit       int64    `yaml:"pids_limit"`
+	ReadOnlyRootfs  bool     `yaml:"read_only_rootfs"`
+	NetworkMode     string   `yaml:"network_mode"`
+	CapDrop         []string `yaml:"cap_drop"`
+	NoNewPrivileges bool     `yaml:"no_new_privileges"`
 }
 
 // ArtifactConfigDef defines artifact creation for a node
@@ -68,23 +275,28 @@ type OpsxConfigDef struct {
 
 // FormFieldDef defines a form field for human_input nodes
 type FormFieldDef struct {
-	Field    string   `yaml:"field"`
-	Type     string   `yaml:"type"`
-	Label    string   `yaml:"label"`
-	Required bool     `yaml:"required"`
-	Options  []string `yaml:"options"`
+	Field       string   `yaml:"field" json:"field"`
+	Type        string   `yaml:"type" json:"type"` // text / textarea / number / boolean / date / select / multi_select / file_upload
+	Label       string   `yaml:"label" json:"label"`
+	Required    bool     `yaml:"required" json:"required,omitempty"`
+	Options     []string `yaml:"options" json:"options,omitempty"`           // entries may be templates
+	OptionsFrom string   `yaml:"options_from" json:"options_from,omitempty"` // path to an upstream value, e.g. nodes.plan.outputs.modules
+	Default     any      `yaml:"default" json:"default,omitempty"`           // used when the field is not submitted; strings may be templates
+	Pattern     string   `yaml:"pattern" json:"pattern,omitempty"`           // regex text values must match
+	Min         *float64 `yaml:"min" json:"min,omitempty"`                   // number: value; text: length; multi_select: selections
+	Max         *float64 `yaml:"max" json:"max,omitempty"`
 }
 
 // OnRejectDef defines reject behavior
 type OnRejectDef struct {
-	Goto     string                 `yaml:"goto"`
-	MaxLoops interface{}            `yaml:"max_loops"` // can be int or string template
-	Inject   map[string]string      `yaml:"inject"`
+	Goto     string            `yaml:"goto"`
+	MaxLoops interface{}       `yaml:"max_loops"` // can be int or string template
+	Inject   map[string]string `yaml:"inject"`
 }
 
-// GetMaxLoops returns max_loops as int, defaulting to 3 if not parseable
+// GetMaxLoops returns max_loops as int, defaulting to 3 if not set (or no on_reject) or not parseable
 func (o *OnRejectDef) GetMaxLoops() int {
-	if o.MaxLoops == nil {
+	if o == nil || o.MaxLoops == nil {
 		return 3
 	}
 	switch v := o.MaxLoops.(type) {
@@ -129,11 +341,11 @@ type EdgeDef struct {
 
 // DAG represents the directed acyclic graph of a workflow
 type DAG struct {
-	Nodes      map[string]*NodeDef   // nodeID → NodeDef
-	NodeOrder  []string              // ordered node IDs
+	Nodes      map[string]*NodeDef // nodeID → NodeDef
+	NodeOrder  []string            // ordered node IDs
 	Edges      []EdgeDef
-	Deps       map[string][]string   // nodeID → upstream dependencies
-	Successors map[string][]string   // nodeID → downstream nodes
+	Deps       map[string][]string // nodeID → upstream dependencies
+	Successors map[string][]string // nodeID → downstream nodes
 }
 
 // ParseDSL parses a YAML DSL string into a DAG
@@ -147,6 +359,12 @@ func ParseDSL(dslYAML string) (*WorkflowDSL, *DAG, error) {
 		return nil, nil, fmt.Errorf("workflow has no nodes")
 	}
 
+	switch wf.Workspace {
+	case WorkspaceEphemeral, WorkspaceShared, WorkspaceSnapshot:
+	default:
+		return nil, nil, fmt.Errorf("invalid workspace mode: %s (expected shared or snapshot)", wf.Workspace)
+	}
+
 	dag := &DAG{
 		Nodes:      make(map[string]*NodeDef),
 		NodeOrder:  make([]string, 0, len(wf.Nodes)),
@@ -164,6 +382,59 @@ func ParseDSL(dslYAML string) (*WorkflowDSL, *DAG, error) {
 		if _, exists := dag.Nodes[node.ID]; exists {
 			return nil, nil, fmt.Errorf("duplicate node id: %s", node.ID)
 		}
+		if _, err := wf.OutputSchema(node); err != nil {
+			return nil, nil, fmt.Errorf("node %s: %w", node.ID, err)
+		}
+		if node.Config != nil && node.Config.ToolApproval != nil {
+			if _, err := node.Config.ToolApproval.Policy(); err != nil {
+				return nil, nil, fmt.Errorf("node %s: tool_approval: %w", node.ID, err)
+			}
+		}
+		if node.Config != nil {
+			for _, field := range node.Config.Form {
+				if err := field.Validate(); err != nil {
+					return nil, nil, fmt.Errorf("node %s: form: %w", node.ID, err)
+				}
+			}
+		}
+		if node.Timeout != "" {
+			if _, err := parseDelay(node.Timeout); err != nil {
+				return nil, nil, fmt.Errorf("node %s: timeout: %w", node.ID, err)
+			}
+		}
+		if node.Type == "wait" || node.Type == "delay" {
+			if err := validateWait(node.Config); err != nil {
+				return nil, nil, fmt.Errorf("node %s: %w", node.ID, err)
+			}
+		}
+		if node.Type == "script" || node.Type == "shell" {
+			if err := validateScript(node.Config); err != nil {
+				return nil, nil, fmt.Errorf("node %s: %w", node.ID, err)
+			}
+		}
+		if node.Type == "http_request" {
+			if err := validateHTTPRequest(node.Config, node.Retry); err != nil {
+				return nil, nil, fmt.Errorf("node %s: %w", node.ID, err)
+			}
+		}
+		if node.Config != nil && len(node.Config.Reminders) > 0 {
+			if node.Type != "human_review" && node.Type != "human_input" {
+				return nil, nil, fmt.Errorf("node %s: reminders are only supported on human_review / human_input nodes", node.ID)
+			}
+			for i := range node.Config.Reminders {
+				if _, err := node.Config.Reminders[i].Delay(); err != nil {
+					return nil, nil, fmt.Errorf("node %s: reminders[%d]: %w", node.ID, i, err)
+				}
+			}
+		}
+		if node.Config != nil && node.Config.Approvals != nil {
+			if node.Type != "human_review" {
+				return nil, nil, fmt.Errorf("node %s: approvals is only supported on human_review nodes", node.ID)
+			}
+			if err := node.Config.Approvals.Validate(); err != nil {
+				return nil, nil, fmt.Errorf("node %s: approvals: %w", node.ID, err)
+			}
+		}
 		dag.Nodes[node.ID] = node
 		dag.NodeOrder = append(dag.NodeOrder, node.ID)
 	}
@@ -229,3 +500,24 @@ func (d *DAG) GetPreviousNode(nodeID string) *NodeDef {
 	}
 	return d.Nodes[deps[0]]
 }
+
+// GetAncestors returns all transitive upstream node IDs, nearest first
+func (d *DAG) GetAncestors(nodeID string) []string {
+	var ancestors []string
+	seen := map[string]bool{nodeID: true}
+	frontier := []string{nodeID}
+	for len(frontier) > 0 {
+		var next []string
+		for _, id := range frontier {
+			for _, dep := range d.Deps[id] {
+				if !seen[dep] {
+					seen[dep] = true
+					ancestors = append(ancestors, dep)
+					next = append(next, dep)
+				}
+			}
+		}
+		frontier = next
+	}
+	return ancestors
+}
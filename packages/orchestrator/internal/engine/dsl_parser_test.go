@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApprovalsDefValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     ApprovalsDef
+		wantErr string
+	}{
+		{"default policy", ApprovalsDef{}, ""},
+		{"quorum", ApprovalsDef{Policy: ApprovalPolicyQuorum, Required: 2}, ""},
+		{"quorum within assignees", ApprovalsDef{Policy: ApprovalPolicyQuorum, Required: 2, Assignees: []string{"a", "b"}}, ""},
+		{"quorum exceeds assignees", ApprovalsDef{Policy: ApprovalPolicyQuorum, Required: 3, Assignees: []string{"a", "b"}}, "exceeds"},
+		{"required with the default policy", ApprovalsDef{Required: 2}, "needs policy quorum"},
+		{"required with policy any", ApprovalsDef{Policy: ApprovalPolicyAny, Required: 2}, "needs policy quorum"},
+		{"required with policy all", ApprovalsDef{Policy: ApprovalPolicyAll, Required: 2, Groups: []string{"admin"}}, "needs policy quorum"},
+		{"required 1 with the default policy", ApprovalsDef{Required: 1}, ""},
+		{"negative required", ApprovalsDef{Required: -1}, "must be positive"},
+		{"all without reviewers", ApprovalsDef{Policy: ApprovalPolicyAll}, "requires assignees or groups"},
+		{"unknown policy", ApprovalsDef{Policy: "most"}, "invalid policy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.def.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDSLChecksOutputSchemas(t *testing.T) {
+	const workflow = `
+name: review
+schemas:
+  verdict:
+    type: object
+    properties:
+      passed: { type: boolean }
+      %s
+nodes:
+  - id: review
+    type: agent_task
+    config:
+      output_schema: %s
+`
+	tests := []struct {
+		name    string
+		extra   string
+		ref     string
+		wantErr string
+	}{
+		{"named schema", "", "verdict", ""},
+		{"built-in schema", "", "review", ""},
+		{"unsupported keyword in a named schema", "link: { $ref: '#/defs/link' }", "verdict", `invalid schema "verdict": $.properties.link: unsupported keyword "$ref"`},
+		{"invalid pattern in a named schema", "name: { type: string, pattern: '([a-z' }", "verdict", "invalid pattern"},
+		{"unsupported keyword inline", "", "{ type: object, anyOf: [] }", `invalid output_schema: $: unsupported keyword "anyOf"`},
+		{"unknown name", "", "missing", `unknown output_schema "missing"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseDSL(fmt.Sprintf(workflow, tt.extra, tt.ref))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/sunshow/workgear/orchestrator/internal/agent"
 	"github.com/sunshow/workgear/orchestrator/internal/db"
 	"github.com/sunshow/workgear/orchestrator/internal/event"
+	"github.com/sunshow/workgear/orchestrator/internal/gitmirror"
+	"github.com/sunshow/workgear/orchestrator/internal/scheduler"
+	"github.com/sunshow/workgear/orchestrator/internal/secrets"
 )
 
 // FlowExecutor is the core engine that drives flow execution
@@ -23,6 +27,25 @@ type FlowExecutor struct {
 	logger   *zap.SugaredLogger
 	workerID string
 
+	// optional bare-mirror cache for task repositories (nil = disabled)
+	repoMirror *gitmirror.Manager
+
+	// Docker volumes for flows with a shared workspace (nil = unavailable)
+	workspaces *agent.WorkspaceVolumes
+
+	// scrubs secrets from events, timeline entries and errors; forked per agent execution
+	redactor *agent.Redactor
+
+	// durable timers (reminders, wait nodes, flow schedules) stored in the database
+	scheduler *scheduler.Scheduler
+
+	// runners for script nodes by executor kind (docker / local)
+	scriptRunners map[string]agent.ScriptRunner
+
+	// client for http_request nodes, and the store their {{secrets.NAME}} come from (nil = none)
+	httpClient *http.Client
+	secrets    secrets.Store
+
 	// per-flow cancel context management (for cancelling running containers)
 	flowCancels   map[string]context.CancelFunc
 	flowCancelsMu sync.Mutex
@@ -35,17 +58,67 @@ func NewFlowExecutor(
 	registry *agent.Registry,
 	logger *zap.SugaredLogger,
 ) *FlowExecutor {
-	return &FlowExecutor{
-		db:          dbClient,
-		eventBus:    eventBus,
-		registry:    registry,
-		logger:      logger,
-		workerID:    fmt.Sprintf("worker-%s", uuid.New().String()[:8]),
-		flowCancels: make(map[string]context.CancelFunc),
+	e := &FlowExecutor{
+		db:            dbClient,
+		eventBus:      eventBus,
+		registry:      registry,
+		logger:        logger,
+		workerID:      fmt.Sprintf("worker-%s", uuid.New().String()[:8]),
+		redactor:      agent.NewRedactor(nil),
+		scriptRunners: make(map[string]agent.ScriptRunner),
+		httpClient:    &http.Client{},
+		flowCancels:   make(map[string]context.CancelFunc),
+	}
+	e.SetScheduler(scheduler.New(dbClient, logger))
+	return e
+}
+
+// SetScheduler replaces the timer scheduler (e.g. one on a MemoryStore and FakeClock) and
+// registers the engine's timer handlers on it
+func (e *FlowExecutor) SetScheduler(s *scheduler.Scheduler) {
+	s.Handle(TimerKindNodeReminder, e.handleReminderTimer)
+	s.Handle(TimerKindNodeWait, e.handleWaitTimer)
+	s.Handle(TimerKindFlowSchedule, e.handleScheduleTimer)
+	s.Handle(TimerKindWorkspaceRelease, e.handleWorkspaceReleaseTimer)
+	e.scheduler = s
+}
+
+// SetRepoMirror enables the shared repository mirror: agent containers clone with
+// --reference against it, and artifact files are read from it instead of fresh clones
+func (e *FlowExecutor) SetRepoMirror(m *gitmirror.Manager) {
+	e.repoMirror = m
+}
+
+// SetWorkspaceVolumes enables shared per-flow workspaces (workflow-level `workspace: shared|snapshot`)
+func (e *FlowExecutor) SetWorkspaceVolumes(w *agent.WorkspaceVolumes) {
+	e.workspaces = w
+}
+
+// SetScriptRunner registers the runner for script nodes with `executor: <r.Kind()>`
+func (e *FlowExecutor) SetScriptRunner(r agent.ScriptRunner) {
+	e.scriptRunners[r.Kind()] = r
+}
+
+// SetHTTPClient replaces the client http_request nodes use (e.g. an httptest server's client)
+func (e *FlowExecutor) SetHTTPClient(c *http.Client) {
+	if c != nil {
+		e.httpClient = c
+	}
+}
+
+// SetSecretStore sets the store http_request nodes resolve {{secrets.NAME}} from
+func (e *FlowExecutor) SetSecretStore(s secrets.Store) {
+	e.secrets = s
+}
+
+// SetRedactor replaces the default secret redactor (e.g. with extra configured patterns)
+func (e *FlowExecutor) SetRedactor(r *agent.Redactor) {
+	if r != nil {
+		e.redactor = r
 	}
 }
 
-// Start initializes the executor: recovers stale state and starts the worker loop
+// Start initializes the executor: recovers stale state and starts the worker loop and the timer scheduler
 func (e *FlowExecutor) Start(ctx context.Context) error {
 	// 1. Recovery: reset stale RUNNING nodes from dead workers
 	count, err := e.db.ResetStaleRunningNodes(ctx)
@@ -60,6 +133,9 @@ func (e *FlowExecutor) Start(ctx context.Context) error {
 	e.logger.Infow("Starting worker loop", "worker_id", e.workerID)
 	go e.runWorkerLoop(ctx)
 
+	// 3. Start the timer scheduler (reminders, wait nodes, flow schedules)
+	go e.scheduler.Run(ctx)
+
 	return nil
 }
 
@@ -164,6 +240,12 @@ func (e *FlowExecutor) executeNode(ctx context.Context, nodeRun *db.NodeRun) err
 		return e.executeHumanReview(ctx, nodeRun)
 	case "human_input":
 		return e.executeHumanInput(ctx, nodeRun)
+	case "wait", "delay":
+		return e.executeWait(ctx, nodeRun)
+	case "script", "shell":
+		return e.executeScript(ctx, nodeRun)
+	case "http_request":
+		return e.executeHTTPRequest(ctx, nodeRun)
 	default:
 		return fmt.Errorf("unknown node type: %s", nodeType)
 	}
@@ -171,7 +253,7 @@ func (e *FlowExecutor) executeNode(ctx context.Context, nodeRun *db.NodeRun) err
 
 // handleNodeError marks a node as failed and publishes error event
 func (e *FlowExecutor) handleNodeError(ctx context.Context, nodeRun *db.NodeRun, execErr error) {
-	errMsg := execErr.Error()
+	errMsg := e.redactor.String(execErr.Error())
 	if err := e.db.UpdateNodeRunError(ctx, nodeRun.ID, db.StatusFailed, errMsg); err != nil {
 		e.logger.Errorw("Failed to update node error", "error", err)
 	}
@@ -189,6 +271,8 @@ func (e *FlowExecutor) handleNodeError(ctx context.Context, nodeRun *db.NodeRun,
 		"error":   errMsg,
 		"node_id": nodeRun.NodeID,
 	})
+
+	e.scheduleWorkspaceRelease(ctx, nodeRun.FlowRunID)
 }
 
 // publishEvent is a helper to publish events through the event bus
@@ -198,7 +282,7 @@ func (e *FlowExecutor) publishEvent(flowRunID, nodeRunID, nodeID, eventType stri
 		FlowRunID: flowRunID,
 		NodeRunID: nodeRunID,
 		NodeID:    nodeID,
-		Data:      data,
+		Data:      e.redactor.Map(data),
 	})
 }
 
@@ -0,0 +1,378 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Form field types of human_input nodes
+const (
+	FieldText        = "text"
+	FieldTextarea    = "textarea"
+	FieldNumber      = "number"
+	FieldBoolean     = "boolean"
+	FieldDate        = "date"
+	FieldSelect      = "select"
+	FieldMultiSelect = "multi_select"
+	FieldFileUpload  = "file_upload"
+)
+
+// normalizedType returns the field's type, defaulting to text ("multiselect" is accepted as
+// an alias of multi_select)
+func (f *FormFieldDef) normalizedType() string {
+	switch f.Type {
+	case "":
+		return FieldText
+	case "multiselect":
+		return FieldMultiSelect
+	}
+	return f.Type
+}
+
+// Validate checks a form field definition
+func (f *FormFieldDef) Validate() error {
+	if f.Field == "" {
+		return fmt.Errorf("field has no name")
+	}
+	switch f.normalizedType() {
+	case FieldText, FieldTextarea, FieldNumber, FieldBoolean, FieldDate, FieldFileUpload:
+	case FieldSelect, FieldMultiSelect:
+		if len(f.Options) == 0 && f.OptionsFrom == "" {
+			return fmt.Errorf("field %s: %s needs options or options_from", f.Field, f.Type)
+		}
+	default:
+		return fmt.Errorf("field %s: unknown type %q", f.Field, f.Type)
+	}
+	if f.Pattern != "" {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("field %s: invalid pattern: %w", f.Field, err)
+		}
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("field %s: min is greater than max", f.Field)
+	}
+	return nil
+}
+
+// FormValidationError lists the invalid fields of a human_input submission
+type FormValidationError struct {
+	Fields map[string]string // field → message
+}
+
+func (e *FormValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + ": " + e.Fields[field]
+	}
+	return "invalid input: " + strings.Join(parts, "; ")
+}
+
+// resolveForm renders templated options and defaults against the runtime context and expands
+// options_from. The result is what the form shows and what submissions are validated against.
+func resolveForm(fields []FormFieldDef, runtimeCtx map[string]any) []FormFieldDef {
+	resolved := make([]FormFieldDef, len(fields))
+	for i, f := range fields {
+		var options []string
+		for _, opt := range f.Options {
+			if rendered, err := RenderTemplate(opt, runtimeCtx); err == nil {
+				opt = rendered
+			}
+			options = append(options, opt)
+		}
+		if f.OptionsFrom != "" {
+			options = append(options, optionValues(lookupPath(runtimeCtx, f.OptionsFrom))...)
+		}
+		f.Options = dedupe(options)
+
+		if def, ok := f.Default.(string); ok {
+			if rendered, err := RenderTemplate(def, runtimeCtx); err == nil {
+				f.Default = rendered
+			}
+		}
+		resolved[i] = f
+	}
+	return resolved
+}
+
+// lookupPath resolves a dotted path (optionally wrapped in {{ }}) in the runtime context
+func lookupPath(ctx map[string]any, path string) any {
+	path = strings.TrimSpace(path)
+	path = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(path, "{{"), "}}"))
+	var current any = ctx
+	for _, key := range strings.Split(path, ".") {
+		switch m := current.(type) {
+		case map[string]any:
+			current = m[key]
+		case map[string]string:
+			current = m[key]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// optionValues converts an upstream value into options: arrays item by item, strings split on
+// newlines / commas
+func optionValues(value any) []string {
+	switch v := value.(type) {
+	case []any:
+		var options []string
+		for _, item := range v {
+			if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+				options = append(options, s)
+			}
+		}
+		return options
+	case []string:
+		return v
+	case string:
+		var options []string
+		for _, item := range strings.FieldsFunc(v, func(r rune) bool { return r == '\n' || r == ',' }) {
+			if s := strings.TrimSpace(item); s != "" {
+				options = append(options, s)
+			}
+		}
+		return options
+	}
+	return nil
+}
+
+func dedupe(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// humanInputForm returns the resolved form a human_input node run is waiting on: the one stored
+// in its input when it started waiting, else resolved from the DSL now
+func (e *FlowExecutor) humanInputForm(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun) []FormFieldDef {
+	var input struct {
+		Form []FormFieldDef `json:"_form"`
+	}
+	if nodeRun.Input != nil && json.Unmarshal([]byte(*nodeRun.Input), &input) == nil && input.Form != nil {
+		return input.Form
+	}
+	nodeDef, err := e.getNodeDef(flowRun, nodeRun.NodeID)
+	if err != nil || nodeDef.Config == nil || len(nodeDef.Config.Form) == 0 {
+		return nil
+	}
+	return resolveForm(nodeDef.Config.Form, e.buildRuntimeContext(ctx, flowRun, nodeRun))
+}
+
+// validateForm checks submitted data against the form, applies defaults and coerces values to
+// the field types. Fields the form does not declare are kept as submitted.
+func validateForm(fields []FormFieldDef, data map[string]any) (map[string]any, error) {
+	output := make(map[string]any, len(data))
+	for k, v := range data {
+		output[k] = v
+	}
+
+	errs := map[string]string{}
+	for _, f := range fields {
+		value, present := data[f.Field]
+		if !present || isEmptyValue(value) {
+			if f.Default != nil {
+				value, present = f.Default, true
+			} else {
+				present = false
+			}
+		}
+		if !present {
+			if f.Required {
+				errs[f.Field] = "is required"
+			}
+			delete(output, f.Field)
+			continue
+		}
+
+		coerced, err := coerceField(&f, value)
+		if err != nil {
+			errs[f.Field] = err.Error()
+			continue
+		}
+		output[f.Field] = coerced
+	}
+
+	if len(errs) > 0 {
+		return nil, &FormValidationError{Fields: errs}
+	}
+	return output, nil
+}
+
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// coerceField converts a submitted value to the field's type and checks its constraints
+func coerceField(f *FormFieldDef, value any) (any, error) {
+	switch f.normalizedType() {
+	case FieldNumber:
+		n, err := toNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		if f.Min != nil && n < *f.Min {
+			return nil, fmt.Errorf("must be at least %v", *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return nil, fmt.Errorf("must be at most %v", *f.Max)
+		}
+		return n, nil
+
+	case FieldBoolean:
+		return toBool(value)
+
+	case FieldDate:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a date (YYYY-MM-DD)")
+		}
+		s = strings.TrimSpace(s)
+		if d, err := time.Parse(time.DateOnly, s); err == nil {
+			return d.Format(time.DateOnly), nil
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.Format(time.RFC3339), nil
+		}
+		return nil, fmt.Errorf("must be a date (YYYY-MM-DD)")
+
+	case FieldSelect:
+		s, err := toText(value)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(f.Options, s) {
+			return nil, fmt.Errorf("%q is not one of the options", s)
+		}
+		return s, nil
+
+	case FieldMultiSelect:
+		selected, err := toList(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range selected {
+			if !slices.Contains(f.Options, s) {
+				return nil, fmt.Errorf("%q is not one of the options", s)
+			}
+		}
+		if f.Min != nil && float64(len(selected)) < *f.Min {
+			return nil, fmt.Errorf("select at least %v", *f.Min)
+		}
+		if f.Max != nil && float64(len(selected)) > *f.Max {
+			return nil, fmt.Errorf("select at most %v", *f.Max)
+		}
+		return selected, nil
+
+	case FieldFileUpload:
+		return value, nil
+	}
+
+	// text / textarea
+	s, err := toText(value)
+	if err != nil {
+		return nil, err
+	}
+	length := float64(utf8.RuneCountInString(s))
+	if f.Min != nil && length < *f.Min {
+		return nil, fmt.Errorf("must be at least %v characters", *f.Min)
+	}
+	if f.Max != nil && length > *f.Max {
+		return nil, fmt.Errorf("must be at most %v characters", *f.Max)
+	}
+	if f.Pattern != "" {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil || !re.MatchString(s) {
+			return nil, fmt.Errorf("does not match the pattern %s", f.Pattern)
+		}
+	}
+	return s, nil
+}
+
+func toNumber(value any) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("must be a number")
+}
+
+func toBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "yes", "on":
+			return true, nil
+		case "false", "0", "no", "off":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("must be true or false")
+}
+
+func toText(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64, int, bool:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("must be text")
+}
+
+// toList accepts an array of strings or a comma-separated string
+func toList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := toText(item)
+			if err != nil {
+				return nil, fmt.Errorf("must be a list of options")
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	case string:
+		return optionValues(v), nil
+	}
+	return nil, fmt.Errorf("must be a list of options")
+}
@@ -0,0 +1,245 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestCoerceField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   FormFieldDef
+		value   any
+		want    any
+		wantErr string
+	}{
+		// number
+		{"number", FormFieldDef{Type: FieldNumber}, 3.5, 3.5, ""},
+		{"number from int", FormFieldDef{Type: FieldNumber}, 7, 7.0, ""},
+		{"number from string", FormFieldDef{Type: FieldNumber}, " 42 ", 42.0, ""},
+		{"number at the bounds", FormFieldDef{Type: FieldNumber, Min: floatPtr(1), Max: floatPtr(5)}, 5.0, 5.0, ""},
+		{"number below min", FormFieldDef{Type: FieldNumber, Min: floatPtr(1)}, 0.5, nil, "must be at least 1"},
+		{"number above max", FormFieldDef{Type: FieldNumber, Max: floatPtr(5)}, "6", nil, "must be at most 5"},
+		{"number not numeric", FormFieldDef{Type: FieldNumber}, "many", nil, "must be a number"},
+		{"number from bool", FormFieldDef{Type: FieldNumber}, true, nil, "must be a number"},
+
+		// boolean
+		{"bool", FormFieldDef{Type: FieldBoolean}, true, true, ""},
+		{"bool from string", FormFieldDef{Type: FieldBoolean}, "Yes", true, ""},
+		{"bool off", FormFieldDef{Type: FieldBoolean}, "off", false, ""},
+		{"bool from number", FormFieldDef{Type: FieldBoolean}, 0.0, false, ""},
+		{"bool invalid", FormFieldDef{Type: FieldBoolean}, "maybe", nil, "must be true or false"},
+
+		// date
+		{"date", FormFieldDef{Type: FieldDate}, "2026-03-01", "2026-03-01", ""},
+		{"date with time", FormFieldDef{Type: FieldDate}, " 2026-03-01T09:30:00+08:00 ", "2026-03-01T09:30:00+08:00", ""},
+		{"date out of range", FormFieldDef{Type: FieldDate}, "2026-02-30", nil, "must be a date (YYYY-MM-DD)"},
+		{"date wrong format", FormFieldDef{Type: FieldDate}, "03/01/2026", nil, "must be a date (YYYY-MM-DD)"},
+		{"date not a string", FormFieldDef{Type: FieldDate}, 20260301.0, nil, "must be a date (YYYY-MM-DD)"},
+
+		// select
+		{"select", FormFieldDef{Type: FieldSelect, Options: []string{"low", "high"}}, "high", "high", ""},
+		{"select number option", FormFieldDef{Type: FieldSelect, Options: []string{"1", "2"}}, 2.0, "2", ""},
+		{"select unknown option", FormFieldDef{Type: FieldSelect, Options: []string{"low", "high"}}, "mid", nil, `"mid" is not one of the options`},
+		{"select list", FormFieldDef{Type: FieldSelect, Options: []string{"low"}}, []any{"low"}, nil, "must be text"},
+
+		// multi_select
+		{"multi select", FormFieldDef{Type: FieldMultiSelect, Options: []string{"api", "web", "db"}}, []any{"api", "db"}, []string{"api", "db"}, ""},
+		{"multi select alias and csv", FormFieldDef{Type: "multiselect", Options: []string{"api", "web"}}, "api, web", []string{"api", "web"}, ""},
+		{"multi select unknown option", FormFieldDef{Type: FieldMultiSelect, Options: []string{"api"}}, []any{"api", "ios"}, nil, `"ios" is not one of the options`},
+		{"multi select too few", FormFieldDef{Type: FieldMultiSelect, Options: []string{"api", "web"}, Min: floatPtr(2)}, []any{"api"}, nil, "select at least 2"},
+		{"multi select too many", FormFieldDef{Type: FieldMultiSelect, Options: []string{"api", "web"}, Max: floatPtr(1)}, []any{"api", "web"}, nil, "select at most 1"},
+		{"multi select not a list", FormFieldDef{Type: FieldMultiSelect, Options: []string{"api"}}, map[string]any{}, nil, "must be a list of options"},
+		{"multi select nested list", FormFieldDef{Type: FieldMultiSelect, Options: []string{"api"}}, []any{[]any{"api"}}, nil, "must be a list of options"},
+
+		// text
+		{"text", FormFieldDef{}, "hello", "hello", ""},
+		{"text length counts runes", FormFieldDef{Type: FieldTextarea, Max: floatPtr(4)}, "需求文档", "需求文档", ""},
+		{"text too short", FormFieldDef{Type: FieldText, Min: floatPtr(3)}, "ab", nil, "must be at least 3 characters"},
+		{"text too long", FormFieldDef{Type: FieldText, Max: floatPtr(3)}, "需求文档", nil, "must be at most 3 characters"},
+		{"text pattern", FormFieldDef{Type: FieldText, Pattern: `^[A-Z]+-\d+$`}, "PROJ-12", "PROJ-12", ""},
+		{"text pattern mismatch", FormFieldDef{Type: FieldText, Pattern: `^[A-Z]+-\d+$`}, "proj-12", nil, `does not match the pattern ^[A-Z]+-\d+$`},
+		{"text from number", FormFieldDef{Type: FieldText}, 12.0, "12", ""},
+		{"text not text", FormFieldDef{Type: FieldText}, []any{"a"}, nil, "must be text"},
+
+		// file_upload is passed through
+		{"file upload", FormFieldDef{Type: FieldFileUpload}, map[string]any{"path": "a.txt"}, map[string]any{"path": "a.txt"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.field.Field = "f"
+			got, err := coerceField(&tt.field, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateForm(t *testing.T) {
+	fields := []FormFieldDef{
+		{Field: "title", Type: FieldText, Required: true, Max: floatPtr(20)},
+		{Field: "points", Type: FieldNumber, Min: floatPtr(1), Max: floatPtr(13)},
+		{Field: "urgent", Type: FieldBoolean, Default: false},
+		{Field: "due", Type: FieldDate},
+		{Field: "priority", Type: FieldSelect, Options: []string{"P0", "P1"}, Required: true, Default: "P1"},
+		{Field: "modules", Type: FieldMultiSelect, Options: []string{"api", "web"}, Required: true},
+	}
+
+	tests := []struct {
+		name      string
+		data      map[string]any
+		want      map[string]any
+		wantError map[string]string
+	}{
+		{
+			name: "valid, with defaults and undeclared fields kept",
+			data: map[string]any{"title": "登录", "points": "3", "due": "2026-05-01", "modules": []any{"web"}, "note": "extra"},
+			want: map[string]any{
+				"title": "登录", "points": 3.0, "urgent": false, "due": "2026-05-01",
+				"priority": "P1", "modules": []string{"web"}, "note": "extra",
+			},
+		},
+		{
+			name: "empty optional fields are dropped",
+			data: map[string]any{"title": "x", "points": "", "due": nil, "urgent": true, "priority": "P0", "modules": "api"},
+			want: map[string]any{"title": "x", "urgent": true, "priority": "P0", "modules": []string{"api"}},
+		},
+		{
+			name:      "required fields missing or blank",
+			data:      map[string]any{"title": "  ", "modules": []any{}},
+			wantError: map[string]string{"title": "is required", "modules": "is required"},
+		},
+		{
+			name: "every invalid field is reported",
+			data: map[string]any{"title": "x", "points": 20.0, "urgent": "sometimes", "due": "tomorrow", "priority": "P3", "modules": []any{"ios"}},
+			wantError: map[string]string{
+				"points":   "must be at most 13",
+				"urgent":   "must be true or false",
+				"due":      "must be a date (YYYY-MM-DD)",
+				"priority": `"P3" is not one of the options`,
+				"modules":  `"ios" is not one of the options`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateForm(fields, tt.data)
+			if tt.wantError != nil {
+				var formErr *FormValidationError
+				if !errors.As(err, &formErr) {
+					t.Fatalf("err = %v, want a FormValidationError", err)
+				}
+				if !reflect.DeepEqual(formErr.Fields, tt.wantError) {
+					t.Errorf("field errors = %v, want %v", formErr.Fields, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("output = %#v\nwant %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormValidationErrorMessage(t *testing.T) {
+	err := &FormValidationError{Fields: map[string]string{"b": "is required", "a": "must be a number"}}
+	if got := err.Error(); got != "invalid input: a: must be a number; b: is required" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestResolveForm(t *testing.T) {
+	runtimeCtx := map[string]any{
+		"nodes": map[string]any{
+			"plan": map[string]any{"outputs": map[string]any{
+				"modules": []any{"api", "web", "api"},
+				"owners":  "alice, bob\ncarol",
+				"default": "web",
+			}},
+		},
+		"params": map[string]string{"env": "staging"},
+	}
+	fields := []FormFieldDef{
+		{Field: "modules", Type: FieldMultiSelect, OptionsFrom: "nodes.plan.outputs.modules"},
+		{Field: "owner", Type: FieldSelect, OptionsFrom: "{{ nodes.plan.outputs.owners }}"},
+		{Field: "env", Type: FieldSelect, Options: []string{"{{ params.env }}", "prod"}, Default: "{{ params.env }}"},
+		{Field: "target", Type: FieldSelect, Options: []string{"web"}, OptionsFrom: "nodes.plan.outputs.missing", Default: "{{ nodes.plan.outputs.default }}"},
+	}
+
+	resolved := resolveForm(fields, runtimeCtx)
+	wantOptions := map[string][]string{
+		"modules": {"api", "web"},
+		"owner":   {"alice", "bob", "carol"},
+		"env":     {"staging", "prod"},
+		"target":  {"web"},
+	}
+	for _, f := range resolved {
+		if !reflect.DeepEqual(f.Options, wantOptions[f.Field]) {
+			t.Errorf("%s options = %v, want %v", f.Field, f.Options, wantOptions[f.Field])
+		}
+	}
+	if resolved[2].Default != "staging" || resolved[3].Default != "web" {
+		t.Errorf("defaults = %v, %v", resolved[2].Default, resolved[3].Default)
+	}
+	// The DSL's definitions are not modified
+	if fields[2].Options[0] != "{{ params.env }}" {
+		t.Error("resolveForm modified its input")
+	}
+
+	// Submissions are validated against the resolved options
+	if _, err := validateForm(resolved, map[string]any{"owner": "bob", "modules": []any{"web"}}); err != nil {
+		t.Errorf("valid submission: %v", err)
+	}
+	_, err := validateForm(resolved, map[string]any{"owner": "dave", "env": "qa"})
+	var formErr *FormValidationError
+	if !errors.As(err, &formErr) || !reflect.DeepEqual(formErr.Fields, map[string]string{
+		"owner": `"dave" is not one of the options`,
+		"env":   `"qa" is not one of the options`,
+	}) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestFormFieldDefValidate(t *testing.T) {
+	tests := []struct {
+		field   FormFieldDef
+		wantErr string
+	}{
+		{FormFieldDef{Field: "a"}, ""},
+		{FormFieldDef{Field: "a", Type: FieldSelect, OptionsFrom: "nodes.x.outputs.y"}, ""},
+		{FormFieldDef{Type: FieldText}, "field has no name"},
+		{FormFieldDef{Field: "a", Type: "color"}, `field a: unknown type "color"`},
+		{FormFieldDef{Field: "a", Type: FieldMultiSelect}, "field a: multi_select needs options or options_from"},
+		{FormFieldDef{Field: "a", Pattern: "("}, "field a: invalid pattern"},
+		{FormFieldDef{Field: "a", Type: FieldNumber, Min: floatPtr(5), Max: floatPtr(1)}, "field a: min is greater than max"},
+	}
+	for _, tt := range tests {
+		err := tt.field.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%+v: unexpected error %v", tt.field, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("%+v: err = %v, want %q", tt.field, err, tt.wantErr)
+		}
+	}
+}
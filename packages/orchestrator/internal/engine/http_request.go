@@ -0,0 +1,550 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+	"github.com/sunshow/workgear/orchestrator/internal/secrets"
+)
+
+const (
+	defaultHTTPTimeout   = 30 * time.Second // per attempt
+	maxHTTPResponseBytes = 1 << 20          // larger responses fail the node
+	httpBodyOutputBytes  = 64 * 1024        // non-JSON bodies kept in outputs.body
+	maxHTTPRetryDelay    = time.Minute
+)
+
+// httpMethods are the methods an http_request node may use
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+
+// httpReservedOutputs are the outputs every http_request node sets; mappings may not reuse them
+var httpReservedOutputs = []string{"status", "ok", "body", "attempts", "duration_ms"}
+
+// templateExprPattern finds {{ ... }} expressions, secretRefPattern the secrets.NAME references in them
+var (
+	templateExprPattern = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	secretRefPattern    = regexp.MustCompile(`\bsecrets\.(\w+)`)
+)
+
+// validateHTTPRequest checks an http_request node's config and retry policy
+func validateHTTPRequest(cfg *NodeConfigDef, retry *RetryDef) error {
+	if cfg == nil || strings.TrimSpace(cfg.URL) == "" {
+		return fmt.Errorf("http_request needs a url")
+	}
+	if cfg.Method != "" && !slices.Contains(httpMethods, strings.ToUpper(cfg.Method)) {
+		return fmt.Errorf("unsupported method %q", cfg.Method)
+	}
+	if cfg.Body != "" && cfg.JSON != nil {
+		return fmt.Errorf("set only one of body and json")
+	}
+	if _, err := parseAllowStatus(cfg.AllowStatus); err != nil {
+		return err
+	}
+	for name, expr := range cfg.Outputs {
+		if name == "" || slices.Contains(httpReservedOutputs, name) {
+			return fmt.Errorf("outputs: %q is not a usable output name", name)
+		}
+		if _, err := parseJSONPath(expr); err != nil {
+			return fmt.Errorf("outputs.%s: %w", name, err)
+		}
+	}
+	if cfg.Timeout != "" {
+		if _, err := parseDelay(cfg.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	if _, err := newHTTPRetry(retry); err != nil {
+		return fmt.Errorf("retry: %w", err)
+	}
+	return nil
+}
+
+// parseAllowStatus parses allow_status entries: status codes (404) or classes ("4xx")
+func parseAllowStatus(entries []any) ([]string, error) {
+	patterns := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		pattern := strings.ToLower(strings.TrimSpace(fmt.Sprint(entry)))
+		valid := len(pattern) == 3 && pattern[0] >= '1' && pattern[0] <= '5'
+		for i := 1; valid && i < 3; i++ {
+			valid = pattern[i] == 'x' || (pattern[i] >= '0' && pattern[i] <= '9')
+		}
+		if !valid {
+			return nil, fmt.Errorf("allow_status: invalid status %q (e.g. 404 or \"4xx\")", fmt.Sprint(entry))
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// httpRetry is an http_request node's retry policy (node-level `retry`). Transport errors,
+// 429 and 5xx responses are retried; Retry-After overrides the backoff.
+type httpRetry struct {
+	maxAttempts int
+	delay       time.Duration
+	exponential bool
+}
+
+// newHTTPRetry builds the policy: no retry without `retry`; backoff is exponential (default,
+// from 1s), fixed (1s), none, or a fixed delay such as "5s"
+func newHTTPRetry(r *RetryDef) (httpRetry, error) {
+	if r == nil {
+		return httpRetry{maxAttempts: 1}, nil
+	}
+	p := httpRetry{maxAttempts: max(r.GetMaxAttempts(), 1)}
+	switch r.Backoff {
+	case "", "exponential":
+		p.delay, p.exponential = time.Second, true
+	case "fixed":
+		p.delay = time.Second
+	case "none":
+	default:
+		delay, err := parseDelay(r.Backoff)
+		if err != nil {
+			return httpRetry{}, fmt.Errorf("invalid backoff %q (exponential / fixed / none / a duration)", r.Backoff)
+		}
+		p.delay = delay
+	}
+	return p, nil
+}
+
+// wait returns the delay before the attempt after attempt (1-based). Exponential delays
+// double per attempt, stopping at maxHTTPRetryDelay so large attempt counts cannot overflow.
+func (p httpRetry) wait(attempt int) time.Duration {
+	delay := p.delay
+	for i := 1; p.exponential && i < attempt && delay < maxHTTPRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxHTTPRetryDelay)
+}
+
+// httpRequestSpec is a rendered http_request node, ready to send
+type httpRequestSpec struct {
+	Method      string
+	URL         string
+	Headers     map[string]string
+	Body        []byte
+	Timeout     time.Duration // per attempt
+	AllowStatus []string
+	Outputs     map[string]jsonPath
+	Retry       httpRetry
+}
+
+// secretRefs returns the secret names the node's templates reference as {{secrets.NAME}}
+func secretRefs(cfg *NodeConfigDef) []string {
+	var names []string
+	collect := func(tmpl string) {
+		for _, expr := range templateExprPattern.FindAllStringSubmatch(tmpl, -1) {
+			for _, ref := range secretRefPattern.FindAllStringSubmatch(expr[1], -1) {
+				if !slices.Contains(names, ref[1]) {
+					names = append(names, ref[1])
+				}
+			}
+		}
+	}
+	collect(cfg.URL)
+	collect(cfg.Body)
+	for _, v := range cfg.Headers {
+		collect(v)
+	}
+	walkJSONStrings(cfg.JSON, collect)
+	return names
+}
+
+// walkJSONStrings calls fn for every string in a decoded YAML / JSON value
+func walkJSONStrings(v any, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case map[string]any:
+		for _, item := range v {
+			walkJSONStrings(item, fn)
+		}
+	case []any:
+		for _, item := range v {
+			walkJSONStrings(item, fn)
+		}
+	}
+}
+
+// resolveHTTPSecrets looks up the secrets the node's templates reference and adds their
+// values to redactor. The result goes into the runtime context under "secrets", so the
+// values are only visible to this node's templates.
+func resolveHTTPSecrets(ctx context.Context, store secrets.Store, cfg *NodeConfigDef, redactor *agent.Redactor) (map[string]string, error) {
+	names := secretRefs(cfg)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if store == nil {
+		return nil, fmt.Errorf("node references secrets but no secret store is configured")
+	}
+	resolved := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := store.Get(ctx, name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return nil, fmt.Errorf("secret %s not found", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get secret %s: %w", name, err)
+		}
+		resolved[name] = value
+		redactor.AddSecrets(value)
+	}
+	return resolved, nil
+}
+
+// renderJSONTemplates renders every string in a decoded YAML value as a template
+func renderJSONTemplates(v any, runtimeCtx map[string]any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return RenderTemplate(v, runtimeCtx)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			rendered, err := renderJSONTemplates(item, runtimeCtx)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			rendered, err := renderJSONTemplates(item, runtimeCtx)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// renderHTTPRequest renders the node's URL, headers and body with the runtime context
+// (which carries the resolved secrets under "secrets")
+func renderHTTPRequest(cfg *NodeConfigDef, retry *RetryDef, runtimeCtx map[string]any) (*httpRequestSpec, error) {
+	if err := validateHTTPRequest(cfg, retry); err != nil {
+		return nil, err
+	}
+	spec := &httpRequestSpec{
+		Method:  strings.ToUpper(cfg.Method),
+		Headers: make(map[string]string, len(cfg.Headers)+1),
+		Timeout: defaultHTTPTimeout,
+		Outputs: make(map[string]jsonPath, len(cfg.Outputs)),
+	}
+	var err error
+	if spec.URL, err = RenderTemplate(cfg.URL, runtimeCtx); err != nil {
+		return nil, fmt.Errorf("render url: %w", err)
+	}
+	spec.URL = strings.TrimSpace(spec.URL)
+	for k, v := range cfg.Headers {
+		if spec.Headers[k], err = RenderTemplate(v, runtimeCtx); err != nil {
+			return nil, fmt.Errorf("render header %s: %w", k, err)
+		}
+	}
+	switch {
+	case cfg.JSON != nil:
+		rendered, err := renderJSONTemplates(cfg.JSON, runtimeCtx)
+		if err != nil {
+			return nil, fmt.Errorf("render json: %w", err)
+		}
+		if spec.Body, err = json.Marshal(rendered); err != nil {
+			return nil, fmt.Errorf("encode json: %w", err)
+		}
+		if !hasHeader(spec.Headers, "Content-Type") {
+			spec.Headers["Content-Type"] = "application/json"
+		}
+	case cfg.Body != "":
+		body, err := RenderTemplate(cfg.Body, runtimeCtx)
+		if err != nil {
+			return nil, fmt.Errorf("render body: %w", err)
+		}
+		spec.Body = []byte(body)
+	}
+	if spec.Method == "" {
+		spec.Method = http.MethodGet
+		if spec.Body != nil {
+			spec.Method = http.MethodPost
+		}
+	}
+	if cfg.Timeout != "" {
+		spec.Timeout, _ = parseDelay(cfg.Timeout)
+	}
+	spec.AllowStatus, _ = parseAllowStatus(cfg.AllowStatus)
+	for name, expr := range cfg.Outputs {
+		spec.Outputs[name], _ = parseJSONPath(expr)
+	}
+	spec.Retry, _ = newHTTPRetry(retry)
+	return spec, nil
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusAllowed reports whether a response status completes the node: 2xx or allow_status
+func (s *httpRequestSpec) statusAllowed(status int) bool {
+	if status >= 200 && status < 300 {
+		return true
+	}
+	code := strconv.Itoa(status)
+	for _, pattern := range s.AllowStatus {
+		match := len(code) == 3
+		for i := 0; match && i < 3; i++ {
+			match = pattern[i] == 'x' || pattern[i] == code[i]
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// httpAttempt describes one attempt of an http_request node (Err or Status is set)
+type httpAttempt struct {
+	Attempt    int
+	Status     int
+	Err        error
+	DurationMs int64
+	RetryIn    time.Duration // 0 = no further attempt
+}
+
+// httpResult is the final response of an http_request node
+type httpResult struct {
+	Status     int
+	Header     http.Header
+	Body       []byte
+	Attempts   int
+	DurationMs int64
+}
+
+// doHTTPRequest sends the request, retrying transport errors, 429 and 5xx responses that are
+// not allowed, per the spec's retry policy. An error means no usable response was received.
+func doHTTPRequest(ctx context.Context, client *http.Client, spec *httpRequestSpec, onAttempt func(httpAttempt)) (*httpResult, error) {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		attemptStart := time.Now()
+		res, retryAfter, err := sendHTTPRequest(ctx, client, spec)
+		info := httpAttempt{Attempt: attempt, Err: err, DurationMs: time.Since(attemptStart).Milliseconds()}
+		if res != nil {
+			info.Status = res.Status
+		}
+
+		retryable := ctx.Err() == nil && attempt < spec.Retry.maxAttempts
+		if err == nil {
+			retryable = retryable && !spec.statusAllowed(res.Status) &&
+				(res.Status == http.StatusTooManyRequests || res.Status >= 500)
+		} else {
+			retryable = retryable && !errors.Is(err, errHTTPResponseTooLarge)
+		}
+		if retryable {
+			info.RetryIn = spec.Retry.wait(attempt)
+			if retryAfter > 0 {
+				info.RetryIn = min(retryAfter, maxHTTPRetryDelay)
+			}
+		}
+		if onAttempt != nil {
+			onAttempt(info)
+		}
+
+		if !retryable {
+			if err != nil {
+				return nil, err
+			}
+			res.Attempts = attempt
+			res.DurationMs = time.Since(start).Milliseconds()
+			return res, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(info.RetryIn):
+		}
+	}
+}
+
+var errHTTPResponseTooLarge = fmt.Errorf("response exceeds %d bytes", maxHTTPResponseBytes)
+
+// sendHTTPRequest makes one attempt; retryAfter is the response's Retry-After in seconds, if any
+func sendHTTPRequest(ctx context.Context, client *http.Client, spec *httpRequestSpec) (*httpResult, time.Duration, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, spec.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if spec.Body != nil {
+		body = bytes.NewReader(spec.Body)
+	}
+	req, err := http.NewRequestWithContext(attemptCtx, spec.Method, spec.URL, body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("User-Agent", "workgear-orchestrator")
+	for k, v := range spec.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			return nil, 0, fmt.Errorf("%s %s: timed out after %s", spec.Method, spec.URL, spec.Timeout)
+		}
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPResponseBytes+1))
+	if err != nil {
+		return nil, 0, fmt.Errorf("read response: %w", err)
+	}
+	if len(data) > maxHTTPResponseBytes {
+		return nil, 0, errHTTPResponseTooLarge
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return &httpResult{Status: resp.StatusCode, Header: resp.Header, Body: data}, retryAfter, nil
+}
+
+// httpOutputs builds an http_request node's outputs: the status, the body (decoded when it
+// is JSON) and the configured JSONPath mappings (null when the path does not exist)
+func httpOutputs(spec *httpRequestSpec, res *httpResult) map[string]any {
+	output := map[string]any{
+		"status":      res.Status,
+		"ok":          res.Status >= 200 && res.Status < 300,
+		"attempts":    res.Attempts,
+		"duration_ms": res.DurationMs,
+	}
+
+	var body any
+	isJSON := len(bytes.TrimSpace(res.Body)) > 0 && json.Unmarshal(res.Body, &body) == nil
+	if isJSON {
+		output["body"] = body
+	} else {
+		output["body"] = truncateUTF8(string(res.Body), httpBodyOutputBytes)
+	}
+
+	for name, path := range spec.Outputs {
+		output[name] = nil
+		if isJSON {
+			if value, ok := path.eval(body); ok {
+				output[name] = value
+			}
+		}
+	}
+	return output
+}
+
+// httpStatusError is the error of a response whose status is not allowed, with the start of
+// the body for context (secrets scrubbed)
+func httpStatusError(spec *httpRequestSpec, res *httpResult, redactor *agent.Redactor) error {
+	snippet := truncateUTF8(strings.TrimSpace(string(res.Body)), 512)
+	return fmt.Errorf("%s %s returned HTTP %d: %s", spec.Method, redactor.String(spec.URL), res.Status, redactor.String(snippet))
+}
+
+// ─── http_request ───
+
+func (e *FlowExecutor) executeHTTPRequest(ctx context.Context, nodeRun *db.NodeRun) error {
+	// HTTP request: call an external system with the rendered request; non-2xx responses
+	// fail the node unless listed in allow_status
+
+	flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID)
+	if err != nil {
+		return fmt.Errorf("load flow run: %w", err)
+	}
+	nodeDef, err := e.getNodeDef(flowRun, nodeRun.NodeID)
+	if err != nil {
+		return err
+	}
+	if err := validateHTTPRequest(nodeDef.Config, nodeDef.Retry); err != nil {
+		return err
+	}
+
+	// 1. Resolve the referenced secrets; they are only visible to this node's templates
+	// and are scrubbed from its log, outputs and errors
+	redactor := e.redactor.Fork()
+	runtimeCtx := e.buildRuntimeContext(ctx, flowRun, nodeRun)
+	resolved, err := resolveHTTPSecrets(ctx, e.secrets, nodeDef.Config, redactor)
+	if err != nil {
+		return err
+	}
+	if resolved != nil {
+		runtimeCtx["secrets"] = resolved
+	}
+
+	spec, err := renderHTTPRequest(nodeDef.Config, nodeDef.Retry, runtimeCtx)
+	if err != nil {
+		return redactor.Error(err)
+	}
+
+	// 2. Send, logging every attempt to the node log
+	logWriter := e.newNodeLogWriter(ctx, nodeRun.ID, func(flatEvent map[string]any) {
+		e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.log_stream", flatEvent)
+	})
+	e.logger.Infow("Executing HTTP request", "node_id", nodeRun.NodeID, "method", spec.Method,
+		"url", redactor.String(spec.URL), "max_attempts", spec.Retry.maxAttempts)
+	res, err := doHTTPRequest(ctx, e.httpClient, spec, func(a httpAttempt) {
+		outcome := strconv.Itoa(a.Status)
+		if a.Err != nil {
+			outcome = a.Err.Error()
+		}
+		content := fmt.Sprintf("#%d %s %s → %s (%dms)", a.Attempt, spec.Method, spec.URL, outcome, a.DurationMs)
+		if a.RetryIn > 0 {
+			content += fmt.Sprintf("，%s 后重试", a.RetryIn)
+		}
+		logWriter.Append(map[string]any{
+			"type":      "http_request",
+			"content":   redactor.String(content),
+			"timestamp": time.Now().UnixMilli(),
+		})
+	})
+	logWriter.Close()
+	if err != nil {
+		return redactor.Error(err)
+	}
+
+	// 3. Save the outputs; a status that is not allowed fails the node after that
+	output := redactor.Map(httpOutputs(spec, res))
+	if err := e.db.UpdateNodeRunOutput(ctx, nodeRun.ID, output); err != nil {
+		return fmt.Errorf("save output: %w", err)
+	}
+	if !spec.statusAllowed(res.Status) {
+		return httpStatusError(spec, res, redactor)
+	}
+
+	if err := e.db.UpdateNodeRunStatus(ctx, nodeRun.ID, db.StatusCompleted); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.completed", map[string]any{
+		"output": output,
+	})
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "http_request_completed", map[string]any{
+		"node_id":   nodeRun.NodeID,
+		"node_name": ptrStr(nodeRun.NodeName),
+		"method":    spec.Method,
+		"status":    res.Status,
+		"attempts":  res.Attempts,
+		"message":   fmt.Sprintf("HTTP 请求完成：%s（%s %d）", ptrStr(nodeRun.NodeName), spec.Method, res.Status),
+	})
+	return nil
+}
@@ -0,0 +1,334 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/secrets"
+)
+
+const testToken = "jira-token-0123456789"
+
+// httpStub answers with the queued responses in turn (the last one repeats) and records
+// the requests it received
+type httpStub struct {
+	mu        sync.Mutex
+	responses []stubResponse
+	requests  []stubRequest
+}
+
+type stubResponse struct {
+	status     int
+	body       string
+	retryAfter string
+	delay      time.Duration
+}
+
+type stubRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   string
+}
+
+func (s *httpStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, stubRequest{method: r.Method, path: r.URL.RequestURI(), header: r.Header.Clone(), body: string(body)})
+	resp := s.responses[min(len(s.requests), len(s.responses))-1]
+	s.mu.Unlock()
+
+	if resp.delay > 0 {
+		select {
+		case <-time.After(resp.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if resp.retryAfter != "" {
+		w.Header().Set("Retry-After", resp.retryAfter)
+	}
+	w.WriteHeader(resp.status)
+	fmt.Fprint(w, resp.body)
+}
+
+func (s *httpStub) received() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubRequest(nil), s.requests...)
+}
+
+func newHTTPStub(t *testing.T, responses ...stubResponse) (*httpStub, *httptest.Server) {
+	t.Helper()
+	stub := &httpStub{responses: responses}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return stub, srv
+}
+
+// renderTestRequest resolves the node's secrets from store and renders the request
+func renderTestRequest(t *testing.T, cfg *NodeConfigDef, retry *RetryDef, store secrets.Store) (*httpRequestSpec, *agent.Redactor) {
+	t.Helper()
+	redactor := agent.NewRedactor(nil)
+	runtimeCtx := map[string]any{
+		"task":  map[string]any{"title": "Add login"},
+		"nodes": map[string]any{"plan": map[string]any{"outputs": map[string]any{"change_name": "add-login"}}},
+	}
+	resolved, err := resolveHTTPSecrets(context.Background(), store, cfg, redactor)
+	if err != nil {
+		t.Fatalf("resolve secrets: %v", err)
+	}
+	if resolved != nil {
+		runtimeCtx["secrets"] = resolved
+	}
+	spec, err := renderHTTPRequest(cfg, retry, runtimeCtx)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	return spec, redactor
+}
+
+func TestHTTPRequestTemplatingAndSecrets(t *testing.T) {
+	stub, srv := newHTTPStub(t, stubResponse{status: http.StatusCreated, body: `{"key":"WG-1"}`})
+	cfg := &NodeConfigDef{
+		URL:     srv.URL + "/issues/{{nodes.plan.outputs.change_name}}",
+		Headers: map[string]string{"Authorization": "Bearer {{secrets.JIRA_TOKEN}}"},
+		JSON: map[string]any{
+			"summary": "{{task.title}}",
+			"labels":  []any{"{{nodes.plan.outputs.change_name}}", "workgear"},
+		},
+	}
+	spec, _ := renderTestRequest(t, cfg, nil, secrets.MapStore{"JIRA_TOKEN": testToken})
+	res, err := doHTTPRequest(context.Background(), srv.Client(), spec, nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if res.Status != http.StatusCreated || res.Attempts != 1 {
+		t.Errorf("status = %d, attempts = %d", res.Status, res.Attempts)
+	}
+
+	req := stub.received()[0]
+	if req.method != http.MethodPost || req.path != "/issues/add-login" {
+		t.Errorf("request = %s %s, want POST /issues/add-login", req.method, req.path)
+	}
+	if got := req.header.Get("Authorization"); got != "Bearer "+testToken {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(req.body), &body); err != nil {
+		t.Fatalf("body %q: %v", req.body, err)
+	}
+	if body["summary"] != "Add login" || fmt.Sprint(body["labels"]) != "[add-login workgear]" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestHTTPRequestSecretErrors(t *testing.T) {
+	cfg := &NodeConfigDef{URL: "https://example.com/?token={{secrets.MISSING}}"}
+	redactor := agent.NewRedactor(nil)
+	if _, err := resolveHTTPSecrets(context.Background(), secrets.MapStore{}, cfg, redactor); err == nil || err.Error() != "secret MISSING not found" {
+		t.Errorf("missing secret: err = %v", err)
+	}
+	if _, err := resolveHTTPSecrets(context.Background(), nil, cfg, redactor); err == nil || !strings.Contains(err.Error(), "no secret store") {
+		t.Errorf("no store: err = %v", err)
+	}
+	// Nodes without secret references need no store
+	if resolved, err := resolveHTTPSecrets(context.Background(), nil, &NodeConfigDef{URL: "https://example.com"}, redactor); err != nil || resolved != nil {
+		t.Errorf("no references: %v, %v", resolved, err)
+	}
+}
+
+func TestHTTPRequestRetry(t *testing.T) {
+	stub, srv := newHTTPStub(t,
+		stubResponse{status: http.StatusServiceUnavailable},
+		stubResponse{status: http.StatusTooManyRequests, retryAfter: "1"},
+		stubResponse{status: http.StatusOK, body: `{"done":true}`},
+	)
+	spec, _ := renderTestRequest(t, &NodeConfigDef{URL: srv.URL}, &RetryDef{MaxAttempts: 3, Backoff: "none"}, nil)
+
+	var attempts []httpAttempt
+	start := time.Now()
+	res, err := doHTTPRequest(context.Background(), srv.Client(), spec, func(a httpAttempt) { attempts = append(attempts, a) })
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if res.Status != http.StatusOK || res.Attempts != 3 || len(stub.received()) != 3 {
+		t.Fatalf("status = %d, attempts = %d, requests = %d", res.Status, res.Attempts, len(stub.received()))
+	}
+	// Retry-After overrides the configured backoff
+	if attempts[0].Status != http.StatusServiceUnavailable || attempts[0].RetryIn != 0 {
+		t.Errorf("attempt 1 = %+v", attempts[0])
+	}
+	if attempts[1].Status != http.StatusTooManyRequests || attempts[1].RetryIn != time.Second {
+		t.Errorf("attempt 2 = %+v, want a retry in 1s", attempts[1])
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, before Retry-After", elapsed)
+	}
+}
+
+func TestHTTPRequestRetryGivesUp(t *testing.T) {
+	stub, srv := newHTTPStub(t, stubResponse{status: http.StatusBadGateway, body: "bad gateway"})
+	spec, _ := renderTestRequest(t, &NodeConfigDef{URL: srv.URL}, &RetryDef{MaxAttempts: 2, Backoff: "none"}, nil)
+	res, err := doHTTPRequest(context.Background(), srv.Client(), spec, nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if res.Status != http.StatusBadGateway || res.Attempts != 2 || len(stub.received()) != 2 {
+		t.Errorf("status = %d, attempts = %d, requests = %d", res.Status, res.Attempts, len(stub.received()))
+	}
+
+	// Other client errors are not retried
+	stub, srv = newHTTPStub(t, stubResponse{status: http.StatusBadRequest})
+	spec, _ = renderTestRequest(t, &NodeConfigDef{URL: srv.URL}, &RetryDef{MaxAttempts: 3, Backoff: "none"}, nil)
+	if res, err := doHTTPRequest(context.Background(), srv.Client(), spec, nil); err != nil || res.Attempts != 1 || len(stub.received()) != 1 {
+		t.Errorf("400: res = %+v, err = %v, requests = %d", res, err, len(stub.received()))
+	}
+}
+
+func TestHTTPRetryWait(t *testing.T) {
+	exponential, _ := newHTTPRetry(&RetryDef{})
+	fixed, _ := newHTTPRetry(&RetryDef{Backoff: "5s"})
+	tests := []struct {
+		name    string
+		retry   httpRetry
+		attempt int
+		want    time.Duration
+	}{
+		{"exponential first", exponential, 1, time.Second},
+		{"exponential third", exponential, 3, 4 * time.Second},
+		{"exponential capped", exponential, 7, maxHTTPRetryDelay},
+		{"exponential does not overflow", exponential, 200, maxHTTPRetryDelay},
+		{"fixed", fixed, 9, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.retry.wait(tt.attempt); got != tt.want {
+			t.Errorf("%s: wait(%d) = %s, want %s", tt.name, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPRequestAllowStatus(t *testing.T) {
+	spec, _ := renderTestRequest(t, &NodeConfigDef{URL: "https://example.com", AllowStatus: []any{404, "3xx"}}, nil, nil)
+	for status, want := range map[int]bool{200: true, 204: true, 301: true, 304: true, 404: true, 409: false, 500: false} {
+		if got := spec.statusAllowed(status); got != want {
+			t.Errorf("statusAllowed(%d) = %v, want %v", status, got, want)
+		}
+	}
+
+	// An allowed 5xx is final, not retried
+	stub, srv := newHTTPStub(t, stubResponse{status: http.StatusServiceUnavailable})
+	spec, _ = renderTestRequest(t, &NodeConfigDef{URL: srv.URL, AllowStatus: []any{"5xx"}}, &RetryDef{MaxAttempts: 3, Backoff: "none"}, nil)
+	if res, err := doHTTPRequest(context.Background(), srv.Client(), spec, nil); err != nil || res.Attempts != 1 || len(stub.received()) != 1 {
+		t.Errorf("allowed 503: res = %+v, err = %v, requests = %d", res, err, len(stub.received()))
+	}
+
+	if _, err := parseAllowStatus([]any{"4x"}); err == nil {
+		t.Error("invalid allow_status accepted")
+	}
+}
+
+func TestHTTPRequestOutputs(t *testing.T) {
+	cfg := &NodeConfigDef{
+		URL: "https://example.com",
+		Outputs: map[string]string{
+			"key":     "$.key",
+			"first":   "$.fields.labels[0]",
+			"last":    "$['fields']['labels'][-1]",
+			"names":   "$.comments[*].author.name",
+			"missing": "$.fields.assignee",
+		},
+	}
+	spec, _ := renderTestRequest(t, cfg, nil, nil)
+	res := &httpResult{Status: http.StatusOK, Attempts: 1, Body: []byte(`{
+		"key": "WG-1",
+		"fields": {"labels": ["a", "b", "c"]},
+		"comments": [{"author": {"name": "x"}}, {"author": {"name": "y"}}, {"body": "no author"}]
+	}`)}
+	output := httpOutputs(spec, res)
+	want := map[string]any{"key": "WG-1", "first": "a", "last": "c", "names": []any{"x", "y"}, "missing": nil}
+	for name, value := range want {
+		if fmt.Sprint(output[name]) != fmt.Sprint(value) {
+			t.Errorf("outputs.%s = %v, want %v", name, output[name], value)
+		}
+	}
+	if output["status"] != http.StatusOK || output["ok"] != true || output["attempts"] != 1 {
+		t.Errorf("built-in outputs = %v", output)
+	}
+
+	// Non-JSON bodies are kept as text and leave the mappings null
+	output = httpOutputs(spec, &httpResult{Status: http.StatusOK, Body: []byte("plain text")})
+	if output["body"] != "plain text" || output["key"] != nil {
+		t.Errorf("text response outputs = %v", output)
+	}
+
+	if err := validateHTTPRequest(&NodeConfigDef{URL: "https://example.com", Outputs: map[string]string{"status": "$.status"}}, nil); err == nil {
+		t.Error("output reusing a built-in name accepted")
+	}
+	if err := validateHTTPRequest(&NodeConfigDef{URL: "https://example.com", Outputs: map[string]string{"key": "key"}}, nil); err == nil {
+		t.Error("output with an invalid JSONPath accepted")
+	}
+}
+
+func TestHTTPRequestRedaction(t *testing.T) {
+	_, srv := newHTTPStub(t, stubResponse{status: http.StatusUnauthorized, body: `{"error":"invalid token ` + testToken + `"}`})
+	cfg := &NodeConfigDef{
+		URL:     srv.URL + "/search?token={{secrets.JIRA_TOKEN}}",
+		Outputs: map[string]string{"error": "$.error"},
+	}
+	spec, redactor := renderTestRequest(t, cfg, nil, secrets.MapStore{"JIRA_TOKEN": testToken})
+	res, err := doHTTPRequest(context.Background(), srv.Client(), spec, nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+
+	output := redactor.Map(httpOutputs(spec, res))
+	if data, _ := json.Marshal(output); strings.Contains(string(data), testToken) {
+		t.Errorf("outputs leak the secret: %s", data)
+	}
+	if output["error"] == nil {
+		t.Errorf("outputs.error = nil, want the redacted message")
+	}
+
+	statusErr := httpStatusError(spec, res, redactor)
+	if !strings.Contains(statusErr.Error(), "returned HTTP 401") || strings.Contains(statusErr.Error(), testToken) {
+		t.Errorf("status error = %v", statusErr)
+	}
+}
+
+func TestHTTPRequestTimeout(t *testing.T) {
+	stub, srv := newHTTPStub(t, stubResponse{status: http.StatusOK, delay: time.Second})
+	cfg := &NodeConfigDef{URL: srv.URL + "/?token={{secrets.JIRA_TOKEN}}", Timeout: "100ms"}
+	spec, redactor := renderTestRequest(t, cfg, &RetryDef{MaxAttempts: 2, Backoff: "none"}, secrets.MapStore{"JIRA_TOKEN": testToken})
+	if spec.Timeout != 100*time.Millisecond {
+		t.Fatalf("timeout = %s", spec.Timeout)
+	}
+
+	start := time.Now()
+	_, err := doHTTPRequest(context.Background(), srv.Client(), spec, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Fatalf("err = %v, want a timeout", err)
+	}
+	// Each attempt times out on its own, and a timeout is retried like a transport error
+	if len(stub.received()) != 2 {
+		t.Errorf("requests = %d, want 2", len(stub.received()))
+	}
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("timed out after %s", elapsed)
+	}
+	if redacted := redactor.Error(err).Error(); strings.Contains(redacted, testToken) {
+		t.Errorf("timeout error leaks the secret: %s", redacted)
+	}
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath-style expression over decoded JSON: `$` followed by
+// `.name`, `['name']`, `[index]` (negative counts from the end) and `[*]` / `.*` steps.
+// A wildcard collects the rest of the path over every element into an array.
+type jsonPath []jsonPathStep
+
+type jsonPathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parseJSONPath parses expr ("$" alone selects the whole document)
+func parseJSONPath(expr string) (jsonPath, error) {
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", expr)
+	}
+	s = s[1:]
+
+	var path jsonPath
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := s[:end]
+			if name == "" {
+				return nil, fmt.Errorf("json path %q: empty name", expr)
+			}
+			if name == "*" {
+				path = append(path, jsonPathStep{wildcard: true})
+			} else {
+				path = append(path, jsonPathStep{key: name})
+			}
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("json path %q: unclosed [", expr)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			switch {
+			case inner == "*":
+				path = append(path, jsonPathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, jsonPathStep{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("json path %q: invalid index %q", expr, inner)
+				}
+				path = append(path, jsonPathStep{index: index, isIndex: true})
+			}
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("json path %q: unexpected %q", expr, s[:1])
+		}
+	}
+	return path, nil
+}
+
+// eval returns the value at the path in doc; ok is false when the path does not exist
+func (p jsonPath) eval(doc any) (any, bool) {
+	cur := doc
+	for i, step := range p {
+		switch {
+		case step.wildcard:
+			var items []any
+			switch v := cur.(type) {
+			case []any:
+				items = v
+			case map[string]any:
+				for _, item := range v {
+					items = append(items, item)
+				}
+			default:
+				return nil, false
+			}
+			results := []any{}
+			for _, item := range items {
+				if value, ok := p[i+1:].eval(item); ok {
+					results = append(results, value)
+				}
+			}
+			return results, true
+		case step.isIndex:
+			arr, ok := cur.([]any)
+			if !ok {
+				return nil, false
+			}
+			index := step.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index < 0 || index >= len(arr) {
+				return nil, false
+			}
+			cur = arr[index]
+		default:
+			obj, ok := cur.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if cur, ok = obj[step.key]; !ok {
+				return nil, false
+			}
+		}
+	}
+	return cur, true
+}
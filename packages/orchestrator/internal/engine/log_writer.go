@@ -0,0 +1,223 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Log persistence limits
+const (
+	logFlushBatch    = 50              // flush when this many events are buffered
+	logFlushInterval = time.Second     // ...or at least this often
+	logMaxFieldBytes = 16 * 1024       // per-field cap for content / result / tool_input
+	logMaxTotalBytes = 8 * 1024 * 1024 // per-node-run cap; later events are dropped
+	logCloseAttempts = 3               // final flush attempts on Close before buffered events are given up
+)
+
+// nodeLogWriter appends a node run's log events to node_run_logs in batches.
+// Each event gets a seq number (also published with the live event, so clients can
+// page history and dedupe against the stream). Oversized fields are truncated and,
+// once the node run's log cap is reached, a single marker event is written and the
+// rest are dropped. A batch the database rejects stays buffered and is retried with the
+// next flush.
+type nodeLogWriter struct {
+	db        *db.Client
+	nodeRunID string
+	onEvent   func(event map[string]any) // called for every accepted event (real-time push)
+	logf      func(msg string, keysAndValues ...any)
+
+	mu         sync.Mutex
+	pending    []string
+	firstSeq   int64 // seq of pending[0]
+	nextSeq    int64
+	totalBytes int64
+	capped     bool
+
+	flushMu sync.Mutex // serializes flushes, so a failed batch can be put back in seq order
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newNodeLogWriter starts a log writer for the node run, resuming after any events
+// already stored (e.g. when a stale node run is re-executed after a restart)
+func (e *FlowExecutor) newNodeLogWriter(ctx context.Context, nodeRunID string, onEvent func(map[string]any)) *nodeLogWriter {
+	maxSeq, totalBytes, err := e.db.GetNodeRunLogStats(ctx, nodeRunID)
+	if err != nil {
+		e.logger.Warnw("Failed to read node log stats", "node_run_id", nodeRunID, "error", err)
+	}
+
+	w := &nodeLogWriter{
+		db:         e.db,
+		nodeRunID:  nodeRunID,
+		onEvent:    onEvent,
+		logf:       e.logger.Warnw,
+		nextSeq:    maxSeq + 1,
+		totalBytes: totalBytes,
+		capped:     totalBytes >= logMaxTotalBytes,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Append assigns the event a seq, truncates oversized fields, buffers it for
+// persistence and forwards it to onEvent
+func (w *nodeLogWriter) Append(event map[string]any) {
+	w.mu.Lock()
+	if w.capped {
+		w.mu.Unlock()
+		return
+	}
+
+	truncateLogFields(event)
+	event["seq"] = w.nextSeq
+	data, err := json.Marshal(event)
+	if err != nil {
+		w.mu.Unlock()
+		w.logf("Failed to marshal log event", "node_run_id", w.nodeRunID, "error", err)
+		return
+	}
+
+	if w.totalBytes+int64(len(data)) > logMaxTotalBytes {
+		// Replace this and all further events with one marker
+		w.capped = true
+		event = map[string]any{
+			"type":      "truncated",
+			"timestamp": time.Now().UnixMilli(),
+			"seq":       w.nextSeq,
+			"content":   fmt.Sprintf("日志已达上限 (%d MB)，后续日志不再记录", logMaxTotalBytes/1024/1024),
+		}
+		data, _ = json.Marshal(event)
+	}
+
+	if len(w.pending) == 0 {
+		w.firstSeq = w.nextSeq
+	}
+	w.pending = append(w.pending, string(data))
+	w.nextSeq++
+	w.totalBytes += int64(len(data))
+	shouldFlush := len(w.pending)%logFlushBatch == 0 // also paces retries while batches keep failing
+	w.mu.Unlock()
+
+	if w.onEvent != nil {
+		w.onEvent(event)
+	}
+	if shouldFlush {
+		_ = w.flush()
+	}
+}
+
+// Close stops the periodic flush and writes any buffered events, retrying briefly
+func (w *nodeLogWriter) Close() {
+	close(w.stop)
+	<-w.done
+	for attempt := 1; w.flush() != nil; attempt++ {
+		if attempt == logCloseAttempts {
+			w.mu.Lock()
+			dropped := len(w.pending)
+			w.pending = nil
+			w.mu.Unlock()
+			w.logf("Giving up on unpersisted log events", "node_run_id", w.nodeRunID, "count", dropped)
+			return
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+func (w *nodeLogWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			_ = w.flush()
+		}
+	}
+}
+
+// flush writes buffered events in one batch. Uses a background context so logs of a
+// cancelled node run are still persisted. On failure the batch is put back in front of
+// the events buffered since, so nothing is lost while the database is unavailable.
+func (w *nodeLogWriter) flush() error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	if len(w.pending) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+	batch, firstSeq := w.pending, w.firstSeq
+	w.pending = nil
+	w.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := w.db.AppendNodeRunLogs(ctx, w.nodeRunID, firstSeq, batch); err != nil {
+		w.logf("Failed to persist log events, will retry", "node_run_id", w.nodeRunID, "count", len(batch), "error", err)
+		w.mu.Lock()
+		w.pending = append(batch, w.pending...)
+		w.firstSeq = firstSeq
+		w.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// truncateLogFields caps large payload fields, marking the event as truncated
+func truncateLogFields(event map[string]any) {
+	for _, key := range []string{"content", "result", "tool_input"} {
+		value, ok := event[key]
+		if !ok || value == nil {
+			continue
+		}
+		text, isString := value.(string)
+		if !isString {
+			raw, err := json.Marshal(value)
+			if err != nil || len(raw) <= logMaxFieldBytes {
+				continue
+			}
+			text = string(raw)
+		}
+		if len(text) <= logMaxFieldBytes {
+			continue
+		}
+		event[key] = truncateUTF8(text, logMaxFieldBytes) +
+			fmt.Sprintf("\n…[已截断 %d 字节]", len(text)-logMaxFieldBytes)
+		event["truncated"] = true
+	}
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// GetNodeRunLogs returns up to limit persisted log events after afterSeq, and whether more exist
+func (e *FlowExecutor) GetNodeRunLogs(ctx context.Context, nodeRunID string, afterSeq int64, limit int) ([]*db.NodeRunLog, bool, error) {
+	logs, err := e.db.GetNodeRunLogs(ctx, nodeRunID, afterSeq, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(logs) > limit {
+		return logs[:limit], true, nil
+	}
+	return logs, false, nil
+}
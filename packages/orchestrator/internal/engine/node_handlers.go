@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/sunshow/workgear/orchestrator/internal/agent"
 	"github.com/sunshow/workgear/orchestrator/internal/db"
+	"github.com/sunshow/workgear/orchestrator/internal/scheduler"
 )
 
 // ─── agent_task ───
@@ -32,19 +34,22 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 	runtimeCtx := e.buildRuntimeContext(ctx, flowRun, nodeRun)
 
 	// 3. Resolve agent role (may contain template vars, render it)
-	role := "general-developer"
+	role := defaultAgentRole
 	if nodeDef.Agent != nil && nodeDef.Agent.Role != "" {
-		role = nodeDef.Agent.Role
-		// Render role in case it contains template variables
-		if rendered, err := RenderTemplate(role, runtimeCtx); err == nil {
+		if rendered := renderOrRaw(nodeDef.Agent.Role, runtimeCtx); rendered != "" {
 			role = rendered
 		}
 	}
 
-	// 4. Get adapter from registry (now returns model name too)
-	adapter, registryModel, err := e.registry.GetAdapterForRole(role)
+	// 4. Resolve adapter and model: DSL provider/model override > role mapping > adapter default,
+	// checked against the provider's models and the workflow's allowed_models
+	wf, dag, err := ParseDSL(*flowRun.DslSnapshot)
 	if err != nil {
-		return fmt.Errorf("get agent adapter: %w", err)
+		return fmt.Errorf("parse DSL: %w", err)
+	}
+	adapter, model, err := e.resolveNodeAdapter(ctx, wf, nodeDef.Agent, role, runtimeCtx)
+	if err != nil {
+		return err
 	}
 
 	// 5. Get role config from database
@@ -53,24 +58,21 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		e.logger.Warnw("Failed to get role config from DB", "role", role, "error", err)
 	}
 
-	// 6. Determine model: DSL explicit config > registry mapping > adapter default
-	model := ""
-	if nodeDef.Agent != nil && nodeDef.Agent.Model != "" {
-		model = nodeDef.Agent.Model
-		// Render model in case it contains template variables
-		if rendered, err := RenderTemplate(model, runtimeCtx); err == nil {
-			model = rendered
-		}
-	}
-	if model == "" && registryModel != "" {
-		model = registryModel
-	}
+	// 6. Prompt library: {% include "name@vN" %} in prompt_template plus per-project
+	// role/<slug> and mode/<mode> overrides; every version used is recorded on the node run
+	promptLib := e.newPromptLibrary(ctx, flowRun)
 
-	// 7. Use system prompt from database (overrides hardcoded prompt)
+	// 7. Use system prompt from database (overrides hardcoded prompt); a project's
+	// role/<slug> library template overrides both
 	rolePrompt := ""
 	if roleConfig != nil {
 		rolePrompt = roleConfig.SystemPrompt
 	}
+	if override, ok, err := promptLib.Override(rolePromptPrefix+role, runtimeCtx); err != nil {
+		e.logger.Warnw("Failed to load role prompt override", "role", role, "error", err)
+	} else if ok {
+		rolePrompt = override
+	}
 
 	// 8. Build agent request
 	mode := "execute"
@@ -81,7 +83,7 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		}
 		// Render prompt_template with runtime context
 		if nodeDef.Config.PromptTemplate != "" {
-			rendered, err := RenderTemplate(nodeDef.Config.PromptTemplate, runtimeCtx)
+			rendered, err := promptLib.Render(nodeDef.Config.PromptTemplate, runtimeCtx)
 			if err != nil {
 				e.logger.Warnw("Failed to render prompt template", "error", err)
 				prompt = nodeDef.Config.PromptTemplate // fallback to original
@@ -90,6 +92,13 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 			}
 		}
 	}
+	modeInstruction, _, err := promptLib.Override(modeInstructionPrefix+mode, runtimeCtx)
+	if err != nil {
+		e.logger.Warnw("Failed to load mode instruction override", "mode", mode, "error", err)
+	}
+	if err := e.db.UpdateNodeRunPromptVersions(ctx, nodeRun.ID, promptLib.Versions()); err != nil {
+		e.logger.Warnw("Failed to record prompt template versions", "node_run_id", nodeRun.ID, "error", err)
+	}
 
 	// Parse input context
 	var inputCtx map[string]any
@@ -106,13 +115,14 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		e.logger.Warnw("Failed to get git info", "error", err)
 	}
 
-	// Extract feedback from context (for reject/retry scenarios)
+	// Extract feedback from context (for reject/retry scenarios), with the structured review
 	feedback := ""
 	if fb, ok := inputCtx["_feedback"]; ok {
 		if fbStr, ok := fb.(string); ok {
 			feedback = fbStr
 		}
 	}
+	feedback = formatReviewFeedback(feedback, reviewFromInput(inputCtx))
 
 	// Store role in context for prompt builder
 	inputCtx["_role"] = role
@@ -122,6 +132,7 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		FlowRunID:     nodeRun.FlowRunID,
 		NodeID:         nodeRun.NodeID,
 		Mode:           mode,
+		ModeInstruction: modeInstruction,
 		Prompt:         prompt,
 		Context:        inputCtx,
 		GitRepoURL:     gitRepoURL,
@@ -132,6 +143,7 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		RolePrompt:     rolePrompt,
 		Feedback:       feedback,
 		Model:          model,
+		Redactor:       e.redactor.Fork(),
 	}
 
 	// Resolve OpenSpec config for opsx_plan / opsx_apply modes
@@ -150,6 +162,62 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		}
 	}
 
+	// Prompt budget: outputs of older ancestors (and of upstream nodes missing from the input,
+	// e.g. after a rejection) are added as the lowest-priority context
+	if nodeDef.Config != nil {
+		agentReq.PromptBudget = nodeDef.Config.PromptBudget
+	}
+	agentReq.AncestorContext = ancestorContext(dag, nodeRun.NodeID, runtimeCtx, inputCtx)
+
+	// Re-run after a rejection: continue the agent session of the rejected run
+	if _, rejected := inputCtx["_reject_from"]; rejected {
+		agentReq.ResumeSession = e.previousAgentSession(ctx, nodeRun, adapter.Name())
+	}
+
+	// Structured output contract (config.output_schema)
+	if agentReq.OutputSchema, err = wf.OutputSchema(nodeDef); err != nil {
+		return err
+	}
+
+	// Node-level timeout (node `timeout`, e.g. "300s")
+	if nodeDef.Timeout != "" {
+		if agentReq.Timeout, err = parseDelay(nodeDef.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	// Resolve node-level container limits
+	if nodeDef.Config != nil && nodeDef.Config.Container != nil {
+		limits, err := toContainerLimits(nodeDef.Config.Container)
+		if err != nil {
+			return fmt.Errorf("invalid container config: %w", err)
+		}
+		agentReq.ContainerLimits = limits
+	}
+
+	// Tool approval gates: matching tool calls block until approved via ApproveToolCall/DenyToolCall
+	if nodeDef.Config != nil && nodeDef.Config.ToolApproval != nil {
+		policy, err := nodeDef.Config.ToolApproval.Policy()
+		if err != nil {
+			return fmt.Errorf("invalid tool_approval config: %w", err)
+		}
+		if err := checkToolApprovalSupport(adapter.Name()); err != nil {
+			return err
+		}
+		agentReq.ToolApproval = policy
+		agentReq.ToolApprover = e.newToolApprover(flowRun, nodeRun, policy, agentReq.Redactor)
+	}
+
+	// Shared per-flow workspace volume (workflow-level `workspace: shared|snapshot`)
+	if err := e.attachSharedWorkspace(ctx, wf, flowRun, nodeRun, agentReq); err != nil {
+		return err
+	}
+
+	// Warm repo cache: sync the mirror and mount it read-only as a clone reference
+	if e.repoMirror != nil && gitRepoURL != "" {
+		e.attachRepoMirror(ctx, agentReq)
+	}
+
 	// 5. Execute
 	e.logger.Infow("Executing agent task",
 		"node_id", nodeRun.NodeID,
@@ -161,90 +229,91 @@ func (e *FlowExecutor) executeAgentTask(ctx context.Context, nodeRun *db.NodeRun
 		"git_branch", gitBranch,
 	)
 
-	// 5a. Inject real-time log streaming callback
-	var logEvents []map[string]any
-	var logMutex sync.Mutex
+	// 5a. Attach this execution's real-time log sink: events are pushed live and
+	// appended to node_run_logs in batches while the agent runs
+	logWriter := e.newNodeLogWriter(ctx, nodeRun.ID, func(flatEvent map[string]any) {
+		e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.log_stream", flatEvent)
+	})
 
-	if combinedAdapter, ok := adapter.(*agent.CombinedAdapter); ok {
-		if dockerExec, ok := combinedAdapter.Executor().(*agent.DockerExecutor); ok {
-			dockerExec.SetLogEventCallback(func(event agent.ClaudeStreamEvent) {
-				// Skip system/init events
-				if event.Type == "system" {
-					return
-				}
+	agentReq.LogSink = agent.LogSinkFunc(func(event agent.ClaudeStreamEvent) {
+		// Skip system/init events
+		if event.Type == "system" {
+			return
+		}
 
-				// Flatten nested structure into frontend-friendly events
-				if event.Message != nil {
-					for _, block := range event.Message.Content {
-						flatEvent := map[string]any{
-							"timestamp": event.Timestamp,
-						}
-						
-						switch block.Type {
-						case "text":
-							flatEvent["type"] = "assistant"
-							flatEvent["content"] = block.Text
-						case "tool_use":
-							flatEvent["type"] = "tool_use"
-							flatEvent["tool_name"] = block.Name
-							flatEvent["tool_input"] = block.Input
-						case "tool_result":
-							flatEvent["type"] = "tool_result"
-							flatEvent["content"] = fmt.Sprintf("%v", block.Content)
-							flatEvent["tool_use_id"] = block.ToolUseID
-						default:
-							flatEvent["type"] = block.Type
-							flatEvent["content"] = fmt.Sprintf("%v", block)
-						}
-
-						// Real-time push via event bus
-						e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.log_stream", flatEvent)
-
-						// Collect for persistence
-						logMutex.Lock()
-						logEvents = append(logEvents, flatEvent)
-						logMutex.Unlock()
-					}
-					return
+		// Flatten nested structure into frontend-friendly events
+		if event.Message != nil {
+			for _, block := range event.Message.Content {
+				flatEvent := map[string]any{
+					"timestamp": event.Timestamp,
 				}
 
-				// Handle result event
-				if event.Type == "result" {
-					flatEvent := map[string]any{
-						"type":      "result",
-						"timestamp": event.Timestamp,
-						"subtype":   event.Subtype,
-						"result":    event.Result,
-					}
-					e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.log_stream", flatEvent)
-					logMutex.Lock()
-					logEvents = append(logEvents, flatEvent)
-					logMutex.Unlock()
+				switch block.Type {
+				case "text":
+					flatEvent["type"] = "assistant"
+					flatEvent["content"] = block.Text
+				case "tool_use":
+					flatEvent["type"] = "tool_use"
+					flatEvent["tool_name"] = block.Name
+					flatEvent["tool_input"] = block.Input
+				case "tool_result":
+					flatEvent["type"] = "tool_result"
+					flatEvent["content"] = fmt.Sprintf("%v", block.Content)
+					flatEvent["tool_use_id"] = block.ToolUseID
+				default:
+					flatEvent["type"] = block.Type
+					flatEvent["content"] = fmt.Sprintf("%v", block)
 				}
-			})
+
+				logWriter.Append(flatEvent)
+			}
+			return
 		}
-	}
 
-	resp, err := adapter.Execute(ctx, agentReq)
-	if err != nil {
-		// Persist logs even on failure
-		if len(logEvents) > 0 {
-			if dbErr := e.db.UpdateNodeRunLogStream(ctx, nodeRun.ID, logEvents); dbErr != nil {
-				e.logger.Warnw("Failed to save log stream on error", "error", dbErr)
+		// Handle result event
+		if event.Type == "result" {
+			flatEvent := map[string]any{
+				"type":      "result",
+				"timestamp": event.Timestamp,
+				"subtype":   event.Subtype,
+				"result":    event.Result,
 			}
+			logWriter.Append(flatEvent)
+		}
+	})
+
+	// Log events, errors and output come back scrubbed of the secrets passed to the agent.
+	// With an output_schema the output is validated and repaired by re-prompting.
+	resp, err := e.executeWithOutputContract(ctx, adapter, agentReq, flowRun, nodeRun, nodeDef.OutputRepairs(), logWriter)
+	// Flush remaining log events (also on failure)
+	logWriter.Close()
+	if agentReq.PromptStats != nil {
+		if err := e.db.UpdateNodeRunPromptStats(ctx, nodeRun.ID, agentReq.PromptStats); err != nil {
+			e.logger.Warnw("Failed to record prompt stats", "node_run_id", nodeRun.ID, "error", err)
+		}
+		if len(agentReq.PromptStats.Truncated) > 0 {
+			e.logger.Infow("Prompt truncated to fit budget", "node_id", nodeRun.NodeID,
+				"budget", agentReq.PromptStats.Budget, "estimated_tokens", agentReq.PromptStats.EstimatedTokens,
+				"truncated", agentReq.PromptStats.Truncated)
 		}
-		return fmt.Errorf("agent execution failed: %w", err)
+	}
+	if err != nil {
+		return err
 	}
 
-	// 5a-2. Persist log stream to database
-	if len(logEvents) > 0 {
-		if dbErr := e.db.UpdateNodeRunLogStream(ctx, nodeRun.ID, logEvents); dbErr != nil {
-			e.logger.Warnw("Failed to save log stream", "error", dbErr)
-			// Non-fatal: don't block flow execution
+	// 5b. Keep the agent session so a re-run after rejection can continue it
+	if resp.Session != nil {
+		if err := e.db.SaveNodeRunSession(ctx, &db.NodeRunSession{
+			NodeRunID: nodeRun.ID,
+			SessionID: resp.Session.ID,
+			AgentType: adapter.Name(),
+			State:     resp.Session.State,
+		}); err != nil {
+			e.logger.Warnw("Failed to save agent session", "node_run_id", nodeRun.ID, "error", err)
 		}
 	}
 
-	// 5b. Post-process generate_change_name mode: extract change_name from agent output
+	// 5c. Post-process generate_change_name mode: extract change_name from agent output
 	if mode == "generate_change_name" {
 		changeName := extractChangeName(resp.Output)
 		if changeName != "" {
@@ -327,13 +396,26 @@ func (e *FlowExecutor) executeHumanReview(ctx context.Context, nodeRun *db.NodeR
 		_ = json.Unmarshal([]byte(*nodeRun.Input), &reviewData)
 	}
 
-	// Publish waiting_human event
-	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.waiting_human", map[string]any{
+	// Publish waiting_human event (with the approval policy of multi-approver reviews)
+	payload := map[string]any{
 		"review_target": reviewData,
 		"node_name":     ptrStr(nodeRun.NodeName),
-	})
+	}
+	if flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID); err == nil {
+		if nodeDef, err := e.getNodeDef(flowRun, nodeRun.NodeID); err == nil {
+			if approvals := approvalsOf(nodeDef); approvals != nil {
+				payload["approvals"] = map[string]any{
+					"policy":    approvals.Policy,
+					"required":  approvals.Required,
+					"assignees": approvals.Assignees,
+					"groups":    approvals.Groups,
+				}
+			}
+		}
+	}
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.waiting_human", payload)
 
-	// Record timeline
+	// Record timeline and schedule reminders
 	flowRun, _ := e.db.GetFlowRun(ctx, nodeRun.FlowRunID)
 	if flowRun != nil {
 		e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "waiting_review", map[string]any{
@@ -341,6 +423,7 @@ func (e *FlowExecutor) executeHumanReview(ctx context.Context, nodeRun *db.NodeR
 			"node_name": ptrStr(nodeRun.NodeName),
 			"message":   fmt.Sprintf("等待人工审核：%s", ptrStr(nodeRun.NodeName)),
 		})
+		e.scheduleReminders(ctx, flowRun, nodeRun)
 	}
 
 	return nil
@@ -362,10 +445,20 @@ func (e *FlowExecutor) executeHumanInput(ctx context.Context, nodeRun *db.NodeRu
 		return fmt.Errorf("load flow run: %w", err)
 	}
 
+	// Resolve templated options / defaults once; the node input keeps the resolved form (_form)
+	// so the UI shows, and submissions are validated against, the same fields
 	nodeDef, _ := e.getNodeDef(flowRun, nodeRun.NodeID)
 	var formFields []FormFieldDef
-	if nodeDef != nil && nodeDef.Config != nil {
-		formFields = nodeDef.Config.Form
+	if nodeDef != nil && nodeDef.Config != nil && len(nodeDef.Config.Form) > 0 {
+		formFields = resolveForm(nodeDef.Config.Form, e.buildRuntimeContext(ctx, flowRun, nodeRun))
+		input := map[string]any{}
+		if nodeRun.Input != nil {
+			_ = json.Unmarshal([]byte(*nodeRun.Input), &input)
+		}
+		input["_form"] = formFields
+		if err := e.db.UpdateNodeRunInput(ctx, nodeRun.ID, jsonStr(input)); err != nil {
+			e.logger.Warnw("Failed to store resolved form", "node_run_id", nodeRun.ID, "error", err)
+		}
 	}
 
 	// Publish waiting_human event with form definition
@@ -375,13 +468,14 @@ func (e *FlowExecutor) executeHumanInput(ctx context.Context, nodeRun *db.NodeRu
 		"input_type": "human_input",
 	})
 
-	// Record timeline
+	// Record timeline and schedule reminders
 	if flowRun != nil {
 		e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "waiting_input", map[string]any{
 			"node_id":   nodeRun.NodeID,
 			"node_name": ptrStr(nodeRun.NodeName),
 			"message":   fmt.Sprintf("等待人工输入：%s", ptrStr(nodeRun.NodeName)),
 		})
+		e.scheduleReminders(ctx, flowRun, nodeRun)
 	}
 
 	return nil
@@ -389,6 +483,199 @@ func (e *FlowExecutor) executeHumanInput(ctx context.Context, nodeRun *db.NodeRu
 
 // ─── Helpers ───
 
+// attachSharedWorkspace mounts the flow run's workspace volume when the workflow opts in
+func (e *FlowExecutor) attachSharedWorkspace(ctx context.Context, wf *WorkflowDSL, flowRun *db.FlowRun, nodeRun *db.NodeRun, req *agent.AgentRequest) error {
+	_, rejected := req.Context["_reject_from"]
+	workspaceMount, err := e.sharedWorkspaceMount(ctx, wf, flowRun, nodeRun, rejected)
+	if err != nil || workspaceMount == nil {
+		return err
+	}
+	req.Mounts = append(req.Mounts, *workspaceMount)
+	req.PersistentWorkspace = true
+	return nil
+}
+
+// sharedWorkspaceMount prepares the flow run's workspace volume and returns its mount (nil when
+// the workflow has no shared workspace). In snapshot mode the volume is snapshotted before each
+// node runs, and restored from the node's snapshot when the node is re-run by a reject rollback.
+func (e *FlowExecutor) sharedWorkspaceMount(ctx context.Context, wf *WorkflowDSL, flowRun *db.FlowRun, nodeRun *db.NodeRun, rejected bool) (*agent.Mount, error) {
+	if !wf.SharedWorkspace() {
+		return nil, nil
+	}
+	if e.workspaces == nil {
+		return nil, fmt.Errorf("workflow uses workspace: %s but shared workspaces are not available", wf.Workspace)
+	}
+
+	workspaceMount, err := e.workspaces.Ensure(ctx, flowRun.ID)
+	if err != nil {
+		return nil, fmt.Errorf("prepare shared workspace: %w", err)
+	}
+
+	if wf.Workspace == WorkspaceSnapshot {
+		restored := false
+		if rejected {
+			restored, err = e.workspaces.Restore(ctx, flowRun.ID, nodeRun.NodeID)
+			if err != nil {
+				return nil, fmt.Errorf("restore workspace snapshot: %w", err)
+			}
+			if restored {
+				e.recordTimeline(ctx, flowRun.TaskID, flowRun.ID, nodeRun.ID, "workspace_restored", map[string]any{
+					"node_id":   nodeRun.NodeID,
+					"node_name": ptrStr(nodeRun.NodeName),
+					"message":   fmt.Sprintf("工作区已回滚到节点 %s 执行前的状态", ptrStr(nodeRun.NodeName)),
+				})
+			}
+		}
+		if !restored {
+			if err := e.workspaces.Snapshot(ctx, flowRun.ID, nodeRun.NodeID); err != nil {
+				return nil, fmt.Errorf("snapshot workspace: %w", err)
+			}
+		}
+	}
+
+	return &workspaceMount, nil
+}
+
+// TimerKindWorkspaceRelease is the timer kind that removes a failed flow run's shared workspace
+const TimerKindWorkspaceRelease = "workspace_release"
+
+// failedWorkspaceRetention is how long a failed flow run keeps its shared workspace, so a
+// RetryNode within that window resumes on the same checkout
+const failedWorkspaceRetention = 24 * time.Hour
+
+func workspaceTimerKey(flowRunID string) string {
+	return "workspace:" + flowRunID
+}
+
+// scheduleWorkspaceRelease removes a failed flow run's shared workspace after the retention
+// window; HandleRetry cancels it when the flow is resumed
+func (e *FlowExecutor) scheduleWorkspaceRelease(ctx context.Context, flowRunID string) {
+	if e.workspaces == nil {
+		return
+	}
+	flowRun, err := e.db.GetFlowRun(ctx, flowRunID)
+	if err != nil || flowRun.DslSnapshot == nil {
+		return
+	}
+	if wf, _, err := ParseDSL(*flowRun.DslSnapshot); err != nil || !wf.SharedWorkspace() {
+		return
+	}
+	if _, err := e.scheduler.After(ctx, TimerKindWorkspaceRelease, workspaceTimerKey(flowRunID), failedWorkspaceRetention, map[string]any{
+		"flow_run_id": flowRunID,
+	}); err != nil {
+		// Without the timer the workspace would leak, so release it right away
+		e.logger.Warnw("Failed to schedule workspace release", "flow_run_id", flowRunID, "error", err)
+		e.releaseWorkspace(flowRun)
+	}
+}
+
+// handleWorkspaceReleaseTimer releases the workspace of a flow run that is still failed; flows
+// retried in the meantime release it themselves when they finish
+func (e *FlowExecutor) handleWorkspaceReleaseTimer(ctx context.Context, t *db.Timer) error {
+	var payload struct {
+		FlowRunID string `json:"flow_run_id"`
+	}
+	if err := scheduler.DecodePayload(t, &payload); err != nil {
+		return err
+	}
+	flowRun, err := e.db.GetFlowRun(ctx, payload.FlowRunID)
+	if err != nil {
+		return err
+	}
+	if flowRun.Status == db.StatusFailed {
+		e.releaseWorkspace(flowRun)
+	}
+	return nil
+}
+
+// releaseWorkspace removes a finished flow run's shared workspace and snapshots in the background.
+// Containers of a cancelled flow may still be stopping, so removal is retried briefly.
+func (e *FlowExecutor) releaseWorkspace(flowRun *db.FlowRun) {
+	if e.workspaces == nil || flowRun == nil || flowRun.DslSnapshot == nil {
+		return
+	}
+	wf, _, err := ParseDSL(*flowRun.DslSnapshot)
+	if err != nil || !wf.SharedWorkspace() {
+		return
+	}
+
+	go func() {
+		for attempt := 1; attempt <= 5; attempt++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := e.workspaces.Remove(ctx, flowRun.ID)
+			cancel()
+			if err == nil {
+				return
+			}
+			e.logger.Warnw("Failed to remove shared workspace", "flow_run_id", flowRun.ID, "attempt", attempt, "error", err)
+			time.Sleep(time.Duration(attempt) * 2 * time.Second)
+		}
+	}()
+}
+
+// repoCacheMountPath is where repo mirrors are mounted inside agent containers
+const repoCacheMountPath = "/repo-cache"
+
+// attachRepoMirror syncs the task repo's mirror and mounts it into the agent container.
+// Failures only cost the speedup, so they are logged and the agent clones normally.
+func (e *FlowExecutor) attachRepoMirror(ctx context.Context, req *agent.AgentRequest) {
+	if mirrorMount, referenceRepo, ok := e.repoMirrorMount(ctx, req.GitRepoURL); ok {
+		req.Mounts = append(req.Mounts, mirrorMount)
+		req.GitReferenceRepo = referenceRepo
+	}
+}
+
+// repoMirrorMount syncs the repo's mirror and returns a read-only mount of that one mirror
+// (never the whole cache, which holds every repository the orchestrator has synced) plus its
+// path inside the container (ok is false when the sync failed)
+func (e *FlowExecutor) repoMirrorMount(ctx context.Context, repoURL string) (agent.Mount, string, bool) {
+	mirrorPath, err := e.repoMirror.Sync(ctx, repoURL)
+	if err != nil {
+		e.logger.Warnw("Failed to sync repo mirror, cloning without reference", "error", err)
+		return agent.Mount{}, "", false
+	}
+
+	name := filepath.Base(mirrorPath)
+	mirrorMount := agent.Mount{
+		Type:     agent.MountTypeBind,
+		Source:   filepath.Join(e.repoMirror.HostSource(), name),
+		Target:   path.Join(repoCacheMountPath, name),
+		ReadOnly: true,
+	}
+	if e.repoMirror.IsVolume() {
+		mirrorMount.Type = agent.MountTypeVolume
+		mirrorMount.Source = e.repoMirror.HostSource()
+		mirrorMount.SubPath = name
+	}
+	return mirrorMount, mirrorMount.Target, true
+}
+
+// toContainerLimits converts a DSL container config into agent container limits
+func toContainerLimits(def *ContainerConfigDef) (*agent.ContainerLimits, error) {
+	limits := &agent.ContainerLimits{
+		PidsLimit:       def.PidsLimit,
+		ReadOnlyRootfs:  def.ReadOnlyRootfs,
+		NetworkMode:     def.NetworkMode,
+		CapDrop:         def.CapDrop,
+		NoNewPrivileges: def.NoNewPrivileges,
+	}
+	if def.Memory != "" {
+		mem, err := agent.ParseMemoryLimit(def.Memory)
+		if err != nil {
+			return nil, err
+		}
+		limits.Memory = mem
+	}
+	if def.CPUs != "" {
+		cpus, err := agent.ParseCPULimit(def.CPUs)
+		if err != nil {
+			return nil, err
+		}
+		limits.NanoCPUs = cpus
+	}
+	return limits, nil
+}
+
 // getNodeDef loads the DAG from the flow run's DSL snapshot and returns the node definition
 func (e *FlowExecutor) getNodeDef(flowRun *db.FlowRun, nodeID string) (*NodeDef, error) {
 	if flowRun.DslSnapshot == nil {
@@ -424,6 +711,44 @@ func (e *FlowExecutor) getDAG(ctx context.Context, flowRunID string) (*DAG, erro
 	return dag, nil
 }
 
+// previousAgentSession returns the session of the node's previous run, if it was produced
+// by the same kind of agent; nil means the agent starts a new session
+func (e *FlowExecutor) previousAgentSession(ctx context.Context, nodeRun *db.NodeRun, agentType string) *agent.AgentSession {
+	session, err := e.db.GetPreviousNodeRunSession(ctx, nodeRun.FlowRunID, nodeRun.NodeID, nodeRun.ID)
+	if err != nil {
+		e.logger.Warnw("Failed to load previous agent session", "node_id", nodeRun.NodeID, "error", err)
+		return nil
+	}
+	if session == nil {
+		return nil
+	}
+	if session.AgentType != agentType {
+		e.logger.Infow("Previous agent session belongs to another agent type, starting a new session",
+			"node_id", nodeRun.NodeID, "session_agent", session.AgentType, "agent", agentType)
+		return nil
+	}
+	e.logger.Infow("Resuming previous agent session", "node_id", nodeRun.NodeID, "session_id", session.SessionID, "from_node_run", session.NodeRunID)
+	return &agent.AgentSession{ID: session.SessionID, State: session.State}
+}
+
+// ancestorContext collects the outputs of completed ancestor nodes that are not already part
+// of the node's input, nearest first
+func ancestorContext(dag *DAG, nodeID string, runtimeCtx, inputCtx map[string]any) []agent.ContextEntry {
+	nodesCtx, _ := runtimeCtx["nodes"].(map[string]any)
+	var entries []agent.ContextEntry
+	for _, id := range dag.GetAncestors(nodeID) {
+		if _, ok := inputCtx[id]; ok {
+			continue
+		}
+		node, ok := nodesCtx[id].(map[string]any)
+		if !ok || node["outputs"] == nil {
+			continue
+		}
+		entries = append(entries, agent.ContextEntry{NodeID: id, Output: node["outputs"]})
+	}
+	return entries
+}
+
 // buildRuntimeContext constructs the pongo2 template context for runtime rendering.
 // Includes: params, nodes (upstream outputs), review (feedback), task (id/title).
 func (e *FlowExecutor) buildRuntimeContext(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun) map[string]any {
@@ -455,16 +780,29 @@ func (e *FlowExecutor) buildRuntimeContext(ctx context.Context, flowRun *db.Flow
 	}
 	runtimeCtx["nodes"] = nodesCtx
 
-	// 3. review — extract feedback from node input context
+	// 3. review — the review that re-queued this node (reviewer, severity, file comments,
+	// checklist), plus review.history: every review of the flow run, oldest first
 	review := map[string]any{}
 	if nodeRun.Input != nil {
 		var input map[string]any
 		if err := json.Unmarshal([]byte(*nodeRun.Input), &input); err == nil {
+			if current := reviewFromInput(input); current != nil {
+				review = reviewTemplateContext(current)
+			}
 			if fb, ok := input["_feedback"]; ok {
 				review["comment"] = fb
 			}
 		}
 	}
+	history := []any{}
+	if reviews, err := e.db.ListNodeRunReviews(ctx, flowRun.ID); err != nil {
+		e.logger.Warnw("Failed to get review history for template", "error", err)
+	} else {
+		for _, r := range reviews {
+			history = append(history, reviewTemplateContext(r))
+		}
+	}
+	review["history"] = history
 	runtimeCtx["review"] = review
 
 	// 4. task — basic info
@@ -743,6 +1081,15 @@ func (e *FlowExecutor) fetchFileFromGit(ctx context.Context, taskID, filePath st
 		return "", fmt.Errorf("task has no git info")
 	}
 
+	if e.repoMirror != nil {
+		content, err := e.repoMirror.Show(ctx, repoURL, branch, filePath)
+		if err == nil {
+			return content, nil
+		}
+		e.logger.Warnw("Failed to read file from repo mirror, falling back to clone",
+			"task_id", taskID, "path", filePath, "error", err)
+	}
+
 	// 使用 git show 命令读取文件内容
 	tmpDir, err := os.MkdirTemp("", "workgear-git-")
 	if err != nil {
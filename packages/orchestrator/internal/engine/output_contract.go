@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// pushingAgentModes are the modes whose container runs commit and push their changes
+var pushingAgentModes = map[string]bool{"execute": true, "opsx_plan": true, "opsx_apply": true}
+
+// executeWithOutputContract runs the agent and, when the node declares an output schema,
+// validates the structured output. On mismatch the agent is re-prompted with the validation
+// errors up to maxRepairs times; if the output still does not match, the node fails.
+// Runs that cannot be repaired safely (see canRepairOutput) fail right away.
+func (e *FlowExecutor) executeWithOutputContract(ctx context.Context, adapter agent.Adapter, req *agent.AgentRequest,
+	flowRun *db.FlowRun, nodeRun *db.NodeRun, maxRepairs int, logWriter *nodeLogWriter) (*agent.AgentResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := adapter.Execute(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("agent execution failed: %w", err)
+		}
+		if req.OutputSchema == nil {
+			return resp, nil
+		}
+
+		structured, errs := agent.ExtractStructuredOutput(resp.Output, req.OutputSchema)
+		if len(errs) == 0 {
+			resp.Output = structured
+			return resp, nil
+		}
+		if attempt >= maxRepairs {
+			return nil, fmt.Errorf("agent output does not match output_schema after %d repair attempt(s): %s",
+				attempt, strings.Join(errs, "; "))
+		}
+		if !canRepairOutput(adapter, req, resp) {
+			return nil, fmt.Errorf("agent output does not match output_schema: %s (not repaired: %s cannot resume its session, and re-running the whole task would repeat its commits and pushes)",
+				strings.Join(errs, "; "), adapter.Name())
+		}
+
+		e.logger.Warnw("Agent output does not match output_schema, re-prompting",
+			"node_id", nodeRun.NodeID, "attempt", attempt+1, "max_repairs", maxRepairs, "errors", errs)
+		logWriter.Append(map[string]any{
+			"type":      "output_repair",
+			"timestamp": time.Now().UnixMilli(),
+			"content":   fmt.Sprintf("输出未通过 output_schema 校验，第 %d/%d 次修正：\n- %s", attempt+1, maxRepairs, strings.Join(errs, "\n- ")),
+		})
+		e.recordTimeline(ctx, flowRun.TaskID, flowRun.ID, nodeRun.ID, "output_repair", map[string]any{
+			"node_id":   nodeRun.NodeID,
+			"node_name": ptrStr(nodeRun.NodeName),
+			"attempt":   attempt + 1,
+			"errors":    errs,
+		})
+		req.OutputErrors = errs
+		if resp.Session != nil {
+			req.ResumeSession = resp.Session // let the agent fix its own answer in the same conversation
+		}
+	}
+}
+
+// canRepairOutput reports whether a run whose output failed the contract may be re-prompted.
+// A resumed session only fixes its answer. Without one the whole task runs again, which is
+// only safe when the run has no git side effects: a chat API run, or a container run in a
+// mode that does not push and that pushed nothing.
+func canRepairOutput(adapter agent.Adapter, req *agent.AgentRequest, resp *agent.AgentResponse) bool {
+	if resp.Session != nil {
+		return true
+	}
+	if combined, ok := adapter.(*agent.CombinedAdapter); ok && combined.Executor().Kind() == "http" {
+		return true
+	}
+	if resp.GitMetadata != nil {
+		return false
+	}
+	return req.GitRepoURL == "" || !pushingAgentModes[req.Mode]
+}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+)
+
+func TestCanRepairOutput(t *testing.T) {
+	docker := agent.NewCombinedAdapter(agent.NewCodexAdapter(nil, "p", "", "", ""), &agent.DockerExecutor{})
+	httpExec, err := agent.NewHTTPExecutor(zap.NewNop().Sugar(), "anthropic", "http://localhost", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat := agent.NewCombinedAdapter(agent.NewHTTPChatAdapter(nil, "p", "m"), httpExec)
+	pushed := &agent.GitMetadata{Commit: "abc123"}
+
+	tests := []struct {
+		name    string
+		adapter agent.Adapter
+		req     *agent.AgentRequest
+		resp    *agent.AgentResponse
+		want    bool
+	}{
+		{"resumable session", docker, &agent.AgentRequest{Mode: "execute", GitRepoURL: "https://git/repo"},
+			&agent.AgentResponse{Session: &agent.AgentSession{ID: "s"}, GitMetadata: pushed}, true},
+		{"chat api run", chat, &agent.AgentRequest{Mode: "execute", GitRepoURL: "https://git/repo"},
+			&agent.AgentResponse{}, true},
+		{"container run that pushed", docker, &agent.AgentRequest{Mode: "review", GitRepoURL: "https://git/repo"},
+			&agent.AgentResponse{GitMetadata: pushed}, false},
+		{"container run in a pushing mode", docker, &agent.AgentRequest{Mode: "execute", GitRepoURL: "https://git/repo"},
+			&agent.AgentResponse{}, false},
+		{"container run in a read-only mode", docker, &agent.AgentRequest{Mode: "review", GitRepoURL: "https://git/repo"},
+			&agent.AgentResponse{}, true},
+		{"container run without a repo", docker, &agent.AgentRequest{Mode: "execute"},
+			&agent.AgentResponse{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canRepairOutput(tt.adapter, tt.req, tt.resp); got != tt.want {
+				t.Errorf("canRepairOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
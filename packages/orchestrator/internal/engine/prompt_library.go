@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/flosch/pongo2/v6"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Reserved prompt library name prefixes for per-project overrides
+const (
+	modeInstructionPrefix = "mode/" // mode/<mode> replaces the built-in mode instruction
+	rolePromptPrefix      = "role/" // role/<slug> replaces the role's system prompt
+)
+
+// promptRefPattern matches library references: "name" (latest version) or "name@v3"
+var promptRefPattern = regexp.MustCompile(`^([a-z0-9][a-z0-9._/-]*)(?:@v(\d+))?$`)
+
+// PromptTemplateRef identifies the exact library template version a node run used
+type PromptTemplateRef struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	ID      string `json:"id"`
+	Scope   string `json:"scope"` // project / global
+}
+
+// promptLibrary resolves prompt library templates for one node execution and records
+// every version it hands out. It is a pongo2 TemplateLoader, so prompt templates can use
+// {% include "review-checklist@v3" %} (pinned) or {% include "review-checklist" %} (latest).
+type promptLibrary struct {
+	ctx       context.Context
+	db        *db.Client
+	projectID string // "" = global templates only
+	used      map[string]PromptTemplateRef
+}
+
+func (e *FlowExecutor) newPromptLibrary(ctx context.Context, flowRun *db.FlowRun) *promptLibrary {
+	projectID, err := e.db.GetTaskProjectID(ctx, flowRun.TaskID)
+	if err != nil {
+		e.logger.Warnw("Failed to get task project, using global prompt templates only", "task_id", flowRun.TaskID, "error", err)
+	}
+	return &promptLibrary{ctx: ctx, db: e.db, projectID: projectID, used: make(map[string]PromptTemplateRef)}
+}
+
+// lookup resolves a reference, preferring the project's own template. Returns nil if not found.
+func (l *promptLibrary) lookup(ref string) (*db.PromptTemplate, error) {
+	m := promptRefPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return nil, fmt.Errorf("invalid prompt template reference %q (expected name or name@vN)", ref)
+	}
+	version := 0
+	if m[2] != "" {
+		version, _ = strconv.Atoi(m[2])
+	}
+
+	t, err := l.db.GetPromptTemplate(l.ctx, l.projectID, m[1], version)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	scope := "global"
+	if t.ProjectID != nil {
+		scope = "project"
+	}
+	l.used[t.ID] = PromptTemplateRef{Name: t.Name, Version: t.Version, ID: t.ID, Scope: scope}
+	return t, nil
+}
+
+// Abs implements pongo2.TemplateLoader; library names are flat
+func (l *promptLibrary) Abs(base, name string) string {
+	return name
+}
+
+// Get implements pongo2.TemplateLoader
+func (l *promptLibrary) Get(path string) (io.Reader, error) {
+	t, err := l.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("prompt template %q not found", path)
+	}
+	return strings.NewReader(t.Content), nil
+}
+
+// Render renders tmpl like RenderTemplate, resolving include/extends/import from the library
+func (l *promptLibrary) Render(tmpl string, runtimeCtx map[string]any) (string, error) {
+	if !strings.Contains(tmpl, "{{") && !strings.Contains(tmpl, "{%") {
+		return tmpl, nil
+	}
+	tpl, err := pongo2.NewSet("prompt-library", l).FromString(tmpl)
+	if err != nil {
+		return tmpl, err
+	}
+	result, err := tpl.Execute(pongo2.Context(runtimeCtx))
+	if err != nil {
+		return tmpl, err
+	}
+	return result, nil
+}
+
+// Override returns the rendered latest version of an override template (mode/<mode>,
+// role/<slug>). ok is false when the library has no such template.
+func (l *promptLibrary) Override(name string, runtimeCtx map[string]any) (string, bool, error) {
+	t, err := l.lookup(name)
+	if err != nil || t == nil {
+		return "", false, err
+	}
+	rendered, err := l.Render(t.Content, runtimeCtx)
+	if err != nil {
+		return "", false, fmt.Errorf("render prompt template %s@v%d: %w", t.Name, t.Version, err)
+	}
+	return rendered, true, nil
+}
+
+// Versions returns the template versions resolved so far, sorted by name
+func (l *promptLibrary) Versions() []PromptTemplateRef {
+	refs := make([]PromptTemplateRef, 0, len(l.used))
+	for _, ref := range l.used {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Version < refs[j].Version
+	})
+	return refs
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+	"github.com/sunshow/workgear/orchestrator/internal/scheduler"
+)
+
+// TimerKindNodeReminder is the timer kind of reminders of nodes waiting for a human
+const TimerKindNodeReminder = "node_reminder"
+
+// reminderPayload is the payload of a node_reminder timer
+type reminderPayload struct {
+	NodeRunID  string `json:"node_run_id"`
+	FlowRunID  string `json:"flow_run_id"`
+	Seq        int    `json:"seq"`
+	EscalateTo string `json:"escalate_to,omitempty"`
+}
+
+// nodeRunTimerKey groups the timers of a node run
+func nodeRunTimerKey(nodeRunID string) string {
+	return "node_run:" + nodeRunID
+}
+
+// scheduleReminders schedules the node's reminders as durable timers when it starts waiting
+// for a human
+func (e *FlowExecutor) scheduleReminders(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun) {
+	nodeDef, err := e.getNodeDef(flowRun, nodeRun.NodeID)
+	if err != nil || nodeDef.Config == nil || len(nodeDef.Config.Reminders) == 0 {
+		return
+	}
+
+	for i, def := range nodeDef.Config.Reminders {
+		delay, err := def.Delay()
+		if err != nil {
+			e.logger.Warnw("Skipping invalid reminder", "node_run_id", nodeRun.ID, "index", i, "error", err)
+			continue
+		}
+		payload := map[string]any{
+			"node_run_id": nodeRun.ID,
+			"flow_run_id": nodeRun.FlowRunID,
+			"seq":         i,
+		}
+		if def.EscalateTo != "" {
+			payload["escalate_to"] = def.EscalateTo
+		}
+		if _, err := e.scheduler.After(ctx, TimerKindNodeReminder, nodeRunTimerKey(nodeRun.ID), delay, payload); err != nil {
+			e.logger.Warnw("Failed to schedule reminder", "node_run_id", nodeRun.ID, "index", i, "error", err)
+		}
+	}
+}
+
+// handleReminderTimer fires a node_reminder timer; reminders of nodes no longer waiting for a
+// human (reviewed, submitted, cancelled) are dropped
+func (e *FlowExecutor) handleReminderTimer(ctx context.Context, t *db.Timer) error {
+	var r reminderPayload
+	if err := scheduler.DecodePayload(t, &r); err != nil {
+		return err
+	}
+	nodeRun, err := e.db.GetNodeRun(ctx, r.NodeRunID)
+	if err != nil {
+		return err
+	}
+	if nodeRun == nil || nodeRun.Status != db.StatusWaitingHuman {
+		return nil
+	}
+	flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID)
+	if err != nil {
+		return err
+	}
+	e.fireReminder(ctx, flowRun, nodeRun, r)
+	return nil
+}
+
+// fireReminder publishes node.review_reminder (or node.review_escalated) for a node run still
+// waiting for a human and records it on the task timeline
+func (e *FlowExecutor) fireReminder(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun, r reminderPayload) {
+	nodeName := ptrStr(nodeRun.NodeName)
+	waitingSince := nodeRun.CreatedAt
+	if nodeRun.StartedAt != nil {
+		waitingSince = *nodeRun.StartedAt
+	}
+	waitingFor := time.Since(waitingSince).Round(time.Minute)
+	data := map[string]any{
+		"node_name":     nodeName,
+		"node_type":     ptrStr(nodeRun.NodeType),
+		"reminder":      r.Seq + 1,
+		"waiting_since": waitingSince.Format(time.RFC3339),
+		"waiting_for":   waitingFor.String(),
+	}
+	if pending := e.pendingReviewers(ctx, flowRun, nodeRun); pending != nil {
+		data["pending_reviewers"] = pending
+	}
+
+	if r.EscalateTo == "" {
+		e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.review_reminder", data)
+		e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "review_reminder", map[string]any{
+			"node_id":     nodeRun.NodeID,
+			"node_name":   nodeName,
+			"waiting_for": waitingFor.String(),
+			"message":     fmt.Sprintf("%s 已等待 %s，请尽快处理", nodeName, waitingFor),
+		})
+		e.logger.Infow("Reminder fired", "node_run_id", nodeRun.ID, "reminder", r.Seq+1)
+		return
+	}
+
+	// Escalation: the role (or user) escalated to, resolved to users of the task's project
+	escalatedTo, err := e.db.ResolveReviewers(ctx, flowRun.TaskID, []string{r.EscalateTo}, []string{r.EscalateTo})
+	if err != nil {
+		e.logger.Warnw("Failed to resolve escalation target", "node_run_id", nodeRun.ID, "escalate_to", r.EscalateTo, "error", err)
+	}
+	data["escalate_to"] = r.EscalateTo
+	data["escalated_to"] = escalatedTo
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.review_escalated", data)
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "review_escalated", map[string]any{
+		"node_id":      nodeRun.NodeID,
+		"node_name":    nodeName,
+		"waiting_for":  waitingFor.String(),
+		"escalate_to":  r.EscalateTo,
+		"escalated_to": escalatedTo,
+		"message":      fmt.Sprintf("%s 已等待 %s，升级至 %s", nodeName, waitingFor, r.EscalateTo),
+	})
+	e.logger.Infow("Reminder escalated", "node_run_id", nodeRun.ID, "reminder", r.Seq+1, "escalate_to", r.EscalateTo)
+}
+
+// pendingReviewers returns the assigned reviewers of a multi-approver review who have not
+// approved yet (nil when the review is open to anyone)
+func (e *FlowExecutor) pendingReviewers(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun) []string {
+	nodeDef, _ := e.getNodeDef(flowRun, nodeRun.NodeID)
+	approvals := approvalsOf(nodeDef)
+	if !approvals.Restricted() {
+		return nil
+	}
+	eligible, err := e.eligibleReviewers(ctx, flowRun, approvals)
+	if err != nil {
+		return nil
+	}
+	progress, err := e.approvalProgress(ctx, nodeRun.ID, approvals, eligible)
+	if err != nil {
+		return nil
+	}
+	pending := []string{}
+	for _, id := range eligible {
+		if !slices.Contains(progress.ApprovedBy, id) {
+			pending = append(pending, id)
+		}
+	}
+	return pending
+}
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// ReviewDetails is the structured part of a human review action
+type ReviewDetails struct {
+	// ReviewerID / ReviewerName are used when the caller is not an authenticated user
+	// (shared-token services, authentication disabled); a user's JWT identity wins
+	ReviewerID   string
+	ReviewerName string
+	Severity     string // info / minor / major / critical
+	Comments     []db.ReviewComment
+	Checklist    []db.ReviewChecklistItem
+}
+
+// Validate checks severities and that every comment and checklist item has content
+func (d ReviewDetails) Validate() error {
+	if err := validateSeverity(d.Severity); err != nil {
+		return err
+	}
+	for i, c := range d.Comments {
+		if strings.TrimSpace(c.Body) == "" {
+			return fmt.Errorf("review comment %d has no body", i+1)
+		}
+		if c.Line < 0 || c.EndLine < 0 || (c.EndLine > 0 && c.EndLine < c.Line) {
+			return fmt.Errorf("review comment %d has an invalid line range", i+1)
+		}
+		if c.Line > 0 && c.File == "" {
+			return fmt.Errorf("review comment %d has a line but no file", i+1)
+		}
+		if err := validateSeverity(c.Severity); err != nil {
+			return fmt.Errorf("review comment %d: %w", i+1, err)
+		}
+	}
+	for i, item := range d.Checklist {
+		if strings.TrimSpace(item.Item) == "" {
+			return fmt.Errorf("checklist item %d has no name", i+1)
+		}
+	}
+	return nil
+}
+
+func validateSeverity(severity string) error {
+	switch severity {
+	case "", db.ReviewSeverityInfo, db.ReviewSeverityMinor, db.ReviewSeverityMajor, db.ReviewSeverityCritical:
+		return nil
+	}
+	return fmt.Errorf("invalid review severity %q (expected info, minor, major or critical)", severity)
+}
+
+// recordReview stores a review action on the node run (latest action) and appends it to the
+// node's review history
+func (e *FlowExecutor) recordReview(ctx context.Context, nodeRun *db.NodeRun, action, comment string, details ReviewDetails) (*db.NodeRunReview, error) {
+	review := &db.NodeRunReview{
+		ID:        uuid.New().String(),
+		NodeRunID: nodeRun.ID,
+		FlowRunID: nodeRun.FlowRunID,
+		NodeID:    nodeRun.NodeID,
+		Attempt:   nodeRun.Attempt,
+		Action:    action,
+		Comment:   comment,
+		Severity:  details.Severity,
+		Comments:  details.Comments,
+		Checklist: details.Checklist,
+		CreatedAt: time.Now(),
+	}
+	review.ReviewerID, review.ReviewerName = reviewerIdentity(ctx, details)
+
+	if err := e.db.RecordNodeRunReview(ctx, review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// reviewerIdentity returns who is reviewing: the authenticated user, else the reviewer named in
+// the request, else the calling service
+func reviewerIdentity(ctx context.Context, details ReviewDetails) (id, name string) {
+	principal := auth.FromContext(ctx)
+	if userID := principal.UserID(); userID != "" {
+		return userID, orDefault(principal.Name, details.ReviewerName)
+	}
+	if details.ReviewerID != "" {
+		return details.ReviewerID, details.ReviewerName
+	}
+	return principal.Actor(), details.ReviewerName
+}
+
+// reviewFromInput returns the review a node run was re-queued by (nil if none)
+func reviewFromInput(input map[string]any) *db.NodeRunReview {
+	raw, ok := input["_review"]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var review db.NodeRunReview
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil
+	}
+	return &review
+}
+
+// reviewTemplateContext exposes a review to templates with its JSON field names
+// (review.reviewer_name, review.comments[].file, ...)
+func reviewTemplateContext(review *db.NodeRunReview) map[string]any {
+	data, _ := json.Marshal(review)
+	var m map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // line numbers render as 12, not 12.000000
+	_ = decoder.Decode(&m)
+	if m == nil {
+		m = map[string]any{}
+	}
+	return m
+}
+
+// formatReviewFeedback appends a review's severity, file comments and failed checklist items
+// to the reviewer's free-text feedback for the agent prompt
+func formatReviewFeedback(feedback string, review *db.NodeRunReview) string {
+	if review == nil {
+		return feedback
+	}
+	var b strings.Builder
+	b.WriteString(feedback)
+	section := func(title string) {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(title)
+	}
+
+	if review.ReviewerName != "" || review.Severity != "" {
+		section("审核人：")
+		b.WriteString(orDefault(review.ReviewerName, review.ReviewerID))
+		if review.Severity != "" {
+			fmt.Fprintf(&b, "（严重程度：%s）", review.Severity)
+		}
+	}
+	if len(review.Comments) > 0 {
+		section("逐条意见：")
+		for _, c := range review.Comments {
+			b.WriteString("\n- ")
+			if c.Severity != "" {
+				fmt.Fprintf(&b, "[%s] ", c.Severity)
+			}
+			if c.File != "" {
+				b.WriteString(c.File)
+				if c.Line > 0 {
+					fmt.Fprintf(&b, ":%d", c.Line)
+					if c.EndLine > c.Line {
+						fmt.Fprintf(&b, "-%d", c.EndLine)
+					}
+				}
+				b.WriteString(" — ")
+			}
+			b.WriteString(c.Body)
+		}
+	}
+	var failed []db.ReviewChecklistItem
+	for _, item := range review.Checklist {
+		if !item.Passed {
+			failed = append(failed, item)
+		}
+	}
+	if len(failed) > 0 {
+		section("未通过的检查项：")
+		for _, item := range failed {
+			b.WriteString("\n- " + item.Item)
+			if item.Note != "" {
+				b.WriteString("：" + item.Note)
+			}
+		}
+	}
+	return b.String()
+}
+
+func orDefault(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
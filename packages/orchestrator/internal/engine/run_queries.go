@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// ─── Read-only queries (query RPCs) ───
+
+// GetFlowRun returns a flow run, plus the DAG parsed from its DSL snapshot when withDAG is
+// set (nil if the run has no snapshot yet)
+func (e *FlowExecutor) GetFlowRun(ctx context.Context, flowRunID string, withDAG bool) (*db.FlowRun, *DAG, error) {
+	flowRun, err := e.db.GetFlowRun(ctx, flowRunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !withDAG || flowRun.DslSnapshot == nil || *flowRun.DslSnapshot == "" {
+		return flowRun, nil, nil
+	}
+	_, dag, err := ParseDSL(*flowRun.DslSnapshot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse DSL snapshot: %w", err)
+	}
+	return flowRun, dag, nil
+}
+
+// ListNodeRuns returns the node runs of a flow run matching filter
+func (e *FlowExecutor) ListNodeRuns(ctx context.Context, filter db.NodeRunFilter) ([]*db.NodeRun, error) {
+	if filter.FlowRunID == "" {
+		return nil, fmt.Errorf("flow_run_id is required")
+	}
+	return e.db.ListNodeRuns(ctx, filter)
+}
+
+// GetNodeRunAttempts returns a node run and every attempt of the same node in its flow run,
+// by attempt
+func (e *FlowExecutor) GetNodeRunAttempts(ctx context.Context, nodeRunID string) (*db.NodeRun, []*db.NodeRun, error) {
+	nodeRun, err := e.db.GetNodeRun(ctx, nodeRunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts, err := e.db.ListNodeRuns(ctx, db.NodeRunFilter{FlowRunID: nodeRun.FlowRunID, NodeID: nodeRun.NodeID})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.SliceStable(attempts, func(i, j int) bool { return attempts[i].Attempt < attempts[j].Attempt })
+	return nodeRun, attempts, nil
+}
+
+// ListTimeline returns up to limit timeline events after afterID, and whether more exist
+func (e *FlowExecutor) ListTimeline(ctx context.Context, filter db.TimelineFilter, afterID string, limit int) ([]*db.TimelineEvent, bool, error) {
+	if filter.TaskID == "" && filter.FlowRunID == "" {
+		return nil, false, fmt.Errorf("task_id or flow_run_id is required")
+	}
+	events, err := e.db.ListTimelineEvents(ctx, filter, afterID, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(events) > limit {
+		return events[:limit], true, nil
+	}
+	return events, false, nil
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+	"github.com/sunshow/workgear/orchestrator/internal/scheduler"
+)
+
+// TimerKindFlowSchedule is the timer kind that starts a scheduled flow run
+const TimerKindFlowSchedule = "flow_schedule"
+
+// scheduleTimerKey groups the timers of a flow schedule
+func scheduleTimerKey(scheduleID string) string {
+	return "flow_schedule:" + scheduleID
+}
+
+// scheduleNext returns the schedule's next fire time after from
+func scheduleNext(s *db.FlowSchedule, from time.Time) (time.Time, error) {
+	cron, err := scheduler.ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timezone %q", s.Timezone)
+	}
+	next := cron.Next(from.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never fires", s.Cron)
+	}
+	return next, nil
+}
+
+// ScheduleFlow creates a schedule that starts flow runs of the workflow for the task whenever
+// the cron expression fires
+func (e *FlowExecutor) ScheduleFlow(ctx context.Context, s *db.FlowSchedule) (*db.FlowSchedule, error) {
+	if s.TaskID == "" || s.WorkflowID == "" {
+		return nil, fmt.Errorf("task_id and workflow_id are required")
+	}
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+	next, err := scheduleNext(s, e.scheduler.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// The workflow must exist and its DSL parse with the schedule's variables
+	dsl, params, err := e.db.GetWorkflowDSL(ctx, s.WorkflowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := ParseDSL(RenderParams(dsl, mergeVariables(params, s.Variables))); err != nil {
+		return nil, fmt.Errorf("parse DSL: %w", err)
+	}
+
+	s.ID = uuid.New().String()
+	s.NextRunAt = &next
+	s.CreatedAt = time.Now()
+	if userID := auth.FromContext(ctx).UserID(); userID != "" {
+		if _, err := uuid.Parse(userID); err == nil {
+			s.CreatedBy = &userID
+		}
+	}
+	if err := e.db.CreateFlowSchedule(ctx, s); err != nil {
+		return nil, err
+	}
+	if s.Enabled {
+		if err := e.scheduleFlowTimer(ctx, s.ID, next); err != nil {
+			_, _ = e.db.DeleteFlowSchedule(ctx, s.ID)
+			return nil, err
+		}
+	}
+
+	e.logger.Infow("Flow schedule created", "schedule_id", s.ID, "task_id", s.TaskID, "workflow_id", s.WorkflowID,
+		"cron", s.Cron, "timezone", s.Timezone, "next_run_at", next)
+	return s, nil
+}
+
+// ListFlowSchedules lists the flow schedules of a task (all schedules when taskID is empty)
+func (e *FlowExecutor) ListFlowSchedules(ctx context.Context, taskID string) ([]*db.FlowSchedule, error) {
+	return e.db.ListFlowSchedules(ctx, taskID)
+}
+
+// DeleteFlowSchedule deletes a flow schedule and cancels its pending timer
+func (e *FlowExecutor) DeleteFlowSchedule(ctx context.Context, scheduleID string) error {
+	deleted, err := e.db.DeleteFlowSchedule(ctx, scheduleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("flow schedule not found: %s", scheduleID)
+	}
+	if _, err := e.scheduler.Cancel(ctx, scheduleTimerKey(scheduleID)); err != nil {
+		e.logger.Warnw("Failed to cancel flow schedule timer", "schedule_id", scheduleID, "error", err)
+	}
+	e.logger.Infow("Flow schedule deleted", "schedule_id", scheduleID)
+	return nil
+}
+
+func (e *FlowExecutor) scheduleFlowTimer(ctx context.Context, scheduleID string, fireAt time.Time) error {
+	_, err := e.scheduler.Schedule(ctx, TimerKindFlowSchedule, scheduleTimerKey(scheduleID), fireAt, map[string]any{
+		"schedule_id": scheduleID,
+		"fire_at":     fireAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("schedule flow timer: %w", err)
+	}
+	return nil
+}
+
+// handleScheduleTimer starts the flow run of a schedule and arms its next timer. The next
+// timer is stored before the flow starts, so a retried timer never starts a second run.
+func (e *FlowExecutor) handleScheduleTimer(ctx context.Context, t *db.Timer) error {
+	var payload struct {
+		ScheduleID string `json:"schedule_id"`
+		FireAt     string `json:"fire_at"`
+	}
+	if err := scheduler.DecodePayload(t, &payload); err != nil {
+		return err
+	}
+	s, err := e.db.GetFlowSchedule(ctx, payload.ScheduleID)
+	if err != nil {
+		return err
+	}
+	// Deleted, disabled, or superseded by a newer timer
+	if s == nil || !s.Enabled || s.NextRunAt == nil || s.NextRunAt.UTC().Format(time.RFC3339) != payload.FireAt {
+		return nil
+	}
+
+	next, err := scheduleNext(s, maxTime(e.scheduler.Now(), t.FireAt))
+	if err != nil {
+		e.logger.Errorw("Flow schedule can no longer fire", "schedule_id", s.ID, "error", err)
+		return nil
+	}
+	if err := e.scheduleFlowTimer(ctx, s.ID, next); err != nil {
+		return err
+	}
+	if err := e.db.UpdateFlowScheduleNextRun(ctx, s.ID, next); err != nil {
+		return fmt.Errorf("update next run: %w", err)
+	}
+
+	flowRunID := e.startScheduledFlow(ctx, s)
+	if err := e.db.UpdateFlowScheduleLastRun(ctx, s.ID, e.scheduler.Now(), flowRunID); err != nil {
+		e.logger.Warnw("Failed to record flow schedule run", "schedule_id", s.ID, "error", err)
+	}
+	return nil
+}
+
+// startScheduledFlow creates and starts a flow run for a schedule, returning its ID ("" when
+// skipped). Tasks that still have an active flow run are skipped.
+func (e *FlowExecutor) startScheduledFlow(ctx context.Context, s *db.FlowSchedule) string {
+	active, err := e.db.HasActiveFlowRun(ctx, s.TaskID)
+	if err != nil {
+		e.logger.Warnw("Failed to check active flow runs", "schedule_id", s.ID, "error", err)
+	}
+	if active {
+		e.logger.Infow("Skipping scheduled flow: task has an active flow run", "schedule_id", s.ID, "task_id", s.TaskID)
+		e.recordTimeline(ctx, s.TaskID, "", "", "flow_schedule_skipped", map[string]any{
+			"schedule_id": s.ID,
+			"cron":        s.Cron,
+			"message":     fmt.Sprintf("定时流程已跳过（%s）：任务仍有进行中的流程", s.Cron),
+		})
+		return ""
+	}
+
+	flowRunID, err := e.db.CreateFlowRun(ctx, s.TaskID, s.WorkflowID)
+	if err != nil {
+		e.logger.Errorw("Failed to create scheduled flow run", "schedule_id", s.ID, "error", err)
+		return ""
+	}
+	e.recordTimeline(ctx, s.TaskID, flowRunID, "", "flow_scheduled", map[string]any{
+		"schedule_id": s.ID,
+		"cron":        s.Cron,
+		"message":     fmt.Sprintf("定时启动流程（%s）", s.Cron),
+	})
+
+	dsl, params, err := e.db.GetWorkflowDSL(ctx, s.WorkflowID)
+	if err == nil {
+		err = e.StartFlow(ctx, flowRunID, dsl, mergeVariables(params, s.Variables))
+	}
+	if err != nil {
+		e.logger.Errorw("Scheduled flow failed to start", "schedule_id", s.ID, "flow_run_id", flowRunID, "error", err)
+		if err := e.db.UpdateFlowRunError(ctx, flowRunID, db.StatusFailed, err.Error()); err != nil {
+			e.logger.Warnw("Failed to mark scheduled flow run failed", "flow_run_id", flowRunID, "error", err)
+		}
+		return flowRunID
+	}
+
+	e.logger.Infow("Scheduled flow started", "schedule_id", s.ID, "flow_run_id", flowRunID)
+	return flowRunID
+}
+
+// mergeVariables returns the workflow params overridden by the schedule's variables
+func mergeVariables(params, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(params)+len(overrides))
+	maps.Copy(merged, params)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,296 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Script node on_failure policies
+const (
+	ScriptOnFailureFail   = "fail"   // fail the node and the flow (default)
+	ScriptOnFailureReject = "reject" // roll back like a rejected review (on_reject goto / max_loops)
+)
+
+// scriptOutputTailBytes is how much of stdout / stderr is kept in the node's outputs
+const scriptOutputTailBytes = 4 * 1024
+
+// validateScript checks a script node's config
+func validateScript(cfg *NodeConfigDef) error {
+	if cfg == nil || strings.TrimSpace(cfg.Run) == "" {
+		return fmt.Errorf("script needs a run command")
+	}
+	switch cfg.Executor {
+	case "", agent.ScriptRunnerDocker, agent.ScriptRunnerLocal:
+	default:
+		return fmt.Errorf("unknown script executor %q (docker / local)", cfg.Executor)
+	}
+	switch cfg.OnFailure {
+	case "", ScriptOnFailureFail, ScriptOnFailureReject:
+	default:
+		return fmt.Errorf("unknown on_failure %q (fail / reject)", cfg.OnFailure)
+	}
+	if cfg.OutputFile != "" {
+		clean := path.Clean(cfg.OutputFile)
+		if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return fmt.Errorf("output_file must be a path inside the checkout")
+		}
+	}
+	if cfg.Timeout != "" {
+		if _, err := parseDelay(cfg.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// scriptOutputs builds a script node's outputs from its result
+func scriptOutputs(res *agent.ScriptResult, redactor *agent.Redactor) map[string]any {
+	output := map[string]any{
+		"exit_code":   res.ExitCode,
+		"passed":      res.ExitCode == 0,
+		"duration_ms": res.DurationMs,
+		"stdout":      redactor.String(truncateTail(res.Stdout, scriptOutputTailBytes)),
+		"stderr":      redactor.String(truncateTail(res.Stderr, scriptOutputTailBytes)),
+	}
+	if res.Output != nil {
+		output["result"] = redactor.Map(res.Output)
+	}
+	return output
+}
+
+// truncateTail keeps the last n bytes of s (on a UTF-8 boundary)
+func truncateTail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "…" + strings.ToValidUTF8(s[len(s)-n:], "")
+}
+
+// ─── script ───
+
+func (e *FlowExecutor) executeScript(ctx context.Context, nodeRun *db.NodeRun) error {
+	// Script: run a deterministic command in a checkout of the task repo; the exit code
+	// decides pass / fail, and the optional output_file becomes outputs.result
+
+	flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID)
+	if err != nil {
+		return fmt.Errorf("load flow run: %w", err)
+	}
+	wf, dag, err := ParseDSL(*flowRun.DslSnapshot)
+	if err != nil {
+		return fmt.Errorf("parse DSL: %w", err)
+	}
+	nodeDef := dag.GetNode(nodeRun.NodeID)
+	if nodeDef == nil {
+		return fmt.Errorf("node %s not found in DAG", nodeRun.NodeID)
+	}
+	cfg := nodeDef.Config
+	if err := validateScript(cfg); err != nil {
+		return err
+	}
+
+	kind := cfg.Executor
+	if kind == "" {
+		kind = agent.ScriptRunnerDocker
+	}
+	runner, ok := e.scriptRunners[kind]
+	if !ok {
+		return fmt.Errorf("script executor %s is not available", kind)
+	}
+
+	// 1. Render the command and environment with the runtime context
+	runtimeCtx := e.buildRuntimeContext(ctx, flowRun, nodeRun)
+	command, err := RenderTemplate(cfg.Run, runtimeCtx)
+	if err != nil {
+		return fmt.Errorf("render run: %w", err)
+	}
+	env := make(map[string]string, len(cfg.Env))
+	for k, v := range cfg.Env {
+		rendered, err := RenderTemplate(v, runtimeCtx)
+		if err != nil {
+			return fmt.Errorf("render env %s: %w", k, err)
+		}
+		env[k] = rendered
+	}
+
+	var timeout time.Duration
+	if cfg.Timeout != "" {
+		timeout, _ = parseDelay(cfg.Timeout)
+	}
+
+	gitRepoURL, gitBranch, gitAccessToken, _, err := e.db.GetTaskGitInfoFull(ctx, flowRun.TaskID)
+	if err != nil {
+		e.logger.Warnw("Failed to get git info", "error", err)
+	}
+
+	// Output lines, outputs and errors are scrubbed of the token and secret-looking env values
+	redactor := e.redactor.Fork()
+	redactor.AddSecrets(gitAccessToken)
+	redactor.AddEnvSecrets(env)
+
+	req := &agent.ScriptRequest{
+		NodeRunID:  nodeRun.ID,
+		Command:    command,
+		Image:      cfg.Image,
+		Env:        env,
+		Timeout:    timeout,
+		OutputFile: cfg.OutputFile,
+		GitRepoURL: gitRepoURL,
+		GitBranch:  gitBranch,
+	}
+
+	// 2. Workspace: containers share the flow's workspace volume and the repo mirror like
+	// agent nodes do; local processes clone into a temporary directory
+	var inputCtx map[string]any
+	if nodeRun.Input != nil {
+		_ = json.Unmarshal([]byte(*nodeRun.Input), &inputCtx)
+	}
+	_, rejected := inputCtx["_reject_from"]
+	switch kind {
+	case agent.ScriptRunnerDocker:
+		workspaceMount, err := e.sharedWorkspaceMount(ctx, wf, flowRun, nodeRun, rejected)
+		if err != nil {
+			return err
+		}
+		if workspaceMount != nil {
+			req.Mounts = append(req.Mounts, *workspaceMount)
+			req.PersistentWorkspace = true
+		}
+		if e.repoMirror != nil && gitRepoURL != "" {
+			if mirrorMount, referenceRepo, ok := e.repoMirrorMount(ctx, gitRepoURL); ok {
+				req.Mounts = append(req.Mounts, mirrorMount)
+				req.GitReferenceRepo = referenceRepo
+			}
+		}
+		if cfg.Container != nil {
+			if req.Limits, err = toContainerLimits(cfg.Container); err != nil {
+				return fmt.Errorf("invalid container config: %w", err)
+			}
+		}
+	case agent.ScriptRunnerLocal:
+		if wf.SharedWorkspace() {
+			return fmt.Errorf("the local script executor cannot use workspace: %s", wf.Workspace)
+		}
+		if e.repoMirror != nil && gitRepoURL != "" {
+			if mirrorPath, err := e.repoMirror.Sync(ctx, gitRepoURL); err != nil {
+				e.logger.Warnw("Failed to sync repo mirror, cloning without reference", "error", err)
+			} else {
+				req.GitReferenceRepo = mirrorPath
+			}
+		}
+	}
+
+	// 3. Stream stdout / stderr lines to the node log while the script runs
+	logWriter := e.newNodeLogWriter(ctx, nodeRun.ID, func(flatEvent map[string]any) {
+		e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.log_stream", flatEvent)
+	})
+	req.OnOutput = func(stream, line string) {
+		logWriter.Append(map[string]any{
+			"type":      stream,
+			"content":   redactor.String(line),
+			"timestamp": time.Now().UnixMilli(),
+		})
+	}
+
+	e.logger.Infow("Executing script",
+		"node_id", nodeRun.NodeID,
+		"executor", kind,
+		"image", cfg.Image,
+		"git_repo", gitRepoURL,
+		"git_branch", gitBranch,
+	)
+	res, err := runner.Run(ctx, req)
+	if err != nil {
+		logWriter.Close()
+		return redactor.Error(err)
+	}
+	logWriter.Append(map[string]any{
+		"type":      "result",
+		"subtype":   fmt.Sprintf("exit_code=%d", res.ExitCode),
+		"result":    fmt.Sprintf("脚本退出码 %d，耗时 %dms", res.ExitCode, res.DurationMs),
+		"timestamp": time.Now().UnixMilli(),
+	})
+	logWriter.Close()
+
+	output := scriptOutputs(res, redactor)
+	if err := e.db.UpdateNodeRunOutput(ctx, nodeRun.ID, output); err != nil {
+		return fmt.Errorf("save output: %w", err)
+	}
+
+	// 4. A successful run must produce its output file
+	if res.ExitCode == 0 && cfg.OutputFile != "" && res.Output == nil {
+		return fmt.Errorf("script output: %s", redactor.String(res.OutputError))
+	}
+
+	if res.ExitCode != 0 {
+		if cfg.OnFailure == ScriptOnFailureReject {
+			return e.rejectScript(ctx, flowRun, nodeRun, nodeDef, res, output)
+		}
+		return fmt.Errorf("script exited with code %d: %s", res.ExitCode,
+			redactor.String(truncateTail(strings.TrimSpace(res.Stderr), 1024)))
+	}
+
+	// 5. Mark completed
+	if err := e.db.UpdateNodeRunStatus(ctx, nodeRun.ID, db.StatusCompleted); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.completed", map[string]any{
+		"output": output,
+	})
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "script_completed", map[string]any{
+		"node_id":     nodeRun.NodeID,
+		"node_name":   ptrStr(nodeRun.NodeName),
+		"exit_code":   res.ExitCode,
+		"passed":      res.ExitCode == 0,
+		"duration_ms": res.DurationMs,
+		"message":     fmt.Sprintf("脚本执行完成：%s（退出码 %d）", ptrStr(nodeRun.NodeName), res.ExitCode),
+	})
+	return nil
+}
+
+// rejectScript rolls a failed script node back like a rejected review: the on_reject target
+// re-runs with the script's stderr as feedback
+func (e *FlowExecutor) rejectScript(ctx context.Context, flowRun *db.FlowRun, nodeRun *db.NodeRun, nodeDef *NodeDef, res *agent.ScriptResult, output map[string]any) error {
+	feedback := fmt.Sprintf("脚本 %s 执行失败（退出码 %d）", ptrStr(nodeRun.NodeName), res.ExitCode)
+	if stderr, _ := output["stderr"].(string); strings.TrimSpace(stderr) != "" {
+		feedback += "：\n" + strings.TrimSpace(stderr)
+	}
+
+	if err := e.db.UpdateNodeRunStatus(ctx, nodeRun.ID, db.StatusRejected); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.rejected", map[string]any{
+		"feedback": feedback,
+		"output":   output,
+	})
+
+	targetNodeID, attempt, exhausted, err := e.rollbackFrom(ctx, flowRun, nodeRun, nodeDef, map[string]any{
+		"_feedback":    feedback,
+		"_reject_from": nodeRun.NodeID,
+	})
+	if err != nil || exhausted {
+		return err
+	}
+
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "script_rejected", map[string]any{
+		"node_id":     nodeRun.NodeID,
+		"node_name":   ptrStr(nodeRun.NodeName),
+		"exit_code":   res.ExitCode,
+		"rollback_to": targetNodeID,
+		"attempt":     attempt,
+		"message":     fmt.Sprintf("脚本失败打回：%s → 回退到 %s（第 %d 次）", ptrStr(nodeRun.NodeName), targetNodeID, attempt),
+	})
+	e.logger.Infow("Script failed, rolling back",
+		"from_node", nodeRun.NodeID,
+		"to_node", targetNodeID,
+		"attempt", attempt,
+	)
+	return nil
+}
@@ -3,19 +3,22 @@ package engine
 import (
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/flosch/pongo2/v6"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
 )
 
 func init() {
-	// Register "truncate" as alias for "truncatechars"
+	// Register "truncate": keep the first n characters (runes, so CJK text is never cut mid-character)
 	pongo2.RegisterFilter("truncate", func(in *pongo2.Value, param *pongo2.Value) (*pongo2.Value, *pongo2.Error) {
 		s := in.String()
 		n := param.Integer()
-		if n <= 0 || n >= len(s) {
+		if n <= 0 || n >= utf8.RuneCountInString(s) {
 			return in, nil
 		}
-		return pongo2.AsValue(s[:n]), nil
+		return pongo2.AsValue(agent.TruncateRunes(s, n)), nil
 	})
 }
 
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateFilter(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		in   string
+		want string
+	}{
+		{"{{ s|truncate:3 }}", "hello", "hel"},
+		{"{{ s|truncate:3 }}", "需求文档说明", "需求文"},
+		{"{{ s|truncate:2 }}", "a😀b", "a😀"},
+		{"{{ s|truncate:10 }}", "需求", "需求"},
+		{"{{ s|truncate:0 }}", "需求", "需求"},
+	}
+	for _, tt := range tests {
+		got, err := RenderTemplate(tt.tmpl, map[string]any{"s": tt.in})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.tmpl, err)
+		}
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("%s with %q = %q, want %q", tt.tmpl, tt.in, got, tt.want)
+		}
+	}
+}
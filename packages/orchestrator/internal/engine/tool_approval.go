@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// toolApprovalPollInterval is how often a blocked tool call checks for a decision.
+// Decisions are read from the database, so any orchestrator instance can take the RPC.
+const toolApprovalPollInterval = 2 * time.Second
+
+// toolApprover pauses one node run's gated tool calls until they are approved or denied
+// (agent.ToolApprover)
+type toolApprover struct {
+	e        *FlowExecutor
+	flowRun  *db.FlowRun
+	nodeRun  *db.NodeRun
+	timeout  time.Duration
+	redactor *agent.Redactor
+}
+
+func (e *FlowExecutor) newToolApprover(flowRun *db.FlowRun, nodeRun *db.NodeRun, policy *agent.ToolApprovalPolicy, redactor *agent.Redactor) *toolApprover {
+	return &toolApprover{e: e, flowRun: flowRun, nodeRun: nodeRun, timeout: policy.EffectiveTimeout(), redactor: redactor}
+}
+
+// RequestToolApproval records the call, moves the node to waiting_tool_approval and blocks
+// until a decision arrives, the timeout expires or the execution is cancelled
+func (a *toolApprover) RequestToolApproval(ctx context.Context, req *agent.ToolApprovalRequest) agent.ToolApprovalDecision {
+	e, nodeRun := a.e, a.nodeRun
+	if _, err := uuid.Parse(req.ID); err != nil {
+		return agent.ToolApprovalDecision{Reason: "invalid approval request id"}
+	}
+	input := a.redactor.Map(req.ToolInput)
+
+	if err := e.db.CreateToolApproval(ctx, &db.ToolApproval{
+		ID:        req.ID,
+		NodeRunID: nodeRun.ID,
+		ToolName:  req.ToolName,
+		ToolInput: input,
+		Rule:      req.Rule,
+		Reason:    req.Reason,
+	}); err != nil {
+		e.logger.Errorw("Failed to record tool approval, denying tool call", "node_run_id", nodeRun.ID, "approval_id", req.ID, "error", err)
+		return agent.ToolApprovalDecision{Reason: "approval could not be recorded"}
+	}
+
+	expiresAt := time.Now().Add(a.timeout)
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.tool_approval_requested", map[string]any{
+		"approval_id": req.ID,
+		"tool_name":   req.ToolName,
+		"tool_input":  input,
+		"rule":        req.Rule,
+		"reason":      req.Reason,
+		"expires_at":  expiresAt.Format(time.RFC3339),
+	})
+	e.recordTimeline(ctx, a.flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "tool_approval_requested", map[string]any{
+		"node_id":     nodeRun.NodeID,
+		"node_name":   ptrStr(nodeRun.NodeName),
+		"approval_id": req.ID,
+		"tool_name":   req.ToolName,
+		"message":     fmt.Sprintf("工具调用等待审批：%s", req.ToolName),
+	})
+
+	status, decision := a.wait(ctx, req.ID, expiresAt)
+
+	if err := e.db.ResumeNodeRunAfterToolApproval(context.WithoutCancel(ctx), nodeRun.ID); err != nil {
+		e.logger.Warnw("Failed to resume node run after tool approval", "node_run_id", nodeRun.ID, "error", err)
+	}
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.tool_approval_resolved", map[string]any{
+		"approval_id": req.ID,
+		"tool_name":   req.ToolName,
+		"status":      status,
+		"approved":    decision.Approved,
+		"reason":      decision.Reason,
+	})
+	e.logger.Infow("Tool approval resolved", "node_run_id", nodeRun.ID, "approval_id", req.ID, "tool", req.ToolName, "status", status)
+	return decision
+}
+
+// wait polls for the decision; undecided calls expire at expiresAt
+func (a *toolApprover) wait(ctx context.Context, id string, expiresAt time.Time) (string, agent.ToolApprovalDecision) {
+	ticker := time.NewTicker(toolApprovalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Execution cancelled (flow cancelled or worker shutting down)
+			_, _ = a.e.db.DecideToolApproval(context.WithoutCancel(ctx), id, db.ToolApprovalExpired, "execution cancelled", "")
+			return db.ToolApprovalExpired, agent.ToolApprovalDecision{Reason: "execution cancelled"}
+		case <-ticker.C:
+		}
+
+		approval, err := a.e.db.GetToolApproval(ctx, id)
+		if err != nil {
+			a.e.logger.Warnw("Failed to check tool approval", "approval_id", id, "error", err)
+			continue
+		}
+		if approval != nil && approval.Status != db.ToolApprovalPending {
+			decision := agent.ToolApprovalDecision{Approved: approval.Status == db.ToolApprovalApproved}
+			if approval.DecisionReason != nil {
+				decision.Reason = *approval.DecisionReason
+			}
+			return approval.Status, decision
+		}
+
+		if time.Now().After(expiresAt) {
+			expired, err := a.e.db.DecideToolApproval(ctx, id, db.ToolApprovalExpired, "approval timed out", "")
+			if err != nil {
+				a.e.logger.Warnw("Failed to expire tool approval", "approval_id", id, "error", err)
+			}
+			if expired {
+				return db.ToolApprovalExpired, agent.ToolApprovalDecision{Reason: "approval timed out"}
+			}
+			// Decided at the last moment: pick it up on the next tick
+		}
+	}
+}
+
+// toolApprovalDecider returns who decided a tool call: the authenticated user, else the user
+// named in the request (service callers and dev mode). decided_by references users, so a
+// non-UUID name is dropped.
+func toolApprovalDecider(ctx context.Context, requested string) string {
+	decidedBy := requested
+	if userID := auth.FromContext(ctx).UserID(); userID != "" {
+		decidedBy = userID
+	}
+	if _, err := uuid.Parse(decidedBy); err != nil {
+		return ""
+	}
+	return decidedBy
+}
+
+// HandleToolCallDecision approves or denies a pending tool call. The blocked execution
+// picks the decision up on its next poll.
+func (e *FlowExecutor) HandleToolCallDecision(ctx context.Context, approvalID string, approved bool, reason, decidedBy string) error {
+	approval, err := e.db.GetToolApproval(ctx, approvalID)
+	if err != nil {
+		return err
+	}
+	if approval == nil {
+		return fmt.Errorf("tool approval not found: %s", approvalID)
+	}
+	if approval.Status != db.ToolApprovalPending {
+		return fmt.Errorf("tool approval is not pending, current status: %s", approval.Status)
+	}
+
+	decidedBy = toolApprovalDecider(ctx, decidedBy)
+
+	status := db.ToolApprovalDenied
+	if approved {
+		status = db.ToolApprovalApproved
+	}
+	decided, err := e.db.DecideToolApproval(ctx, approvalID, status, reason, decidedBy)
+	if err != nil {
+		return err
+	}
+	if !decided {
+		return fmt.Errorf("tool approval was decided concurrently")
+	}
+
+	nodeRun, err := e.db.GetNodeRun(ctx, approval.NodeRunID)
+	if err != nil {
+		return fmt.Errorf("get node run: %w", err)
+	}
+	if flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID); err == nil {
+		eventType, message := "tool_approval_approved", fmt.Sprintf("工具调用已批准：%s", approval.ToolName)
+		if !approved {
+			eventType, message = "tool_approval_denied", fmt.Sprintf("工具调用已拒绝：%s", approval.ToolName)
+		}
+		e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, eventType, withActor(ctx, map[string]any{
+			"node_id":     nodeRun.NodeID,
+			"node_name":   ptrStr(nodeRun.NodeName),
+			"approval_id": approvalID,
+			"tool_name":   approval.ToolName,
+			"reason":      reason,
+			"message":     message,
+		}))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+)
+
+func TestToolApprovalDecider(t *testing.T) {
+	const (
+		user    = "6f1c2a3e-8b4d-4e5f-9a0b-1c2d3e4f5a6b"
+		claimed = "0d9e8f7a-6b5c-4d3e-2f1a-0b9c8d7e6f5a"
+	)
+	jwt := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: user, Kind: auth.KindJWT})
+	service := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "service", Kind: auth.KindToken})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		requested string
+		want      string
+	}{
+		{"authenticated user wins over the request", jwt, claimed, user},
+		{"authenticated user without a request", jwt, "", user},
+		{"service caller names the user", service, claimed, claimed},
+		{"unauthenticated dev mode", context.Background(), claimed, claimed},
+		{"non-UUID name is dropped", service, "alice", ""},
+		{"nobody", context.Background(), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolApprovalDecider(tt.ctx, tt.requested); got != tt.want {
+				t.Errorf("decider = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+	"github.com/sunshow/workgear/orchestrator/internal/scheduler"
+)
+
+// TimerKindNodeWait is the timer kind that resumes a wait node
+const TimerKindNodeWait = "node_wait"
+
+// validateWait checks that a wait node sets exactly one of duration / until
+func validateWait(cfg *NodeConfigDef) error {
+	if cfg == nil || (cfg.Duration == "") == (cfg.Until == "") {
+		return fmt.Errorf("wait needs exactly one of duration or until")
+	}
+	if cfg.Duration != "" {
+		if _, err := parseDelay(cfg.Duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitUntil returns when a wait node's wait ends: now + duration, or the rendered until
+// timestamp (RFC3339, "2006-01-02 15:04:05" or "2006-01-02", the latter two in UTC)
+func waitUntil(cfg *NodeConfigDef, runtimeCtx map[string]any, now time.Time) (time.Time, error) {
+	if cfg.Duration != "" {
+		delay, err := parseDelay(cfg.Duration)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(delay), nil
+	}
+
+	rendered, err := RenderTemplate(cfg.Until, runtimeCtx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("render until: %w", err)
+	}
+	rendered = strings.TrimSpace(rendered)
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.Parse(layout, rendered); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("until %q is not a timestamp", rendered)
+}
+
+// ─── wait ───
+
+func (e *FlowExecutor) executeWait(ctx context.Context, nodeRun *db.NodeRun) error {
+	// Wait: park the node in WAITING_TIMER and release the worker; the node_wait timer
+	// completes it when the wait is over
+
+	flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID)
+	if err != nil {
+		return fmt.Errorf("load flow run: %w", err)
+	}
+	nodeDef, err := e.getNodeDef(flowRun, nodeRun.NodeID)
+	if err != nil {
+		return err
+	}
+	if err := validateWait(nodeDef.Config); err != nil {
+		return err
+	}
+
+	now := e.scheduler.Now()
+	until, err := waitUntil(nodeDef.Config, e.buildRuntimeContext(ctx, flowRun, nodeRun), now)
+	if err != nil {
+		return err
+	}
+
+	// Already past: complete right away (the worker loop advances the DAG)
+	if !until.After(now) {
+		output := map[string]any{"waited_until": until.Format(time.RFC3339), "waited": "0s"}
+		if err := e.db.UpdateNodeRunOutput(ctx, nodeRun.ID, output); err != nil {
+			return fmt.Errorf("save output: %w", err)
+		}
+		if err := e.db.UpdateNodeRunStatus(ctx, nodeRun.ID, db.StatusCompleted); err != nil {
+			return fmt.Errorf("update status: %w", err)
+		}
+		e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.completed", map[string]any{
+			"output": output,
+		})
+		return nil
+	}
+
+	if err := e.db.UpdateNodeRunStatus(ctx, nodeRun.ID, db.StatusWaitingTimer); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	if _, err := e.scheduler.Schedule(ctx, TimerKindNodeWait, nodeRunTimerKey(nodeRun.ID), until, map[string]any{
+		"node_run_id": nodeRun.ID,
+		"started_at":  now.Format(time.RFC3339),
+	}); err != nil {
+		return fmt.Errorf("schedule wait timer: %w", err)
+	}
+
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.waiting_timer", map[string]any{
+		"node_name": ptrStr(nodeRun.NodeName),
+		"until":     until.Format(time.RFC3339),
+	})
+	e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "waiting_timer", map[string]any{
+		"node_id":   nodeRun.NodeID,
+		"node_name": ptrStr(nodeRun.NodeName),
+		"until":     until.Format(time.RFC3339),
+		"message":   fmt.Sprintf("等待至 %s：%s", until.Format(time.DateTime), ptrStr(nodeRun.NodeName)),
+	})
+
+	return nil
+}
+
+// handleWaitTimer completes a wait node whose wait is over and advances the DAG. Nodes that
+// left waiting_timer in the meantime (flow cancelled) are left alone.
+func (e *FlowExecutor) handleWaitTimer(ctx context.Context, t *db.Timer) error {
+	var payload struct {
+		NodeRunID string `json:"node_run_id"`
+		StartedAt string `json:"started_at"`
+	}
+	if err := scheduler.DecodePayload(t, &payload); err != nil {
+		return err
+	}
+	nodeRun, err := e.db.GetNodeRun(ctx, payload.NodeRunID)
+	if err != nil {
+		return err
+	}
+	if nodeRun.Status != db.StatusWaitingTimer {
+		return nil
+	}
+
+	output := map[string]any{"waited_until": t.FireAt.Format(time.RFC3339)}
+	if started, err := time.Parse(time.RFC3339, payload.StartedAt); err == nil {
+		output["waited"] = t.FireAt.Sub(started).Round(time.Second).String()
+	}
+	if err := e.db.UpdateNodeRunOutput(ctx, nodeRun.ID, output); err != nil {
+		return fmt.Errorf("save output: %w", err)
+	}
+	completed, err := e.db.TransitionNodeRunStatus(ctx, nodeRun.ID, db.StatusWaitingTimer, db.StatusCompleted)
+	if err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	if !completed {
+		return nil
+	}
+
+	e.publishEvent(nodeRun.FlowRunID, nodeRun.ID, nodeRun.NodeID, "node.completed", map[string]any{
+		"output": output,
+	})
+	if flowRun, err := e.db.GetFlowRun(ctx, nodeRun.FlowRunID); err == nil {
+		e.recordTimeline(ctx, flowRun.TaskID, nodeRun.FlowRunID, nodeRun.ID, "wait_completed", map[string]any{
+			"node_id":   nodeRun.NodeID,
+			"node_name": ptrStr(nodeRun.NodeName),
+			"message":   fmt.Sprintf("等待结束：%s", ptrStr(nodeRun.NodeName)),
+		})
+	}
+
+	return e.advanceDAG(ctx, nodeRun.FlowRunID)
+}
@@ -0,0 +1,201 @@
+package gitmirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// freshness is how long a fetched mirror is considered up to date for reads (git show).
+// Explicit Sync calls always fetch.
+const freshness = 10 * time.Second
+
+// Manager maintains one bare mirror per repository under a cache directory.
+// Mirrors are fetched incrementally and mounted read-only into agent containers
+// as a `git clone --reference` source, so each run only downloads new objects.
+type Manager struct {
+	dir        string // cache directory as seen by the orchestrator
+	hostSource string // same cache as seen by the Docker daemon: a host path or a named volume
+	logger     *zap.SugaredLogger
+
+	mu        sync.Mutex
+	repoLocks map[string]*sync.Mutex
+	lastFetch map[string]time.Time
+}
+
+// NewManager creates a mirror manager rooted at dir.
+// hostSource is what agent containers mount: an absolute host path (bind mount) or a
+// named volume, needed when the orchestrator itself runs in a container. Empty means dir.
+func NewManager(dir, hostSource string, logger *zap.SugaredLogger) (*Manager, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve repo cache dir: %w", err)
+	}
+	if err := os.MkdirAll(absDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create repo cache dir: %w", err)
+	}
+	if hostSource == "" {
+		hostSource = absDir
+	}
+	return &Manager{
+		dir:        absDir,
+		hostSource: hostSource,
+		logger:     logger,
+		repoLocks:  make(map[string]*sync.Mutex),
+		lastFetch:  make(map[string]time.Time),
+	}, nil
+}
+
+// Sync creates the mirror for repoURL if missing and fetches all branches and tags.
+// repoURL may carry credentials; they are used for the fetch but never stored in the mirror.
+// Returns the mirror's local path.
+func (m *Manager) Sync(ctx context.Context, repoURL string) (string, error) {
+	return m.sync(ctx, repoURL, 0)
+}
+
+// Show returns the content of filePath at branch, read from the mirror
+func (m *Manager) Show(ctx context.Context, repoURL, branch, filePath string) (string, error) {
+	path, err := m.sync(ctx, repoURL, freshness)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "--git-dir", path, "show", fmt.Sprintf("refs/heads/%s:%s", branch, filePath))
+	cmd.Env = gitEnv()
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git show: %w", err)
+	}
+	return string(output), nil
+}
+
+// HostSource returns the cache as seen by the Docker daemon; containers mount a single
+// mirror beneath it (<HostSource>/<name>.git, or that subpath of the volume)
+func (m *Manager) HostSource() string {
+	return m.hostSource
+}
+
+// IsVolume reports whether HostSource is a named volume rather than a host path
+func (m *Manager) IsVolume() bool {
+	return !filepath.IsAbs(m.hostSource)
+}
+
+func (m *Manager) sync(ctx context.Context, repoURL string, maxAge time.Duration) (string, error) {
+	cleanURL := stripCredentials(repoURL)
+	if cleanURL == "" {
+		return "", fmt.Errorf("empty repo URL")
+	}
+	key := mirrorKey(cleanURL)
+	path := filepath.Join(m.dir, key+".git")
+
+	lock := m.repoLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if maxAge > 0 && m.fetchedWithin(key, maxAge) {
+		return path, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(path, "HEAD")); os.IsNotExist(err) {
+		m.logger.Infow("Creating repo mirror", "repo", cleanURL, "path", path)
+		if err := runGit(ctx, "", "init", "--bare", "--quiet", path); err != nil {
+			return "", fmt.Errorf("init mirror: %w", err)
+		}
+	}
+
+	start := time.Now()
+	// Fetch from the (possibly credentialed) URL directly instead of a stored remote,
+	// so tokens never end up in the mirror's config.
+	if err := runGit(ctx, path, "fetch", "--prune", "--quiet", repoURL,
+		"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"); err != nil {
+		return "", fmt.Errorf("fetch mirror: %w", err)
+	}
+
+	m.mu.Lock()
+	m.lastFetch[key] = time.Now()
+	m.mu.Unlock()
+
+	m.logger.Infow("Repo mirror synced", "repo", cleanURL, "duration_ms", time.Since(start).Milliseconds())
+	return path, nil
+}
+
+func (m *Manager) repoLock(key string) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lock, ok := m.repoLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.repoLocks[key] = lock
+	}
+	return lock
+}
+
+func (m *Manager) fetchedWithin(key string, maxAge time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	last, ok := m.lastFetch[key]
+	return ok && time.Since(last) < maxAge
+}
+
+// runGit runs a git command, returning stderr in the error on failure
+func runGit(ctx context.Context, gitDir string, args ...string) error {
+	if gitDir != "" {
+		args = append([]string{"--git-dir", gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = gitEnv()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, redactURLs(strings.TrimSpace(string(out))))
+	}
+	return nil
+}
+
+// gitEnv disables interactive credential prompts
+func gitEnv() []string {
+	return append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+}
+
+// mirrorKey derives a stable directory name from a credential-free repo URL
+func mirrorKey(cleanURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(strings.ToLower(cleanURL), ".git")))
+	name := strings.TrimSuffix(filepath.Base(cleanURL), ".git")
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+	return name + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// stripCredentials removes user info from an HTTPS URL: https://token@host/... → https://host/...
+func stripCredentials(rawURL string) string {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rest := rawURL[i+3:]
+		slash := strings.Index(rest, "/")
+		at := strings.Index(rest, "@")
+		if at >= 0 && (slash < 0 || at < slash) {
+			return rawURL[:i+3] + rest[at+1:]
+		}
+	}
+	return rawURL
+}
+
+// redactURLs strips credentials from any URLs in git output
+func redactURLs(s string) string {
+	for _, f := range strings.Fields(s) {
+		if strings.Contains(f, "://") {
+			s = strings.ReplaceAll(s, f, stripCredentials(f))
+		}
+	}
+	return s
+}
@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+)
+
+// methodRoles is the role each OrchestratorService RPC requires. Methods missing here
+// (e.g. RPCs added later) require admin, so a new RPC is never open by accident.
+var methodRoles = map[string]string{
+	"StartFlow":           auth.RoleOperator,
+	"CancelFlow":          auth.RoleOperator,
+	"ScheduleFlow":        auth.RoleOperator,
+	"ListFlowSchedules":   auth.RoleViewer,
+	"DeleteFlowSchedule":  auth.RoleOperator,
+	"RetryNode":           auth.RoleOperator,
+	"SubmitHumanInput":    auth.RoleOperator,
+	"ApproveNode":         auth.RoleReviewer,
+	"RejectNode":          auth.RoleReviewer,
+	"EditNode":            auth.RoleReviewer,
+	"ApproveToolCall":     auth.RoleReviewer,
+	"DenyToolCall":        auth.RoleReviewer,
+	"TestAgent":           auth.RoleAdmin,
+	"ReloadAgentRegistry": auth.RoleAdmin,
+	"DescribeAgentRoles":  auth.RoleViewer,
+	"GetNodeRunLogs":      auth.RoleViewer,
+	"GetFlowRun":          auth.RoleViewer,
+	"ListNodeRuns":        auth.RoleViewer,
+	"GetNodeRun":          auth.RoleViewer,
+	"ListTimeline":        auth.RoleViewer,
+	"EventStream":         auth.RoleViewer,
+}
+
+// unauthenticatedServices are reachable without credentials (probes)
+var unauthenticatedServices = []string{"/grpc.health.v1.Health/"}
+
+// Authenticator authenticates bearer tokens on every RPC and enforces methodRoles
+type Authenticator struct {
+	verifier *auth.Verifier
+	logger   *zap.SugaredLogger
+}
+
+// NewAuthenticator creates the interceptors for a verifier
+func NewAuthenticator(verifier *auth.Verifier, logger *zap.SugaredLogger) *Authenticator {
+	return &Authenticator{verifier: verifier, logger: logger}
+}
+
+// ServerOptions returns the unary and stream interceptors
+func (a *Authenticator) ServerOptions() []grpclib.ServerOption {
+	return []grpclib.ServerOption{
+		grpclib.ChainUnaryInterceptor(a.unary),
+		grpclib.ChainStreamInterceptor(a.stream),
+	}
+}
+
+func (a *Authenticator) unary(ctx context.Context, req any, info *grpclib.UnaryServerInfo, handler grpclib.UnaryHandler) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *Authenticator) stream(srv any, ss grpclib.ServerStream, info *grpclib.StreamServerInfo, handler grpclib.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize verifies the caller's token and role, and attaches the principal to ctx
+func (a *Authenticator) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	for _, prefix := range unauthenticatedServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return ctx, nil
+		}
+	}
+
+	principal, err := a.verifier.Verify(bearerToken(ctx))
+	if err != nil {
+		a.logger.Warnw("Rejected unauthenticated RPC", "method", fullMethod, "error", err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	method := path.Base(fullMethod)
+	required, ok := methodRoles[method]
+	if !ok {
+		required = auth.RoleAdmin
+	}
+	if !principal.HasRole(required) {
+		a.logger.Warnw("Rejected unauthorized RPC", "method", method, "subject", principal.Subject, "roles", principal.Roles, "required", required)
+		return nil, status.Errorf(codes.PermissionDenied, "%s requires the %s role", method, required)
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// bearerToken returns the token of the "authorization: Bearer <token>" metadata
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token, found := strings.CutPrefix(value, "Bearer "); found {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// authenticatedStream carries the principal in the stream's context
+type authenticatedStream struct {
+	grpclib.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// TLSCredentials loads the server certificate for TLS. With a client CA, clients must
+// present a certificate signed by it (mTLS).
+func TLSCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+	cfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", clientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(cfg), nil
+}
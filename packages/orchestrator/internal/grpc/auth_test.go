@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sunshow/workgear/orchestrator/internal/auth"
+	pb "github.com/sunshow/workgear/orchestrator/internal/grpc/pb"
+)
+
+func TestMethodRolesCoverService(t *testing.T) {
+	desc := pb.OrchestratorService_ServiceDesc
+	var methods []string
+	for _, m := range desc.Methods {
+		methods = append(methods, m.MethodName)
+	}
+	for _, s := range desc.Streams {
+		methods = append(methods, s.StreamName)
+	}
+	for _, m := range methods {
+		if _, ok := methodRoles[m]; !ok {
+			t.Errorf("RPC %s has no role in methodRoles (it falls back to admin)", m)
+		}
+	}
+	if len(methodRoles) != len(methods) {
+		t.Errorf("methodRoles lists %d RPCs, the service has %d", len(methodRoles), len(methods))
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	a := NewAuthenticator(auth.NewVerifier(auth.Config{SharedToken: "viewer-token", TokenRoles: []string{auth.RoleViewer}}), zap.NewNop().Sugar())
+	roleTokens := map[string]string{}
+	for _, role := range []string{auth.RoleViewer, auth.RoleOperator, auth.RoleReviewer, auth.RoleAdmin} {
+		roleTokens[role] = role + "-token"
+	}
+	// One authenticator per role: shared-token callers carry the configured roles
+	authenticators := map[string]*Authenticator{}
+	for role, token := range roleTokens {
+		authenticators[role] = NewAuthenticator(auth.NewVerifier(auth.Config{SharedToken: token, TokenRoles: []string{role}}), zap.NewNop().Sugar())
+	}
+
+	tests := []struct {
+		role   string
+		method string
+		want   codes.Code
+	}{
+		{auth.RoleViewer, "GetFlowRun", codes.OK},
+		{auth.RoleViewer, "EventStream", codes.OK},
+		{auth.RoleViewer, "StartFlow", codes.PermissionDenied},
+		{auth.RoleViewer, "ApproveNode", codes.PermissionDenied},
+		{auth.RoleOperator, "StartFlow", codes.OK},
+		{auth.RoleOperator, "SubmitHumanInput", codes.OK},
+		{auth.RoleOperator, "ListTimeline", codes.OK},
+		{auth.RoleOperator, "ApproveNode", codes.PermissionDenied},
+		{auth.RoleOperator, "ApproveToolCall", codes.PermissionDenied},
+		{auth.RoleReviewer, "RejectNode", codes.OK},
+		{auth.RoleReviewer, "DenyToolCall", codes.OK},
+		{auth.RoleReviewer, "CancelFlow", codes.PermissionDenied},
+		{auth.RoleReviewer, "TestAgent", codes.PermissionDenied},
+		{auth.RoleOperator, "ReloadAgentRegistry", codes.PermissionDenied},
+		{auth.RoleAdmin, "ReloadAgentRegistry", codes.OK},
+		{auth.RoleAdmin, "ApproveNode", codes.OK},
+		// RPCs missing from methodRoles require admin
+		{auth.RoleOperator, "SomeFutureRPC", codes.PermissionDenied},
+		{auth.RoleAdmin, "SomeFutureRPC", codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role+"/"+tt.method, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+roleTokens[tt.role]))
+			ctx, err := authenticators[tt.role].authorize(ctx, "/orchestrator.OrchestratorService/"+tt.method)
+			if got := status.Code(err); got != tt.want {
+				t.Fatalf("code = %s, want %s (%v)", got, tt.want, err)
+			}
+			if err == nil {
+				if p := auth.FromContext(ctx); p == nil || p.Subject != auth.ServiceSubject {
+					t.Errorf("principal = %+v", p)
+				}
+			}
+		})
+	}
+
+	t.Run("missing or wrong credentials", func(t *testing.T) {
+		for _, md := range []metadata.MD{
+			nil,
+			metadata.Pairs("authorization", "viewer-token"),
+			metadata.Pairs("authorization", "Bearer wrong-token"),
+		} {
+			ctx := context.Background()
+			if md != nil {
+				ctx = metadata.NewIncomingContext(ctx, md)
+			}
+			if _, err := a.authorize(ctx, "/orchestrator.OrchestratorService/GetFlowRun"); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("metadata %v: err = %v", md, err)
+			}
+		}
+	})
+
+	t.Run("health checks need no credentials", func(t *testing.T) {
+		if _, err := a.authorize(context.Background(), "/grpc.health.v1.Health/Check"); err != nil {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
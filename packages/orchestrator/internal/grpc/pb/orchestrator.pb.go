@@ -245,27 +245,38 @@ func (x *CancelFlowResponse) GetError() string {
 	return ""
 }
 
-type ApproveNodeRequest struct {
+type FlowSchedule struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TaskId        string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	WorkflowId    string                 `protobuf:"bytes,3,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Cron          string                 `protobuf:"bytes,4,opt,name=cron,proto3" json:"cron,omitempty"`                                                                                     // 5 段 cron 表达式或 @daily 等宏
+	Timezone      string                 `protobuf:"bytes,5,opt,name=timezone,proto3" json:"timezone,omitempty"`                                                                             // IANA 时区，默认 UTC
+	Variables     map[string]string      `protobuf:"bytes,6,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 覆盖 workflow 模板参数
+	Enabled       bool                   `protobuf:"varint,7,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	NextRunAt     int64                  `protobuf:"varint,8,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`
+	LastRunAt     int64                  `protobuf:"varint,9,opt,name=last_run_at,json=lastRunAt,proto3" json:"last_run_at,omitempty"`
+	LastFlowRunId string                 `protobuf:"bytes,10,opt,name=last_flow_run_id,json=lastFlowRunId,proto3" json:"last_flow_run_id,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,11,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApproveNodeRequest) Reset() {
-	*x = ApproveNodeRequest{}
+func (x *FlowSchedule) Reset() {
+	*x = FlowSchedule{}
 	mi := &file_orchestrator_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApproveNodeRequest) String() string {
+func (x *FlowSchedule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApproveNodeRequest) ProtoMessage() {}
+func (*FlowSchedule) ProtoMessage() {}
 
-func (x *ApproveNodeRequest) ProtoReflect() protoreflect.Message {
+func (x *FlowSchedule) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -277,40 +288,121 @@ func (x *ApproveNodeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApproveNodeRequest.ProtoReflect.Descriptor instead.
-func (*ApproveNodeRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use FlowSchedule.ProtoReflect.Descriptor instead.
+func (*FlowSchedule) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ApproveNodeRequest) GetNodeRunId() string {
+func (x *FlowSchedule) GetId() string {
 	if x != nil {
-		return x.NodeRunId
+		return x.Id
 	}
 	return ""
 }
 
-type RejectNodeRequest struct {
+func (x *FlowSchedule) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *FlowSchedule) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *FlowSchedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *FlowSchedule) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *FlowSchedule) GetVariables() map[string]string {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+func (x *FlowSchedule) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *FlowSchedule) GetNextRunAt() int64 {
+	if x != nil {
+		return x.NextRunAt
+	}
+	return 0
+}
+
+func (x *FlowSchedule) GetLastRunAt() int64 {
+	if x != nil {
+		return x.LastRunAt
+	}
+	return 0
+}
+
+func (x *FlowSchedule) GetLastFlowRunId() string {
+	if x != nil {
+		return x.LastFlowRunId
+	}
+	return ""
+}
+
+func (x *FlowSchedule) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+func (x *FlowSchedule) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ScheduleFlowRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
-	Feedback      string                 `protobuf:"bytes,2,opt,name=feedback,proto3" json:"feedback,omitempty"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	WorkflowId    string                 `protobuf:"bytes,2,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Cron          string                 `protobuf:"bytes,3,opt,name=cron,proto3" json:"cron,omitempty"`
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Variables     map[string]string      `protobuf:"bytes,5,rep,name=variables,proto3" json:"variables,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Disabled      bool                   `protobuf:"varint,6,opt,name=disabled,proto3" json:"disabled,omitempty"` // 创建为停用状态
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RejectNodeRequest) Reset() {
-	*x = RejectNodeRequest{}
+func (x *ScheduleFlowRequest) Reset() {
+	*x = ScheduleFlowRequest{}
 	mi := &file_orchestrator_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RejectNodeRequest) String() string {
+func (x *ScheduleFlowRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RejectNodeRequest) ProtoMessage() {}
+func (*ScheduleFlowRequest) ProtoMessage() {}
 
-func (x *RejectNodeRequest) ProtoReflect() protoreflect.Message {
+func (x *ScheduleFlowRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -322,49 +414,2292 @@ func (x *RejectNodeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RejectNodeRequest.ProtoReflect.Descriptor instead.
-func (*RejectNodeRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ScheduleFlowRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleFlowRequest) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *RejectNodeRequest) GetNodeRunId() string {
+func (x *ScheduleFlowRequest) GetTaskId() string {
 	if x != nil {
-		return x.NodeRunId
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *RejectNodeRequest) GetFeedback() string {
+func (x *ScheduleFlowRequest) GetWorkflowId() string {
 	if x != nil {
-		return x.Feedback
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *ScheduleFlowRequest) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *ScheduleFlowRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *ScheduleFlowRequest) GetVariables() map[string]string {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+func (x *ScheduleFlowRequest) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type ScheduleFlowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Schedule      *FlowSchedule          `protobuf:"bytes,3,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleFlowResponse) Reset() {
+	*x = ScheduleFlowResponse{}
+	mi := &file_orchestrator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleFlowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleFlowResponse) ProtoMessage() {}
+
+func (x *ScheduleFlowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleFlowResponse.ProtoReflect.Descriptor instead.
+func (*ScheduleFlowResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ScheduleFlowResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ScheduleFlowResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScheduleFlowResponse) GetSchedule() *FlowSchedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+type ListFlowSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"` // 为空时返回全部
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlowSchedulesRequest) Reset() {
+	*x = ListFlowSchedulesRequest{}
+	mi := &file_orchestrator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlowSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlowSchedulesRequest) ProtoMessage() {}
+
+func (x *ListFlowSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlowSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListFlowSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListFlowSchedulesRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type ListFlowSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Schedules     []*FlowSchedule        `protobuf:"bytes,3,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlowSchedulesResponse) Reset() {
+	*x = ListFlowSchedulesResponse{}
+	mi := &file_orchestrator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlowSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlowSchedulesResponse) ProtoMessage() {}
+
+func (x *ListFlowSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlowSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListFlowSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListFlowSchedulesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ListFlowSchedulesResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type EditNodeRequest struct {
+func (x *ListFlowSchedulesResponse) GetSchedules() []*FlowSchedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type DeleteFlowScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFlowScheduleRequest) Reset() {
+	*x = DeleteFlowScheduleRequest{}
+	mi := &file_orchestrator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFlowScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFlowScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteFlowScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFlowScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFlowScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteFlowScheduleRequest) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+type DeleteFlowScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFlowScheduleResponse) Reset() {
+	*x = DeleteFlowScheduleResponse{}
+	mi := &file_orchestrator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFlowScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFlowScheduleResponse) ProtoMessage() {}
+
+func (x *DeleteFlowScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFlowScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFlowScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteFlowScheduleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteFlowScheduleResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ApproveNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	Review        *ReviewDetails         `protobuf:"bytes,2,opt,name=review,proto3" json:"review,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveNodeRequest) Reset() {
+	*x = ApproveNodeRequest{}
+	mi := &file_orchestrator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveNodeRequest) ProtoMessage() {}
+
+func (x *ApproveNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveNodeRequest.ProtoReflect.Descriptor instead.
+func (*ApproveNodeRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ApproveNodeRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+func (x *ApproveNodeRequest) GetReview() *ReviewDetails {
+	if x != nil {
+		return x.Review
+	}
+	return nil
+}
+
+type RejectNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	Feedback      string                 `protobuf:"bytes,2,opt,name=feedback,proto3" json:"feedback,omitempty"`
+	Review        *ReviewDetails         `protobuf:"bytes,3,opt,name=review,proto3" json:"review,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectNodeRequest) Reset() {
+	*x = RejectNodeRequest{}
+	mi := &file_orchestrator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectNodeRequest) ProtoMessage() {}
+
+func (x *RejectNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectNodeRequest.ProtoReflect.Descriptor instead.
+func (*RejectNodeRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RejectNodeRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+func (x *RejectNodeRequest) GetFeedback() string {
+	if x != nil {
+		return x.Feedback
+	}
+	return ""
+}
+
+func (x *RejectNodeRequest) GetReview() *ReviewDetails {
+	if x != nil {
+		return x.Review
+	}
+	return nil
+}
+
+type EditNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	EditedContent string                 `protobuf:"bytes,2,opt,name=edited_content,json=editedContent,proto3" json:"edited_content,omitempty"`
+	ChangeSummary string                 `protobuf:"bytes,3,opt,name=change_summary,json=changeSummary,proto3" json:"change_summary,omitempty"`
+	Review        *ReviewDetails         `protobuf:"bytes,4,opt,name=review,proto3" json:"review,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditNodeRequest) Reset() {
+	*x = EditNodeRequest{}
+	mi := &file_orchestrator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditNodeRequest) ProtoMessage() {}
+
+func (x *EditNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditNodeRequest.ProtoReflect.Descriptor instead.
+func (*EditNodeRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *EditNodeRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+func (x *EditNodeRequest) GetEditedContent() string {
+	if x != nil {
+		return x.EditedContent
+	}
+	return ""
+}
+
+func (x *EditNodeRequest) GetChangeSummary() string {
+	if x != nil {
+		return x.ChangeSummary
+	}
+	return ""
+}
+
+func (x *EditNodeRequest) GetReview() *ReviewDetails {
+	if x != nil {
+		return x.Review
+	}
+	return nil
+}
+
+// 审核的结构化信息（每次审核在 node_run_reviews 中追加一行）
+type ReviewDetails struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReviewerId    string                 `protobuf:"bytes,1,opt,name=reviewer_id,json=reviewerId,proto3" json:"reviewer_id,omitempty"` // 调用方不是已认证用户时使用（用户 JWT 的身份优先）
+	ReviewerName  string                 `protobuf:"bytes,2,opt,name=reviewer_name,json=reviewerName,proto3" json:"reviewer_name,omitempty"`
+	Severity      string                 `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"` // info / minor / major / critical
+	Comments      []*ReviewComment       `protobuf:"bytes,4,rep,name=comments,proto3" json:"comments,omitempty"`
+	Checklist     []*ReviewChecklistItem `protobuf:"bytes,5,rep,name=checklist,proto3" json:"checklist,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewDetails) Reset() {
+	*x = ReviewDetails{}
+	mi := &file_orchestrator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewDetails) ProtoMessage() {}
+
+func (x *ReviewDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewDetails.ProtoReflect.Descriptor instead.
+func (*ReviewDetails) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReviewDetails) GetReviewerId() string {
+	if x != nil {
+		return x.ReviewerId
+	}
+	return ""
+}
+
+func (x *ReviewDetails) GetReviewerName() string {
+	if x != nil {
+		return x.ReviewerName
+	}
+	return ""
+}
+
+func (x *ReviewDetails) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ReviewDetails) GetComments() []*ReviewComment {
+	if x != nil {
+		return x.Comments
+	}
+	return nil
+}
+
+func (x *ReviewDetails) GetChecklist() []*ReviewChecklistItem {
+	if x != nil {
+		return x.Checklist
+	}
+	return nil
+}
+
+// 针对文件 / 行的审核意见
+type ReviewComment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          string                 `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	Line          int32                  `protobuf:"varint,2,opt,name=line,proto3" json:"line,omitempty"`
+	EndLine       int32                  `protobuf:"varint,3,opt,name=end_line,json=endLine,proto3" json:"end_line,omitempty"`
+	Body          string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Severity      string                 `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewComment) Reset() {
+	*x = ReviewComment{}
+	mi := &file_orchestrator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewComment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewComment) ProtoMessage() {}
+
+func (x *ReviewComment) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewComment.ProtoReflect.Descriptor instead.
+func (*ReviewComment) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReviewComment) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *ReviewComment) GetLine() int32 {
+	if x != nil {
+		return x.Line
+	}
+	return 0
+}
+
+func (x *ReviewComment) GetEndLine() int32 {
+	if x != nil {
+		return x.EndLine
+	}
+	return 0
+}
+
+func (x *ReviewComment) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *ReviewComment) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+type ReviewChecklistItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Item          string                 `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	Passed        bool                   `protobuf:"varint,2,opt,name=passed,proto3" json:"passed,omitempty"`
+	Note          string                 `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewChecklistItem) Reset() {
+	*x = ReviewChecklistItem{}
+	mi := &file_orchestrator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewChecklistItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewChecklistItem) ProtoMessage() {}
+
+func (x *ReviewChecklistItem) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewChecklistItem.ProtoReflect.Descriptor instead.
+func (*ReviewChecklistItem) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReviewChecklistItem) GetItem() string {
+	if x != nil {
+		return x.Item
+	}
+	return ""
+}
+
+func (x *ReviewChecklistItem) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *ReviewChecklistItem) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type SubmitHumanInputRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	DataJson      string                 `protobuf:"bytes,2,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitHumanInputRequest) Reset() {
+	*x = SubmitHumanInputRequest{}
+	mi := &file_orchestrator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitHumanInputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitHumanInputRequest) ProtoMessage() {}
+
+func (x *SubmitHumanInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitHumanInputRequest.ProtoReflect.Descriptor instead.
+func (*SubmitHumanInputRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SubmitHumanInputRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+func (x *SubmitHumanInputRequest) GetDataJson() string {
+	if x != nil {
+		return x.DataJson
+	}
+	return ""
+}
+
+type RetryNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RetryNodeRequest) Reset() {
+	*x = RetryNodeRequest{}
+	mi := &file_orchestrator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetryNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryNodeRequest) ProtoMessage() {}
+
+func (x *RetryNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryNodeRequest.ProtoReflect.Descriptor instead.
+func (*RetryNodeRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RetryNodeRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+type ToolCallDecisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApprovalId    string                 `protobuf:"bytes,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`                        // 可选，拒绝原因会反馈给 Agent
+	DecidedBy     string                 `protobuf:"bytes,3,opt,name=decided_by,json=decidedBy,proto3" json:"decided_by,omitempty"` // 可选，决定人用户 ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolCallDecisionRequest) Reset() {
+	*x = ToolCallDecisionRequest{}
+	mi := &file_orchestrator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCallDecisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCallDecisionRequest) ProtoMessage() {}
+
+func (x *ToolCallDecisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCallDecisionRequest.ProtoReflect.Descriptor instead.
+func (*ToolCallDecisionRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ToolCallDecisionRequest) GetApprovalId() string {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return ""
+}
+
+func (x *ToolCallDecisionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ToolCallDecisionRequest) GetDecidedBy() string {
+	if x != nil {
+		return x.DecidedBy
+	}
+	return ""
+}
+
+type NodeActionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	FieldErrors   map[string]string      `protobuf:"bytes,3,rep,name=field_errors,json=fieldErrors,proto3" json:"field_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // SubmitHumanInput：按字段返回的校验错误
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeActionResponse) Reset() {
+	*x = NodeActionResponse{}
+	mi := &file_orchestrator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeActionResponse) ProtoMessage() {}
+
+func (x *NodeActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeActionResponse.ProtoReflect.Descriptor instead.
+func (*NodeActionResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *NodeActionResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *NodeActionResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *NodeActionResponse) GetFieldErrors() map[string]string {
+	if x != nil {
+		return x.FieldErrors
+	}
+	return nil
+}
+
+type TestAgentRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	RoleId         string                 `protobuf:"bytes,1,opt,name=role_id,json=roleId,proto3" json:"role_id,omitempty"`
+	AgentType      string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	ProviderId     *string                `protobuf:"bytes,3,opt,name=provider_id,json=providerId,proto3,oneof" json:"provider_id,omitempty"`
+	ProviderConfig map[string]string      `protobuf:"bytes,4,rep,name=provider_config,json=providerConfig,proto3" json:"provider_config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ModelName      *string                `protobuf:"bytes,5,opt,name=model_name,json=modelName,proto3,oneof" json:"model_name,omitempty"`
+	SystemPrompt   string                 `protobuf:"bytes,6,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`
+	TestPrompt     string                 `protobuf:"bytes,7,opt,name=test_prompt,json=testPrompt,proto3" json:"test_prompt,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TestAgentRequest) Reset() {
+	*x = TestAgentRequest{}
+	mi := &file_orchestrator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TestAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestAgentRequest) ProtoMessage() {}
+
+func (x *TestAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestAgentRequest.ProtoReflect.Descriptor instead.
+func (*TestAgentRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *TestAgentRequest) GetRoleId() string {
+	if x != nil {
+		return x.RoleId
+	}
+	return ""
+}
+
+func (x *TestAgentRequest) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *TestAgentRequest) GetProviderId() string {
+	if x != nil && x.ProviderId != nil {
+		return *x.ProviderId
+	}
+	return ""
+}
+
+func (x *TestAgentRequest) GetProviderConfig() map[string]string {
+	if x != nil {
+		return x.ProviderConfig
+	}
+	return nil
+}
+
+func (x *TestAgentRequest) GetModelName() string {
+	if x != nil && x.ModelName != nil {
+		return *x.ModelName
+	}
+	return ""
+}
+
+func (x *TestAgentRequest) GetSystemPrompt() string {
+	if x != nil {
+		return x.SystemPrompt
+	}
+	return ""
+}
+
+func (x *TestAgentRequest) GetTestPrompt() string {
+	if x != nil {
+		return x.TestPrompt
+	}
+	return ""
+}
+
+type TestAgentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Result        *string                `protobuf:"bytes,2,opt,name=result,proto3,oneof" json:"result,omitempty"`
+	Error         *string                `protobuf:"bytes,3,opt,name=error,proto3,oneof" json:"error,omitempty"`
+	Logs          []string               `protobuf:"bytes,4,rep,name=logs,proto3" json:"logs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TestAgentResponse) Reset() {
+	*x = TestAgentResponse{}
+	mi := &file_orchestrator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TestAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestAgentResponse) ProtoMessage() {}
+
+func (x *TestAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestAgentResponse.ProtoReflect.Descriptor instead.
+func (*TestAgentResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *TestAgentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TestAgentResponse) GetResult() string {
+	if x != nil && x.Result != nil {
+		return *x.Result
+	}
+	return ""
+}
+
+func (x *TestAgentResponse) GetError() string {
+	if x != nil && x.Error != nil {
+		return *x.Error
+	}
+	return ""
+}
+
+func (x *TestAgentResponse) GetLogs() []string {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+type ReloadAgentRegistryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadAgentRegistryRequest) Reset() {
+	*x = ReloadAgentRegistryRequest{}
+	mi := &file_orchestrator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadAgentRegistryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadAgentRegistryRequest) ProtoMessage() {}
+
+func (x *ReloadAgentRegistryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadAgentRegistryRequest.ProtoReflect.Descriptor instead.
+func (*ReloadAgentRegistryRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{23}
+}
+
+type ReloadAgentRegistryResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Success         bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error           string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Providers       int32                  `protobuf:"varint,3,opt,name=providers,proto3" json:"providers,omitempty"`                                    // 已注册的 Provider 数
+	ReusedProviders int32                  `protobuf:"varint,4,opt,name=reused_providers,json=reusedProviders,proto3" json:"reused_providers,omitempty"` // 配置未变、沿用原 Adapter 的 Provider 数
+	Roles           int32                  `protobuf:"varint,5,opt,name=roles,proto3" json:"roles,omitempty"`                                            // 已映射的角色数
+	Skipped         []string               `protobuf:"bytes,6,rep,name=skipped,proto3" json:"skipped,omitempty"`                                         // 未能注册的 Provider / 角色及原因
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReloadAgentRegistryResponse) Reset() {
+	*x = ReloadAgentRegistryResponse{}
+	mi := &file_orchestrator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadAgentRegistryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadAgentRegistryResponse) ProtoMessage() {}
+
+func (x *ReloadAgentRegistryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadAgentRegistryResponse.ProtoReflect.Descriptor instead.
+func (*ReloadAgentRegistryResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ReloadAgentRegistryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReloadAgentRegistryResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ReloadAgentRegistryResponse) GetProviders() int32 {
+	if x != nil {
+		return x.Providers
+	}
+	return 0
+}
+
+func (x *ReloadAgentRegistryResponse) GetReusedProviders() int32 {
+	if x != nil {
+		return x.ReusedProviders
+	}
+	return 0
+}
+
+func (x *ReloadAgentRegistryResponse) GetRoles() int32 {
+	if x != nil {
+		return x.Roles
+	}
+	return 0
+}
+
+func (x *ReloadAgentRegistryResponse) GetSkipped() []string {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type DescribeAgentRolesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeAgentRolesRequest) Reset() {
+	*x = DescribeAgentRolesRequest{}
+	mi := &file_orchestrator_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeAgentRolesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeAgentRolesRequest) ProtoMessage() {}
+
+func (x *DescribeAgentRolesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeAgentRolesRequest.ProtoReflect.Descriptor instead.
+func (*DescribeAgentRolesRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{25}
+}
+
+type AgentRoleResolution struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Role           string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	AgentType      string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	InDatabase     bool                   `protobuf:"varint,3,opt,name=in_database,json=inDatabase,proto3" json:"in_database,omitempty"` // false = 内置默认角色（agent_roles 中不存在）
+	ProviderId     string                 `protobuf:"bytes,4,opt,name=provider_id,json=providerId,proto3" json:"provider_id,omitempty"`
+	ProviderName   string                 `protobuf:"bytes,5,opt,name=provider_name,json=providerName,proto3" json:"provider_name,omitempty"`
+	ModelName      string                 `protobuf:"bytes,6,opt,name=model_name,json=modelName,proto3" json:"model_name,omitempty"`                // 为空表示使用 Adapter 默认模型
+	ProviderSource string                 `protobuf:"bytes,7,opt,name=provider_source,json=providerSource,proto3" json:"provider_source,omitempty"` // role / type_default / env_fallback
+	ModelSource    string                 `protobuf:"bytes,8,opt,name=model_source,json=modelSource,proto3" json:"model_source,omitempty"`          // role / provider_default / env_fallback / 空
+	Resolved       bool                   `protobuf:"varint,9,opt,name=resolved,proto3" json:"resolved,omitempty"`                                  // false = 运行时会报 NoAdapterError
+	AdapterReady   bool                   `protobuf:"varint,10,opt,name=adapter_ready,json=adapterReady,proto3" json:"adapter_ready,omitempty"`     // Provider 的 Adapter 已成功注册
+	Reason         string                 `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AgentRoleResolution) Reset() {
+	*x = AgentRoleResolution{}
+	mi := &file_orchestrator_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentRoleResolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentRoleResolution) ProtoMessage() {}
+
+func (x *AgentRoleResolution) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentRoleResolution.ProtoReflect.Descriptor instead.
+func (*AgentRoleResolution) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AgentRoleResolution) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetInDatabase() bool {
+	if x != nil {
+		return x.InDatabase
+	}
+	return false
+}
+
+func (x *AgentRoleResolution) GetProviderId() string {
+	if x != nil {
+		return x.ProviderId
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetProviderName() string {
+	if x != nil {
+		return x.ProviderName
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetModelName() string {
+	if x != nil {
+		return x.ModelName
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetProviderSource() string {
+	if x != nil {
+		return x.ProviderSource
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetModelSource() string {
+	if x != nil {
+		return x.ModelSource
+	}
+	return ""
+}
+
+func (x *AgentRoleResolution) GetResolved() bool {
+	if x != nil {
+		return x.Resolved
+	}
+	return false
+}
+
+func (x *AgentRoleResolution) GetAdapterReady() bool {
+	if x != nil {
+		return x.AdapterReady
+	}
+	return false
+}
+
+func (x *AgentRoleResolution) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type DescribeAgentRolesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Roles         []*AgentRoleResolution `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeAgentRolesResponse) Reset() {
+	*x = DescribeAgentRolesResponse{}
+	mi := &file_orchestrator_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeAgentRolesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeAgentRolesResponse) ProtoMessage() {}
+
+func (x *DescribeAgentRolesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeAgentRolesResponse.ProtoReflect.Descriptor instead.
+func (*DescribeAgentRolesResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DescribeAgentRolesResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DescribeAgentRolesResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DescribeAgentRolesResponse) GetRoles() []*AgentRoleResolution {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type GetNodeRunLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	AfterSeq      int64                  `protobuf:"varint,2,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"` // 返回 seq > after_seq 的日志，0 表示从头开始
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                       // 默认 200，最大 1000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeRunLogsRequest) Reset() {
+	*x = GetNodeRunLogsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeRunLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeRunLogsRequest) ProtoMessage() {}
+
+func (x *GetNodeRunLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeRunLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeRunLogsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetNodeRunLogsRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+func (x *GetNodeRunLogsRequest) GetAfterSeq() int64 {
+	if x != nil {
+		return x.AfterSeq
+	}
+	return 0
+}
+
+func (x *GetNodeRunLogsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type NodeRunLogEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	EventJson     string                 `protobuf:"bytes,2,opt,name=event_json,json=eventJson,proto3" json:"event_json,omitempty"` // JSON 序列化的日志事件 {type, content, timestamp, seq, ...}
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeRunLogEntry) Reset() {
+	*x = NodeRunLogEntry{}
+	mi := &file_orchestrator_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeRunLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeRunLogEntry) ProtoMessage() {}
+
+func (x *NodeRunLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeRunLogEntry.ProtoReflect.Descriptor instead.
+func (*NodeRunLogEntry) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *NodeRunLogEntry) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *NodeRunLogEntry) GetEventJson() string {
+	if x != nil {
+		return x.EventJson
+	}
+	return ""
+}
+
+type GetNodeRunLogsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Entries       []*NodeRunLogEntry     `protobuf:"bytes,3,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextSeq       int64                  `protobuf:"varint,4,opt,name=next_seq,json=nextSeq,proto3" json:"next_seq,omitempty"` // 下一页的 after_seq
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeRunLogsResponse) Reset() {
+	*x = GetNodeRunLogsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeRunLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeRunLogsResponse) ProtoMessage() {}
+
+func (x *GetNodeRunLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeRunLogsResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeRunLogsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetNodeRunLogsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetNodeRunLogsResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetNodeRunLogsResponse) GetEntries() []*NodeRunLogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *GetNodeRunLogsResponse) GetNextSeq() int64 {
+	if x != nil {
+		return x.NextSeq
+	}
+	return 0
+}
+
+func (x *GetNodeRunLogsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type FlowRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TaskId        string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	WorkflowId    string                 `protobuf:"bytes,3,opt,name=workflow_id,json=workflowId,proto3" json:"workflow_id,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // pending / running / completed / failed / cancelled
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	VariablesJson string                 `protobuf:"bytes,6,opt,name=variables_json,json=variablesJson,proto3" json:"variables_json,omitempty"` // JSON 序列化的流程变量
+	StartedAt     int64                  `protobuf:"varint,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   int64                  `protobuf:"varint,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowRun) Reset() {
+	*x = FlowRun{}
+	mi := &file_orchestrator_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowRun) ProtoMessage() {}
+
+func (x *FlowRun) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowRun.ProtoReflect.Descriptor instead.
+func (*FlowRun) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *FlowRun) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FlowRun) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *FlowRun) GetWorkflowId() string {
+	if x != nil {
+		return x.WorkflowId
+	}
+	return ""
+}
+
+func (x *FlowRun) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *FlowRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FlowRun) GetVariablesJson() string {
+	if x != nil {
+		return x.VariablesJson
+	}
+	return ""
+}
+
+func (x *FlowRun) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *FlowRun) GetCompletedAt() int64 {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return 0
+}
+
+func (x *FlowRun) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type DagNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                            // agent_task / human_review / human_input / ...
+	AgentRole     string                 `protobuf:"bytes,4,opt,name=agent_role,json=agentRole,proto3" json:"agent_role,omitempty"` // 仅 agent 节点
+	DependsOn     []string               `protobuf:"bytes,5,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DagNode) Reset() {
+	*x = DagNode{}
+	mi := &file_orchestrator_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DagNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DagNode) ProtoMessage() {}
+
+func (x *DagNode) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DagNode.ProtoReflect.Descriptor instead.
+func (*DagNode) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DagNode) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DagNode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DagNode) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DagNode) GetAgentRole() string {
+	if x != nil {
+		return x.AgentRole
+	}
+	return ""
+}
+
+func (x *DagNode) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+type DagEdge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DagEdge) Reset() {
+	*x = DagEdge{}
+	mi := &file_orchestrator_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DagEdge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DagEdge) ProtoMessage() {}
+
+func (x *DagEdge) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DagEdge.ProtoReflect.Descriptor instead.
+func (*DagEdge) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DagEdge) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *DagEdge) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+// 由 FlowRun 的 DSL 快照解析得到的 DAG 结构
+type FlowDag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*DagNode             `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"` // DSL 中的节点顺序
+	Edges         []*DagEdge             `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"` // 未声明 edges 时为推导出的线性链
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowDag) Reset() {
+	*x = FlowDag{}
+	mi := &file_orchestrator_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowDag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowDag) ProtoMessage() {}
+
+func (x *FlowDag) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowDag.ProtoReflect.Descriptor instead.
+func (*FlowDag) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *FlowDag) GetNodes() []*DagNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *FlowDag) GetEdges() []*DagEdge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+type GetFlowRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlowRunId     string                 `protobuf:"bytes,1,opt,name=flow_run_id,json=flowRunId,proto3" json:"flow_run_id,omitempty"`
+	IncludeDag    bool                   `protobuf:"varint,2,opt,name=include_dag,json=includeDag,proto3" json:"include_dag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlowRunRequest) Reset() {
+	*x = GetFlowRunRequest{}
+	mi := &file_orchestrator_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlowRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlowRunRequest) ProtoMessage() {}
+
+func (x *GetFlowRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlowRunRequest.ProtoReflect.Descriptor instead.
+func (*GetFlowRunRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetFlowRunRequest) GetFlowRunId() string {
+	if x != nil {
+		return x.FlowRunId
+	}
+	return ""
+}
+
+func (x *GetFlowRunRequest) GetIncludeDag() bool {
+	if x != nil {
+		return x.IncludeDag
+	}
+	return false
+}
+
+type GetFlowRunResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	FlowRun       *FlowRun               `protobuf:"bytes,3,opt,name=flow_run,json=flowRun,proto3" json:"flow_run,omitempty"`
+	Dag           *FlowDag               `protobuf:"bytes,4,opt,name=dag,proto3" json:"dag,omitempty"` // include_dag 且存在 DSL 快照时返回
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlowRunResponse) Reset() {
+	*x = GetFlowRunResponse{}
+	mi := &file_orchestrator_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlowRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlowRunResponse) ProtoMessage() {}
+
+func (x *GetFlowRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlowRunResponse.ProtoReflect.Descriptor instead.
+func (*GetFlowRunResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetFlowRunResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetFlowRunResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetFlowRunResponse) GetFlowRun() *FlowRun {
+	if x != nil {
+		return x.FlowRun
+	}
+	return nil
+}
+
+func (x *GetFlowRunResponse) GetDag() *FlowDag {
+	if x != nil {
+		return x.Dag
+	}
+	return nil
+}
+
+type NodeRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FlowRunId     string                 `protobuf:"bytes,2,opt,name=flow_run_id,json=flowRunId,proto3" json:"flow_run_id,omitempty"`
+	NodeId        string                 `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	NodeType      string                 `protobuf:"bytes,4,opt,name=node_type,json=nodeType,proto3" json:"node_type,omitempty"`
+	NodeName      string                 `protobuf:"bytes,5,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	Attempt       int32                  `protobuf:"varint,7,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	InputJson     string                 `protobuf:"bytes,8,opt,name=input_json,json=inputJson,proto3" json:"input_json,omitempty"`
+	OutputJson    string                 `protobuf:"bytes,9,opt,name=output_json,json=outputJson,proto3" json:"output_json,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	ReviewAction  string                 `protobuf:"bytes,11,opt,name=review_action,json=reviewAction,proto3" json:"review_action,omitempty"` // approve / reject / edit_and_approve
+	ReviewComment string                 `protobuf:"bytes,12,opt,name=review_comment,json=reviewComment,proto3" json:"review_comment,omitempty"`
+	ReviewedAt    int64                  `protobuf:"varint,13,opt,name=reviewed_at,json=reviewedAt,proto3" json:"reviewed_at,omitempty"`
+	StartedAt     int64                  `protobuf:"varint,14,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   int64                  `protobuf:"varint,15,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,16,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ReviewedBy    string                 `protobuf:"bytes,17,opt,name=reviewed_by,json=reviewedBy,proto3" json:"reviewed_by,omitempty"` // 审核人用户 ID，或 "service"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeRun) Reset() {
+	*x = NodeRun{}
+	mi := &file_orchestrator_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeRun) ProtoMessage() {}
+
+func (x *NodeRun) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeRun.ProtoReflect.Descriptor instead.
+func (*NodeRun) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *NodeRun) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NodeRun) GetFlowRunId() string {
+	if x != nil {
+		return x.FlowRunId
+	}
+	return ""
+}
+
+func (x *NodeRun) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *NodeRun) GetNodeType() string {
+	if x != nil {
+		return x.NodeType
+	}
+	return ""
+}
+
+func (x *NodeRun) GetNodeName() string {
+	if x != nil {
+		return x.NodeName
+	}
+	return ""
+}
+
+func (x *NodeRun) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *NodeRun) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *NodeRun) GetInputJson() string {
+	if x != nil {
+		return x.InputJson
+	}
+	return ""
+}
+
+func (x *NodeRun) GetOutputJson() string {
+	if x != nil {
+		return x.OutputJson
+	}
+	return ""
+}
+
+func (x *NodeRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *NodeRun) GetReviewAction() string {
+	if x != nil {
+		return x.ReviewAction
+	}
+	return ""
+}
+
+func (x *NodeRun) GetReviewComment() string {
+	if x != nil {
+		return x.ReviewComment
+	}
+	return ""
+}
+
+func (x *NodeRun) GetReviewedAt() int64 {
+	if x != nil {
+		return x.ReviewedAt
+	}
+	return 0
+}
+
+func (x *NodeRun) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *NodeRun) GetCompletedAt() int64 {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return 0
+}
+
+func (x *NodeRun) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *NodeRun) GetReviewedBy() string {
+	if x != nil {
+		return x.ReviewedBy
+	}
+	return ""
+}
+
+type ListNodeRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FlowRunId     string                 `protobuf:"bytes,1,opt,name=flow_run_id,json=flowRunId,proto3" json:"flow_run_id,omitempty"`
+	Statuses      []string               `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"`                        // 为空表示不过滤
+	NodeId        string                 `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`              // 为空表示所有节点
+	Attempt       int32                  `protobuf:"varint,4,opt,name=attempt,proto3" json:"attempt,omitempty"`                         // 0 表示所有尝试
+	LatestOnly    bool                   `protobuf:"varint,5,opt,name=latest_only,json=latestOnly,proto3" json:"latest_only,omitempty"` // 每个节点只返回最新一次尝试
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNodeRunsRequest) Reset() {
+	*x = ListNodeRunsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNodeRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNodeRunsRequest) ProtoMessage() {}
+
+func (x *ListNodeRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNodeRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListNodeRunsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListNodeRunsRequest) GetFlowRunId() string {
+	if x != nil {
+		return x.FlowRunId
+	}
+	return ""
+}
+
+func (x *ListNodeRunsRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListNodeRunsRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *ListNodeRunsRequest) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *ListNodeRunsRequest) GetLatestOnly() bool {
+	if x != nil {
+		return x.LatestOnly
+	}
+	return false
+}
+
+type ListNodeRunsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
-	EditedContent string                 `protobuf:"bytes,2,opt,name=edited_content,json=editedContent,proto3" json:"edited_content,omitempty"`
-	ChangeSummary string                 `protobuf:"bytes,3,opt,name=change_summary,json=changeSummary,proto3" json:"change_summary,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	NodeRuns      []*NodeRun             `protobuf:"bytes,3,rep,name=node_runs,json=nodeRuns,proto3" json:"node_runs,omitempty"` // 按创建时间升序
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EditNodeRequest) Reset() {
-	*x = EditNodeRequest{}
-	mi := &file_orchestrator_proto_msgTypes[6]
+func (x *ListNodeRunsResponse) Reset() {
+	*x = ListNodeRunsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EditNodeRequest) String() string {
+func (x *ListNodeRunsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EditNodeRequest) ProtoMessage() {}
+func (*ListNodeRunsResponse) ProtoMessage() {}
 
-func (x *EditNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[6]
+func (x *ListNodeRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -375,55 +2710,54 @@ func (x *EditNodeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EditNodeRequest.ProtoReflect.Descriptor instead.
-func (*EditNodeRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ListNodeRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListNodeRunsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *EditNodeRequest) GetNodeRunId() string {
+func (x *ListNodeRunsResponse) GetSuccess() bool {
 	if x != nil {
-		return x.NodeRunId
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *EditNodeRequest) GetEditedContent() string {
+func (x *ListNodeRunsResponse) GetError() string {
 	if x != nil {
-		return x.EditedContent
+		return x.Error
 	}
 	return ""
 }
 
-func (x *EditNodeRequest) GetChangeSummary() string {
+func (x *ListNodeRunsResponse) GetNodeRuns() []*NodeRun {
 	if x != nil {
-		return x.ChangeSummary
+		return x.NodeRuns
 	}
-	return ""
+	return nil
 }
 
-type SubmitHumanInputRequest struct {
+type GetNodeRunRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
-	DataJson      string                 `protobuf:"bytes,2,opt,name=data_json,json=dataJson,proto3" json:"data_json,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SubmitHumanInputRequest) Reset() {
-	*x = SubmitHumanInputRequest{}
-	mi := &file_orchestrator_proto_msgTypes[7]
+func (x *GetNodeRunRequest) Reset() {
+	*x = GetNodeRunRequest{}
+	mi := &file_orchestrator_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SubmitHumanInputRequest) String() string {
+func (x *GetNodeRunRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SubmitHumanInputRequest) ProtoMessage() {}
+func (*GetNodeRunRequest) ProtoMessage() {}
 
-func (x *SubmitHumanInputRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[7]
+func (x *GetNodeRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -434,47 +2768,43 @@ func (x *SubmitHumanInputRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SubmitHumanInputRequest.ProtoReflect.Descriptor instead.
-func (*SubmitHumanInputRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use GetNodeRunRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeRunRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *SubmitHumanInputRequest) GetNodeRunId() string {
+func (x *GetNodeRunRequest) GetNodeRunId() string {
 	if x != nil {
 		return x.NodeRunId
 	}
 	return ""
 }
 
-func (x *SubmitHumanInputRequest) GetDataJson() string {
-	if x != nil {
-		return x.DataJson
-	}
-	return ""
-}
-
-type RetryNodeRequest struct {
+type GetNodeRunResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NodeRunId     string                 `protobuf:"bytes,1,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	NodeRun       *NodeRun               `protobuf:"bytes,3,opt,name=node_run,json=nodeRun,proto3" json:"node_run,omitempty"`
+	Attempts      []*NodeRun             `protobuf:"bytes,4,rep,name=attempts,proto3" json:"attempts,omitempty"` // 同一流程中该节点的所有尝试（含本次），按 attempt 升序
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RetryNodeRequest) Reset() {
-	*x = RetryNodeRequest{}
-	mi := &file_orchestrator_proto_msgTypes[8]
+func (x *GetNodeRunResponse) Reset() {
+	*x = GetNodeRunResponse{}
+	mi := &file_orchestrator_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RetryNodeRequest) String() string {
+func (x *GetNodeRunResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RetryNodeRequest) ProtoMessage() {}
+func (*GetNodeRunResponse) ProtoMessage() {}
 
-func (x *RetryNodeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[8]
+func (x *GetNodeRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -485,41 +2815,67 @@ func (x *RetryNodeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RetryNodeRequest.ProtoReflect.Descriptor instead.
-func (*RetryNodeRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use GetNodeRunResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeRunResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *RetryNodeRequest) GetNodeRunId() string {
+func (x *GetNodeRunResponse) GetSuccess() bool {
 	if x != nil {
-		return x.NodeRunId
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetNodeRunResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-type NodeActionResponse struct {
+func (x *GetNodeRunResponse) GetNodeRun() *NodeRun {
+	if x != nil {
+		return x.NodeRun
+	}
+	return nil
+}
+
+func (x *GetNodeRunResponse) GetAttempts() []*NodeRun {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+type TimelineEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TaskId        string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	FlowRunId     string                 `protobuf:"bytes,3,opt,name=flow_run_id,json=flowRunId,proto3" json:"flow_run_id,omitempty"`
+	NodeRunId     string                 `protobuf:"bytes,4,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	EventType     string                 `protobuf:"bytes,5,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	ContentJson   string                 `protobuf:"bytes,6,opt,name=content_json,json=contentJson,proto3" json:"content_json,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *NodeActionResponse) Reset() {
-	*x = NodeActionResponse{}
-	mi := &file_orchestrator_proto_msgTypes[9]
+func (x *TimelineEvent) Reset() {
+	*x = TimelineEvent{}
+	mi := &file_orchestrator_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *NodeActionResponse) String() string {
+func (x *TimelineEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*NodeActionResponse) ProtoMessage() {}
+func (*TimelineEvent) ProtoMessage() {}
 
-func (x *NodeActionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[9]
+func (x *TimelineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -530,53 +2886,87 @@ func (x *NodeActionResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use NodeActionResponse.ProtoReflect.Descriptor instead.
-func (*NodeActionResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use TimelineEvent.ProtoReflect.Descriptor instead.
+func (*TimelineEvent) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *NodeActionResponse) GetSuccess() bool {
+func (x *TimelineEvent) GetId() string {
 	if x != nil {
-		return x.Success
+		return x.Id
 	}
-	return false
+	return ""
 }
 
-func (x *NodeActionResponse) GetError() string {
+func (x *TimelineEvent) GetTaskId() string {
 	if x != nil {
-		return x.Error
+		return x.TaskId
 	}
 	return ""
 }
 
-type TestAgentRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	RoleId         string                 `protobuf:"bytes,1,opt,name=role_id,json=roleId,proto3" json:"role_id,omitempty"`
-	AgentType      string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
-	ProviderId     *string                `protobuf:"bytes,3,opt,name=provider_id,json=providerId,proto3,oneof" json:"provider_id,omitempty"`
-	ProviderConfig map[string]string      `protobuf:"bytes,4,rep,name=provider_config,json=providerConfig,proto3" json:"provider_config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ModelName      *string                `protobuf:"bytes,5,opt,name=model_name,json=modelName,proto3,oneof" json:"model_name,omitempty"`
-	SystemPrompt   string                 `protobuf:"bytes,6,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`
-	TestPrompt     string                 `protobuf:"bytes,7,opt,name=test_prompt,json=testPrompt,proto3" json:"test_prompt,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *TimelineEvent) GetFlowRunId() string {
+	if x != nil {
+		return x.FlowRunId
+	}
+	return ""
 }
 
-func (x *TestAgentRequest) Reset() {
-	*x = TestAgentRequest{}
-	mi := &file_orchestrator_proto_msgTypes[10]
+func (x *TimelineEvent) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
+	}
+	return ""
+}
+
+func (x *TimelineEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *TimelineEvent) GetContentJson() string {
+	if x != nil {
+		return x.ContentJson
+	}
+	return ""
+}
+
+func (x *TimelineEvent) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+type ListTimelineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"` // task_id / flow_run_id 至少指定一个
+	FlowRunId     string                 `protobuf:"bytes,2,opt,name=flow_run_id,json=flowRunId,proto3" json:"flow_run_id,omitempty"`
+	NodeRunId     string                 `protobuf:"bytes,3,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
+	EventTypes    []string               `protobuf:"bytes,4,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`
+	AfterId       string                 `protobuf:"bytes,5,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"` // 返回该事件之后的事件，为空表示从头开始；不属于该时间线的事件返回 INVALID_ARGUMENT
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`                   // 默认 100，最大 500
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTimelineRequest) Reset() {
+	*x = ListTimelineRequest{}
+	mi := &file_orchestrator_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TestAgentRequest) String() string {
+func (x *ListTimelineRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TestAgentRequest) ProtoMessage() {}
+func (*ListTimelineRequest) ProtoMessage() {}
 
-func (x *TestAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[10]
+func (x *ListTimelineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -587,85 +2977,79 @@ func (x *TestAgentRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TestAgentRequest.ProtoReflect.Descriptor instead.
-func (*TestAgentRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use ListTimelineRequest.ProtoReflect.Descriptor instead.
+func (*ListTimelineRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *TestAgentRequest) GetRoleId() string {
+func (x *ListTimelineRequest) GetTaskId() string {
 	if x != nil {
-		return x.RoleId
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *TestAgentRequest) GetAgentType() string {
+func (x *ListTimelineRequest) GetFlowRunId() string {
 	if x != nil {
-		return x.AgentType
+		return x.FlowRunId
 	}
 	return ""
 }
 
-func (x *TestAgentRequest) GetProviderId() string {
-	if x != nil && x.ProviderId != nil {
-		return *x.ProviderId
+func (x *ListTimelineRequest) GetNodeRunId() string {
+	if x != nil {
+		return x.NodeRunId
 	}
 	return ""
 }
 
-func (x *TestAgentRequest) GetProviderConfig() map[string]string {
+func (x *ListTimelineRequest) GetEventTypes() []string {
 	if x != nil {
-		return x.ProviderConfig
+		return x.EventTypes
 	}
 	return nil
 }
 
-func (x *TestAgentRequest) GetModelName() string {
-	if x != nil && x.ModelName != nil {
-		return *x.ModelName
-	}
-	return ""
-}
-
-func (x *TestAgentRequest) GetSystemPrompt() string {
+func (x *ListTimelineRequest) GetAfterId() string {
 	if x != nil {
-		return x.SystemPrompt
+		return x.AfterId
 	}
 	return ""
 }
 
-func (x *TestAgentRequest) GetTestPrompt() string {
+func (x *ListTimelineRequest) GetLimit() int32 {
 	if x != nil {
-		return x.TestPrompt
+		return x.Limit
 	}
-	return ""
+	return 0
 }
 
-type TestAgentResponse struct {
+type ListTimelineResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Result        *string                `protobuf:"bytes,2,opt,name=result,proto3,oneof" json:"result,omitempty"`
-	Error         *string                `protobuf:"bytes,3,opt,name=error,proto3,oneof" json:"error,omitempty"`
-	Logs          []string               `protobuf:"bytes,4,rep,name=logs,proto3" json:"logs,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Events        []*TimelineEvent       `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`                                // 按时间升序
+	NextAfterId   string                 `protobuf:"bytes,4,opt,name=next_after_id,json=nextAfterId,proto3" json:"next_after_id,omitempty"` // 下一页的 after_id
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TestAgentResponse) Reset() {
-	*x = TestAgentResponse{}
-	mi := &file_orchestrator_proto_msgTypes[11]
+func (x *ListTimelineResponse) Reset() {
+	*x = ListTimelineResponse{}
+	mi := &file_orchestrator_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TestAgentResponse) String() string {
+func (x *ListTimelineResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TestAgentResponse) ProtoMessage() {}
+func (*ListTimelineResponse) ProtoMessage() {}
 
-func (x *TestAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[11]
+func (x *ListTimelineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -676,37 +3060,44 @@ func (x *TestAgentResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TestAgentResponse.ProtoReflect.Descriptor instead.
-func (*TestAgentResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ListTimelineResponse.ProtoReflect.Descriptor instead.
+func (*ListTimelineResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *TestAgentResponse) GetSuccess() bool {
+func (x *ListTimelineResponse) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *TestAgentResponse) GetResult() string {
-	if x != nil && x.Result != nil {
-		return *x.Result
+func (x *ListTimelineResponse) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-func (x *TestAgentResponse) GetError() string {
-	if x != nil && x.Error != nil {
-		return *x.Error
+func (x *ListTimelineResponse) GetEvents() []*TimelineEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *ListTimelineResponse) GetNextAfterId() string {
+	if x != nil {
+		return x.NextAfterId
 	}
 	return ""
 }
 
-func (x *TestAgentResponse) GetLogs() []string {
+func (x *ListTimelineResponse) GetHasMore() bool {
 	if x != nil {
-		return x.Logs
+		return x.HasMore
 	}
-	return nil
+	return false
 }
 
 type EventStreamRequest struct {
@@ -718,7 +3109,7 @@ type EventStreamRequest struct {
 
 func (x *EventStreamRequest) Reset() {
 	*x = EventStreamRequest{}
-	mi := &file_orchestrator_proto_msgTypes[12]
+	mi := &file_orchestrator_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -730,7 +3121,7 @@ func (x *EventStreamRequest) String() string {
 func (*EventStreamRequest) ProtoMessage() {}
 
 func (x *EventStreamRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[12]
+	mi := &file_orchestrator_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -743,7 +3134,7 @@ func (x *EventStreamRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EventStreamRequest.ProtoReflect.Descriptor instead.
 func (*EventStreamRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{12}
+	return file_orchestrator_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *EventStreamRequest) GetFlowRunId() string {
@@ -755,7 +3146,7 @@ func (x *EventStreamRequest) GetFlowRunId() string {
 
 type ServerEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	EventType     string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"` // node.queued / node.started / node.completed / node.waiting_human / node.failed / node.rejected / flow.started / flow.completed / flow.failed / flow.cancelled
+	EventType     string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"` // node.queued / node.started / node.completed / node.waiting_human / node.tool_approval_requested / node.tool_approval_resolved / node.waiting_timer / node.failed / node.rejected / flow.started / flow.completed / flow.failed / flow.cancelled
 	FlowRunId     string                 `protobuf:"bytes,2,opt,name=flow_run_id,json=flowRunId,proto3" json:"flow_run_id,omitempty"`
 	NodeRunId     string                 `protobuf:"bytes,3,opt,name=node_run_id,json=nodeRunId,proto3" json:"node_run_id,omitempty"`
 	NodeId        string                 `protobuf:"bytes,4,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
@@ -767,7 +3158,7 @@ type ServerEvent struct {
 
 func (x *ServerEvent) Reset() {
 	*x = ServerEvent{}
-	mi := &file_orchestrator_proto_msgTypes[13]
+	mi := &file_orchestrator_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -779,7 +3170,7 @@ func (x *ServerEvent) String() string {
 func (*ServerEvent) ProtoMessage() {}
 
 func (x *ServerEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[13]
+	mi := &file_orchestrator_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -792,7 +3183,7 @@ func (x *ServerEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerEvent.ProtoReflect.Descriptor instead.
 func (*ServerEvent) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{13}
+	return file_orchestrator_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *ServerEvent) GetEventType() string {
@@ -859,24 +3250,101 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vflow_run_id\x18\x01 \x01(\tR\tflowRunId\"D\n" +
 	"\x12CancelFlowResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"4\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xd0\x03\n" +
+	"\fFlowSchedule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1f\n" +
+	"\vworkflow_id\x18\x03 \x01(\tR\n" +
+	"workflowId\x12\x12\n" +
+	"\x04cron\x18\x04 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x05 \x01(\tR\btimezone\x12G\n" +
+	"\tvariables\x18\x06 \x03(\v2).orchestrator.FlowSchedule.VariablesEntryR\tvariables\x12\x18\n" +
+	"\aenabled\x18\a \x01(\bR\aenabled\x12\x1e\n" +
+	"\vnext_run_at\x18\b \x01(\x03R\tnextRunAt\x12\x1e\n" +
+	"\vlast_run_at\x18\t \x01(\x03R\tlastRunAt\x12'\n" +
+	"\x10last_flow_run_id\x18\n" +
+	" \x01(\tR\rlastFlowRunId\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\v \x01(\tR\tcreatedBy\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\x03R\tcreatedAt\x1a<\n" +
+	"\x0eVariablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa9\x02\n" +
+	"\x13ScheduleFlowRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1f\n" +
+	"\vworkflow_id\x18\x02 \x01(\tR\n" +
+	"workflowId\x12\x12\n" +
+	"\x04cron\x18\x03 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12N\n" +
+	"\tvariables\x18\x05 \x03(\v20.orchestrator.ScheduleFlowRequest.VariablesEntryR\tvariables\x12\x1a\n" +
+	"\bdisabled\x18\x06 \x01(\bR\bdisabled\x1a<\n" +
+	"\x0eVariablesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"~\n" +
+	"\x14ScheduleFlowResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x126\n" +
+	"\bschedule\x18\x03 \x01(\v2\x1a.orchestrator.FlowScheduleR\bschedule\"3\n" +
+	"\x18ListFlowSchedulesRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x85\x01\n" +
+	"\x19ListFlowSchedulesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x128\n" +
+	"\tschedules\x18\x03 \x03(\v2\x1a.orchestrator.FlowScheduleR\tschedules\"<\n" +
+	"\x19DeleteFlowScheduleRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\"L\n" +
+	"\x1aDeleteFlowScheduleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"i\n" +
 	"\x12ApproveNodeRequest\x12\x1e\n" +
-	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\"O\n" +
+	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\x123\n" +
+	"\x06review\x18\x02 \x01(\v2\x1b.orchestrator.ReviewDetailsR\x06review\"\x84\x01\n" +
 	"\x11RejectNodeRequest\x12\x1e\n" +
 	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\x12\x1a\n" +
-	"\bfeedback\x18\x02 \x01(\tR\bfeedback\"\x7f\n" +
+	"\bfeedback\x18\x02 \x01(\tR\bfeedback\x123\n" +
+	"\x06review\x18\x03 \x01(\v2\x1b.orchestrator.ReviewDetailsR\x06review\"\xb4\x01\n" +
 	"\x0fEditNodeRequest\x12\x1e\n" +
 	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\x12%\n" +
 	"\x0eedited_content\x18\x02 \x01(\tR\reditedContent\x12%\n" +
-	"\x0echange_summary\x18\x03 \x01(\tR\rchangeSummary\"V\n" +
+	"\x0echange_summary\x18\x03 \x01(\tR\rchangeSummary\x123\n" +
+	"\x06review\x18\x04 \x01(\v2\x1b.orchestrator.ReviewDetailsR\x06review\"\xeb\x01\n" +
+	"\rReviewDetails\x12\x1f\n" +
+	"\vreviewer_id\x18\x01 \x01(\tR\n" +
+	"reviewerId\x12#\n" +
+	"\rreviewer_name\x18\x02 \x01(\tR\freviewerName\x12\x1a\n" +
+	"\bseverity\x18\x03 \x01(\tR\bseverity\x127\n" +
+	"\bcomments\x18\x04 \x03(\v2\x1b.orchestrator.ReviewCommentR\bcomments\x12?\n" +
+	"\tchecklist\x18\x05 \x03(\v2!.orchestrator.ReviewChecklistItemR\tchecklist\"\x82\x01\n" +
+	"\rReviewComment\x12\x12\n" +
+	"\x04file\x18\x01 \x01(\tR\x04file\x12\x12\n" +
+	"\x04line\x18\x02 \x01(\x05R\x04line\x12\x19\n" +
+	"\bend_line\x18\x03 \x01(\x05R\aendLine\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x1a\n" +
+	"\bseverity\x18\x05 \x01(\tR\bseverity\"U\n" +
+	"\x13ReviewChecklistItem\x12\x12\n" +
+	"\x04item\x18\x01 \x01(\tR\x04item\x12\x16\n" +
+	"\x06passed\x18\x02 \x01(\bR\x06passed\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note\"V\n" +
 	"\x17SubmitHumanInputRequest\x12\x1e\n" +
 	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\x12\x1b\n" +
 	"\tdata_json\x18\x02 \x01(\tR\bdataJson\"2\n" +
 	"\x10RetryNodeRequest\x12\x1e\n" +
-	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\"D\n" +
+	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\"q\n" +
+	"\x17ToolCallDecisionRequest\x12\x1f\n" +
+	"\vapproval_id\x18\x01 \x01(\tR\n" +
+	"approvalId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"decided_by\x18\x03 \x01(\tR\tdecidedBy\"\xda\x01\n" +
 	"\x12NodeActionResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x99\x03\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12T\n" +
+	"\ffield_errors\x18\x03 \x03(\v21.orchestrator.NodeActionResponse.FieldErrorsEntryR\vfieldErrors\x1a>\n" +
+	"\x10FieldErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x99\x03\n" +
 	"\x10TestAgentRequest\x12\x17\n" +
 	"\arole_id\x18\x01 \x01(\tR\x06roleId\x12\x1d\n" +
 	"\n" +
@@ -900,7 +3368,154 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\x05error\x18\x03 \x01(\tH\x01R\x05error\x88\x01\x01\x12\x12\n" +
 	"\x04logs\x18\x04 \x03(\tR\x04logsB\t\n" +
 	"\a_resultB\b\n" +
-	"\x06_error\"4\n" +
+	"\x06_error\"\x1c\n" +
+	"\x1aReloadAgentRegistryRequest\"\xc6\x01\n" +
+	"\x1bReloadAgentRegistryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1c\n" +
+	"\tproviders\x18\x03 \x01(\x05R\tproviders\x12)\n" +
+	"\x10reused_providers\x18\x04 \x01(\x05R\x0freusedProviders\x12\x14\n" +
+	"\x05roles\x18\x05 \x01(\x05R\x05roles\x12\x18\n" +
+	"\askipped\x18\x06 \x03(\tR\askipped\"\x1b\n" +
+	"\x19DescribeAgentRolesRequest\"\xf3\x02\n" +
+	"\x13AgentRoleResolution\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\x02 \x01(\tR\tagentType\x12\x1f\n" +
+	"\vin_database\x18\x03 \x01(\bR\n" +
+	"inDatabase\x12\x1f\n" +
+	"\vprovider_id\x18\x04 \x01(\tR\n" +
+	"providerId\x12#\n" +
+	"\rprovider_name\x18\x05 \x01(\tR\fproviderName\x12\x1d\n" +
+	"\n" +
+	"model_name\x18\x06 \x01(\tR\tmodelName\x12'\n" +
+	"\x0fprovider_source\x18\a \x01(\tR\x0eproviderSource\x12!\n" +
+	"\fmodel_source\x18\b \x01(\tR\vmodelSource\x12\x1a\n" +
+	"\bresolved\x18\t \x01(\bR\bresolved\x12#\n" +
+	"\radapter_ready\x18\n" +
+	" \x01(\bR\fadapterReady\x12\x16\n" +
+	"\x06reason\x18\v \x01(\tR\x06reason\"\x85\x01\n" +
+	"\x1aDescribeAgentRolesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x127\n" +
+	"\x05roles\x18\x03 \x03(\v2!.orchestrator.AgentRoleResolutionR\x05roles\"j\n" +
+	"\x15GetNodeRunLogsRequest\x12\x1e\n" +
+	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\x12\x1b\n" +
+	"\tafter_seq\x18\x02 \x01(\x03R\bafterSeq\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"B\n" +
+	"\x0fNodeRunLogEntry\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12\x1d\n" +
+	"\n" +
+	"event_json\x18\x02 \x01(\tR\teventJson\"\xb7\x01\n" +
+	"\x16GetNodeRunLogsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x127\n" +
+	"\aentries\x18\x03 \x03(\v2\x1d.orchestrator.NodeRunLogEntryR\aentries\x12\x19\n" +
+	"\bnext_seq\x18\x04 \x01(\x03R\anextSeq\x12\x19\n" +
+	"\bhas_more\x18\x05 \x01(\bR\ahasMore\"\x89\x02\n" +
+	"\aFlowRun\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1f\n" +
+	"\vworkflow_id\x18\x03 \x01(\tR\n" +
+	"workflowId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12%\n" +
+	"\x0evariables_json\x18\x06 \x01(\tR\rvariablesJson\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\a \x01(\x03R\tstartedAt\x12!\n" +
+	"\fcompleted_at\x18\b \x01(\x03R\vcompletedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\"\x7f\n" +
+	"\aDagNode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"agent_role\x18\x04 \x01(\tR\tagentRole\x12\x1d\n" +
+	"\n" +
+	"depends_on\x18\x05 \x03(\tR\tdependsOn\"-\n" +
+	"\aDagEdge\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\"c\n" +
+	"\aFlowDag\x12+\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x15.orchestrator.DagNodeR\x05nodes\x12+\n" +
+	"\x05edges\x18\x02 \x03(\v2\x15.orchestrator.DagEdgeR\x05edges\"T\n" +
+	"\x11GetFlowRunRequest\x12\x1e\n" +
+	"\vflow_run_id\x18\x01 \x01(\tR\tflowRunId\x12\x1f\n" +
+	"\vinclude_dag\x18\x02 \x01(\bR\n" +
+	"includeDag\"\x9f\x01\n" +
+	"\x12GetFlowRunResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x120\n" +
+	"\bflow_run\x18\x03 \x01(\v2\x15.orchestrator.FlowRunR\aflowRun\x12'\n" +
+	"\x03dag\x18\x04 \x01(\v2\x15.orchestrator.FlowDagR\x03dag\"\x83\x04\n" +
+	"\aNodeRun\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1e\n" +
+	"\vflow_run_id\x18\x02 \x01(\tR\tflowRunId\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12\x1b\n" +
+	"\tnode_type\x18\x04 \x01(\tR\bnodeType\x12\x1b\n" +
+	"\tnode_name\x18\x05 \x01(\tR\bnodeName\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x18\n" +
+	"\aattempt\x18\a \x01(\x05R\aattempt\x12\x1d\n" +
+	"\n" +
+	"input_json\x18\b \x01(\tR\tinputJson\x12\x1f\n" +
+	"\voutput_json\x18\t \x01(\tR\n" +
+	"outputJson\x12\x14\n" +
+	"\x05error\x18\n" +
+	" \x01(\tR\x05error\x12#\n" +
+	"\rreview_action\x18\v \x01(\tR\freviewAction\x12%\n" +
+	"\x0ereview_comment\x18\f \x01(\tR\rreviewComment\x12\x1f\n" +
+	"\vreviewed_at\x18\r \x01(\x03R\n" +
+	"reviewedAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x0e \x01(\x03R\tstartedAt\x12!\n" +
+	"\fcompleted_at\x18\x0f \x01(\x03R\vcompletedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x10 \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vreviewed_by\x18\x11 \x01(\tR\n" +
+	"reviewedBy\"\xa5\x01\n" +
+	"\x13ListNodeRunsRequest\x12\x1e\n" +
+	"\vflow_run_id\x18\x01 \x01(\tR\tflowRunId\x12\x1a\n" +
+	"\bstatuses\x18\x02 \x03(\tR\bstatuses\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\x12\x18\n" +
+	"\aattempt\x18\x04 \x01(\x05R\aattempt\x12\x1f\n" +
+	"\vlatest_only\x18\x05 \x01(\bR\n" +
+	"latestOnly\"z\n" +
+	"\x14ListNodeRunsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x122\n" +
+	"\tnode_runs\x18\x03 \x03(\v2\x15.orchestrator.NodeRunR\bnodeRuns\"3\n" +
+	"\x11GetNodeRunRequest\x12\x1e\n" +
+	"\vnode_run_id\x18\x01 \x01(\tR\tnodeRunId\"\xa9\x01\n" +
+	"\x12GetNodeRunResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x120\n" +
+	"\bnode_run\x18\x03 \x01(\v2\x15.orchestrator.NodeRunR\anodeRun\x121\n" +
+	"\battempts\x18\x04 \x03(\v2\x15.orchestrator.NodeRunR\battempts\"\xd9\x01\n" +
+	"\rTimelineEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x1e\n" +
+	"\vflow_run_id\x18\x03 \x01(\tR\tflowRunId\x12\x1e\n" +
+	"\vnode_run_id\x18\x04 \x01(\tR\tnodeRunId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x05 \x01(\tR\teventType\x12!\n" +
+	"\fcontent_json\x18\x06 \x01(\tR\vcontentJson\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\"\xc0\x01\n" +
+	"\x13ListTimelineRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1e\n" +
+	"\vflow_run_id\x18\x02 \x01(\tR\tflowRunId\x12\x1e\n" +
+	"\vnode_run_id\x18\x03 \x01(\tR\tnodeRunId\x12\x1f\n" +
+	"\vevent_types\x18\x04 \x03(\tR\n" +
+	"eventTypes\x12\x19\n" +
+	"\bafter_id\x18\x05 \x01(\tR\aafterId\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\"\xba\x01\n" +
+	"\x14ListTimelineResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x123\n" +
+	"\x06events\x18\x03 \x03(\v2\x1b.orchestrator.TimelineEventR\x06events\x12\"\n" +
+	"\rnext_after_id\x18\x04 \x01(\tR\vnextAfterId\x12\x19\n" +
+	"\bhas_more\x18\x05 \x01(\bR\ahasMore\"4\n" +
 	"\x12EventStreamRequest\x12\x1e\n" +
 	"\vflow_run_id\x18\x01 \x01(\tR\tflowRunId\"\xc0\x01\n" +
 	"\vServerEvent\x12\x1d\n" +
@@ -910,18 +3525,32 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vnode_run_id\x18\x03 \x01(\tR\tnodeRunId\x12\x17\n" +
 	"\anode_id\x18\x04 \x01(\tR\x06nodeId\x12\x1b\n" +
 	"\tdata_json\x18\x05 \x01(\tR\bdataJson\x12\x1c\n" +
-	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp2\xed\x05\n" +
+	"\ttimestamp\x18\x06 \x01(\x03R\ttimestamp2\xca\x0e\n" +
 	"\x13OrchestratorService\x12L\n" +
 	"\tStartFlow\x12\x1e.orchestrator.StartFlowRequest\x1a\x1f.orchestrator.StartFlowResponse\x12O\n" +
 	"\n" +
-	"CancelFlow\x12\x1f.orchestrator.CancelFlowRequest\x1a .orchestrator.CancelFlowResponse\x12Q\n" +
+	"CancelFlow\x12\x1f.orchestrator.CancelFlowRequest\x1a .orchestrator.CancelFlowResponse\x12U\n" +
+	"\fScheduleFlow\x12!.orchestrator.ScheduleFlowRequest\x1a\".orchestrator.ScheduleFlowResponse\x12d\n" +
+	"\x11ListFlowSchedules\x12&.orchestrator.ListFlowSchedulesRequest\x1a'.orchestrator.ListFlowSchedulesResponse\x12g\n" +
+	"\x12DeleteFlowSchedule\x12'.orchestrator.DeleteFlowScheduleRequest\x1a(.orchestrator.DeleteFlowScheduleResponse\x12Q\n" +
 	"\vApproveNode\x12 .orchestrator.ApproveNodeRequest\x1a .orchestrator.NodeActionResponse\x12O\n" +
 	"\n" +
 	"RejectNode\x12\x1f.orchestrator.RejectNodeRequest\x1a .orchestrator.NodeActionResponse\x12K\n" +
 	"\bEditNode\x12\x1d.orchestrator.EditNodeRequest\x1a .orchestrator.NodeActionResponse\x12[\n" +
 	"\x10SubmitHumanInput\x12%.orchestrator.SubmitHumanInputRequest\x1a .orchestrator.NodeActionResponse\x12M\n" +
-	"\tRetryNode\x12\x1e.orchestrator.RetryNodeRequest\x1a .orchestrator.NodeActionResponse\x12L\n" +
-	"\tTestAgent\x12\x1e.orchestrator.TestAgentRequest\x1a\x1f.orchestrator.TestAgentResponse\x12L\n" +
+	"\tRetryNode\x12\x1e.orchestrator.RetryNodeRequest\x1a .orchestrator.NodeActionResponse\x12Z\n" +
+	"\x0fApproveToolCall\x12%.orchestrator.ToolCallDecisionRequest\x1a .orchestrator.NodeActionResponse\x12W\n" +
+	"\fDenyToolCall\x12%.orchestrator.ToolCallDecisionRequest\x1a .orchestrator.NodeActionResponse\x12L\n" +
+	"\tTestAgent\x12\x1e.orchestrator.TestAgentRequest\x1a\x1f.orchestrator.TestAgentResponse\x12j\n" +
+	"\x13ReloadAgentRegistry\x12(.orchestrator.ReloadAgentRegistryRequest\x1a).orchestrator.ReloadAgentRegistryResponse\x12g\n" +
+	"\x12DescribeAgentRoles\x12'.orchestrator.DescribeAgentRolesRequest\x1a(.orchestrator.DescribeAgentRolesResponse\x12[\n" +
+	"\x0eGetNodeRunLogs\x12#.orchestrator.GetNodeRunLogsRequest\x1a$.orchestrator.GetNodeRunLogsResponse\x12O\n" +
+	"\n" +
+	"GetFlowRun\x12\x1f.orchestrator.GetFlowRunRequest\x1a .orchestrator.GetFlowRunResponse\x12U\n" +
+	"\fListNodeRuns\x12!.orchestrator.ListNodeRunsRequest\x1a\".orchestrator.ListNodeRunsResponse\x12O\n" +
+	"\n" +
+	"GetNodeRun\x12\x1f.orchestrator.GetNodeRunRequest\x1a .orchestrator.GetNodeRunResponse\x12U\n" +
+	"\fListTimeline\x12!.orchestrator.ListTimelineRequest\x1a\".orchestrator.ListTimelineResponse\x12L\n" +
 	"\vEventStream\x12 .orchestrator.EventStreamRequest\x1a\x19.orchestrator.ServerEvent0\x01B;Z9github.com/sunshow/workgear/orchestrator/internal/grpc/pbb\x06proto3"
 
 var (
@@ -936,51 +3565,131 @@ func file_orchestrator_proto_rawDescGZIP() []byte {
 	return file_orchestrator_proto_rawDescData
 }
 
-var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
 var file_orchestrator_proto_goTypes = []any{
-	(*StartFlowRequest)(nil),        // 0: orchestrator.StartFlowRequest
-	(*StartFlowResponse)(nil),       // 1: orchestrator.StartFlowResponse
-	(*CancelFlowRequest)(nil),       // 2: orchestrator.CancelFlowRequest
-	(*CancelFlowResponse)(nil),      // 3: orchestrator.CancelFlowResponse
-	(*ApproveNodeRequest)(nil),      // 4: orchestrator.ApproveNodeRequest
-	(*RejectNodeRequest)(nil),       // 5: orchestrator.RejectNodeRequest
-	(*EditNodeRequest)(nil),         // 6: orchestrator.EditNodeRequest
-	(*SubmitHumanInputRequest)(nil), // 7: orchestrator.SubmitHumanInputRequest
-	(*RetryNodeRequest)(nil),        // 8: orchestrator.RetryNodeRequest
-	(*NodeActionResponse)(nil),      // 9: orchestrator.NodeActionResponse
-	(*TestAgentRequest)(nil),        // 10: orchestrator.TestAgentRequest
-	(*TestAgentResponse)(nil),       // 11: orchestrator.TestAgentResponse
-	(*EventStreamRequest)(nil),      // 12: orchestrator.EventStreamRequest
-	(*ServerEvent)(nil),             // 13: orchestrator.ServerEvent
-	nil,                             // 14: orchestrator.StartFlowRequest.VariablesEntry
-	nil,                             // 15: orchestrator.TestAgentRequest.ProviderConfigEntry
+	(*StartFlowRequest)(nil),            // 0: orchestrator.StartFlowRequest
+	(*StartFlowResponse)(nil),           // 1: orchestrator.StartFlowResponse
+	(*CancelFlowRequest)(nil),           // 2: orchestrator.CancelFlowRequest
+	(*CancelFlowResponse)(nil),          // 3: orchestrator.CancelFlowResponse
+	(*FlowSchedule)(nil),                // 4: orchestrator.FlowSchedule
+	(*ScheduleFlowRequest)(nil),         // 5: orchestrator.ScheduleFlowRequest
+	(*ScheduleFlowResponse)(nil),        // 6: orchestrator.ScheduleFlowResponse
+	(*ListFlowSchedulesRequest)(nil),    // 7: orchestrator.ListFlowSchedulesRequest
+	(*ListFlowSchedulesResponse)(nil),   // 8: orchestrator.ListFlowSchedulesResponse
+	(*DeleteFlowScheduleRequest)(nil),   // 9: orchestrator.DeleteFlowScheduleRequest
+	(*DeleteFlowScheduleResponse)(nil),  // 10: orchestrator.DeleteFlowScheduleResponse
+	(*ApproveNodeRequest)(nil),          // 11: orchestrator.ApproveNodeRequest
+	(*RejectNodeRequest)(nil),           // 12: orchestrator.RejectNodeRequest
+	(*EditNodeRequest)(nil),             // 13: orchestrator.EditNodeRequest
+	(*ReviewDetails)(nil),               // 14: orchestrator.ReviewDetails
+	(*ReviewComment)(nil),               // 15: orchestrator.ReviewComment
+	(*ReviewChecklistItem)(nil),         // 16: orchestrator.ReviewChecklistItem
+	(*SubmitHumanInputRequest)(nil),     // 17: orchestrator.SubmitHumanInputRequest
+	(*RetryNodeRequest)(nil),            // 18: orchestrator.RetryNodeRequest
+	(*ToolCallDecisionRequest)(nil),     // 19: orchestrator.ToolCallDecisionRequest
+	(*NodeActionResponse)(nil),          // 20: orchestrator.NodeActionResponse
+	(*TestAgentRequest)(nil),            // 21: orchestrator.TestAgentRequest
+	(*TestAgentResponse)(nil),           // 22: orchestrator.TestAgentResponse
+	(*ReloadAgentRegistryRequest)(nil),  // 23: orchestrator.ReloadAgentRegistryRequest
+	(*ReloadAgentRegistryResponse)(nil), // 24: orchestrator.ReloadAgentRegistryResponse
+	(*DescribeAgentRolesRequest)(nil),   // 25: orchestrator.DescribeAgentRolesRequest
+	(*AgentRoleResolution)(nil),         // 26: orchestrator.AgentRoleResolution
+	(*DescribeAgentRolesResponse)(nil),  // 27: orchestrator.DescribeAgentRolesResponse
+	(*GetNodeRunLogsRequest)(nil),       // 28: orchestrator.GetNodeRunLogsRequest
+	(*NodeRunLogEntry)(nil),             // 29: orchestrator.NodeRunLogEntry
+	(*GetNodeRunLogsResponse)(nil),      // 30: orchestrator.GetNodeRunLogsResponse
+	(*FlowRun)(nil),                     // 31: orchestrator.FlowRun
+	(*DagNode)(nil),                     // 32: orchestrator.DagNode
+	(*DagEdge)(nil),                     // 33: orchestrator.DagEdge
+	(*FlowDag)(nil),                     // 34: orchestrator.FlowDag
+	(*GetFlowRunRequest)(nil),           // 35: orchestrator.GetFlowRunRequest
+	(*GetFlowRunResponse)(nil),          // 36: orchestrator.GetFlowRunResponse
+	(*NodeRun)(nil),                     // 37: orchestrator.NodeRun
+	(*ListNodeRunsRequest)(nil),         // 38: orchestrator.ListNodeRunsRequest
+	(*ListNodeRunsResponse)(nil),        // 39: orchestrator.ListNodeRunsResponse
+	(*GetNodeRunRequest)(nil),           // 40: orchestrator.GetNodeRunRequest
+	(*GetNodeRunResponse)(nil),          // 41: orchestrator.GetNodeRunResponse
+	(*TimelineEvent)(nil),               // 42: orchestrator.TimelineEvent
+	(*ListTimelineRequest)(nil),         // 43: orchestrator.ListTimelineRequest
+	(*ListTimelineResponse)(nil),        // 44: orchestrator.ListTimelineResponse
+	(*EventStreamRequest)(nil),          // 45: orchestrator.EventStreamRequest
+	(*ServerEvent)(nil),                 // 46: orchestrator.ServerEvent
+	nil,                                 // 47: orchestrator.StartFlowRequest.VariablesEntry
+	nil,                                 // 48: orchestrator.FlowSchedule.VariablesEntry
+	nil,                                 // 49: orchestrator.ScheduleFlowRequest.VariablesEntry
+	nil,                                 // 50: orchestrator.NodeActionResponse.FieldErrorsEntry
+	nil,                                 // 51: orchestrator.TestAgentRequest.ProviderConfigEntry
 }
 var file_orchestrator_proto_depIdxs = []int32{
-	14, // 0: orchestrator.StartFlowRequest.variables:type_name -> orchestrator.StartFlowRequest.VariablesEntry
-	15, // 1: orchestrator.TestAgentRequest.provider_config:type_name -> orchestrator.TestAgentRequest.ProviderConfigEntry
-	0,  // 2: orchestrator.OrchestratorService.StartFlow:input_type -> orchestrator.StartFlowRequest
-	2,  // 3: orchestrator.OrchestratorService.CancelFlow:input_type -> orchestrator.CancelFlowRequest
-	4,  // 4: orchestrator.OrchestratorService.ApproveNode:input_type -> orchestrator.ApproveNodeRequest
-	5,  // 5: orchestrator.OrchestratorService.RejectNode:input_type -> orchestrator.RejectNodeRequest
-	6,  // 6: orchestrator.OrchestratorService.EditNode:input_type -> orchestrator.EditNodeRequest
-	7,  // 7: orchestrator.OrchestratorService.SubmitHumanInput:input_type -> orchestrator.SubmitHumanInputRequest
-	8,  // 8: orchestrator.OrchestratorService.RetryNode:input_type -> orchestrator.RetryNodeRequest
-	10, // 9: orchestrator.OrchestratorService.TestAgent:input_type -> orchestrator.TestAgentRequest
-	12, // 10: orchestrator.OrchestratorService.EventStream:input_type -> orchestrator.EventStreamRequest
-	1,  // 11: orchestrator.OrchestratorService.StartFlow:output_type -> orchestrator.StartFlowResponse
-	3,  // 12: orchestrator.OrchestratorService.CancelFlow:output_type -> orchestrator.CancelFlowResponse
-	9,  // 13: orchestrator.OrchestratorService.ApproveNode:output_type -> orchestrator.NodeActionResponse
-	9,  // 14: orchestrator.OrchestratorService.RejectNode:output_type -> orchestrator.NodeActionResponse
-	9,  // 15: orchestrator.OrchestratorService.EditNode:output_type -> orchestrator.NodeActionResponse
-	9,  // 16: orchestrator.OrchestratorService.SubmitHumanInput:output_type -> orchestrator.NodeActionResponse
-	9,  // 17: orchestrator.OrchestratorService.RetryNode:output_type -> orchestrator.NodeActionResponse
-	11, // 18: orchestrator.OrchestratorService.TestAgent:output_type -> orchestrator.TestAgentResponse
-	13, // 19: orchestrator.OrchestratorService.EventStream:output_type -> orchestrator.ServerEvent
-	11, // [11:20] is the sub-list for method output_type
-	2,  // [2:11] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	47, // 0: orchestrator.StartFlowRequest.variables:type_name -> orchestrator.StartFlowRequest.VariablesEntry
+	48, // 1: orchestrator.FlowSchedule.variables:type_name -> orchestrator.FlowSchedule.VariablesEntry
+	49, // 2: orchestrator.ScheduleFlowRequest.variables:type_name -> orchestrator.ScheduleFlowRequest.VariablesEntry
+	4,  // 3: orchestrator.ScheduleFlowResponse.schedule:type_name -> orchestrator.FlowSchedule
+	4,  // 4: orchestrator.ListFlowSchedulesResponse.schedules:type_name -> orchestrator.FlowSchedule
+	14, // 5: orchestrator.ApproveNodeRequest.review:type_name -> orchestrator.ReviewDetails
+	14, // 6: orchestrator.RejectNodeRequest.review:type_name -> orchestrator.ReviewDetails
+	14, // 7: orchestrator.EditNodeRequest.review:type_name -> orchestrator.ReviewDetails
+	15, // 8: orchestrator.ReviewDetails.comments:type_name -> orchestrator.ReviewComment
+	16, // 9: orchestrator.ReviewDetails.checklist:type_name -> orchestrator.ReviewChecklistItem
+	50, // 10: orchestrator.NodeActionResponse.field_errors:type_name -> orchestrator.NodeActionResponse.FieldErrorsEntry
+	51, // 11: orchestrator.TestAgentRequest.provider_config:type_name -> orchestrator.TestAgentRequest.ProviderConfigEntry
+	26, // 12: orchestrator.DescribeAgentRolesResponse.roles:type_name -> orchestrator.AgentRoleResolution
+	29, // 13: orchestrator.GetNodeRunLogsResponse.entries:type_name -> orchestrator.NodeRunLogEntry
+	32, // 14: orchestrator.FlowDag.nodes:type_name -> orchestrator.DagNode
+	33, // 15: orchestrator.FlowDag.edges:type_name -> orchestrator.DagEdge
+	31, // 16: orchestrator.GetFlowRunResponse.flow_run:type_name -> orchestrator.FlowRun
+	34, // 17: orchestrator.GetFlowRunResponse.dag:type_name -> orchestrator.FlowDag
+	37, // 18: orchestrator.ListNodeRunsResponse.node_runs:type_name -> orchestrator.NodeRun
+	37, // 19: orchestrator.GetNodeRunResponse.node_run:type_name -> orchestrator.NodeRun
+	37, // 20: orchestrator.GetNodeRunResponse.attempts:type_name -> orchestrator.NodeRun
+	42, // 21: orchestrator.ListTimelineResponse.events:type_name -> orchestrator.TimelineEvent
+	0,  // 22: orchestrator.OrchestratorService.StartFlow:input_type -> orchestrator.StartFlowRequest
+	2,  // 23: orchestrator.OrchestratorService.CancelFlow:input_type -> orchestrator.CancelFlowRequest
+	5,  // 24: orchestrator.OrchestratorService.ScheduleFlow:input_type -> orchestrator.ScheduleFlowRequest
+	7,  // 25: orchestrator.OrchestratorService.ListFlowSchedules:input_type -> orchestrator.ListFlowSchedulesRequest
+	9,  // 26: orchestrator.OrchestratorService.DeleteFlowSchedule:input_type -> orchestrator.DeleteFlowScheduleRequest
+	11, // 27: orchestrator.OrchestratorService.ApproveNode:input_type -> orchestrator.ApproveNodeRequest
+	12, // 28: orchestrator.OrchestratorService.RejectNode:input_type -> orchestrator.RejectNodeRequest
+	13, // 29: orchestrator.OrchestratorService.EditNode:input_type -> orchestrator.EditNodeRequest
+	17, // 30: orchestrator.OrchestratorService.SubmitHumanInput:input_type -> orchestrator.SubmitHumanInputRequest
+	18, // 31: orchestrator.OrchestratorService.RetryNode:input_type -> orchestrator.RetryNodeRequest
+	19, // 32: orchestrator.OrchestratorService.ApproveToolCall:input_type -> orchestrator.ToolCallDecisionRequest
+	19, // 33: orchestrator.OrchestratorService.DenyToolCall:input_type -> orchestrator.ToolCallDecisionRequest
+	21, // 34: orchestrator.OrchestratorService.TestAgent:input_type -> orchestrator.TestAgentRequest
+	23, // 35: orchestrator.OrchestratorService.ReloadAgentRegistry:input_type -> orchestrator.ReloadAgentRegistryRequest
+	25, // 36: orchestrator.OrchestratorService.DescribeAgentRoles:input_type -> orchestrator.DescribeAgentRolesRequest
+	28, // 37: orchestrator.OrchestratorService.GetNodeRunLogs:input_type -> orchestrator.GetNodeRunLogsRequest
+	35, // 38: orchestrator.OrchestratorService.GetFlowRun:input_type -> orchestrator.GetFlowRunRequest
+	38, // 39: orchestrator.OrchestratorService.ListNodeRuns:input_type -> orchestrator.ListNodeRunsRequest
+	40, // 40: orchestrator.OrchestratorService.GetNodeRun:input_type -> orchestrator.GetNodeRunRequest
+	43, // 41: orchestrator.OrchestratorService.ListTimeline:input_type -> orchestrator.ListTimelineRequest
+	45, // 42: orchestrator.OrchestratorService.EventStream:input_type -> orchestrator.EventStreamRequest
+	1,  // 43: orchestrator.OrchestratorService.StartFlow:output_type -> orchestrator.StartFlowResponse
+	3,  // 44: orchestrator.OrchestratorService.CancelFlow:output_type -> orchestrator.CancelFlowResponse
+	6,  // 45: orchestrator.OrchestratorService.ScheduleFlow:output_type -> orchestrator.ScheduleFlowResponse
+	8,  // 46: orchestrator.OrchestratorService.ListFlowSchedules:output_type -> orchestrator.ListFlowSchedulesResponse
+	10, // 47: orchestrator.OrchestratorService.DeleteFlowSchedule:output_type -> orchestrator.DeleteFlowScheduleResponse
+	20, // 48: orchestrator.OrchestratorService.ApproveNode:output_type -> orchestrator.NodeActionResponse
+	20, // 49: orchestrator.OrchestratorService.RejectNode:output_type -> orchestrator.NodeActionResponse
+	20, // 50: orchestrator.OrchestratorService.EditNode:output_type -> orchestrator.NodeActionResponse
+	20, // 51: orchestrator.OrchestratorService.SubmitHumanInput:output_type -> orchestrator.NodeActionResponse
+	20, // 52: orchestrator.OrchestratorService.RetryNode:output_type -> orchestrator.NodeActionResponse
+	20, // 53: orchestrator.OrchestratorService.ApproveToolCall:output_type -> orchestrator.NodeActionResponse
+	20, // 54: orchestrator.OrchestratorService.DenyToolCall:output_type -> orchestrator.NodeActionResponse
+	22, // 55: orchestrator.OrchestratorService.TestAgent:output_type -> orchestrator.TestAgentResponse
+	24, // 56: orchestrator.OrchestratorService.ReloadAgentRegistry:output_type -> orchestrator.ReloadAgentRegistryResponse
+	27, // 57: orchestrator.OrchestratorService.DescribeAgentRoles:output_type -> orchestrator.DescribeAgentRolesResponse
+	30, // 58: orchestrator.OrchestratorService.GetNodeRunLogs:output_type -> orchestrator.GetNodeRunLogsResponse
+	36, // 59: orchestrator.OrchestratorService.GetFlowRun:output_type -> orchestrator.GetFlowRunResponse
+	39, // 60: orchestrator.OrchestratorService.ListNodeRuns:output_type -> orchestrator.ListNodeRunsResponse
+	41, // 61: orchestrator.OrchestratorService.GetNodeRun:output_type -> orchestrator.GetNodeRunResponse
+	44, // 62: orchestrator.OrchestratorService.ListTimeline:output_type -> orchestrator.ListTimelineResponse
+	46, // 63: orchestrator.OrchestratorService.EventStream:output_type -> orchestrator.ServerEvent
+	43, // [43:64] is the sub-list for method output_type
+	22, // [22:43] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_orchestrator_proto_init() }
@@ -988,15 +3697,15 @@ func file_orchestrator_proto_init() {
 	if File_orchestrator_proto != nil {
 		return
 	}
-	file_orchestrator_proto_msgTypes[10].OneofWrappers = []any{}
-	file_orchestrator_proto_msgTypes[11].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[21].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[22].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_orchestrator_proto_rawDesc), len(file_orchestrator_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   16,
+			NumMessages:   52,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
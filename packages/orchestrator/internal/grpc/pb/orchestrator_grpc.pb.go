@@ -19,15 +19,27 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrchestratorService_StartFlow_FullMethodName        = "/orchestrator.OrchestratorService/StartFlow"
-	OrchestratorService_CancelFlow_FullMethodName       = "/orchestrator.OrchestratorService/CancelFlow"
-	OrchestratorService_ApproveNode_FullMethodName      = "/orchestrator.OrchestratorService/ApproveNode"
-	OrchestratorService_RejectNode_FullMethodName       = "/orchestrator.OrchestratorService/RejectNode"
-	OrchestratorService_EditNode_FullMethodName         = "/orchestrator.OrchestratorService/EditNode"
-	OrchestratorService_SubmitHumanInput_FullMethodName = "/orchestrator.OrchestratorService/SubmitHumanInput"
-	OrchestratorService_RetryNode_FullMethodName        = "/orchestrator.OrchestratorService/RetryNode"
-	OrchestratorService_TestAgent_FullMethodName        = "/orchestrator.OrchestratorService/TestAgent"
-	OrchestratorService_EventStream_FullMethodName      = "/orchestrator.OrchestratorService/EventStream"
+	OrchestratorService_StartFlow_FullMethodName           = "/orchestrator.OrchestratorService/StartFlow"
+	OrchestratorService_CancelFlow_FullMethodName          = "/orchestrator.OrchestratorService/CancelFlow"
+	OrchestratorService_ScheduleFlow_FullMethodName        = "/orchestrator.OrchestratorService/ScheduleFlow"
+	OrchestratorService_ListFlowSchedules_FullMethodName   = "/orchestrator.OrchestratorService/ListFlowSchedules"
+	OrchestratorService_DeleteFlowSchedule_FullMethodName  = "/orchestrator.OrchestratorService/DeleteFlowSchedule"
+	OrchestratorService_ApproveNode_FullMethodName         = "/orchestrator.OrchestratorService/ApproveNode"
+	OrchestratorService_RejectNode_FullMethodName          = "/orchestrator.OrchestratorService/RejectNode"
+	OrchestratorService_EditNode_FullMethodName            = "/orchestrator.OrchestratorService/EditNode"
+	OrchestratorService_SubmitHumanInput_FullMethodName    = "/orchestrator.OrchestratorService/SubmitHumanInput"
+	OrchestratorService_RetryNode_FullMethodName           = "/orchestrator.OrchestratorService/RetryNode"
+	OrchestratorService_ApproveToolCall_FullMethodName     = "/orchestrator.OrchestratorService/ApproveToolCall"
+	OrchestratorService_DenyToolCall_FullMethodName        = "/orchestrator.OrchestratorService/DenyToolCall"
+	OrchestratorService_TestAgent_FullMethodName           = "/orchestrator.OrchestratorService/TestAgent"
+	OrchestratorService_ReloadAgentRegistry_FullMethodName = "/orchestrator.OrchestratorService/ReloadAgentRegistry"
+	OrchestratorService_DescribeAgentRoles_FullMethodName  = "/orchestrator.OrchestratorService/DescribeAgentRoles"
+	OrchestratorService_GetNodeRunLogs_FullMethodName      = "/orchestrator.OrchestratorService/GetNodeRunLogs"
+	OrchestratorService_GetFlowRun_FullMethodName          = "/orchestrator.OrchestratorService/GetFlowRun"
+	OrchestratorService_ListNodeRuns_FullMethodName        = "/orchestrator.OrchestratorService/ListNodeRuns"
+	OrchestratorService_GetNodeRun_FullMethodName          = "/orchestrator.OrchestratorService/GetNodeRun"
+	OrchestratorService_ListTimeline_FullMethodName        = "/orchestrator.OrchestratorService/ListTimeline"
+	OrchestratorService_EventStream_FullMethodName         = "/orchestrator.OrchestratorService/EventStream"
 )
 
 // OrchestratorServiceClient is the client API for OrchestratorService service.
@@ -37,14 +49,32 @@ type OrchestratorServiceClient interface {
 	// 流程管理
 	StartFlow(ctx context.Context, in *StartFlowRequest, opts ...grpc.CallOption) (*StartFlowResponse, error)
 	CancelFlow(ctx context.Context, in *CancelFlowRequest, opts ...grpc.CallOption) (*CancelFlowResponse, error)
+	// 定时启动流程（cron）
+	ScheduleFlow(ctx context.Context, in *ScheduleFlowRequest, opts ...grpc.CallOption) (*ScheduleFlowResponse, error)
+	ListFlowSchedules(ctx context.Context, in *ListFlowSchedulesRequest, opts ...grpc.CallOption) (*ListFlowSchedulesResponse, error)
+	DeleteFlowSchedule(ctx context.Context, in *DeleteFlowScheduleRequest, opts ...grpc.CallOption) (*DeleteFlowScheduleResponse, error)
 	// 人工操作
 	ApproveNode(ctx context.Context, in *ApproveNodeRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
 	RejectNode(ctx context.Context, in *RejectNodeRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
 	EditNode(ctx context.Context, in *EditNodeRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
 	SubmitHumanInput(ctx context.Context, in *SubmitHumanInputRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
 	RetryNode(ctx context.Context, in *RetryNodeRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
+	// Agent 工具调用审批（节点 tool_approval 规则命中的调用）
+	ApproveToolCall(ctx context.Context, in *ToolCallDecisionRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
+	DenyToolCall(ctx context.Context, in *ToolCallDecisionRequest, opts ...grpc.CallOption) (*NodeActionResponse, error)
 	// Agent 测试
 	TestAgent(ctx context.Context, in *TestAgentRequest, opts ...grpc.CallOption) (*TestAgentResponse, error)
+	// Agent 注册表热加载（Provider / Model / 角色映射变更后调用）
+	ReloadAgentRegistry(ctx context.Context, in *ReloadAgentRegistryRequest, opts ...grpc.CallOption) (*ReloadAgentRegistryResponse, error)
+	// 角色解析结果：每个角色生效的 Provider / Model 及来源
+	DescribeAgentRoles(ctx context.Context, in *DescribeAgentRolesRequest, opts ...grpc.CallOption) (*DescribeAgentRolesResponse, error)
+	// 节点日志（分页查询）
+	GetNodeRunLogs(ctx context.Context, in *GetNodeRunLogsRequest, opts ...grpc.CallOption) (*GetNodeRunLogsResponse, error)
+	// 运行状态查询（只读）
+	GetFlowRun(ctx context.Context, in *GetFlowRunRequest, opts ...grpc.CallOption) (*GetFlowRunResponse, error)
+	ListNodeRuns(ctx context.Context, in *ListNodeRunsRequest, opts ...grpc.CallOption) (*ListNodeRunsResponse, error)
+	GetNodeRun(ctx context.Context, in *GetNodeRunRequest, opts ...grpc.CallOption) (*GetNodeRunResponse, error)
+	ListTimeline(ctx context.Context, in *ListTimelineRequest, opts ...grpc.CallOption) (*ListTimelineResponse, error)
 	// 事件流（服务端流式推送）
 	EventStream(ctx context.Context, in *EventStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServerEvent], error)
 }
@@ -77,6 +107,36 @@ func (c *orchestratorServiceClient) CancelFlow(ctx context.Context, in *CancelFl
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) ScheduleFlow(ctx context.Context, in *ScheduleFlowRequest, opts ...grpc.CallOption) (*ScheduleFlowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduleFlowResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ScheduleFlow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ListFlowSchedules(ctx context.Context, in *ListFlowSchedulesRequest, opts ...grpc.CallOption) (*ListFlowSchedulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFlowSchedulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListFlowSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) DeleteFlowSchedule(ctx context.Context, in *DeleteFlowScheduleRequest, opts ...grpc.CallOption) (*DeleteFlowScheduleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFlowScheduleResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_DeleteFlowSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) ApproveNode(ctx context.Context, in *ApproveNodeRequest, opts ...grpc.CallOption) (*NodeActionResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(NodeActionResponse)
@@ -127,6 +187,26 @@ func (c *orchestratorServiceClient) RetryNode(ctx context.Context, in *RetryNode
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) ApproveToolCall(ctx context.Context, in *ToolCallDecisionRequest, opts ...grpc.CallOption) (*NodeActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeActionResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ApproveToolCall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) DenyToolCall(ctx context.Context, in *ToolCallDecisionRequest, opts ...grpc.CallOption) (*NodeActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeActionResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_DenyToolCall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) TestAgent(ctx context.Context, in *TestAgentRequest, opts ...grpc.CallOption) (*TestAgentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TestAgentResponse)
@@ -137,6 +217,76 @@ func (c *orchestratorServiceClient) TestAgent(ctx context.Context, in *TestAgent
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) ReloadAgentRegistry(ctx context.Context, in *ReloadAgentRegistryRequest, opts ...grpc.CallOption) (*ReloadAgentRegistryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadAgentRegistryResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ReloadAgentRegistry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) DescribeAgentRoles(ctx context.Context, in *DescribeAgentRolesRequest, opts ...grpc.CallOption) (*DescribeAgentRolesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DescribeAgentRolesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_DescribeAgentRoles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetNodeRunLogs(ctx context.Context, in *GetNodeRunLogsRequest, opts ...grpc.CallOption) (*GetNodeRunLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNodeRunLogsResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetNodeRunLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetFlowRun(ctx context.Context, in *GetFlowRunRequest, opts ...grpc.CallOption) (*GetFlowRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFlowRunResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetFlowRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ListNodeRuns(ctx context.Context, in *ListNodeRunsRequest, opts ...grpc.CallOption) (*ListNodeRunsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNodeRunsResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListNodeRuns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetNodeRun(ctx context.Context, in *GetNodeRunRequest, opts ...grpc.CallOption) (*GetNodeRunResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNodeRunResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetNodeRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ListTimeline(ctx context.Context, in *ListTimelineRequest, opts ...grpc.CallOption) (*ListTimelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTimelineResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListTimeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) EventStream(ctx context.Context, in *EventStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServerEvent], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &OrchestratorService_ServiceDesc.Streams[0], OrchestratorService_EventStream_FullMethodName, cOpts...)
@@ -163,14 +313,32 @@ type OrchestratorServiceServer interface {
 	// 流程管理
 	StartFlow(context.Context, *StartFlowRequest) (*StartFlowResponse, error)
 	CancelFlow(context.Context, *CancelFlowRequest) (*CancelFlowResponse, error)
+	// 定时启动流程（cron）
+	ScheduleFlow(context.Context, *ScheduleFlowRequest) (*ScheduleFlowResponse, error)
+	ListFlowSchedules(context.Context, *ListFlowSchedulesRequest) (*ListFlowSchedulesResponse, error)
+	DeleteFlowSchedule(context.Context, *DeleteFlowScheduleRequest) (*DeleteFlowScheduleResponse, error)
 	// 人工操作
 	ApproveNode(context.Context, *ApproveNodeRequest) (*NodeActionResponse, error)
 	RejectNode(context.Context, *RejectNodeRequest) (*NodeActionResponse, error)
 	EditNode(context.Context, *EditNodeRequest) (*NodeActionResponse, error)
 	SubmitHumanInput(context.Context, *SubmitHumanInputRequest) (*NodeActionResponse, error)
 	RetryNode(context.Context, *RetryNodeRequest) (*NodeActionResponse, error)
+	// Agent 工具调用审批（节点 tool_approval 规则命中的调用）
+	ApproveToolCall(context.Context, *ToolCallDecisionRequest) (*NodeActionResponse, error)
+	DenyToolCall(context.Context, *ToolCallDecisionRequest) (*NodeActionResponse, error)
 	// Agent 测试
 	TestAgent(context.Context, *TestAgentRequest) (*TestAgentResponse, error)
+	// Agent 注册表热加载（Provider / Model / 角色映射变更后调用）
+	ReloadAgentRegistry(context.Context, *ReloadAgentRegistryRequest) (*ReloadAgentRegistryResponse, error)
+	// 角色解析结果：每个角色生效的 Provider / Model 及来源
+	DescribeAgentRoles(context.Context, *DescribeAgentRolesRequest) (*DescribeAgentRolesResponse, error)
+	// 节点日志（分页查询）
+	GetNodeRunLogs(context.Context, *GetNodeRunLogsRequest) (*GetNodeRunLogsResponse, error)
+	// 运行状态查询（只读）
+	GetFlowRun(context.Context, *GetFlowRunRequest) (*GetFlowRunResponse, error)
+	ListNodeRuns(context.Context, *ListNodeRunsRequest) (*ListNodeRunsResponse, error)
+	GetNodeRun(context.Context, *GetNodeRunRequest) (*GetNodeRunResponse, error)
+	ListTimeline(context.Context, *ListTimelineRequest) (*ListTimelineResponse, error)
 	// 事件流（服务端流式推送）
 	EventStream(*EventStreamRequest, grpc.ServerStreamingServer[ServerEvent]) error
 	mustEmbedUnimplementedOrchestratorServiceServer()
@@ -189,6 +357,15 @@ func (UnimplementedOrchestratorServiceServer) StartFlow(context.Context, *StartF
 func (UnimplementedOrchestratorServiceServer) CancelFlow(context.Context, *CancelFlowRequest) (*CancelFlowResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CancelFlow not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) ScheduleFlow(context.Context, *ScheduleFlowRequest) (*ScheduleFlowResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScheduleFlow not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ListFlowSchedules(context.Context, *ListFlowSchedulesRequest) (*ListFlowSchedulesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListFlowSchedules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) DeleteFlowSchedule(context.Context, *DeleteFlowScheduleRequest) (*DeleteFlowScheduleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteFlowSchedule not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) ApproveNode(context.Context, *ApproveNodeRequest) (*NodeActionResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ApproveNode not implemented")
 }
@@ -204,9 +381,36 @@ func (UnimplementedOrchestratorServiceServer) SubmitHumanInput(context.Context,
 func (UnimplementedOrchestratorServiceServer) RetryNode(context.Context, *RetryNodeRequest) (*NodeActionResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RetryNode not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) ApproveToolCall(context.Context, *ToolCallDecisionRequest) (*NodeActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApproveToolCall not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) DenyToolCall(context.Context, *ToolCallDecisionRequest) (*NodeActionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DenyToolCall not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) TestAgent(context.Context, *TestAgentRequest) (*TestAgentResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method TestAgent not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) ReloadAgentRegistry(context.Context, *ReloadAgentRegistryRequest) (*ReloadAgentRegistryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReloadAgentRegistry not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) DescribeAgentRoles(context.Context, *DescribeAgentRolesRequest) (*DescribeAgentRolesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DescribeAgentRoles not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetNodeRunLogs(context.Context, *GetNodeRunLogsRequest) (*GetNodeRunLogsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNodeRunLogs not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetFlowRun(context.Context, *GetFlowRunRequest) (*GetFlowRunResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetFlowRun not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ListNodeRuns(context.Context, *ListNodeRunsRequest) (*ListNodeRunsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListNodeRuns not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetNodeRun(context.Context, *GetNodeRunRequest) (*GetNodeRunResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNodeRun not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ListTimeline(context.Context, *ListTimelineRequest) (*ListTimelineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTimeline not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) EventStream(*EventStreamRequest, grpc.ServerStreamingServer[ServerEvent]) error {
 	return status.Error(codes.Unimplemented, "method EventStream not implemented")
 }
@@ -267,6 +471,60 @@ func _OrchestratorService_CancelFlow_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_ScheduleFlow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleFlowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ScheduleFlow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ScheduleFlow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ScheduleFlow(ctx, req.(*ScheduleFlowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ListFlowSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlowSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListFlowSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListFlowSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListFlowSchedules(ctx, req.(*ListFlowSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_DeleteFlowSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFlowScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).DeleteFlowSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_DeleteFlowSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).DeleteFlowSchedule(ctx, req.(*DeleteFlowScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_ApproveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ApproveNodeRequest)
 	if err := dec(in); err != nil {
@@ -357,6 +615,42 @@ func _OrchestratorService_RetryNode_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_ApproveToolCall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ToolCallDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ApproveToolCall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ApproveToolCall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ApproveToolCall(ctx, req.(*ToolCallDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_DenyToolCall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ToolCallDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).DenyToolCall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_DenyToolCall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).DenyToolCall(ctx, req.(*ToolCallDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_TestAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TestAgentRequest)
 	if err := dec(in); err != nil {
@@ -375,6 +669,132 @@ func _OrchestratorService_TestAgent_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_ReloadAgentRegistry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadAgentRegistryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ReloadAgentRegistry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ReloadAgentRegistry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ReloadAgentRegistry(ctx, req.(*ReloadAgentRegistryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_DescribeAgentRoles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeAgentRolesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).DescribeAgentRoles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_DescribeAgentRoles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).DescribeAgentRoles(ctx, req.(*DescribeAgentRolesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetNodeRunLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeRunLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetNodeRunLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetNodeRunLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetNodeRunLogs(ctx, req.(*GetNodeRunLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetFlowRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlowRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetFlowRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetFlowRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetFlowRun(ctx, req.(*GetFlowRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ListNodeRuns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNodeRunsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListNodeRuns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListNodeRuns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListNodeRuns(ctx, req.(*ListNodeRunsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetNodeRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetNodeRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetNodeRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetNodeRun(ctx, req.(*GetNodeRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ListTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListTimeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListTimeline(ctx, req.(*ListTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_EventStream_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(EventStreamRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -401,6 +821,18 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelFlow",
 			Handler:    _OrchestratorService_CancelFlow_Handler,
 		},
+		{
+			MethodName: "ScheduleFlow",
+			Handler:    _OrchestratorService_ScheduleFlow_Handler,
+		},
+		{
+			MethodName: "ListFlowSchedules",
+			Handler:    _OrchestratorService_ListFlowSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteFlowSchedule",
+			Handler:    _OrchestratorService_DeleteFlowSchedule_Handler,
+		},
 		{
 			MethodName: "ApproveNode",
 			Handler:    _OrchestratorService_ApproveNode_Handler,
@@ -421,10 +853,46 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RetryNode",
 			Handler:    _OrchestratorService_RetryNode_Handler,
 		},
+		{
+			MethodName: "ApproveToolCall",
+			Handler:    _OrchestratorService_ApproveToolCall_Handler,
+		},
+		{
+			MethodName: "DenyToolCall",
+			Handler:    _OrchestratorService_DenyToolCall_Handler,
+		},
 		{
 			MethodName: "TestAgent",
 			Handler:    _OrchestratorService_TestAgent_Handler,
 		},
+		{
+			MethodName: "ReloadAgentRegistry",
+			Handler:    _OrchestratorService_ReloadAgentRegistry_Handler,
+		},
+		{
+			MethodName: "DescribeAgentRoles",
+			Handler:    _OrchestratorService_DescribeAgentRoles_Handler,
+		},
+		{
+			MethodName: "GetNodeRunLogs",
+			Handler:    _OrchestratorService_GetNodeRunLogs_Handler,
+		},
+		{
+			MethodName: "GetFlowRun",
+			Handler:    _OrchestratorService_GetFlowRun_Handler,
+		},
+		{
+			MethodName: "ListNodeRuns",
+			Handler:    _OrchestratorService_ListNodeRuns_Handler,
+		},
+		{
+			MethodName: "GetNodeRun",
+			Handler:    _OrchestratorService_GetNodeRun_Handler,
+		},
+		{
+			MethodName: "ListTimeline",
+			Handler:    _OrchestratorService_ListTimeline_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
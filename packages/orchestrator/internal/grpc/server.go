@@ -3,14 +3,18 @@ package grpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/sunshow/workgear/orchestrator/internal/agent"
+	"github.com/sunshow/workgear/orchestrator/internal/db"
 	"github.com/sunshow/workgear/orchestrator/internal/engine"
 	"github.com/sunshow/workgear/orchestrator/internal/event"
 	pb "github.com/sunshow/workgear/orchestrator/internal/grpc/pb"
@@ -23,16 +27,18 @@ type OrchestratorServer struct {
 	eventBus        *event.Bus
 	registry        *agent.Registry
 	factoryRegistry *agent.AgentFactoryRegistry
+	registryLoader  *agent.RegistryLoader
 	logger          *zap.SugaredLogger
 }
 
 // NewOrchestratorServer creates a new gRPC server
-func NewOrchestratorServer(executor *engine.FlowExecutor, eventBus *event.Bus, registry *agent.Registry, factoryRegistry *agent.AgentFactoryRegistry, logger *zap.SugaredLogger) *OrchestratorServer {
+func NewOrchestratorServer(executor *engine.FlowExecutor, eventBus *event.Bus, registry *agent.Registry, factoryRegistry *agent.AgentFactoryRegistry, registryLoader *agent.RegistryLoader, logger *zap.SugaredLogger) *OrchestratorServer {
 	return &OrchestratorServer{
 		executor:        executor,
 		eventBus:        eventBus,
 		registry:        registry,
 		factoryRegistry: factoryRegistry,
+		registryLoader:  registryLoader,
 		logger:          logger,
 	}
 }
@@ -70,12 +76,77 @@ func (s *OrchestratorServer) CancelFlow(ctx context.Context, req *pb.CancelFlowR
 	return &pb.CancelFlowResponse{Success: true}, nil
 }
 
+// ─── Flow Schedules ───
+
+func (s *OrchestratorServer) ScheduleFlow(ctx context.Context, req *pb.ScheduleFlowRequest) (*pb.ScheduleFlowResponse, error) {
+	s.logger.Infow("ScheduleFlow called",
+		"task_id", req.TaskId,
+		"workflow_id", req.WorkflowId,
+		"cron", req.Cron,
+		"timezone", req.Timezone,
+	)
+
+	schedule, err := s.executor.ScheduleFlow(ctx, &db.FlowSchedule{
+		TaskID:     req.TaskId,
+		WorkflowID: req.WorkflowId,
+		Cron:       req.Cron,
+		Timezone:   req.Timezone,
+		Variables:  req.Variables,
+		Enabled:    !req.Disabled,
+	})
+	if err != nil {
+		s.logger.Errorw("ScheduleFlow failed", "error", err)
+		return &pb.ScheduleFlowResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &pb.ScheduleFlowResponse{Success: true, Schedule: toPBFlowSchedule(schedule)}, nil
+}
+
+func (s *OrchestratorServer) ListFlowSchedules(ctx context.Context, req *pb.ListFlowSchedulesRequest) (*pb.ListFlowSchedulesResponse, error) {
+	schedules, err := s.executor.ListFlowSchedules(ctx, req.TaskId)
+	if err != nil {
+		s.logger.Errorw("ListFlowSchedules failed", "task_id", req.TaskId, "error", err)
+		return &pb.ListFlowSchedulesResponse{Success: false, Error: err.Error()}, nil
+	}
+	resp := &pb.ListFlowSchedulesResponse{Success: true, Schedules: make([]*pb.FlowSchedule, 0, len(schedules))}
+	for _, schedule := range schedules {
+		resp.Schedules = append(resp.Schedules, toPBFlowSchedule(schedule))
+	}
+	return resp, nil
+}
+
+func (s *OrchestratorServer) DeleteFlowSchedule(ctx context.Context, req *pb.DeleteFlowScheduleRequest) (*pb.DeleteFlowScheduleResponse, error) {
+	s.logger.Infow("DeleteFlowSchedule called", "schedule_id", req.ScheduleId)
+
+	if err := s.executor.DeleteFlowSchedule(ctx, req.ScheduleId); err != nil {
+		s.logger.Errorw("DeleteFlowSchedule failed", "error", err)
+		return &pb.DeleteFlowScheduleResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &pb.DeleteFlowScheduleResponse{Success: true}, nil
+}
+
+func toPBFlowSchedule(fs *db.FlowSchedule) *pb.FlowSchedule {
+	return &pb.FlowSchedule{
+		Id:            fs.ID,
+		TaskId:        fs.TaskID,
+		WorkflowId:    fs.WorkflowID,
+		Cron:          fs.Cron,
+		Timezone:      fs.Timezone,
+		Variables:     fs.Variables,
+		Enabled:       fs.Enabled,
+		NextRunAt:     unixMilli(fs.NextRunAt),
+		LastRunAt:     unixMilli(fs.LastRunAt),
+		LastFlowRunId: derefStr(fs.LastFlowRunID),
+		CreatedBy:     derefStr(fs.CreatedBy),
+		CreatedAt:     fs.CreatedAt.UnixMilli(),
+	}
+}
+
 // ─── Human Actions ───
 
 func (s *OrchestratorServer) ApproveNode(ctx context.Context, req *pb.ApproveNodeRequest) (*pb.NodeActionResponse, error) {
 	s.logger.Infow("ApproveNode called", "node_run_id", req.NodeRunId)
 
-	if err := s.executor.HandleApprove(ctx, req.NodeRunId); err != nil {
+	if err := s.executor.HandleApprove(ctx, req.NodeRunId, fromPBReview(req.Review)); err != nil {
 		s.logger.Errorw("ApproveNode failed", "error", err)
 		return &pb.NodeActionResponse{Success: false, Error: err.Error()}, nil
 	}
@@ -86,7 +157,7 @@ func (s *OrchestratorServer) ApproveNode(ctx context.Context, req *pb.ApproveNod
 func (s *OrchestratorServer) RejectNode(ctx context.Context, req *pb.RejectNodeRequest) (*pb.NodeActionResponse, error) {
 	s.logger.Infow("RejectNode called", "node_run_id", req.NodeRunId, "feedback", req.Feedback)
 
-	if err := s.executor.HandleReject(ctx, req.NodeRunId, req.Feedback); err != nil {
+	if err := s.executor.HandleReject(ctx, req.NodeRunId, req.Feedback, fromPBReview(req.Review)); err != nil {
 		s.logger.Errorw("RejectNode failed", "error", err)
 		return &pb.NodeActionResponse{Success: false, Error: err.Error()}, nil
 	}
@@ -97,7 +168,7 @@ func (s *OrchestratorServer) RejectNode(ctx context.Context, req *pb.RejectNodeR
 func (s *OrchestratorServer) EditNode(ctx context.Context, req *pb.EditNodeRequest) (*pb.NodeActionResponse, error) {
 	s.logger.Infow("EditNode called", "node_run_id", req.NodeRunId)
 
-	if err := s.executor.HandleEdit(ctx, req.NodeRunId, req.EditedContent, req.ChangeSummary); err != nil {
+	if err := s.executor.HandleEdit(ctx, req.NodeRunId, req.EditedContent, req.ChangeSummary, fromPBReview(req.Review)); err != nil {
 		s.logger.Errorw("EditNode failed", "error", err)
 		return &pb.NodeActionResponse{Success: false, Error: err.Error()}, nil
 	}
@@ -105,10 +176,40 @@ func (s *OrchestratorServer) EditNode(ctx context.Context, req *pb.EditNodeReque
 	return &pb.NodeActionResponse{Success: true}, nil
 }
 
+// fromPBReview converts the optional structured review of a human action
+func fromPBReview(r *pb.ReviewDetails) engine.ReviewDetails {
+	if r == nil {
+		return engine.ReviewDetails{}
+	}
+	details := engine.ReviewDetails{
+		ReviewerID:   r.ReviewerId,
+		ReviewerName: r.ReviewerName,
+		Severity:     r.Severity,
+	}
+	for _, c := range r.Comments {
+		details.Comments = append(details.Comments, db.ReviewComment{
+			File:     c.File,
+			Line:     int(c.Line),
+			EndLine:  int(c.EndLine),
+			Body:     c.Body,
+			Severity: c.Severity,
+		})
+	}
+	for _, item := range r.Checklist {
+		details.Checklist = append(details.Checklist, db.ReviewChecklistItem{Item: item.Item, Passed: item.Passed, Note: item.Note})
+	}
+	return details
+}
+
 func (s *OrchestratorServer) SubmitHumanInput(ctx context.Context, req *pb.SubmitHumanInputRequest) (*pb.NodeActionResponse, error) {
 	s.logger.Infow("SubmitHumanInput called", "node_run_id", req.NodeRunId)
 
 	if err := s.executor.HandleHumanInput(ctx, req.NodeRunId, req.DataJson); err != nil {
+		var formErr *engine.FormValidationError
+		if errors.As(err, &formErr) {
+			s.logger.Infow("SubmitHumanInput rejected invalid input", "node_run_id", req.NodeRunId, "fields", formErr.Fields)
+			return &pb.NodeActionResponse{Success: false, Error: err.Error(), FieldErrors: formErr.Fields}, nil
+		}
 		s.logger.Errorw("SubmitHumanInput failed", "error", err)
 		return &pb.NodeActionResponse{Success: false, Error: err.Error()}, nil
 	}
@@ -127,6 +228,30 @@ func (s *OrchestratorServer) RetryNode(ctx context.Context, req *pb.RetryNodeReq
 	return &pb.NodeActionResponse{Success: true}, nil
 }
 
+// ─── Tool Call Approval ───
+
+func (s *OrchestratorServer) ApproveToolCall(ctx context.Context, req *pb.ToolCallDecisionRequest) (*pb.NodeActionResponse, error) {
+	s.logger.Infow("ApproveToolCall called", "approval_id", req.ApprovalId, "decided_by", req.DecidedBy)
+
+	if err := s.executor.HandleToolCallDecision(ctx, req.ApprovalId, true, req.Reason, req.DecidedBy); err != nil {
+		s.logger.Errorw("ApproveToolCall failed", "error", err)
+		return &pb.NodeActionResponse{Success: false, Error: err.Error()}, nil
+	}
+
+	return &pb.NodeActionResponse{Success: true}, nil
+}
+
+func (s *OrchestratorServer) DenyToolCall(ctx context.Context, req *pb.ToolCallDecisionRequest) (*pb.NodeActionResponse, error) {
+	s.logger.Infow("DenyToolCall called", "approval_id", req.ApprovalId, "decided_by", req.DecidedBy, "reason", req.Reason)
+
+	if err := s.executor.HandleToolCallDecision(ctx, req.ApprovalId, false, req.Reason, req.DecidedBy); err != nil {
+		s.logger.Errorw("DenyToolCall failed", "error", err)
+		return &pb.NodeActionResponse{Success: false, Error: err.Error()}, nil
+	}
+
+	return &pb.NodeActionResponse{Success: true}, nil
+}
+
 // ─── Event Stream ───
 
 func (s *OrchestratorServer) EventStream(req *pb.EventStreamRequest, stream pb.OrchestratorService_EventStreamServer) error {
@@ -260,23 +385,19 @@ func (s *OrchestratorServer) TestAgent(ctx context.Context, req *pb.TestAgentReq
 	// Collect logs
 	var logs []string
 	var logsMu sync.Mutex
-	if combined, ok := adapterInstance.(*agent.CombinedAdapter); ok {
-		if dockerExec, ok := combined.Executor().(*agent.DockerExecutor); ok {
-			dockerExec.SetLogEventCallback(func(evt agent.ClaudeStreamEvent) {
-				logLine := fmt.Sprintf("[%s] %s", evt.Type, evt.Subtype)
-				if evt.Message != nil {
-					for _, block := range evt.Message.Content {
-						if block.Type == "text" && block.Text != "" {
-							logLine += ": " + truncateStr(block.Text, 200)
-						}
-					}
+	agentReq.LogSink = agent.LogSinkFunc(func(evt agent.ClaudeStreamEvent) {
+		logLine := fmt.Sprintf("[%s] %s", evt.Type, evt.Subtype)
+		if evt.Message != nil {
+			for _, block := range evt.Message.Content {
+				if block.Type == "text" && block.Text != "" {
+					logLine += ": " + truncateStr(block.Text, 200)
 				}
-				logsMu.Lock()
-				logs = append(logs, logLine)
-				logsMu.Unlock()
-			})
+			}
 		}
-	}
+		logsMu.Lock()
+		logs = append(logs, logLine)
+		logsMu.Unlock()
+	})
 
 	// Execute with timeout
 	testCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
@@ -301,6 +422,85 @@ func (s *OrchestratorServer) TestAgent(ctx context.Context, req *pb.TestAgentReq
 	}, nil
 }
 
+// ─── Agent Registry ───
+
+func (s *OrchestratorServer) ReloadAgentRegistry(ctx context.Context, req *pb.ReloadAgentRegistryRequest) (*pb.ReloadAgentRegistryResponse, error) {
+	s.logger.Info("ReloadAgentRegistry called")
+
+	summary, err := s.registryLoader.Reload(ctx)
+	if err != nil {
+		s.logger.Warnw("Agent registry reload failed, keeping current registry", "error", err)
+		return &pb.ReloadAgentRegistryResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &pb.ReloadAgentRegistryResponse{
+		Success:         true,
+		Providers:       int32(summary.Providers),
+		ReusedProviders: int32(summary.Reused),
+		Roles:           int32(summary.Roles),
+		Skipped:         summary.Skipped,
+	}, nil
+}
+
+func (s *OrchestratorServer) DescribeAgentRoles(ctx context.Context, req *pb.DescribeAgentRolesRequest) (*pb.DescribeAgentRolesResponse, error) {
+	resolutions, ready, err := s.registryLoader.Describe(ctx)
+	if err != nil {
+		return &pb.DescribeAgentRolesResponse{Success: false, Error: err.Error()}, nil
+	}
+
+	roles := make([]*pb.AgentRoleResolution, 0, len(resolutions))
+	for _, res := range resolutions {
+		roles = append(roles, &pb.AgentRoleResolution{
+			Role:           res.Role,
+			AgentType:      res.AgentType,
+			InDatabase:     res.InDatabase,
+			ProviderId:     res.ProviderID,
+			ProviderName:   res.ProviderName,
+			ModelName:      res.ModelName,
+			ProviderSource: res.ProviderSource,
+			ModelSource:    res.ModelSource,
+			Resolved:       res.Resolved,
+			AdapterReady:   ready[res.Role],
+			Reason:         res.Reason,
+		})
+	}
+	return &pb.DescribeAgentRolesResponse{Success: true, Roles: roles}, nil
+}
+
+// ─── Node Logs ───
+
+const (
+	defaultLogPageSize = 200
+	maxLogPageSize     = 1000
+)
+
+func (s *OrchestratorServer) GetNodeRunLogs(ctx context.Context, req *pb.GetNodeRunLogsRequest) (*pb.GetNodeRunLogsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultLogPageSize
+	}
+	if limit > maxLogPageSize {
+		limit = maxLogPageSize
+	}
+
+	logs, hasMore, err := s.executor.GetNodeRunLogs(ctx, req.NodeRunId, req.AfterSeq, limit)
+	if err != nil {
+		s.logger.Errorw("GetNodeRunLogs failed", "node_run_id", req.NodeRunId, "error", err)
+		return &pb.GetNodeRunLogsResponse{Success: false, Error: err.Error()}, nil
+	}
+
+	resp := &pb.GetNodeRunLogsResponse{
+		Success: true,
+		Entries: make([]*pb.NodeRunLogEntry, 0, len(logs)),
+		NextSeq: req.AfterSeq,
+		HasMore: hasMore,
+	}
+	for _, l := range logs {
+		resp.Entries = append(resp.Entries, &pb.NodeRunLogEntry{Seq: l.Seq, EventJson: l.Event})
+		resp.NextSeq = l.Seq
+	}
+	return resp, nil
+}
+
 func strPtr(s string) *string { return &s }
 
 func truncateStr(s string, maxLen int) string {
@@ -309,3 +509,168 @@ func truncateStr(s string, maxLen int) string {
 	}
 	return s[:maxLen] + "..."
 }
+
+// ─── Run Queries ───
+
+const (
+	defaultTimelinePageSize = 100
+	maxTimelinePageSize     = 500
+)
+
+func (s *OrchestratorServer) GetFlowRun(ctx context.Context, req *pb.GetFlowRunRequest) (*pb.GetFlowRunResponse, error) {
+	flowRun, dag, err := s.executor.GetFlowRun(ctx, req.FlowRunId, req.IncludeDag)
+	if err != nil {
+		s.logger.Errorw("GetFlowRun failed", "flow_run_id", req.FlowRunId, "error", err)
+		return &pb.GetFlowRunResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &pb.GetFlowRunResponse{Success: true, FlowRun: toPBFlowRun(flowRun), Dag: toPBFlowDag(dag)}, nil
+}
+
+func (s *OrchestratorServer) ListNodeRuns(ctx context.Context, req *pb.ListNodeRunsRequest) (*pb.ListNodeRunsResponse, error) {
+	nodeRuns, err := s.executor.ListNodeRuns(ctx, db.NodeRunFilter{
+		FlowRunID:  req.FlowRunId,
+		Statuses:   req.Statuses,
+		NodeID:     req.NodeId,
+		Attempt:    int(req.Attempt),
+		LatestOnly: req.LatestOnly,
+	})
+	if err != nil {
+		s.logger.Errorw("ListNodeRuns failed", "flow_run_id", req.FlowRunId, "error", err)
+		return &pb.ListNodeRunsResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &pb.ListNodeRunsResponse{Success: true, NodeRuns: toPBNodeRuns(nodeRuns)}, nil
+}
+
+func (s *OrchestratorServer) GetNodeRun(ctx context.Context, req *pb.GetNodeRunRequest) (*pb.GetNodeRunResponse, error) {
+	nodeRun, attempts, err := s.executor.GetNodeRunAttempts(ctx, req.NodeRunId)
+	if err != nil {
+		s.logger.Errorw("GetNodeRun failed", "node_run_id", req.NodeRunId, "error", err)
+		return &pb.GetNodeRunResponse{Success: false, Error: err.Error()}, nil
+	}
+	return &pb.GetNodeRunResponse{Success: true, NodeRun: toPBNodeRun(nodeRun), Attempts: toPBNodeRuns(attempts)}, nil
+}
+
+func (s *OrchestratorServer) ListTimeline(ctx context.Context, req *pb.ListTimelineRequest) (*pb.ListTimelineResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultTimelinePageSize
+	}
+	if limit > maxTimelinePageSize {
+		limit = maxTimelinePageSize
+	}
+
+	events, hasMore, err := s.executor.ListTimeline(ctx, db.TimelineFilter{
+		TaskID:     req.TaskId,
+		FlowRunID:  req.FlowRunId,
+		NodeRunID:  req.NodeRunId,
+		EventTypes: req.EventTypes,
+	}, req.AfterId, limit)
+	if errors.Is(err, db.ErrInvalidTimelineCursor) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		s.logger.Errorw("ListTimeline failed", "task_id", req.TaskId, "flow_run_id", req.FlowRunId, "error", err)
+		return &pb.ListTimelineResponse{Success: false, Error: err.Error()}, nil
+	}
+
+	resp := &pb.ListTimelineResponse{
+		Success:     true,
+		Events:      make([]*pb.TimelineEvent, 0, len(events)),
+		NextAfterId: req.AfterId,
+		HasMore:     hasMore,
+	}
+	for _, evt := range events {
+		resp.Events = append(resp.Events, &pb.TimelineEvent{
+			Id:          evt.ID,
+			TaskId:      evt.TaskID,
+			FlowRunId:   derefStr(evt.FlowRunID),
+			NodeRunId:   derefStr(evt.NodeRunID),
+			EventType:   evt.EventType,
+			ContentJson: evt.Content,
+			CreatedAt:   evt.CreatedAt.UnixMilli(),
+		})
+		resp.NextAfterId = evt.ID
+	}
+	return resp, nil
+}
+
+func toPBFlowRun(fr *db.FlowRun) *pb.FlowRun {
+	return &pb.FlowRun{
+		Id:            fr.ID,
+		TaskId:        fr.TaskID,
+		WorkflowId:    fr.WorkflowID,
+		Status:        fr.Status,
+		Error:         derefStr(fr.Error),
+		VariablesJson: derefStr(fr.Variables),
+		StartedAt:     unixMilli(fr.StartedAt),
+		CompletedAt:   unixMilli(fr.CompletedAt),
+		CreatedAt:     fr.CreatedAt.UnixMilli(),
+	}
+}
+
+func toPBFlowDag(dag *engine.DAG) *pb.FlowDag {
+	if dag == nil {
+		return nil
+	}
+	out := &pb.FlowDag{
+		Nodes: make([]*pb.DagNode, 0, len(dag.NodeOrder)),
+		Edges: make([]*pb.DagEdge, 0, len(dag.Edges)),
+	}
+	for _, id := range dag.NodeOrder {
+		node := dag.Nodes[id]
+		pbNode := &pb.DagNode{Id: node.ID, Name: node.Name, Type: node.Type, DependsOn: dag.Deps[id]}
+		if node.Agent != nil {
+			pbNode.AgentRole = node.Agent.Role
+		}
+		out.Nodes = append(out.Nodes, pbNode)
+	}
+	for _, edge := range dag.Edges {
+		out.Edges = append(out.Edges, &pb.DagEdge{From: edge.From, To: edge.To})
+	}
+	return out
+}
+
+func toPBNodeRun(nr *db.NodeRun) *pb.NodeRun {
+	return &pb.NodeRun{
+		Id:            nr.ID,
+		FlowRunId:     nr.FlowRunID,
+		NodeId:        nr.NodeID,
+		NodeType:      derefStr(nr.NodeType),
+		NodeName:      derefStr(nr.NodeName),
+		Status:        nr.Status,
+		Attempt:       int32(nr.Attempt),
+		InputJson:     derefStr(nr.Input),
+		OutputJson:    derefStr(nr.Output),
+		Error:         derefStr(nr.Error),
+		ReviewAction:  derefStr(nr.ReviewAction),
+		ReviewComment: derefStr(nr.ReviewComment),
+		ReviewedAt:    unixMilli(nr.ReviewedAt),
+		ReviewedBy:    derefStr(nr.ReviewedBy),
+		StartedAt:     unixMilli(nr.StartedAt),
+		CompletedAt:   unixMilli(nr.CompletedAt),
+		CreatedAt:     nr.CreatedAt.UnixMilli(),
+	}
+}
+
+func toPBNodeRuns(nodeRuns []*db.NodeRun) []*pb.NodeRun {
+	out := make([]*pb.NodeRun, 0, len(nodeRuns))
+	for _, nr := range nodeRuns {
+		out = append(out, toPBNodeRun(nr))
+	}
+	return out
+}
+
+func derefStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// unixMilli converts an optional timestamp to Unix milliseconds (0 = unset)
+func unixMilli(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
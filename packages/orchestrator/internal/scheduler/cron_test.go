@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"", "expected 5 fields"},
+		{"* * * *", "expected 5 fields"},
+		{"* * * * * *", "expected 5 fields"},
+		{"@every 5m", "expected 5 fields"},
+		// values and ranges
+		{"60 * * * *", `minute: "60" is out of range 0-59`},
+		{"* 24 * * *", `hour: "24" is out of range 0-23`},
+		{"* * 0 * *", `day of month: "0" is out of range 1-31`},
+		{"* * 32 * *", `day of month: "32" is out of range 1-31`},
+		{"* * * 13 *", `month: "13" is out of range 1-12`},
+		{"* * * * 8", `day of week: "8" is out of range 0-7`},
+		{"* 18-8 * * *", `hour: "18-8" is out of range 0-23`},
+		{"* 8-24 * * *", `hour: "8-24" is out of range 0-23`},
+		{"* * * * FRI-MON", `day of week: "FRI-MON" is out of range 0-7`},
+		{"-5 * * * *", `minute: invalid value ""`},
+		{"1- * * * *", `minute: invalid value ""`},
+		{"a * * * *", `minute: invalid value "a"`},
+		{"* * ? * *", `day of month: invalid value "?"`},
+		{"* * L * *", `day of month: invalid value "L"`},
+		{"* * * FOO *", `month: invalid value "FOO"`},
+		{"* * * * MON,XYZ", `day of week: invalid value "XYZ"`},
+		// steps
+		{"*/0 * * * *", `minute: invalid step in "*/0"`},
+		{"*/-1 * * * *", `minute: invalid step in "*/-1"`},
+		{"*/x * * * *", `minute: invalid step in "*/x"`},
+		{"0-30/ * * * *", `minute: invalid step in "0-30/"`},
+		{"*/5/2 * * * *", `minute: invalid step in "*/5/2"`},
+		// lists
+		{"1,,2 * * * *", `minute: invalid value ""`},
+		{"1,2, * * * *", `minute: invalid value ""`},
+		{"0 0 1,40 * *", `day of month: "40" is out of range 1-31`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCronFields(t *testing.T) {
+	tests := []struct {
+		expr   string
+		minute []int
+		dow    []int
+	}{
+		{"0,15,30,45 * * * *", []int{0, 15, 30, 45}, nil},
+		{"*/20 * * * *", []int{0, 20, 40}, nil},
+		{"10-20/5 * * * *", []int{10, 15, 20}, nil},
+		{"50/4 * * * *", []int{50, 54, 58}, nil},
+		{"1-3,58 * * * *", []int{1, 2, 3, 58}, nil},
+		{"0 * * * mon-fri", nil, []int{1, 2, 3, 4, 5}},
+		{"0 * * * 5-7", nil, []int{0, 5, 6}}, // 7 is Sunday
+		{"0 * * * SUN,sat", nil, []int{0, 6}},
+	}
+	bitsOf := func(values []int) uint64 {
+		var bits uint64
+		for _, v := range values {
+			bits |= 1 << uint(v)
+		}
+		return bits
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if tt.minute != nil && c.minute != bitsOf(tt.minute) {
+			t.Errorf("%s: minutes = %b, want %v", tt.expr, c.minute, tt.minute)
+		}
+		if tt.dow != nil && c.dow&^(1<<7) != bitsOf(tt.dow) {
+			t.Errorf("%s: weekdays = %b, want %v", tt.expr, c.dow, tt.dow)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{"next minute", "* * * * *", "2026-03-10 08:00", "2026-03-10 08:01"},
+		{"later the same hour", "*/15 * * * *", "2026-03-10 08:16", "2026-03-10 08:30"},
+		{"next hour", "5 * * * *", "2026-03-10 08:05", "2026-03-10 09:05"},
+		{"next day", "30 9 * * *", "2026-03-10 10:00", "2026-03-11 09:30"},
+		{"across a month end", "0 0 * * *", "2026-04-30 23:59", "2026-05-01 00:00"},
+		{"across a year end", "0 0 * * *", "2026-12-31 23:30", "2027-01-01 00:00"},
+		{"yearly macro across a year", "@yearly", "2026-01-01 00:00", "2027-01-01 00:00"},
+		{"monthly macro", "@monthly", "2026-01-31 12:00", "2026-02-01 00:00"},
+		{"day 31 skips short months", "0 12 31 * *", "2026-04-01 00:00", "2026-05-31 12:00"},
+		{"day 30 skips February", "0 0 30 * *", "2026-01-30 00:00", "2026-03-30 00:00"},
+		{"leap day", "0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"month list across a year", "0 8 1 JAN,JUL *", "2026-07-01 08:00", "2027-01-01 08:00"},
+		{"weekdays over a weekend", "0 9 * * MON-FRI", "2026-03-13 09:00", "2026-03-16 09:00"}, // Fri → Mon
+		{"sunday as 7", "0 0 * * 7", "2026-03-10 00:00", "2026-03-15 00:00"},
+		{"weekday across a year", "0 0 * * SUN", "2026-12-28 00:00", "2027-01-03 00:00"},
+		// Both day fields restricted: a day matching either fires
+		{"dom or dow: weekday first", "0 0 13 * FRI", "2026-03-01 00:00", "2026-03-06 00:00"},
+		{"dom or dow: day first", "0 0 13 * FRI", "2026-03-12 00:00", "2026-03-13 00:00"},
+		{"dom or dow: across a month", "0 0 1 * MON", "2026-03-30 00:00", "2026-04-01 00:00"},
+		// A starred field (even with a step) does not restrict the day: both must match
+		{"dow with dom star", "0 0 * * MON", "2026-03-10 00:00", "2026-03-16 00:00"},
+		{"dom step with dow", "0 0 */10 * MON", "2026-03-01 00:00", "2026-05-11 00:00"}, // day 1/11/21/31 on a Monday
+		{"impossible date", "0 0 31 2 *", "2026-01-01 00:00", "0001-01-01 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := at(tt.from).Add(25 * time.Second) // seconds are dropped
+			got := c.Next(from)
+			if tt.want == "0001-01-01 00:00" {
+				if !got.IsZero() {
+					t.Errorf("Next = %s, want none", got)
+				}
+				return
+			}
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", from.Format(time.DateTime), got.Format(time.DateTime), want.Format(time.DateTime))
+			}
+		})
+	}
+}
+
+// The expression is evaluated in the location of the given time
+func TestCronNextInLocation(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip("time zone data unavailable")
+	}
+	c, _ := ParseCron("0 9 * * *")
+	from := time.Date(2026, 3, 10, 9, 0, 0, 0, shanghai) // 01:00 UTC
+	if got := c.Next(from); !got.Equal(time.Date(2026, 3, 11, 9, 0, 0, 0, shanghai)) || got.Location() != shanghai {
+		t.Errorf("Next in Asia/Shanghai = %s", got)
+	}
+}
@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Handler fires a timer. A returned error retries the timer with backoff.
+type Handler func(ctx context.Context, t *db.Timer) error
+
+// Scheduler claims due timers from the store and dispatches them to the handler registered
+// for their kind
+type Scheduler struct {
+	store    Store
+	clock    Clock
+	logger   *zap.SugaredLogger
+	workerID string
+
+	handlers   map[string]Handler
+	handlersMu sync.RWMutex
+
+	PollInterval time.Duration // how often due timers are claimed (default 5s)
+	Lease        time.Duration // a firing timer is reclaimed after this long (default 5m)
+	BatchSize    int           // timers claimed per poll (default 50)
+	MaxAttempts  int           // handler attempts before the timer fails (default 5)
+}
+
+// New creates a scheduler on the wall clock
+func New(store Store, logger *zap.SugaredLogger) *Scheduler {
+	return &Scheduler{
+		store:        store,
+		clock:        RealClock,
+		logger:       logger,
+		workerID:     fmt.Sprintf("scheduler-%s", uuid.New().String()[:8]),
+		handlers:     make(map[string]Handler),
+		PollInterval: 5 * time.Second,
+		Lease:        5 * time.Minute,
+		BatchSize:    50,
+		MaxAttempts:  5,
+	}
+}
+
+// SetClock replaces the clock (e.g. with a FakeClock in tests)
+func (s *Scheduler) SetClock(c Clock) {
+	if c != nil {
+		s.clock = c
+	}
+}
+
+// Now returns the scheduler's current time
+func (s *Scheduler) Now() time.Time {
+	return s.clock.Now()
+}
+
+// Handle registers the handler of a timer kind
+func (s *Scheduler) Handle(kind string, h Handler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.handlers[kind] = h
+}
+
+// Schedule stores a timer firing at fireAt. key (optional) groups timers for Cancel.
+func (s *Scheduler) Schedule(ctx context.Context, kind, key string, fireAt time.Time, payload map[string]any) (*db.Timer, error) {
+	t := &db.Timer{
+		ID:      uuid.New().String(),
+		Kind:    kind,
+		Key:     key,
+		FireAt:  fireAt,
+		Payload: payload,
+		Status:  db.TimerPending,
+	}
+	if err := s.store.CreateTimer(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// After stores a timer firing after delay
+func (s *Scheduler) After(ctx context.Context, kind, key string, delay time.Duration, payload map[string]any) (*db.Timer, error) {
+	return s.Schedule(ctx, kind, key, s.clock.Now().Add(delay), payload)
+}
+
+// Cancel cancels the pending timers with the given key
+func (s *Scheduler) Cancel(ctx context.Context, key string) (int, error) {
+	if key == "" {
+		return 0, nil
+	}
+	return s.store.CancelTimers(ctx, key)
+}
+
+// Run fires due timers every PollInterval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	s.logger.Infow("Starting timer scheduler", "worker_id", s.workerID)
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Timer scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+		if _, err := s.RunDue(ctx); err != nil {
+			s.logger.Errorw("Failed to fire due timers", "error", err)
+		}
+	}
+}
+
+// RunDue claims the timers due now and fires them, returning how many were claimed.
+// Tests drive it directly after advancing a FakeClock.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	timers, err := s.store.ClaimDueTimers(ctx, s.workerID, s.clock.Now(), s.Lease, s.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range timers {
+		s.fire(ctx, t)
+	}
+	return len(timers), nil
+}
+
+// fire dispatches one claimed timer and records the outcome
+func (s *Scheduler) fire(ctx context.Context, t *db.Timer) {
+	s.handlersMu.RLock()
+	handler, ok := s.handlers[t.Kind]
+	s.handlersMu.RUnlock()
+	if !ok {
+		s.logger.Warnw("No handler for timer kind", "timer_id", t.ID, "kind", t.Kind)
+		s.recordOutcome(t, "fail", s.store.FailTimer(ctx, t.ID, s.workerID, "no handler for timer kind "+t.Kind))
+		return
+	}
+
+	err := callHandler(ctx, handler, t)
+	if err == nil {
+		s.recordOutcome(t, "complete", s.store.CompleteTimer(ctx, t.ID, s.workerID))
+		return
+	}
+
+	if t.Attempts >= s.MaxAttempts {
+		s.logger.Errorw("Timer failed", "timer_id", t.ID, "kind", t.Kind, "attempts", t.Attempts, "error", err)
+		s.recordOutcome(t, "fail", s.store.FailTimer(ctx, t.ID, s.workerID, err.Error()))
+		return
+	}
+	retryAt := s.clock.Now().Add(backoff(t.Attempts))
+	s.logger.Warnw("Timer handler failed, retrying", "timer_id", t.ID, "kind", t.Kind, "attempts", t.Attempts, "retry_at", retryAt, "error", err)
+	s.recordOutcome(t, "reschedule", s.store.RetryTimer(ctx, t.ID, s.workerID, retryAt, err.Error()))
+}
+
+// recordOutcome logs a failure to store a timer's outcome. A lost lease means another worker
+// reclaimed the timer and owns its outcome now.
+func (s *Scheduler) recordOutcome(t *db.Timer, op string, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, db.ErrTimerLeaseLost):
+		s.logger.Infow("Timer lease lost before its outcome was recorded", "timer_id", t.ID, "kind", t.Kind, "op", op)
+	default:
+		s.logger.Warnw("Failed to "+op+" timer", "timer_id", t.ID, "error", err)
+	}
+}
+
+// callHandler runs the handler, turning a panic into an error
+func callHandler(ctx context.Context, h Handler, t *db.Timer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("timer handler panicked: %v", r)
+		}
+	}()
+	return h(ctx, t)
+}
+
+// backoff is the retry delay after the given number of attempts: 10s, 20s, 40s, ... capped at 10m
+func backoff(attempts int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempts && delay < 10*time.Minute; i++ {
+		delay *= 2
+	}
+	return min(delay, 10*time.Minute)
+}
+
+// DecodePayload decodes a timer's payload into v
+func DecodePayload(t *db.Timer, v any) error {
+	data, err := json.Marshal(t.Payload)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s timer payload: %w", t.Kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+func newTestScheduler(t *testing.T) (*Scheduler, *MemoryStore, *FakeClock) {
+	t.Helper()
+	clock := NewFakeClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	store := NewMemoryStore(clock)
+	s := New(store, zap.NewNop().Sugar())
+	s.SetClock(clock)
+	return s, store, clock
+}
+
+// runDue fires the due timers and checks how many were claimed
+func runDue(t *testing.T, s *Scheduler, want int) {
+	t.Helper()
+	claimed, err := s.RunDue(context.Background())
+	if err != nil {
+		t.Fatalf("RunDue: %v", err)
+	}
+	if claimed != want {
+		t.Fatalf("claimed %d timers, want %d", claimed, want)
+	}
+}
+
+// onlyTimer returns the store's single timer
+func onlyTimer(t *testing.T, store *MemoryStore) db.Timer {
+	t.Helper()
+	timers := store.Timers()
+	if len(timers) != 1 {
+		t.Fatalf("got %d timers, want 1", len(timers))
+	}
+	return timers[0]
+}
+
+func TestSchedulerFire(t *testing.T) {
+	s, store, clock := newTestScheduler(t)
+	var fired []*db.Timer
+	s.Handle("reminder", func(_ context.Context, timer *db.Timer) error {
+		fired = append(fired, timer)
+		return nil
+	})
+
+	if _, err := s.After(context.Background(), "reminder", "node_run:1", time.Minute, map[string]any{"seq": 2}); err != nil {
+		t.Fatal(err)
+	}
+	runDue(t, s, 0)
+
+	clock.Advance(time.Minute)
+	runDue(t, s, 1)
+	if len(fired) != 1 {
+		t.Fatalf("handler called %d times, want 1", len(fired))
+	}
+	var payload struct {
+		Seq int `json:"seq"`
+	}
+	if err := DecodePayload(fired[0], &payload); err != nil || payload.Seq != 2 {
+		t.Errorf("payload = %+v (%v)", payload, err)
+	}
+	if timer := onlyTimer(t, store); timer.Status != db.TimerDone || timer.Attempts != 1 || timer.LockedBy != nil {
+		t.Errorf("timer = %+v", timer)
+	}
+
+	// A done timer never fires again
+	clock.Advance(time.Hour)
+	runDue(t, s, 0)
+}
+
+func TestSchedulerRetryBackoff(t *testing.T) {
+	s, store, clock := newTestScheduler(t)
+	s.MaxAttempts = 3
+	calls := 0
+	s.Handle("flaky", func(context.Context, *db.Timer) error {
+		calls++
+		return errors.New("downstream unavailable")
+	})
+	if _, err := s.After(context.Background(), "flaky", "", 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each failure reschedules after 10s, 20s, ... until MaxAttempts
+	runDue(t, s, 1)
+	for attempt, delay := range []time.Duration{10 * time.Second, 20 * time.Second} {
+		timer := onlyTimer(t, store)
+		if timer.Status != db.TimerPending || timer.Attempts != attempt+1 {
+			t.Fatalf("after attempt %d: %+v", attempt+1, timer)
+		}
+		if want := clock.Now().Add(delay); !timer.FireAt.Equal(want) {
+			t.Errorf("after attempt %d: fire_at = %s, want %s", attempt+1, timer.FireAt, want)
+		}
+		if timer.LastError == nil || *timer.LastError != "downstream unavailable" {
+			t.Errorf("last_error = %v", timer.LastError)
+		}
+		clock.Advance(delay - time.Second)
+		runDue(t, s, 0)
+		clock.Advance(time.Second)
+		runDue(t, s, 1)
+	}
+
+	if timer := onlyTimer(t, store); timer.Status != db.TimerFailed || timer.Attempts != 3 || timer.CompletedAt == nil {
+		t.Errorf("timer = %+v, want failed after 3 attempts", timer)
+	}
+	if calls != 3 {
+		t.Errorf("handler called %d times, want 3", calls)
+	}
+}
+
+func TestSchedulerHandlerPanicRetries(t *testing.T) {
+	s, store, _ := newTestScheduler(t)
+	s.Handle("boom", func(context.Context, *db.Timer) error { panic("nil map") })
+	if _, err := s.After(context.Background(), "boom", "", 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	runDue(t, s, 1)
+	timer := onlyTimer(t, store)
+	if timer.Status != db.TimerPending || timer.LastError == nil || *timer.LastError != "timer handler panicked: nil map" {
+		t.Errorf("timer = %+v", timer)
+	}
+}
+
+func TestSchedulerUnknownKindFails(t *testing.T) {
+	s, store, _ := newTestScheduler(t)
+	if _, err := s.After(context.Background(), "unknown", "", 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	runDue(t, s, 1)
+	if timer := onlyTimer(t, store); timer.Status != db.TimerFailed {
+		t.Errorf("timer = %+v, want failed", timer)
+	}
+}
+
+func TestSchedulerLeaseReclaim(t *testing.T) {
+	s, store, clock := newTestScheduler(t)
+	calls := 0
+	s.Handle("reminder", func(context.Context, *db.Timer) error {
+		calls++
+		return nil
+	})
+	if _, err := s.After(context.Background(), "reminder", "", 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A worker claims the timer and dies before recording the outcome
+	ctx := context.Background()
+	if claimed, err := store.ClaimDueTimers(ctx, "dead-worker", clock.Now(), s.Lease, s.BatchSize); err != nil || len(claimed) != 1 {
+		t.Fatalf("claim = %d timers, %v", len(claimed), err)
+	}
+
+	// Within the lease nobody else may fire it
+	clock.Advance(s.Lease)
+	runDue(t, s, 0)
+
+	clock.Advance(time.Second)
+	runDue(t, s, 1)
+	if calls != 1 {
+		t.Fatalf("handler called %d times, want 1", calls)
+	}
+	timer := onlyTimer(t, store)
+	if timer.Status != db.TimerDone || timer.Attempts != 2 {
+		t.Errorf("timer = %+v", timer)
+	}
+
+	// The original worker coming back cannot overwrite the outcome
+	for name, err := range map[string]error{
+		"complete": store.CompleteTimer(ctx, timer.ID, "dead-worker"),
+		"retry":    store.RetryTimer(ctx, timer.ID, "dead-worker", clock.Now(), "late"),
+		"fail":     store.FailTimer(ctx, timer.ID, "dead-worker", "late"),
+	} {
+		if !errors.Is(err, db.ErrTimerLeaseLost) {
+			t.Errorf("%s by the old worker: err = %v, want ErrTimerLeaseLost", name, err)
+		}
+	}
+	if timer := onlyTimer(t, store); timer.Status != db.TimerDone || timer.LastError != nil {
+		t.Errorf("timer after late outcome = %+v", timer)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s, store, clock := newTestScheduler(t)
+	s.Handle("reminder", func(context.Context, *db.Timer) error {
+		t.Error("cancelled timer fired")
+		return nil
+	})
+	ctx := context.Background()
+	for _, delay := range []time.Duration{time.Minute, time.Hour} {
+		if _, err := s.After(ctx, "reminder", "node_run:1", delay, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, err := s.After(ctx, "other", "node_run:2", time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := s.Cancel(ctx, "node_run:1"); err != nil || n != 2 {
+		t.Fatalf("Cancel = %d, %v; want 2", n, err)
+	}
+	if n, err := s.Cancel(ctx, ""); err != nil || n != 0 {
+		t.Errorf("Cancel without key = %d, %v; want 0", n, err)
+	}
+
+	var fired []string
+	s.Handle("other", func(_ context.Context, timer *db.Timer) error {
+		fired = append(fired, timer.ID)
+		return nil
+	})
+	clock.Advance(2 * time.Hour)
+	runDue(t, s, 1)
+	if len(fired) != 1 || fired[0] != other.ID {
+		t.Errorf("fired = %v, want only %s", fired, other.ID)
+	}
+	for _, timer := range store.Timers() {
+		if timer.Key == "node_run:1" && timer.Status != db.TimerCancelled {
+			t.Errorf("timer %s status = %s, want cancelled", timer.ID, timer.Status)
+		}
+	}
+
+	// Cancelling again finds nothing pending
+	if n, _ := s.Cancel(ctx, "node_run:1"); n != 0 {
+		t.Errorf("second Cancel = %d, want 0", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{7, 10 * time.Minute},
+		{1000, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
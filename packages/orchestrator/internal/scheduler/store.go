@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sunshow/workgear/orchestrator/internal/db"
+)
+
+// Store persists timers. *db.Client is the PostgreSQL store; MemoryStore keeps timers in
+// process for tests and single-process tools.
+type Store interface {
+	CreateTimer(ctx context.Context, t *db.Timer) error
+	ClaimDueTimers(ctx context.Context, workerID string, now time.Time, lease time.Duration, limit int) ([]*db.Timer, error)
+	// The outcome methods only update a timer still firing under workerID's lease, and
+	// return db.ErrTimerLeaseLost otherwise
+	CompleteTimer(ctx context.Context, id, workerID string) error
+	RetryTimer(ctx context.Context, id, workerID string, fireAt time.Time, lastError string) error
+	FailTimer(ctx context.Context, id, workerID, lastError string) error
+	CancelTimers(ctx context.Context, key string) (int, error)
+}
+
+var _ Store = (*db.Client)(nil)
+
+// MemoryStore is an in-memory Store with the same claim semantics as the database
+type MemoryStore struct {
+	mu     sync.Mutex
+	clock  Clock
+	timers map[string]*db.Timer
+}
+
+// NewMemoryStore creates an empty in-memory store; clock stamps creation and completion times
+func NewMemoryStore(clock Clock) *MemoryStore {
+	if clock == nil {
+		clock = RealClock
+	}
+	return &MemoryStore{clock: clock, timers: make(map[string]*db.Timer)}
+}
+
+// CreateTimer stores a pending timer
+func (m *MemoryStore) CreateTimer(_ context.Context, t *db.Timer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *t
+	stored.Payload = clonePayload(t.Payload)
+	stored.Status = db.TimerPending
+	stored.Attempts = 0
+	stored.CreatedAt = m.clock.Now()
+	m.timers[t.ID] = &stored
+	return nil
+}
+
+// ClaimDueTimers claims up to limit due (or abandoned) timers, earliest first
+func (m *MemoryStore) ClaimDueTimers(_ context.Context, workerID string, now time.Time, lease time.Duration, limit int) ([]*db.Timer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*db.Timer
+	for _, t := range m.timers {
+		switch {
+		case t.Status == db.TimerPending && !t.FireAt.After(now):
+		case t.Status == db.TimerFiring && t.LockedAt != nil && t.LockedAt.Before(now.Add(-lease)):
+		default:
+			continue
+		}
+		due = append(due, t)
+	}
+	slices.SortFunc(due, func(a, b *db.Timer) int { return a.FireAt.Compare(b.FireAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*db.Timer, 0, len(due))
+	for _, t := range due {
+		lockedAt := now
+		t.Status = db.TimerFiring
+		t.LockedBy = &workerID
+		t.LockedAt = &lockedAt
+		t.Attempts++
+		c := *t
+		c.Payload = clonePayload(t.Payload)
+		claimed = append(claimed, &c)
+	}
+	return claimed, nil
+}
+
+// CompleteTimer marks a timer fired by workerID done
+func (m *MemoryStore) CompleteTimer(_ context.Context, id, workerID string) error {
+	return m.finish(id, workerID, db.TimerDone, nil)
+}
+
+// RetryTimer puts a timer fired by workerID back to pending, due at fireAt
+func (m *MemoryStore) RetryTimer(_ context.Context, id, workerID string, fireAt time.Time, lastError string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, err := m.leased(id, workerID)
+	if err != nil {
+		return err
+	}
+	t.Status = db.TimerPending
+	t.FireAt = fireAt
+	t.LastError = &lastError
+	t.LockedBy, t.LockedAt = nil, nil
+	return nil
+}
+
+// FailTimer gives up on a timer fired by workerID
+func (m *MemoryStore) FailTimer(_ context.Context, id, workerID, lastError string) error {
+	return m.finish(id, workerID, db.TimerFailed, &lastError)
+}
+
+// CancelTimers cancels the pending timers with the given key
+func (m *MemoryStore) CancelTimers(_ context.Context, key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, t := range m.timers {
+		if key != "" && t.Key == key && t.Status == db.TimerPending {
+			now := m.clock.Now()
+			t.Status = db.TimerCancelled
+			t.CompletedAt = &now
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Timers returns a snapshot of every stored timer (for assertions in tests)
+func (m *MemoryStore) Timers() []db.Timer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	timers := make([]db.Timer, 0, len(m.timers))
+	for _, t := range m.timers {
+		timers = append(timers, *t)
+	}
+	slices.SortFunc(timers, func(a, b db.Timer) int { return a.FireAt.Compare(b.FireAt) })
+	return timers
+}
+
+func (m *MemoryStore) finish(id, workerID, status string, lastError *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, err := m.leased(id, workerID)
+	if err != nil {
+		return err
+	}
+	now := m.clock.Now()
+	t.Status = status
+	t.LockedBy, t.LockedAt = nil, nil
+	t.CompletedAt = &now
+	if lastError != nil {
+		t.LastError = lastError
+	}
+	return nil
+}
+
+// leased returns the timer if workerID still holds it (m.mu must be held)
+func (m *MemoryStore) leased(id, workerID string) (*db.Timer, error) {
+	t, ok := m.timers[id]
+	if !ok || t.Status != db.TimerFiring || t.LockedBy == nil || *t.LockedBy != workerID {
+		return nil, db.ErrTimerLeaseLost
+	}
+	return t, nil
+}
+
+// clonePayload copies a payload through JSON, as the database store does, so handlers see
+// the same types (numbers as float64) whichever store is used
+func clonePayload(payload map[string]any) map[string]any {
+	if payload == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	var out map[string]any
+	_ = json.Unmarshal(data, &out)
+	return out
+}
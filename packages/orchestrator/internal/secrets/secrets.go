@@ -0,0 +1,91 @@
+// Package secrets resolves named secrets that workflows reference as {{secrets.NAME}}, so
+// credentials for external systems never appear inline in a DSL.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound is returned by stores that have no secret of the given name
+var ErrNotFound = errors.New("secret not found")
+
+// namePattern is what a secret name may look like (also keeps DirStore inside its directory)
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidName reports whether name can be used as a secret name
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Store looks up secrets by name
+type Store interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// EnvStore reads secret NAME from the environment variable <Prefix>NAME
+type EnvStore struct {
+	Prefix string // e.g. "WORKGEAR_SECRET_"
+}
+
+func (s EnvStore) Get(_ context.Context, name string) (string, error) {
+	if !ValidName(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	value, ok := os.LookupEnv(s.Prefix + name)
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// DirStore reads secret NAME from the file <Dir>/NAME, the layout of Docker and Kubernetes
+// secret mounts. One trailing newline is trimmed.
+type DirStore struct {
+	Dir string // e.g. "/run/secrets"
+}
+
+func (s DirStore) Get(_ context.Context, name string) (string, error) {
+	if !ValidName(name) {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("read secret %s: %w", name, err)
+	}
+	value := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(value, "\r"), nil
+}
+
+// MapStore holds secrets in memory, for tests and single-process tools
+type MapStore map[string]string
+
+func (s MapStore) Get(_ context.Context, name string) (string, error) {
+	value, ok := s[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Chain looks a secret up in each store in turn and returns the first one found
+type Chain []Store
+
+func (c Chain) Get(ctx context.Context, name string) (string, error) {
+	for _, s := range c {
+		value, err := s.Get(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		return value, err
+	}
+	return "", ErrNotFound
+}
@@ -1,7 +1,7 @@
 import { useEffect, useState, useCallback, useRef } from 'react'
 import { Dialog, DialogContent, DialogHeader, DialogTitle } from '@/components/ui/dialog'
 import { Badge } from '@/components/ui/badge'
-import { Loader2, Brain, Wrench, CheckCircle } from 'lucide-react'
+import { Loader2, Brain, Wrench, CheckCircle, Terminal, Globe } from 'lucide-react'
 import { api } from '@/lib/api'
 import { useNodeLogStream, type LogStreamEvent } from '@/hooks/use-websocket'
 import { CodeBlock } from '@/components/code-block'
@@ -25,23 +25,45 @@ export function NodeLogDialog({ nodeRun, open, onClose }: NodeLogDialogProps) {
       return
     }
 
+    let cancelled = false
     setLoading(true)
-    api
-      .get(`node-runs/${nodeRun.id}/logs`)
-      .json<{ logs: LogStreamEvent[] }>()
-      .then((data) => {
-        setLogs(data.logs || [])
+
+    // Page through persisted logs; events pushed in the meantime are merged by seq
+    const loadAll = async () => {
+      const all: LogStreamEvent[] = []
+      let afterSeq = 0
+      for (;;) {
+        const data = await api
+          .get(`node-runs/${nodeRun.id}/logs`, { searchParams: { afterSeq, limit: 1000 } })
+          .json<{ logs: LogStreamEvent[]; nextSeq: number; hasMore: boolean }>()
+        all.push(...(data.logs || []))
+        if (!data.hasMore || cancelled) break
+        afterSeq = data.nextSeq
+      }
+      return all
+    }
+
+    loadAll()
+      .then((history) => {
+        if (cancelled) return
+        setLogs((live) => mergeLogs(history, live))
       })
       .catch((err) => {
         console.error('Failed to load logs:', err)
-        setLogs([])
+        if (!cancelled) setLogs([])
       })
-      .finally(() => setLoading(false))
+      .finally(() => {
+        if (!cancelled) setLoading(false)
+      })
+
+    return () => {
+      cancelled = true
+    }
   }, [nodeRun?.id, open])
 
   // Real-time subscription for running nodes
   const handleNewLog = useCallback((event: LogStreamEvent) => {
-    setLogs((prev) => [...prev, event])
+    setLogs((prev) => mergeLogs(prev, [event]))
   }, [])
 
   useNodeLogStream(nodeRun?.status === 'running' ? nodeRun.id : undefined, handleNewLog)
@@ -147,6 +169,29 @@ function LogEntry({ event }: { event: LogStreamEvent }) {
         </div>
       )
 
+    case 'stdout':
+    case 'stderr':
+      // 脚本节点的输出行
+      return (
+        <div className="flex gap-2 px-1 font-mono text-xs">
+          <span className="shrink-0 text-muted-foreground">{time}</span>
+          <Terminal className={`mt-0.5 h-3 w-3 shrink-0 ${event.type === 'stderr' ? 'text-red-500' : 'text-muted-foreground'}`} />
+          <span className={`whitespace-pre-wrap break-all ${event.type === 'stderr' ? 'text-red-600' : ''}`}>
+            {event.content}
+          </span>
+        </div>
+      )
+
+    case 'http_request':
+      // HTTP 请求节点的每次尝试
+      return (
+        <div className="flex gap-2 px-1 font-mono text-xs">
+          <span className="shrink-0 text-muted-foreground">{time}</span>
+          <Globe className="mt-0.5 h-3 w-3 shrink-0 text-muted-foreground" />
+          <span className="whitespace-pre-wrap break-all">{event.content}</span>
+        </div>
+      )
+
     case 'result':
       return (
         <div className="rounded-lg border bg-gray-50 p-3">
@@ -171,3 +216,15 @@ function LogEntry({ event }: { event: LogStreamEvent }) {
       )
   }
 }
+
+// mergeLogs appends events not already present (by seq); events without seq are kept as-is
+function mergeLogs(base: LogStreamEvent[], extra: LogStreamEvent[]): LogStreamEvent[] {
+  const seen = new Set(base.map((e) => e.seq).filter((seq) => seq !== undefined))
+  const merged = [...base]
+  for (const event of extra) {
+    if (event.seq !== undefined && seen.has(event.seq)) continue
+    if (event.seq !== undefined) seen.add(event.seq)
+    merged.push(event)
+  }
+  return merged
+}
@@ -136,6 +136,12 @@ export function useFlowRunEvents(flowRunId: string | null | undefined, handlers:
   onNodeStarted?: (data: Record<string, unknown>) => void
   onNodeCompleted?: (data: Record<string, unknown>) => void
   onNodeWaitingHuman?: (data: Record<string, unknown>) => void
+  onNodeWaitingTimer?: (data: Record<string, unknown>) => void
+  onNodeToolApprovalRequested?: (data: Record<string, unknown>) => void
+  onNodeToolApprovalResolved?: (data: Record<string, unknown>) => void
+  onNodeApprovalProgress?: (data: Record<string, unknown>) => void
+  onNodeReviewReminder?: (data: Record<string, unknown>) => void
+  onNodeReviewEscalated?: (data: Record<string, unknown>) => void
   onNodeFailed?: (data: Record<string, unknown>) => void
   onNodeRejected?: (data: Record<string, unknown>) => void
   onNodeCancelled?: (data: Record<string, unknown>) => void
@@ -158,6 +164,12 @@ export function useFlowRunEvents(flowRunId: string | null | undefined, handlers:
       case 'node.started': h.onNodeStarted?.(data); break
       case 'node.completed': h.onNodeCompleted?.(data); break
       case 'node.waiting_human': h.onNodeWaitingHuman?.(data); break
+      case 'node.waiting_timer': h.onNodeWaitingTimer?.(data); break
+      case 'node.tool_approval_requested': h.onNodeToolApprovalRequested?.(data); break
+      case 'node.tool_approval_resolved': h.onNodeToolApprovalResolved?.(data); break
+      case 'node.approval_progress': h.onNodeApprovalProgress?.(data); break
+      case 'node.review_reminder': h.onNodeReviewReminder?.(data); break
+      case 'node.review_escalated': h.onNodeReviewEscalated?.(data); break
       case 'node.failed': h.onNodeFailed?.(data); break
       case 'node.rejected': h.onNodeRejected?.(data); break
       case 'node.cancelled': h.onNodeCancelled?.(data); break
@@ -178,6 +190,8 @@ export interface LogStreamEvent {
   tool_name?: string
   tool_input?: Record<string, any>
   timestamp: number
+  seq?: number
+  truncated?: boolean
 }
 
 /**
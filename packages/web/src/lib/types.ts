@@ -162,19 +162,52 @@ export interface NodeRun {
   nodeId: string
   nodeType: string | null
   nodeName: string | null
-  status: 'pending' | 'queued' | 'running' | 'completed' | 'failed' | 'rejected' | 'waiting_human' | 'cancelled'
+  status: 'pending' | 'queued' | 'running' | 'completed' | 'failed' | 'rejected' | 'waiting_human' | 'waiting_tool_approval' | 'waiting_timer' | 'cancelled'
   attempt: number
   input: Record<string, any> | null
   output: Record<string, any> | null
   error: string | null
   reviewAction: string | null
   reviewComment: string | null
+  reviewedBy: string | null
   reviewedAt: string | null
   startedAt: string | null
   completedAt: string | null
   createdAt: string
 }
 
+// Agent 工具调用审批（节点 tool_approval 规则命中的调用）
+export interface ToolApproval {
+  id: string
+  nodeRunId: string
+  toolName: string
+  toolInput: Record<string, any> | null
+  rule: string | null
+  reason: string | null
+  status: 'pending' | 'approved' | 'denied' | 'expired'
+  decisionReason: string | null
+  decidedBy: string | null
+  requestedAt: string
+  decidedAt: string | null
+}
+
+// 人工审核记录（每次审核一行）
+export interface NodeRunReview {
+  id: string
+  nodeRunId: string
+  flowRunId: string
+  nodeId: string
+  attempt: number
+  action: 'approve' | 'reject' | 'edit_and_approve'
+  comment: string | null
+  severity: 'info' | 'minor' | 'major' | 'critical' | null
+  comments: { file?: string; line?: number; end_line?: number; body: string; severity?: string }[]
+  checklist: { item: string; passed: boolean; note?: string }[]
+  reviewerId: string | null
+  reviewerName: string | null
+  createdAt: string
+}
+
 // Artifact types
 export interface Artifact {
   id: string
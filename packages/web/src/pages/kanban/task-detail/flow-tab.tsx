@@ -1,12 +1,12 @@
 import { useEffect, useState, useCallback } from 'react'
 import { api } from '@/lib/api'
-import type { FlowRun, NodeRun, Artifact } from '@/lib/types'
+import type { FlowRun, NodeRun, Artifact, ToolApproval, NodeRunReview } from '@/lib/types'
 import { Badge } from '@/components/ui/badge'
 import { Button } from '@/components/ui/button'
 import { Textarea } from '@/components/ui/textarea'
 import { Input } from '@/components/ui/input'
 import { useFlowRunEvents } from '@/hooks/use-websocket'
-import { XCircle, CheckCircle, RotateCcw, Clock, Play, AlertCircle, Pencil, Loader2, FileText } from 'lucide-react'
+import { XCircle, CheckCircle, RotateCcw, Clock, Play, AlertCircle, Pencil, Loader2, FileText, ShieldAlert, Timer } from 'lucide-react'
 import { NodeLogDialog } from '@/components/node-log-dialog'
 import { CodeBlock } from '@/components/code-block'
 import { ArtifactPreviewCard } from '@/components/artifact-preview-card'
@@ -27,6 +27,8 @@ const statusLabels: Record<string, string> = {
   cancelled: '已取消',
   rejected: '已拒绝',
   waiting_human: '等待人工',
+  waiting_tool_approval: '等待工具审批',
+  waiting_timer: '等待定时',
 }
 
 const statusColors: Record<string, 'default' | 'secondary' | 'destructive' | 'outline'> = {
@@ -38,6 +40,8 @@ const statusColors: Record<string, 'default' | 'secondary' | 'destructive' | 'ou
   cancelled: 'outline',
   rejected: 'destructive',
   waiting_human: 'default',
+  waiting_tool_approval: 'default',
+  waiting_timer: 'outline',
 }
 
 const statusIcons: Record<string, React.ReactNode> = {
@@ -49,6 +53,8 @@ const statusIcons: Record<string, React.ReactNode> = {
   cancelled: <XCircle className="h-4 w-4 text-muted-foreground" />,
   rejected: <RotateCcw className="h-4 w-4 text-orange-500" />,
   waiting_human: <Pencil className="h-4 w-4 text-yellow-500" />,
+  waiting_tool_approval: <ShieldAlert className="h-4 w-4 text-yellow-500" />,
+  waiting_timer: <Timer className="h-4 w-4 text-blue-500" />,
 }
 
 export function FlowTab({ taskId, refreshKey }: FlowTabProps) {
@@ -92,6 +98,10 @@ export function FlowTab({ taskId, refreshKey }: FlowTabProps) {
     onNodeStarted: () => refreshNodeRuns(),
     onNodeCompleted: () => refreshNodeRuns(),
     onNodeWaitingHuman: () => refreshNodeRuns(),
+    onNodeWaitingTimer: () => refreshNodeRuns(),
+    onNodeToolApprovalRequested: () => refreshNodeRuns(),
+    onNodeToolApprovalResolved: () => refreshNodeRuns(),
+    onNodeApprovalProgress: () => refreshNodeRuns(),
     onNodeFailed: () => refreshNodeRuns(),
     onNodeRejected: () => refreshNodeRuns(),
     onNodeCancelled: () => refreshNodeRuns(),
@@ -234,18 +244,20 @@ function NodeRunItem({ nodeRun, flowStatus, onActionComplete, onViewLogs, artifa
   artifactRefreshKey: number
   onEditArtifact: (artifact: Artifact, content: string, version: number) => void
 }) {
-  const [expanded, setExpanded] = useState(nodeRun.status === 'waiting_human')
+  const isWaiting = nodeRun.status === 'waiting_human' || nodeRun.status === 'waiting_tool_approval'
+  const [expanded, setExpanded] = useState(isWaiting)
   const [feedback, setFeedback] = useState('')
   const [submitting, setSubmitting] = useState(false)
   const [nodeArtifacts, setNodeArtifacts] = useState<Artifact[]>([])
+  const [approvedBy, setApprovedBy] = useState<string[]>([])
   const isFlowTerminal = flowStatus === 'cancelled' || flowStatus === 'completed'
 
   // Auto-expand when waiting for human
   useEffect(() => {
-    if (nodeRun.status === 'waiting_human') {
+    if (isWaiting) {
       setExpanded(true)
     }
-  }, [nodeRun.status])
+  }, [isWaiting])
 
   // Load artifacts when expanded
   useEffect(() => {
@@ -254,6 +266,16 @@ function NodeRunItem({ nodeRun, flowStatus, onActionComplete, onViewLogs, artifa
     }
   }, [expanded, nodeRun.id, artifactRefreshKey])
 
+  // Partial approvals of multi-approver reviews
+  useEffect(() => {
+    if (nodeRun.status !== 'waiting_human' || nodeRun.nodeType !== 'human_review') return
+    api.get(`node-runs/${nodeRun.id}/reviews`).json<NodeRunReview[]>()
+      .then((reviews) => setApprovedBy(reviews
+        .filter((r) => r.nodeRunId === nodeRun.id && r.action === 'approve')
+        .map((r) => r.reviewerName || r.reviewerId || '')))
+      .catch((error) => console.error('Failed to load reviews:', error))
+  }, [nodeRun.id, nodeRun.status, nodeRun.nodeType, nodeRun.reviewedAt])
+
   async function loadNodeArtifacts() {
     try {
       const data = await api.get(`artifacts?nodeRunId=${nodeRun.id}`).json<Artifact[]>()
@@ -294,7 +316,7 @@ function NodeRunItem({ nodeRun, flowStatus, onActionComplete, onViewLogs, artifa
   }
 
   const displayName = nodeRun.nodeName || nodeRun.nodeId
-  const isClickable = nodeRun.status === 'waiting_human' || nodeRun.status === 'completed' || nodeRun.status === 'failed'
+  const isClickable = isWaiting || nodeRun.status === 'completed' || nodeRun.status === 'failed'
 
   return (
     <div className="rounded-md border">
@@ -318,7 +340,7 @@ function NodeRunItem({ nodeRun, flowStatus, onActionComplete, onViewLogs, artifa
         <Badge variant={statusColors[nodeRun.status] || 'outline'} className="text-xs shrink-0">
           {statusLabels[nodeRun.status] || nodeRun.status}
         </Badge>
-        {nodeRun.nodeType === 'agent_task' && (
+        {(nodeRun.nodeType === 'agent_task' || nodeRun.nodeType === 'script' || nodeRun.nodeType === 'shell' || nodeRun.nodeType === 'http_request') && (
           <Button
             size="sm"
             variant="ghost"
@@ -380,6 +402,9 @@ function NodeRunItem({ nodeRun, flowStatus, onActionComplete, onViewLogs, artifa
           {/* Review actions for waiting_human */}
           {nodeRun.status === 'waiting_human' && nodeRun.nodeType === 'human_review' && !isFlowTerminal && (
             <div className="space-y-2">
+              {approvedBy.length > 0 && (
+                <p className="text-xs text-muted-foreground">已通过（{approvedBy.length}）：{approvedBy.join('、')}</p>
+              )}
               <Textarea
                 placeholder="输入反馈（拒绝时必填）..."
                 value={feedback}
@@ -410,13 +435,20 @@ function NodeRunItem({ nodeRun, flowStatus, onActionComplete, onViewLogs, artifa
                 })
                 onActionComplete()
               } catch (error: any) {
-                alert(`提交失败: ${error.message}`)
+                const body = await error.response?.json?.().catch(() => null)
+                if (body?.fieldErrors) return body.fieldErrors
+                alert(`提交失败: ${body?.error || error.message}`)
               } finally {
                 setSubmitting(false)
               }
             }} submitting={submitting} />
           )}
 
+          {/* Pending tool calls of an agent blocked on approval */}
+          {nodeRun.status === 'waiting_tool_approval' && !isFlowTerminal && (
+            <ToolApprovalPanel nodeRun={nodeRun} onActionComplete={onActionComplete} />
+          )}
+
           {/* Flow cancelled hint for waiting_human nodes */}
           {nodeRun.status === 'waiting_human' && isFlowTerminal && (
             <p className="text-xs text-muted-foreground">流程已取消，无法操作</p>
@@ -481,28 +513,138 @@ function filterInternalFields(output: any): any {
 
 interface FormFieldDef {
   field: string
-  type: 'text' | 'textarea' | 'select' | 'number'
+  type: 'text' | 'textarea' | 'select' | 'multi_select' | 'number' | 'boolean' | 'date' | 'file_upload'
   label: string
   required?: boolean
   options?: string[]
+  default?: any
+  pattern?: string
+  min?: number
+  max?: number
+}
+
+type FormValue = string | string[] | boolean
+
+function initialFormData(fields: FormFieldDef[]): Record<string, FormValue> {
+  const data: Record<string, FormValue> = {}
+  for (const f of fields) {
+    if (f.default === undefined || f.default === null) continue
+    data[f.field] = f.type === 'multi_select'
+      ? (Array.isArray(f.default) ? f.default.map(String) : String(f.default).split(',').map(s => s.trim()).filter(Boolean))
+      : f.type === 'boolean' ? Boolean(f.default) : String(f.default)
+  }
+  return data
+}
+
+function hasValue(value: FormValue | undefined): boolean {
+  if (Array.isArray(value)) return value.length > 0
+  if (typeof value === 'boolean') return true
+  return !!value?.trim()
+}
+
+function ToolApprovalPanel({ nodeRun, onActionComplete }: {
+  nodeRun: NodeRun
+  onActionComplete: () => void
+}) {
+  const [approvals, setApprovals] = useState<ToolApproval[]>([])
+  const [reason, setReason] = useState('')
+  const [submitting, setSubmitting] = useState(false)
+
+  useEffect(() => {
+    api.get(`node-runs/${nodeRun.id}/tool-approvals`).json<ToolApproval[]>()
+      .then(setApprovals)
+      .catch((error) => console.error('Failed to load tool approvals:', error))
+  }, [nodeRun]) // node runs are refetched on every tool approval event
+
+  async function handleDecision(approvalId: string, action: 'approve' | 'deny') {
+    setSubmitting(true)
+    try {
+      await api.post(`node-runs/${nodeRun.id}/tool-approvals/${approvalId}`, {
+        json: { action, reason: reason || undefined },
+      })
+      setReason('')
+      onActionComplete()
+    } catch (error: any) {
+      alert(`操作失败: ${error.message}`)
+    } finally {
+      setSubmitting(false)
+    }
+  }
+
+  const pending = approvals.filter((a) => a.status === 'pending')
+  if (pending.length === 0) return null
+
+  return (
+    <div className="space-y-3">
+      {pending.map((approval) => (
+        <div key={approval.id} className="space-y-2 rounded-md border border-yellow-500/50 p-2">
+          <p className="text-xs font-medium">
+            工具调用待审批：{approval.toolName}
+            {approval.reason && <span className="ml-1 text-muted-foreground">（{approval.reason}）</span>}
+          </p>
+          <CodeBlock
+            code={JSON.stringify(approval.toolInput, null, 2)}
+            language="json"
+            maxHeight="12rem"
+          />
+          {approval.rule && <p className="text-xs text-muted-foreground">命中规则：{approval.rule}</p>}
+          <div className="flex gap-2">
+            <Button size="sm" onClick={() => handleDecision(approval.id, 'approve')} disabled={submitting}>
+              <CheckCircle className="mr-1 h-3 w-3" />
+              允许
+            </Button>
+            <Button size="sm" variant="destructive" onClick={() => handleDecision(approval.id, 'deny')} disabled={submitting}>
+              <XCircle className="mr-1 h-3 w-3" />
+              拒绝
+            </Button>
+          </div>
+        </div>
+      ))}
+      <Textarea
+        placeholder="拒绝原因（可选，会反馈给 Agent）..."
+        value={reason}
+        onChange={(e) => setReason(e.target.value)}
+        rows={2}
+        className="text-sm"
+      />
+    </div>
+  )
 }
 
 function HumanInputForm({ nodeRun, onSubmit, submitting }: {
   nodeRun: NodeRun
-  onSubmit: (data: Record<string, string>) => void
+  // Resolves to per-field errors when the orchestrator rejects the submission
+  onSubmit: (data: Record<string, any>) => Promise<Record<string, string> | void>
   submitting: boolean
 }) {
-  // Try to extract form definition from input (set by WebSocket event)
-  const formFields: FormFieldDef[] = nodeRun.input?.form || []
-  const [formData, setFormData] = useState<Record<string, string>>({})
+  // Resolved form stored by the orchestrator when the node started waiting (options_from and
+  // templated defaults already expanded)
+  const formFields: FormFieldDef[] = nodeRun.input?._form || nodeRun.input?.form || []
+  const [formData, setFormData] = useState<Record<string, FormValue>>(() => initialFormData(formFields))
+  const [fieldErrors, setFieldErrors] = useState<Record<string, string>>({})
 
-  const updateField = (field: string, value: string) => {
+  const updateField = (field: string, value: FormValue) => {
     setFormData(prev => ({ ...prev, [field]: value }))
+    setFieldErrors(prev => {
+      const { [field]: _, ...rest } = prev
+      return rest
+    })
+  }
+
+  const toggleOption = (field: string, option: string, checked: boolean) => {
+    const current = (formData[field] as string[] | undefined) || []
+    updateField(field, checked ? [...current, option] : current.filter(o => o !== option))
+  }
+
+  async function handleSubmit(data: Record<string, any>) {
+    setFieldErrors({})
+    const errors = await onSubmit(data)
+    if (errors) setFieldErrors(errors)
   }
 
   const isValid = formFields.length > 0
-    ? formFields.filter(f => f.required).every(f => formData[f.field]?.trim())
-    : formData._text?.trim()
+    ? formFields.filter(f => f.required).every(f => hasValue(formData[f.field]))
+    : hasValue(formData._text)
 
   // Fallback: single textarea if no form definition
   if (formFields.length === 0) {
@@ -510,12 +652,12 @@ function HumanInputForm({ nodeRun, onSubmit, submitting }: {
       <div className="space-y-2">
         <Textarea
           placeholder="输入内容..."
-          value={formData._text || ''}
+          value={(formData._text as string) || ''}
           onChange={(e) => updateField('_text', e.target.value)}
           rows={3}
           className="text-sm"
         />
-        <Button size="sm" onClick={() => onSubmit({ text: formData._text || '' })} disabled={submitting || !isValid}>
+        <Button size="sm" onClick={() => handleSubmit({ text: formData._text || '' })} disabled={submitting || !isValid}>
           <Play className="mr-1 h-3 w-3" />
           提交
         </Button>
@@ -528,13 +670,13 @@ function HumanInputForm({ nodeRun, onSubmit, submitting }: {
       {formFields.map((field) => (
         <div key={field.field} className="space-y-1">
           <label className="text-xs font-medium text-muted-foreground">
-            {field.label}
+            {field.label || field.field}
             {field.required && <span className="text-destructive ml-0.5">*</span>}
           </label>
           {field.type === 'textarea' ? (
             <Textarea
               placeholder={field.label}
-              value={formData[field.field] || ''}
+              value={(formData[field.field] as string) || ''}
               onChange={(e) => updateField(field.field, e.target.value)}
               rows={3}
               className="text-sm"
@@ -542,7 +684,7 @@ function HumanInputForm({ nodeRun, onSubmit, submitting }: {
           ) : field.type === 'select' && field.options ? (
             <select
               className="flex h-9 w-full rounded-md border border-input bg-transparent px-3 py-1 text-sm shadow-sm"
-              value={formData[field.field] || ''}
+              value={(formData[field.field] as string) || ''}
               onChange={(e) => updateField(field.field, e.target.value)}
             >
               <option value="">请选择...</option>
@@ -550,18 +692,45 @@ function HumanInputForm({ nodeRun, onSubmit, submitting }: {
                 <option key={opt} value={opt}>{opt}</option>
               ))}
             </select>
+          ) : field.type === 'multi_select' && field.options ? (
+            <div className="flex flex-wrap gap-3">
+              {field.options.map(opt => (
+                <label key={opt} className="flex items-center gap-1 text-sm">
+                  <input
+                    type="checkbox"
+                    checked={((formData[field.field] as string[] | undefined) || []).includes(opt)}
+                    onChange={(e) => toggleOption(field.field, opt, e.target.checked)}
+                  />
+                  {opt}
+                </label>
+              ))}
+            </div>
+          ) : field.type === 'boolean' ? (
+            <label className="flex items-center gap-1 text-sm">
+              <input
+                type="checkbox"
+                checked={formData[field.field] === true}
+                onChange={(e) => updateField(field.field, e.target.checked)}
+              />
+              {field.label || field.field}
+            </label>
           ) : (
             <Input
-              type={field.type === 'number' ? 'number' : 'text'}
+              type={field.type === 'number' ? 'number' : field.type === 'date' ? 'date' : 'text'}
               placeholder={field.label}
-              value={formData[field.field] || ''}
+              value={(formData[field.field] as string) || ''}
               onChange={(e) => updateField(field.field, e.target.value)}
+              min={field.type === 'number' ? field.min : undefined}
+              max={field.type === 'number' ? field.max : undefined}
               className="text-sm"
             />
           )}
+          {fieldErrors[field.field] && (
+            <p className="text-xs text-destructive">{fieldErrors[field.field]}</p>
+          )}
         </div>
       ))}
-      <Button size="sm" onClick={() => onSubmit(formData)} disabled={submitting || !isValid}>
+      <Button size="sm" onClick={() => handleSubmit(formData)} disabled={submitting || !isValid}>
         <Play className="mr-1 h-3 w-3" />
         提交
       </Button>
@@ -14,6 +14,15 @@ const eventTypeLabels: Record<string, string> = {
   review_action: 'Review 操作',
   git_event: 'Git 事件',
   system_event: '系统事件',
+  review_reminder: '待处理提醒',
+  review_escalated: '已升级',
+  waiting_timer: '等待定时',
+  wait_completed: '等待结束',
+  flow_scheduled: '定时启动',
+  flow_schedule_skipped: '定时跳过',
+  script_completed: '脚本完成',
+  script_rejected: '脚本打回',
+  http_request_completed: 'HTTP 请求',
 }
 
 const eventTypeColors: Record<string, 'default' | 'secondary' | 'destructive' | 'outline'> = {
@@ -23,6 +32,15 @@ const eventTypeColors: Record<string, 'default' | 'secondary' | 'destructive' |
   review_action: 'destructive',
   git_event: 'secondary',
   system_event: 'outline',
+  review_reminder: 'secondary',
+  review_escalated: 'destructive',
+  waiting_timer: 'outline',
+  wait_completed: 'secondary',
+  flow_scheduled: 'default',
+  flow_schedule_skipped: 'outline',
+  script_completed: 'secondary',
+  script_rejected: 'destructive',
+  http_request_completed: 'secondary',
 }
 
 export function TimelineTab({ taskId }: TimelineTabProps) {
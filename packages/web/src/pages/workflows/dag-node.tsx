@@ -1,6 +1,6 @@
 import { memo } from 'react'
 import { Handle, Position, type NodeProps } from '@xyflow/react'
-import { User, Bot, GitBranch, Users, Plug } from 'lucide-react'
+import { User, Bot, GitBranch, Users, Plug, Terminal, Globe } from 'lucide-react'
 import { getNodeTypeColor, getNodeTypeLabel } from './dsl-parser'
 
 interface DagNodeData {
@@ -19,6 +19,9 @@ const typeIcons: Record<string, React.ReactNode> = {
   agent_task: <Bot className="h-3.5 w-3.5" />,
   parallel_group: <Users className="h-3.5 w-3.5" />,
   integration: <Plug className="h-3.5 w-3.5" />,
+  script: <Terminal className="h-3.5 w-3.5" />,
+  shell: <Terminal className="h-3.5 w-3.5" />,
+  http_request: <Globe className="h-3.5 w-3.5" />,
 }
 
 export const DagNode = memo(function DagNode({ data }: NodeProps) {
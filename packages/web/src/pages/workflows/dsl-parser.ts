@@ -28,6 +28,9 @@ const VALID_NODE_TYPES = [
   'agent_task',
   'parallel_group',
   'integration',
+  'script',
+  'shell',
+  'http_request',
 ]
 
 export function parseDsl(yamlStr: string): ParseResult {
@@ -209,6 +212,9 @@ export function getNodeTypeLabel(type: string): string {
     agent_task: 'Agent 任务',
     parallel_group: '并行组',
     integration: '外部集成',
+    script: '脚本',
+    shell: '脚本',
+    http_request: 'HTTP 请求',
   }
   return labels[type] || type
 }
@@ -220,6 +226,9 @@ export function getNodeTypeColor(type: string): string {
     agent_task: '#10b981',
     parallel_group: '#8b5cf6',
     integration: '#6366f1',
+    script: '#475569',
+    shell: '#475569',
+    http_request: '#0ea5e9',
   }
   return colors[type] || '#6b7280'
 }
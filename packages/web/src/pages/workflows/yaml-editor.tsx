@@ -18,10 +18,13 @@ const DSL_KEYWORDS = [
   'role', 'model', 'from', 'to',
   'create_branch', 'branch_pattern', 'auto_commit', 'run_tests',
   'max_attempts', 'backoff', 'execution_mode', 'foreach', 'as', 'max_concurrency',
+  'run', 'image', 'executor', 'env', 'output_file', 'on_failure',
+  'method', 'url', 'headers', 'body', 'json', 'allow_status', 'outputs',
 ]
 
 const NODE_TYPES = [
-  'human_input', 'human_review', 'agent_task', 'parallel_group', 'integration',
+  'human_input', 'human_review', 'agent_task', 'parallel_group', 'integration', 'script',
+  'http_request',
 ]
 
 const AGENT_MODES = ['spec', 'execute', 'review']